@@ -26,7 +26,6 @@ import (
 	"github.com/ava-labs/go-ethereum/accounts/abi"
 	"github.com/ava-labs/go-ethereum/common"
 	"github.com/ava-labs/go-ethereum/core/types"
-	"github.com/ava-labs/go-ethereum/crypto"
 	"github.com/ava-labs/go-ethereum/event"
 )
 
@@ -109,7 +108,7 @@ func DeployContract(opts *TransactOpts, abi abi.ABI, bytecode []byte, backend Co
 	if err != nil {
 		return common.Address{}, nil, nil, err
 	}
-	c.address = crypto.CreateAddress(opts.From, tx.Nonce())
+	c.address = types.CreateAddress(opts.From, tx.Nonce())
 	return c.address, tx, c, nil
 }
 
@@ -91,6 +91,10 @@ var (
 		Name:  "prestate",
 		Usage: "JSON file with prestate (genesis) config",
 	}
+	ChainFlag = cli.StringFlag{
+		Name:  "chain",
+		Usage: "Name or chain ID of a registered chain config to execute with",
+	}
 	MachineFlag = cli.BoolFlag{
 		Name:  "json",
 		Usage: "output trace logs in machine readable format (json)",
@@ -134,6 +138,7 @@ func init() {
 		CPUProfileFlag,
 		StatDumpFlag,
 		GenesisFlag,
+		ChainFlag,
 		MachineFlag,
 		SenderFlag,
 		ReceiverFlag,
@@ -68,6 +68,21 @@ func readGenesis(genesisPath string) *core.Genesis {
 	return genesis
 }
 
+// readChainConfig will look up the registered chain config with the given
+// name or chain ID.
+func readChainConfig(chain string) *params.ChainConfig {
+	if id, ok := new(big.Int).SetString(chain, 10); ok {
+		if config, ok := params.ChainConfigByID(id); ok {
+			return config
+		}
+	}
+	if config, ok := params.ChainConfigByName(chain); ok {
+		return config
+	}
+	utils.Fatalf("Unknown chain %q (registered: %v)", chain, params.ChainPresetNames())
+	return nil
+}
+
 func runCmd(ctx *cli.Context) error {
 	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
 	glogger.Verbosity(log.Lvl(ctx.GlobalInt(VerbosityFlag.Name)))
@@ -106,6 +121,9 @@ func runCmd(ctx *cli.Context) error {
 		statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
 		genesisConfig = new(core.Genesis)
 	}
+	if chain := ctx.GlobalString(ChainFlag.Name); chain != "" {
+		chainConfig = readChainConfig(chain)
+	}
 	if ctx.GlobalString(SenderFlag.Name) != "" {
 		sender = common.HexToAddress(ctx.GlobalString(SenderFlag.Name))
 	}
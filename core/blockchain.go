@@ -437,6 +437,8 @@ func (bc *BlockChain) SetHead(head uint64) error {
 			rawdb.DeleteBody(db, hash, num)
 			rawdb.DeleteReceipts(db, hash, num)
 		}
+		// Implicit receipts are kept in the active store in both cases.
+		rawdb.DeleteImplicitReceipt(db, hash, num)
 		// Todo(rjl493456442) txlookup, bloombits, etc
 	}
 	bc.hc.SetHead(head, updateFn, delFn)
@@ -764,6 +766,16 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	return receipts
 }
 
+// GetImplicitReceiptByHash retrieves the receipt of the implicit messages
+// executed at the boundaries of a block, or nil if none were executed.
+func (bc *BlockChain) GetImplicitReceiptByHash(hash common.Hash) *types.Receipt {
+	number := rawdb.ReadHeaderNumber(bc.db, hash)
+	if number == nil {
+		return nil
+	}
+	return rawdb.ReadImplicitReceipt(bc.db, hash, *number)
+}
+
 // GetBlocksFromHash returns the block corresponding to hash and up to n-1 ancestors.
 // [deprecated by eth/62]
 func (bc *BlockChain) GetBlocksFromHash(hash common.Hash, n int) (blocks []*types.Block) {
@@ -829,6 +841,9 @@ func (bc *BlockChain) Stop() {
 				if err := triedb.Commit(recent.Root(), true); err != nil {
 					log.Error("Failed to commit recent state trie", "err", err)
 				}
+				if err := commitRetainedTries(triedb, recent.Header(), true); err != nil {
+					log.Error("Failed to commit retained tries", "err", err)
+				}
 			}
 		}
 		for !bc.triegc.Empty() {
@@ -1292,10 +1307,14 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 		if err := triedb.Commit(root, false); err != nil {
 			return NonStatTy, err
 		}
+		if err := commitRetainedTries(triedb, block.Header(), false); err != nil {
+			return NonStatTy, err
+		}
 	} else {
 		// Full but not archive node, do proper garbage collection
 		triedb.Reference(root, common.Hash{}) // metadata reference to keep trie alive
 		bc.triegc.Push(root, -int64(block.NumberU64()))
+		bc.pinRetainedTries(triedb, block)
 
 		if current := block.NumberU64(); current > TriesInMemory {
 			// If we exceeded our memory allowance, flush matured singleton nodes to disk
@@ -1324,6 +1343,7 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 					}
 					// Flush an entire trie and restart the counters
 					triedb.Commit(header.Root, true)
+					commitRetainedTries(triedb, header, true)
 					lastWrite = chosen
 					bc.gcproc = 0
 				}
@@ -1343,6 +1363,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	// Write other block data using a batch.
 	batch := bc.db.NewBatch()
 	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+	if receipt := state.ImplicitReceipt(); receipt != nil {
+		rawdb.WriteImplicitReceipt(batch, block.Hash(), block.NumberU64(), receipt)
+	}
 
 	// If the total difficulty is higher than our known, add it to the canonical chain
 	// Second clause in the if statement reduces the vulnerability to selfish mining.
@@ -22,7 +22,7 @@ import (
 
 	"github.com/ava-labs/go-ethereum/common"
 	"github.com/ava-labs/go-ethereum/consensus"
-	"github.com/ava-labs/go-ethereum/consensus/misc"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
 	"github.com/ava-labs/go-ethereum/core/state"
 	"github.com/ava-labs/go-ethereum/core/types"
 	"github.com/ava-labs/go-ethereum/core/vm"
@@ -44,8 +44,10 @@ type BlockGen struct {
 	receipts []*types.Receipt
 	uncles   []*types.Header
 
-	config *params.ChainConfig
-	engine consensus.Engine
+	config  *params.ChainConfig
+	engine  consensus.Engine
+	bc      ChainContext // Context of the implicit messages of the block
+	started bool         // Whether the preamble of the block was applied
 }
 
 // SetCoinbase sets the coinbase of the generated block.
@@ -58,7 +60,7 @@ func (b *BlockGen) SetCoinbase(addr common.Address) {
 		panic("coinbase can only be set once")
 	}
 	b.header.Coinbase = addr
-	b.gasPool = new(GasPool).AddGas(b.header.GasLimit)
+	b.gasPool = NewBlockGasPool(b.config, b.header)
 }
 
 // SetExtra sets the extra data field of the generated block.
@@ -102,6 +104,7 @@ func (b *BlockGen) AddTxWithChain(bc *BlockChain, tx *types.Transaction) {
 	if b.gasPool == nil {
 		b.SetCoinbase(common.Address{})
 	}
+	b.start()
 	b.statedb.Prepare(tx.Hash(), common.Hash{}, len(b.txs))
 	receipt, _, err := ApplyTransaction(b.config, bc, &b.header.Coinbase, b.gasPool, b.statedb, b.header, tx, &b.header.GasUsed, vm.Config{})
 	if err != nil {
@@ -111,6 +114,20 @@ func (b *BlockGen) AddTxWithChain(bc *BlockChain, tx *types.Transaction) {
 	b.receipts = append(b.receipts, receipt)
 }
 
+// start applies the preamble of the block unless already done. It is deferred
+// until the coinbase is final, so that the implicit messages of the block start
+// see the same author as the transactions and those of the block end, like
+// during block processing.
+func (b *BlockGen) start() {
+	if b.started {
+		return
+	}
+	b.started = true
+	if err := ApplyBlockPreamble(b.config, b.bc, &b.header.Coinbase, b.parent.Header(), b.header, b.statedb, vm.Config{}); err != nil {
+		panic(fmt.Sprintf("block start error: %v", err))
+	}
+}
+
 // AddUncheckedTx forcefully adds a transaction to the block without any
 // validation.
 //
@@ -191,8 +208,9 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 	}
 	blocks, receipts := make(types.Blocks, n), make([]types.Receipts, n)
 	chainreader := &fakeChainReader{config: config}
+	chain := &generatorChain{engine: engine, parent: parent, blocks: blocks}
 	genblock := func(i int, parent *types.Block, statedb *state.StateDB) (*types.Block, types.Receipts) {
-		b := &BlockGen{i: i, chain: blocks, parent: parent, statedb: statedb, config: config, engine: engine}
+		b := &BlockGen{i: i, chain: blocks, parent: parent, statedb: statedb, config: config, engine: engine, bc: chain}
 		b.header = makeHeader(chainreader, parent, statedb, b.engine)
 
 		// Mutate the state and block according to any hard-fork specs
@@ -204,13 +222,15 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 				}
 			}
 		}
-		if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(b.header.Number) == 0 {
-			misc.ApplyDAOHardFork(statedb)
-		}
 		// Execute any user modifications to the block
 		if gen != nil {
 			gen(i, b)
 		}
+		b.start()
+		ApplyImplicitBlockEnd(config, chain, &b.header.Coinbase, b.header, statedb, vm.Config{})
+		if err := ApplyBlockEnd(config, b.header, statedb, b.receipts); err != nil {
+			panic(fmt.Sprintf("block end error: %v", err))
+		}
 		if b.engine != nil {
 			// Finalize and seal the block
 			block, _ := b.engine.FinalizeAndAssemble(chainreader, b.header, statedb, b.txs, b.uncles, b.receipts)
@@ -282,6 +302,47 @@ func makeBlockChain(parent *types.Block, n int, engine consensus.Engine, db ethd
 	return blocks
 }
 
+// NewTestStateDB creates an in-memory state database populated with the given
+// genesis allocation. The init functions are then invoked in order, e.g. to
+// populate the storage of stateful precompiles through vm.NewPrecompileStorage,
+// and the result is finalised, so that unit tests run against realistic state.
+func NewTestStateDB(alloc GenesisAlloc, init ...func(statedb *state.StateDB)) *state.StateDB {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	alloc.apply(statedb)
+	for _, fn := range init {
+		fn(statedb)
+	}
+	statedb.Finalise(false)
+	return statedb
+}
+
+// generatorChain is the ChainContext of the blocks generated by GenerateChain,
+// providing the headers of the parent of the chain and the blocks generated so
+// far.
+type generatorChain struct {
+	engine consensus.Engine
+	parent *types.Block
+	blocks []*types.Block
+}
+
+// Engine retrieves the consensus engine the chain is generated with.
+func (c *generatorChain) Engine() consensus.Engine {
+	return c.engine
+}
+
+// GetHeader retrieves a header of the generated chain or of its parent.
+func (c *generatorChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if c.parent.Hash() == hash {
+		return c.parent.Header()
+	}
+	for _, block := range c.blocks {
+		if block != nil && block.Hash() == hash {
+			return block.Header()
+		}
+	}
+	return nil
+}
+
 type fakeChainReader struct {
 	config  *params.ChainConfig
 	genesis *types.Block
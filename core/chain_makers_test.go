@@ -19,9 +19,12 @@ package core
 import (
 	"fmt"
 	"math/big"
+	"testing"
 
+	"github.com/ava-labs/go-ethereum/common"
 	"github.com/ava-labs/go-ethereum/consensus/ethash"
 	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/core/state"
 	"github.com/ava-labs/go-ethereum/core/types"
 	"github.com/ava-labs/go-ethereum/core/vm"
 	"github.com/ava-labs/go-ethereum/crypto"
@@ -98,3 +101,26 @@ func ExampleGenerateChain() {
 	// balance of addr2: 10000
 	// balance of addr3: 19687500000000001000
 }
+
+func TestNewTestStateDB(t *testing.T) {
+	var (
+		addr       = common.HexToAddress("0xc0ffee")
+		precompile = common.HexToAddress("0x0100000000000000000000000000000000000000")
+	)
+	alloc := GenesisAlloc{
+		addr:       {Balance: big.NewInt(1), Storage: map[common.Hash]common.Hash{{1}: {2}}},
+		precompile: {Balance: new(big.Int), Nonce: 1},
+	}
+	statedb := NewTestStateDB(alloc, func(statedb *state.StateDB) {
+		vm.NewPrecompileStorage(statedb, precompile, "config", 0).SetUint64([]byte("limit"), 42)
+	})
+	if balance := statedb.GetBalance(addr); balance.Int64() != 1 {
+		t.Errorf("balance mismatch: have %v, want 1", balance)
+	}
+	if value := statedb.GetState(addr, common.Hash{1}); value != (common.Hash{2}) {
+		t.Errorf("storage mismatch: have %x", value)
+	}
+	if limit := vm.NewPrecompileStorage(statedb, precompile, "config", 0).GetUint64([]byte("limit")); limit != 42 {
+		t.Errorf("precompile storage mismatch: have %d, want 42", limit)
+	}
+}
@@ -26,6 +26,11 @@ var (
 	// by a transaction is higher than what's left in the block.
 	ErrGasLimitReached = errors.New("gas limit reached")
 
+	// ErrGasDimensionLimitReached is returned by the gas pool if the amount of a
+	// gas dimension consumed by a transaction is higher than what's left in the
+	// block, see GasDimension.
+	ErrGasDimensionLimitReached = errors.New("gas dimension limit reached")
+
 	// ErrBlacklistedHash is returned if a block to import is on the blacklist.
 	ErrBlacklistedHash = errors.New("blacklisted hash")
 
@@ -33,6 +38,22 @@ var (
 	// next one expected based on the local chain.
 	ErrNonceTooHigh = errors.New("nonce too high")
 
+	// ErrFloorDataGas is returned if the gas limit of a transaction is below the
+	// floor gas computed by the registered FloorGasHook.
+	ErrFloorDataGas = errors.New("insufficient gas for floor data gas cost")
+
+	// ErrSystemMessageNotAllowed is returned if a system message is executed
+	// without a registered SystemMessageHook.
+	ErrSystemMessageNotAllowed = errors.New("system message not allowed")
+
+	// ErrSystemMessageCreation is returned if a system message has no recipient,
+	// as system messages can't create contracts.
+	ErrSystemMessageCreation = errors.New("system message creates contract")
+
+	// ErrSystemTx is returned if a system transaction is submitted to the
+	// transaction pool, as only block producers may include them.
+	ErrSystemTx = errors.New("system transaction")
+
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
 )
@@ -23,6 +23,7 @@ import (
 	"github.com/ava-labs/go-ethereum/consensus"
 	"github.com/ava-labs/go-ethereum/core/types"
 	"github.com/ava-labs/go-ethereum/core/vm"
+	"github.com/ava-labs/go-ethereum/params"
 )
 
 // ChainContext supports retrieving headers and consensus parameters from the
@@ -35,6 +36,33 @@ type ChainContext interface {
 	GetHeader(common.Hash, uint64) *types.Header
 }
 
+// The value transfer functions installed into every EVM context, replaceable
+// through RegisterTransferFuncs.
+var (
+	canTransferFn vm.CanTransferFunc = CanTransfer
+	transferFn    vm.TransferFunc    = Transfer
+	transferFnSet bool
+)
+
+// RegisterTransferFuncs replaces the CanTransfer and Transfer functions that
+// NewEVMContext installs for checking and performing value transfers, e.g. to
+// support multiple native assets. Either may be nil to retain the default.
+//
+// RegisterTransferFuncs is not safe for concurrent use and must be called during
+// initialisation. It panics if called more than once.
+func RegisterTransferFuncs(canTransfer vm.CanTransferFunc, transfer vm.TransferFunc) {
+	if transferFnSet {
+		panic("core: transfer functions already registered")
+	}
+	if canTransfer != nil {
+		canTransferFn = canTransfer
+	}
+	if transfer != nil {
+		transferFn = transfer
+	}
+	transferFnSet = true
+}
+
 // NewEVMContext creates a new context for use in the EVM.
 func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author *common.Address) vm.Context {
 	// If we don't have an explicit author (i.e. not mining), extract from the header
@@ -45,8 +73,8 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 		beneficiary = *author
 	}
 	return vm.Context{
-		CanTransfer: CanTransfer,
-		Transfer:    Transfer,
+		CanTransfer: canTransferFn,
+		Transfer:    transferFn,
 		GetHash:     GetHashFn(header, chain),
 		Origin:      msg.From(),
 		Coinbase:    beneficiary,
@@ -58,6 +86,19 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 	}
 }
 
+// NewTransactionContext creates a new context for executing a transaction
+// included in the block of the given header, like NewEVMContext, additionally
+// providing the outcomes of verifying the predicates of the transaction. It must
+// be used wherever such a transaction is executed, e.g. for tracing, so that
+// precompiles see the same predicates as during block processing.
+func NewTransactionContext(config *params.ChainConfig, msg Message, header *types.Header, chain ChainContext, author *common.Address) vm.Context {
+	context := NewEVMContext(msg, header, chain, author)
+	if msg, ok := msg.(AccessListMessage); ok {
+		context.Predicates = vm.VerifyPredicates(&vm.PredicateContext{ChainConfig: config, Header: header}, msg.AccessList())
+	}
+	return context
+}
+
 // GetHashFn returns a GetHashFunc which retrieves header hashes by number
 func GetHashFn(ref *types.Header, chain ChainContext) func(n uint64) common.Hash {
 	var cache map[uint64]common.Hash
@@ -92,6 +133,6 @@ func CanTransfer(db vm.StateDB, addr common.Address, amount *big.Int) bool {
 
 // Transfer subtracts amount from sender and adds amount to recipient using the given Db
 func Transfer(db vm.StateDB, sender, recipient common.Address, amount *big.Int) {
-	db.SubBalance(sender, amount)
-	db.AddBalance(recipient, amount)
+	db.SubBalanceWithReason(sender, amount, types.BalanceChangeTransfer)
+	db.AddBalanceWithReason(recipient, amount, types.BalanceChangeTransfer)
 }
@@ -0,0 +1,107 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+func TestRegisterTransferFuncs(t *testing.T) {
+	defer func() {
+		canTransferFn, transferFn, transferFnSet = CanTransfer, Transfer, false
+	}()
+
+	var transferred *big.Int
+	RegisterTransferFuncs(nil, func(db vm.StateDB, sender, recipient common.Address, amount *big.Int) {
+		transferred = amount
+	})
+	var (
+		msg    = types.NewMessage(common.Address{}, nil, 0, new(big.Int), 0, new(big.Int), nil, false)
+		header = &types.Header{Number: big.NewInt(1), Difficulty: new(big.Int)}
+		ctx    = NewEVMContext(msg, header, nil, &common.Address{})
+	)
+	ctx.Transfer(nil, common.Address{}, common.Address{}, big.NewInt(42))
+	if transferred == nil || transferred.Int64() != 42 {
+		t.Errorf("custom transfer function not installed")
+	}
+	if ctx.CanTransfer == nil {
+		t.Errorf("default can-transfer function dropped")
+	}
+}
+
+// testPredicater accepts the predicates consisting of a single all-ones key.
+type testPredicater struct{}
+
+var errTestPredicate = errors.New("invalid test predicate")
+
+func (testPredicater) PredicateGas(predicate []byte) (uint64, error) { return 0, nil }
+
+func (testPredicater) VerifyPredicate(ctx *vm.PredicateContext, predicate []byte) error {
+	if common.BytesToHash(predicate) != (common.Hash{0xff}) {
+		return errTestPredicate
+	}
+	return nil
+}
+
+// accessListMessage is a message carrying an access list.
+type accessListMessage struct {
+	types.Message
+	list types.AccessList
+}
+
+func (m accessListMessage) AccessList() types.AccessList { return m.list }
+
+// Precompiles and predicaters can't be unregistered outside of package vm, so
+// the tests of this package register them once.
+var (
+	testPredicaterOnce sync.Once
+	testPredicaterAddr = common.HexToAddress("0x03000000000000000000000000000000000000fd")
+)
+
+func TestNewTransactionContext(t *testing.T) {
+	testPredicaterOnce.Do(func() {
+		vm.RegisterPrecompile(vm.RegisteredPrecompile{Address: testPredicaterAddr, Contract: vm.PrecompiledContractsIstanbul[common.BytesToAddress([]byte{4})]})
+		vm.RegisterPredicater(testPredicaterAddr, testPredicater{})
+	})
+	var (
+		config = &params.ChainConfig{ChainID: big.NewInt(1)}
+		header = &types.Header{Number: big.NewInt(1), Difficulty: new(big.Int)}
+		msg    = accessListMessage{
+			Message: types.NewMessage(common.Address{}, nil, 0, new(big.Int), 0, new(big.Int), nil, false),
+			list: types.AccessList{
+				{Address: testPredicaterAddr, StorageKeys: []common.Hash{{0xff}}},
+				{Address: testPredicaterAddr, StorageKeys: []common.Hash{{0x01}}},
+			},
+		}
+	)
+	ctx := NewTransactionContext(config, msg, header, nil, &common.Address{})
+	results := ctx.Predicates[testPredicaterAddr]
+	if len(results) != 2 || results[0].Err != nil || results[1].Err != errTestPredicate {
+		t.Errorf("predicate results mismatch: have %+v", results)
+	}
+	if ctx := NewEVMContext(msg, header, nil, &common.Address{}); ctx.Predicates != nil {
+		t.Errorf("predicates verified for plain context: %+v", ctx.Predicates)
+	}
+}
@@ -19,36 +19,170 @@ package core
 import (
 	"fmt"
 	"math"
+
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
+	"github.com/ava-labs/go-ethereum/params"
 )
 
 // GasPool tracks the amount of gas available during execution of the transactions
-// in a block. The zero value is a pool with zero gas available.
-type GasPool uint64
+// in a block, along with the budgets of the registered gas dimensions if created
+// by NewBlockGasPool. The zero value is a pool with zero gas available, which
+// doesn't limit any gas dimension.
+type GasPool struct {
+	gas        uint64
+	dimensions []uint64 // Remaining budgets of the gas dimensions, nil if untracked
+}
+
+// NewBlockGasPool creates a gas pool for the transactions of the block with the
+// given header, providing its gas limit and the budgets of all registered gas
+// dimensions.
+func NewBlockGasPool(config *params.ChainConfig, header *types.Header) *GasPool {
+	gp := new(GasPool).AddGas(header.GasLimit)
+	if len(gasDimensions) > 0 {
+		gp.dimensions = make([]uint64, len(gasDimensions))
+		for i, dim := range gasDimensions {
+			gp.dimensions[i] = dim.Limit(config, header)
+		}
+	}
+	return gp
+}
 
 // AddGas makes gas available for execution.
 func (gp *GasPool) AddGas(amount uint64) *GasPool {
-	if uint64(*gp) > math.MaxUint64-amount {
+	if gp.gas > math.MaxUint64-amount {
 		panic("gas pool pushed above uint64")
 	}
-	*(*uint64)(gp) += amount
+	gp.gas += amount
 	return gp
 }
 
 // SubGas deducts the given amount from the pool if enough gas is
 // available and returns an error otherwise.
 func (gp *GasPool) SubGas(amount uint64) error {
-	if uint64(*gp) < amount {
+	if gp.gas < amount {
 		return ErrGasLimitReached
 	}
-	*(*uint64)(gp) -= amount
+	gp.gas -= amount
 	return nil
 }
 
 // Gas returns the amount of gas remaining in the pool.
 func (gp *GasPool) Gas() uint64 {
-	return uint64(*gp)
+	return gp.gas
+}
+
+// Dimension returns the remaining budget of the i-th registered gas dimension,
+// and whether the pool tracks it at all.
+func (gp *GasPool) Dimension(i int) (uint64, bool) {
+	if gp.dimensions == nil {
+		return 0, false
+	}
+	return gp.dimensions[i], true
+}
+
+// subDimensions deducts the given amounts from the budgets of the gas
+// dimensions if all of them are available, and returns an error otherwise.
+// Pools not tracking dimensions accept any amounts.
+func (gp *GasPool) subDimensions(amounts []uint64) error {
+	if gp.dimensions == nil {
+		return nil
+	}
+	for i, amount := range amounts {
+		if gp.dimensions[i] < amount {
+			return ErrGasDimensionLimitReached
+		}
+	}
+	for i, amount := range amounts {
+		gp.dimensions[i] -= amount
+	}
+	return nil
+}
+
+// Copy returns an independent copy of the pool, e.g. to restore it if the
+// transactions applied since must be dropped again.
+func (gp *GasPool) Copy() *GasPool {
+	cpy := &GasPool{gas: gp.gas}
+	if gp.dimensions != nil {
+		cpy.dimensions = append([]uint64(nil), gp.dimensions...)
+	}
+	return cpy
 }
 
 func (gp *GasPool) String() string {
-	return fmt.Sprintf("%d", *gp)
+	return fmt.Sprintf("%d", gp.gas)
+}
+
+// GasDimension is a resource tracked by block gas pools next to gas, e.g. a
+// separate budget for precompile-heavy operations. Every block has a budget of
+// each registered dimension, which transactions draw from as they are applied.
+// Transactions exceeding the remaining budget are invalid and leave the state
+// untouched, like transactions exceeding the remaining gas of the block.
+type GasDimension struct {
+	// Name identifies the dimension.
+	Name string
+
+	// Limit returns the budget of the dimension available to the transactions
+	// of the block with the given header.
+	Limit func(config *params.ChainConfig, header *types.Header) uint64
+
+	// Charge returns the amount of the dimension consumed by an executed
+	// message, given the EVM it was executed in and the gas it used. It is
+	// called before refunds and fees are settled.
+	Charge func(evm *vm.EVM, msg Message, gasUsed uint64) uint64
+
+	// Validate optionally validates the amount of the dimension used by all
+	// transactions of a processed block, e.g. against a header field. An error
+	// renders the block invalid.
+	Validate func(config *params.ChainConfig, header *types.Header, used uint64) error
+}
+
+// gasDimensions are the dimensions registered with RegisterGasDimension, in
+// order of registration.
+var gasDimensions []GasDimension
+
+// RegisterGasDimension registers an additional gas dimension, returning its
+// index in block gas pools, see GasPool.Dimension.
+//
+// RegisterGasDimension is not safe for concurrent use and must be called
+// during initialisation. It panics if a dimension of the same name is already
+// registered.
+func RegisterGasDimension(dim GasDimension) int {
+	for _, registered := range gasDimensions {
+		if registered.Name == dim.Name {
+			panic(fmt.Sprintf("core: gas dimension %q already registered", dim.Name))
+		}
+	}
+	gasDimensions = append(gasDimensions, dim)
+	return len(gasDimensions) - 1
+}
+
+// chargeGasDimensions charges the amounts of the gas dimensions consumed by an
+// executed message to the pool.
+func chargeGasDimensions(gp *GasPool, evm *vm.EVM, msg Message, gasUsed uint64) error {
+	if len(gasDimensions) == 0 {
+		return nil
+	}
+	amounts := make([]uint64, len(gasDimensions))
+	for i, dim := range gasDimensions {
+		amounts[i] = dim.Charge(evm, msg, gasUsed)
+	}
+	return gp.subDimensions(amounts)
+}
+
+// ValidateGasDimensions validates the amounts of the gas dimensions used by the
+// transactions of a block, given the gas pool they were applied with. Block
+// processing rejects blocks failing it, so block producers must check it before
+// sealing.
+func ValidateGasDimensions(config *params.ChainConfig, header *types.Header, gp *GasPool) error {
+	for i, dim := range gasDimensions {
+		if dim.Validate == nil {
+			continue
+		}
+		remaining, _ := gp.Dimension(i)
+		if err := dim.Validate(config, header, dim.Limit(config, header)-remaining); err != nil {
+			return err
+		}
+	}
+	return nil
 }
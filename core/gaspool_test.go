@@ -0,0 +1,120 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/consensus/ethash"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
+	"github.com/ava-labs/go-ethereum/crypto"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+func TestGasDimension(t *testing.T) {
+	var (
+		key, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr      = crypto.PubkeyToAddress(key.PublicKey)
+		heavy     = common.HexToAddress("0x4ea7")
+		validated []uint64
+	)
+	// Charge one unit per call to the heavy address, allowing two per block
+	index := RegisterGasDimension(GasDimension{
+		Name:  "heavy",
+		Limit: func(config *params.ChainConfig, header *types.Header) uint64 { return 2 },
+		Charge: func(evm *vm.EVM, msg Message, gasUsed uint64) uint64 {
+			if to := msg.To(); to != nil && *to == heavy {
+				return 1
+			}
+			return 0
+		},
+		Validate: func(config *params.ChainConfig, header *types.Header, used uint64) error {
+			validated = append(validated, used)
+			return nil
+		},
+	})
+	defer func() { gasDimensions = nil }()
+
+	var (
+		gspec  = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		signer = types.NewEIP155Signer(gspec.Config.ChainID)
+		header = &types.Header{Number: big.NewInt(1), GasLimit: params.GenesisGasLimit, Difficulty: big.NewInt(1)}
+	)
+	newTx := func(nonce uint64, to common.Address) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, to, new(big.Int), params.TxGas, nil, nil), signer, key)
+		return tx
+	}
+	// Transactions exceeding the budget must be rejected without consuming gas
+	var (
+		statedb = NewTestStateDB(gspec.Alloc)
+		gp      = NewBlockGasPool(gspec.Config, header)
+		usedGas uint64
+	)
+	for i := uint64(0); i < 3; i++ {
+		tx := newTx(i, heavy)
+		statedb.Prepare(tx.Hash(), common.Hash{}, int(i))
+
+		var (
+			gas     = gp.Gas()
+			nonce   = statedb.GetNonce(addr)
+			balance = statedb.GetBalance(addr)
+		)
+		_, _, err := ApplyTransaction(gspec.Config, nil, &common.Address{}, gp, statedb, header, tx, &usedGas, vm.Config{})
+		switch {
+		case i < 2 && err != nil:
+			t.Fatalf("tx %d: failed to apply: %v", i, err)
+		case i == 2 && err != ErrGasDimensionLimitReached:
+			t.Fatalf("tx %d: error mismatch: have %v, want %v", i, err, ErrGasDimensionLimitReached)
+		case i == 2 && gp.Gas() != gas:
+			t.Errorf("tx %d: gas consumed: have %d, want %d", i, gp.Gas(), gas)
+		case i == 2 && (statedb.GetNonce(addr) != nonce || statedb.GetBalance(addr).Cmp(balance) != 0):
+			t.Errorf("tx %d: state changed: have nonce %d, balance %v, want %d, %v", i, statedb.GetNonce(addr), statedb.GetBalance(addr), nonce, balance)
+		}
+	}
+	if remaining, ok := gp.Dimension(index); !ok || remaining != 0 {
+		t.Errorf("remaining budget mismatch: have %d (tracked %v), want 0", remaining, ok)
+	}
+	// Untracked pools must not limit dimensions
+	if _, ok := new(GasPool).AddGas(1).Dimension(index); ok {
+		t.Errorf("plain gas pool tracks dimensions")
+	}
+	// Processed blocks must validate the used budget
+	db := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(i int, b *BlockGen) {
+		b.AddTx(newTx(0, heavy))
+		b.AddTx(newTx(1, common.Address{1}))
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+	chain, err := NewBlockChain(diskdb, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if len(validated) != 1 || validated[0] != 1 {
+		t.Errorf("validated usage mismatch: have %v, want [1]", validated)
+	}
+}
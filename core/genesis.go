@@ -31,6 +31,7 @@ import (
 	"github.com/ava-labs/go-ethereum/core/rawdb"
 	"github.com/ava-labs/go-ethereum/core/state"
 	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
 	"github.com/ava-labs/go-ethereum/crypto"
 	"github.com/ava-labs/go-ethereum/ethdb"
 	"github.com/ava-labs/go-ethereum/log"
@@ -78,6 +79,18 @@ func (ga *GenesisAlloc) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// apply populates the given state with the allocated accounts.
+func (ga GenesisAlloc) apply(statedb *state.StateDB) {
+	for addr, account := range ga {
+		statedb.AddBalanceWithReason(addr, account.Balance, types.BalanceChangeGenesis)
+		statedb.SetCode(addr, account.Code)
+		statedb.SetNonce(addr, account.Nonce)
+		for key, value := range account.Storage {
+			statedb.SetState(addr, key, value)
+		}
+	}
+}
+
 // GenesisAccount is an account in the state of the genesis block.
 type GenesisAccount struct {
 	Code       []byte                      `json:"code,omitempty"`
@@ -226,7 +239,20 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, override
 	if height == nil {
 		return newcfg, stored, fmt.Errorf("missing block number for head header hash")
 	}
-	compatErr := storedcfg.CheckCompatible(newcfg, *height)
+	if err := vm.CheckPrecompileUpgrades(newcfg); err != nil {
+		return newcfg, stored, err
+	}
+	if err := newcfg.CheckStateUpgrades(); err != nil {
+		return newcfg, stored, err
+	}
+	head := rawdb.ReadHeader(db, rawdb.ReadHeadHeaderHash(db), *height)
+	if head == nil {
+		return newcfg, stored, fmt.Errorf("missing head header")
+	}
+	compatErr := storedcfg.CheckCompatibleAt(newcfg, *height, head.Time)
+	if compatErr != nil && (compatErr.StoredTime != nil || compatErr.NewTime != nil) {
+		compatErr.RewindTo = rewindNumber(db, head, compatErr.RewindToTime)
+	}
 	if compatErr != nil && *height != 0 && compatErr.RewindTo != 0 {
 		return newcfg, stored, compatErr
 	}
@@ -234,6 +260,17 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, override
 	return newcfg, stored, nil
 }
 
+// rewindNumber returns the number of the latest canonical block up to the given
+// head with a timestamp of at most the given one.
+func rewindNumber(db ethdb.Database, head *types.Header, time uint64) uint64 {
+	for header := head; header != nil; header = rawdb.ReadHeader(db, header.ParentHash, header.Number.Uint64()-1) {
+		if header.Time <= time || header.Number.Sign() == 0 {
+			return header.Number.Uint64()
+		}
+	}
+	return 0
+}
+
 func (g *Genesis) configOrDefault(ghash common.Hash) *params.ChainConfig {
 	switch {
 	case g != nil:
@@ -254,14 +291,7 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 		db = rawdb.NewMemoryDatabase()
 	}
 	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
-	for addr, account := range g.Alloc {
-		statedb.AddBalance(addr, account.Balance)
-		statedb.SetCode(addr, account.Code)
-		statedb.SetNonce(addr, account.Nonce)
-		for key, value := range account.Storage {
-			statedb.SetState(addr, key, value)
-		}
-	}
+	g.Alloc.apply(statedb)
 	root := statedb.IntermediateRoot(false)
 	head := &types.Header{
 		Number:     new(big.Int).SetUint64(g.Number),
@@ -291,6 +321,16 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 // Commit writes the block and state of a genesis specification to the database.
 // The block is committed as the canonical head block.
 func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
+	config := g.Config
+	if config == nil {
+		config = params.AllEthashProtocolChanges
+	}
+	if err := vm.CheckPrecompileUpgrades(config); err != nil {
+		return nil, err
+	}
+	if err := config.CheckStateUpgrades(); err != nil {
+		return nil, err
+	}
 	block := g.ToBlock(db)
 	if block.Number().Sign() != 0 {
 		return nil, fmt.Errorf("can't commit genesis block with number > 0")
@@ -303,10 +343,6 @@ func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
 	rawdb.WriteHeadFastBlockHash(db, block.Hash())
 	rawdb.WriteHeadHeaderHash(db, block.Hash())
 
-	config := g.Config
-	if config == nil {
-		config = params.AllEthashProtocolChanges
-	}
 	rawdb.WriteChainConfig(db, block.Hash(), config)
 	return block, nil
 }
@@ -0,0 +1,143 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/state"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+// ImplicitMessageHooks contribute implicit messages executed at the start or
+// end of every block, e.g. to run keeper logic or store block roots in a
+// contract. Implicit messages are not part of the block, but are executed by
+// every block producer and processor alike, so they must be derived from the
+// chain config, header and state alone. Either of the hooks may be nil.
+//
+// Implicit messages are executed in the EVM without drawing from the block's
+// gas pool, checking or incrementing the nonce of their sender, or charging it
+// for gas. A failing message is reverted but doesn't render the block invalid.
+// The results of the messages of a block are recorded in a single receipt,
+// which is stored along with the block, see rawdb.ReadImplicitReceipt.
+type ImplicitMessageHooks struct {
+	// BlockStart returns the messages executed before the transactions of a
+	// block are applied, right after the BlockStart processor hook.
+	BlockStart func(config *params.ChainConfig, header *types.Header, statedb *state.StateDB) []Message
+
+	// BlockEnd returns the messages executed once the transactions of a block
+	// are applied, right before the BlockEnd processor hook.
+	BlockEnd func(config *params.ChainConfig, header *types.Header, statedb *state.StateDB) []Message
+}
+
+// implicitMessageHooks are the hooks registered with
+// RegisterImplicitMessageHooks, if any.
+var implicitMessageHooks ImplicitMessageHooks
+
+// implicitMessageHooksRegistered reports whether RegisterImplicitMessageHooks
+// was called.
+var implicitMessageHooksRegistered bool
+
+// RegisterImplicitMessageHooks registers hooks contributing implicit messages.
+//
+// It is not safe for concurrent use and must be called during initialisation.
+// It panics if hooks are already registered.
+func RegisterImplicitMessageHooks(hooks ImplicitMessageHooks) {
+	if implicitMessageHooksRegistered {
+		panic("core: implicit message hooks already registered")
+	}
+	implicitMessageHooks, implicitMessageHooksRegistered = hooks, true
+}
+
+// ApplyImplicitBlockStart executes the implicit messages returned by the
+// registered BlockStart hook, if any, recording their results in the implicit
+// receipt of the state.
+func ApplyImplicitBlockStart(config *params.ChainConfig, bc ChainContext, author *common.Address, header *types.Header, statedb *state.StateDB, cfg vm.Config) {
+	if implicitMessageHooks.BlockStart == nil {
+		return
+	}
+	applyImplicitMessages(config, bc, author, header, statedb, cfg, implicitMessageHooks.BlockStart(config, header, statedb))
+}
+
+// ApplyImplicitBlockEnd executes the implicit messages returned by the
+// registered BlockEnd hook, if any, recording their results in the implicit
+// receipt of the state.
+func ApplyImplicitBlockEnd(config *params.ChainConfig, bc ChainContext, author *common.Address, header *types.Header, statedb *state.StateDB, cfg vm.Config) {
+	if implicitMessageHooks.BlockEnd == nil {
+		return
+	}
+	applyImplicitMessages(config, bc, author, header, statedb, cfg, implicitMessageHooks.BlockEnd(config, header, statedb))
+}
+
+// applyImplicitMessages executes the given implicit messages and extends the
+// implicit receipt of the state by their results. The receipt fails if any of
+// the messages did.
+func applyImplicitMessages(config *params.ChainConfig, bc ChainContext, author *common.Address, header *types.Header, statedb *state.StateDB, cfg vm.Config, msgs []Message) {
+	if len(msgs) == 0 {
+		return
+	}
+	var (
+		receipt = &types.Receipt{Status: types.ReceiptStatusSuccessful}
+		db      = &implicitStateDB{StateDB: statedb}
+	)
+	if prev := statedb.ImplicitReceipt(); prev != nil {
+		receipt.Status = prev.Status
+		receipt.CumulativeGasUsed = prev.CumulativeGasUsed
+		db.logs = append(db.logs, prev.Logs...)
+	}
+	for _, msg := range msgs {
+		// Discard the transient storage of any previous message or transaction
+		statedb.Prepare(common.Hash{}, common.Hash{}, 0)
+
+		var (
+			evm      = vm.NewEVM(NewEVMContext(msg, header, bc, author), db, config, cfg)
+			sender   = vm.AccountRef(msg.From())
+			leftOver uint64
+			err      error
+		)
+		if to := msg.To(); to == nil {
+			_, _, leftOver, err = evm.Create(sender, msg.Data(), msg.Gas(), msg.Value())
+		} else {
+			_, leftOver, err = evm.Call(sender, *to, msg.Data(), msg.Gas(), msg.Value())
+		}
+		if err != nil {
+			receipt.Status = types.ReceiptStatusFailed
+		}
+		receipt.CumulativeGasUsed += msg.Gas() - leftOver
+		statedb.Finalise(config.IsEIP158(header.Number))
+	}
+	receipt.GasUsed = receipt.CumulativeGasUsed
+	receipt.Logs = db.logs
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	statedb.SetImplicitReceipt(receipt)
+}
+
+// implicitStateDB collects the logs emitted by implicit messages, keeping them
+// apart from the logs of the transactions of the block.
+type implicitStateDB struct {
+	*state.StateDB
+	logs []*types.Log
+}
+
+// AddLog adds the log to those of the implicit receipt, dropping it again if
+// the emitting call is reverted.
+func (db *implicitStateDB) AddLog(log *types.Log) {
+	size := len(db.logs)
+	db.StateDB.AppendJournalEntry(func() { db.logs = db.logs[:size] }, nil)
+	db.logs = append(db.logs, log)
+}
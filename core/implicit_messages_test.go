@@ -0,0 +1,159 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/consensus/ethash"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/core/state"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+func TestImplicitMessages(t *testing.T) {
+	var (
+		system = common.HexToAddress("0x5157e4")
+		keeper = common.HexToAddress("0xc0de")
+		engine = ethash.NewFaker()
+		gspec  = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{keeper: {
+				// Store the block number and emit an empty log
+				Code:    []byte{byte(vm.NUMBER), byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG0)},
+				Balance: new(big.Int),
+			}},
+		}
+	)
+	RegisterImplicitMessageHooks(ImplicitMessageHooks{
+		BlockStart: func(config *params.ChainConfig, header *types.Header, statedb *state.StateDB) []Message {
+			return []Message{types.NewSystemMessage(system, &keeper, new(big.Int), 100000, nil)}
+		},
+		BlockEnd: func(config *params.ChainConfig, header *types.Header, statedb *state.StateDB) []Message {
+			// Run out of gas, which must fail the receipt but not the block
+			return []Message{types.NewSystemMessage(system, &keeper, new(big.Int), 1, nil)}
+		},
+	})
+	defer func() { implicitMessageHooks, implicitMessageHooksRegistered = ImplicitMessageHooks{}, false }()
+
+	db := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 2, nil)
+
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+	chain, err := NewBlockChain(diskdb, nil, gspec.Config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	statedb, _ := chain.State()
+	if number := statedb.GetState(keeper, common.Hash{}); number != common.BigToHash(big.NewInt(2)) {
+		t.Errorf("keeper state mismatch: have %x, want 2", number)
+	}
+	for _, block := range blocks {
+		receipt := chain.GetImplicitReceiptByHash(block.Hash())
+		if receipt == nil {
+			t.Fatalf("block %d: implicit receipt missing", block.NumberU64())
+		}
+		if receipt.Status != types.ReceiptStatusFailed {
+			t.Errorf("block %d: status mismatch: have %d, want %d", block.NumberU64(), receipt.Status, types.ReceiptStatusFailed)
+		}
+		if len(receipt.Logs) != 1 {
+			t.Fatalf("block %d: log count mismatch: have %d, want 1", block.NumberU64(), len(receipt.Logs))
+		}
+		if log := receipt.Logs[0]; log.Address != keeper || log.BlockHash != block.Hash() {
+			t.Errorf("block %d: log mismatch: have %+v", block.NumberU64(), log)
+		}
+		if receipt.GasUsed <= 1 {
+			t.Errorf("block %d: gas used mismatch: have %d", block.NumberU64(), receipt.GasUsed)
+		}
+		// Implicit messages must not be charged against the block
+		if block.GasUsed() != 0 {
+			t.Errorf("block %d: block gas used: have %d, want 0", block.NumberU64(), block.GasUsed())
+		}
+	}
+}
+
+func TestImplicitMessagesGenerateChain(t *testing.T) {
+	var (
+		system = common.HexToAddress("0x5157e4")
+		first  = common.HexToAddress("0xf157")
+		last   = common.HexToAddress("0x1a57")
+		engine = ethash.NewFaker()
+		gspec  = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				// Store the coinbase by block number
+				first: {Code: []byte{byte(vm.COINBASE), byte(vm.NUMBER), byte(vm.SSTORE)}, Balance: new(big.Int)},
+				// Additionally store the hash of the grandparent in slot 0
+				last: {Code: []byte{
+					byte(vm.COINBASE), byte(vm.NUMBER), byte(vm.SSTORE),
+					byte(vm.PUSH1), 2, byte(vm.NUMBER), byte(vm.SUB), byte(vm.BLOCKHASH), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+				}, Balance: new(big.Int)},
+			},
+		}
+	)
+	RegisterImplicitMessageHooks(ImplicitMessageHooks{
+		BlockStart: func(config *params.ChainConfig, header *types.Header, statedb *state.StateDB) []Message {
+			return []Message{types.NewSystemMessage(system, &first, new(big.Int), 100000, nil)}
+		},
+		BlockEnd: func(config *params.ChainConfig, header *types.Header, statedb *state.StateDB) []Message {
+			return []Message{types.NewSystemMessage(system, &last, new(big.Int), 100000, nil)}
+		},
+	})
+	defer func() { implicitMessageHooks, implicitMessageHooksRegistered = ImplicitMessageHooks{}, false }()
+
+	db := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 3, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{byte(i + 1)})
+	})
+	// The generated blocks must match the processed ones
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+	chain, err := NewBlockChain(diskdb, nil, gspec.Config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	statedb, _ := chain.State()
+	for _, block := range blocks {
+		key, want := common.BigToHash(block.Number()), common.BytesToHash(block.Coinbase().Bytes())
+		if have := statedb.GetState(first, key); have != want {
+			t.Errorf("block %d: block start coinbase mismatch: have %x, want %x", block.NumberU64(), have, want)
+		}
+		if have := statedb.GetState(last, key); have != want {
+			t.Errorf("block %d: block end coinbase mismatch: have %x, want %x", block.NumberU64(), have, want)
+		}
+	}
+	if have := statedb.GetState(last, common.Hash{}); have != blocks[0].Hash() {
+		t.Errorf("grandparent hash mismatch: have %x, want %x", have, blocks[0].Hash())
+	}
+}
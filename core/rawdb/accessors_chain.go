@@ -460,6 +460,51 @@ func DeleteReceipts(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadImplicitReceipt retrieves the receipt of the implicit messages executed at
+// the boundaries of a block, with its block fields and those of its logs set,
+// or nil if none is stored. Unlike transaction receipts, implicit receipts are
+// kept in the key-value store when blocks are moved to the ancient store.
+func ReadImplicitReceipt(db ethdb.Reader, hash common.Hash, number uint64) *types.Receipt {
+	data, _ := db.Get(implicitReceiptKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var stored types.ReceiptForStorage
+	if err := rlp.DecodeBytes(data, &stored); err != nil {
+		log.Error("Invalid implicit receipt RLP", "hash", hash, "err", err)
+		return nil
+	}
+	receipt := (*types.Receipt)(&stored)
+	receipt.BlockHash = hash
+	receipt.BlockNumber = new(big.Int).SetUint64(number)
+	receipt.GasUsed = receipt.CumulativeGasUsed
+	for i, l := range receipt.Logs {
+		l.BlockHash = hash
+		l.BlockNumber = number
+		l.Index = uint(i)
+	}
+	return receipt
+}
+
+// WriteImplicitReceipt stores the receipt of the implicit messages executed at
+// the boundaries of a block.
+func WriteImplicitReceipt(db ethdb.KeyValueWriter, hash common.Hash, number uint64, receipt *types.Receipt) {
+	bytes, err := rlp.EncodeToBytes((*types.ReceiptForStorage)(receipt))
+	if err != nil {
+		log.Crit("Failed to encode implicit receipt", "err", err)
+	}
+	if err := db.Put(implicitReceiptKey(number, hash), bytes); err != nil {
+		log.Crit("Failed to store implicit receipt", "err", err)
+	}
+}
+
+// DeleteImplicitReceipt removes the implicit message receipt of a block.
+func DeleteImplicitReceipt(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(implicitReceiptKey(number, hash)); err != nil {
+		log.Crit("Failed to delete implicit receipt", "err", err)
+	}
+}
+
 // ReadBlock retrieves an entire block corresponding to the hash, assembling it
 // back from the stored header and body. If either the header or body could not
 // be retrieved nil is returned.
@@ -475,7 +520,7 @@ func ReadBlock(db ethdb.Reader, hash common.Hash, number uint64) *types.Block {
 	if body == nil {
 		return nil
 	}
-	return types.NewBlockWithHeader(header).WithBody(body.Transactions, body.Uncles)
+	return types.NewBlockWithHeader(header).WithBody(body.Transactions, body.Uncles).WithExtraPayload(body.ExtraPayload())
 }
 
 // WriteBlock serializes a block into the database, header and body separately.
@@ -518,6 +563,7 @@ func WriteAncientBlock(db ethdb.AncientWriter, block *types.Block, receipts type
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteImplicitReceipt(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
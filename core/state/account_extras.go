@@ -0,0 +1,164 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/rlp"
+)
+
+// accountExtraType is the type of the payload registered with
+// RegisterAccountExtras, if any.
+var accountExtraType reflect.Type
+
+// RegisterAccountExtras registers the chain specific payload that accounts may
+// carry, e.g. a flag marking accounts holding multiple assets. The payload is
+// given as a pointer to a struct, whose exported fields are RLP encoded after
+// the consensus fields of accounts carrying one, and thus covered by the state
+// root. Accounts without a payload keep their canonical encoding. Payloads are
+// copied like header payloads, see types.PayloadCopier.
+//
+// It is not safe for concurrent use and must be called during initialisation,
+// before any state is accessed. It panics if the payload isn't a pointer to a
+// struct or if extras are already registered.
+func RegisterAccountExtras(payload interface{}) {
+	if accountExtraType != nil {
+		panic("state: account extras already registered")
+	}
+	typ := reflect.TypeOf(payload)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("state: account extras must be a pointer to a struct, got %T", payload))
+	}
+	accountExtraType = typ
+}
+
+// accountFieldCount is the number of consensus fields of accounts, preceding
+// the fields of their extra payload.
+const accountFieldCount = 4
+
+// plainAccount is an Account without its RLP encoding methods.
+type plainAccount Account
+
+// EncodeRLP implements rlp.Encoder, appending the fields of the account's extra
+// payload, if any, to its consensus fields.
+func (a Account) EncodeRLP(w io.Writer) error {
+	if a.extra == nil {
+		return rlp.Encode(w, plainAccount(a))
+	}
+	fields, err := rlp.ListElements(plainAccount(a))
+	if err != nil {
+		return err
+	}
+	extras, err := rlp.ListElements(a.extra)
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, append(fields, extras...))
+}
+
+// DecodeRLP implements rlp.Decoder, decoding the fields following the
+// consensus fields of an account into the registered extra payload.
+func (a *Account) DecodeRLP(s *rlp.Stream) error {
+	if accountExtraType == nil {
+		return s.Decode((*plainAccount)(a))
+	}
+	var fields []rlp.RawValue
+	if err := s.Decode(&fields); err != nil {
+		return err
+	}
+	if len(fields) < accountFieldCount {
+		return fmt.Errorf("rlp: too few fields for %T: have %d, want at least %d", a, len(fields), accountFieldCount)
+	}
+	if err := rlp.DecodeListElements(fields[:accountFieldCount], (*plainAccount)(a)); err != nil {
+		return err
+	}
+	a.extra = nil
+	if len(fields) > accountFieldCount {
+		extra := reflect.New(accountExtraType.Elem()).Interface()
+		if err := rlp.DecodeListElements(fields[accountFieldCount:], extra); err != nil {
+			return err
+		}
+		a.extra = extra
+	}
+	return nil
+}
+
+// copyAccountExtra returns a copy of an extra account payload.
+func copyAccountExtra(payload interface{}) interface{} {
+	if payload == nil {
+		return nil
+	}
+	if copier, ok := payload.(types.PayloadCopier); ok {
+		return copier.Copy()
+	}
+	val := reflect.ValueOf(payload)
+	cpy := reflect.New(val.Type().Elem())
+	cpy.Elem().Set(val.Elem())
+	return cpy.Interface()
+}
+
+// accountExtraChange is the journal entry of a change of an account's extra
+// payload.
+type accountExtraChange struct {
+	account *common.Address
+	prev    interface{}
+}
+
+func (ch accountExtraChange) revert(s *StateDB) {
+	s.getStateObject(*ch.account).setExtra(ch.prev)
+}
+
+func (ch accountExtraChange) dirtied() *common.Address {
+	return ch.account
+}
+
+func (s *stateObject) setExtra(payload interface{}) {
+	s.data.extra = payload
+}
+
+// GetAccountExtra returns a copy of the extra payload carried by the account
+// associated with addr, or nil if it has none or doesn't exist.
+func (self *StateDB) GetAccountExtra(addr common.Address) interface{} {
+	stateObject := self.getStateObject(addr)
+	if stateObject != nil {
+		return copyAccountExtra(stateObject.data.extra)
+	}
+	return nil
+}
+
+// SetAccountExtra sets the extra payload carried by the account associated with
+// addr, which must be of the type registered with RegisterAccountExtras. A nil
+// payload restores the account's canonical encoding. It panics if the payload
+// is of another type.
+func (self *StateDB) SetAccountExtra(addr common.Address, payload interface{}) {
+	if payload != nil && (accountExtraType == nil || reflect.TypeOf(payload) != accountExtraType) {
+		panic(fmt.Sprintf("state: account extras of type %T not registered", payload))
+	}
+	stateObject := self.GetOrNewStateObject(addr)
+	if stateObject != nil {
+		self.journal.append(accountExtraChange{
+			account: &stateObject.address,
+			prev:    stateObject.data.extra,
+		})
+		stateObject.setExtra(copyAccountExtra(payload))
+	}
+}
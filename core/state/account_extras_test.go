@@ -0,0 +1,177 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/rlp"
+	"github.com/ava-labs/go-ethereum/trie"
+)
+
+type testAccountExtra struct {
+	IsMultiCoin bool
+}
+
+func TestAccountExtras(t *testing.T) {
+	var (
+		db       = NewDatabase(rawdb.NewMemoryDatabase())
+		state, _ = New(common.Hash{}, db)
+		addr     = common.Address{1}
+	)
+	state.SetBalance(addr, big.NewInt(1))
+	plainRoot := state.IntermediateRoot(false)
+
+	RegisterAccountExtras(&testAccountExtra{})
+	defer func() { accountExtraType = nil }()
+
+	// Accounts without a payload must keep their canonical encoding
+	if root := state.IntermediateRoot(false); root != plainRoot {
+		t.Fatalf("root changed by registration: have %x, want %x", root, plainRoot)
+	}
+	// Payload changes must be reverted along with their snapshot
+	snap := state.Snapshot()
+	state.SetAccountExtra(addr, &testAccountExtra{IsMultiCoin: true})
+	if extra := state.GetAccountExtra(addr); !reflect.DeepEqual(extra, &testAccountExtra{IsMultiCoin: true}) {
+		t.Errorf("payload mismatch: have %+v", extra)
+	}
+	state.RevertToSnapshot(snap)
+	if extra := state.GetAccountExtra(addr); extra != nil {
+		t.Errorf("payload not reverted: have %+v", extra)
+	}
+	// Payloads must be covered by the state root and persisted
+	state.SetAccountExtra(addr, &testAccountExtra{IsMultiCoin: true})
+	root, err := state.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root == plainRoot {
+		t.Errorf("root not affected by payload")
+	}
+	state, _ = New(root, db)
+	if extra := state.GetAccountExtra(addr); !reflect.DeepEqual(extra, &testAccountExtra{IsMultiCoin: true}) {
+		t.Errorf("persisted payload mismatch: have %+v", extra)
+	}
+	if balance := state.GetBalance(addr); balance.Int64() != 1 {
+		t.Errorf("balance mismatch: have %v, want 1", balance)
+	}
+	// Copies must not share payloads
+	cpy := state.Copy()
+	cpy.SetAccountExtra(addr, &testAccountExtra{})
+	if extra := state.GetAccountExtra(addr); !reflect.DeepEqual(extra, &testAccountExtra{IsMultiCoin: true}) {
+		t.Errorf("payload modified through copy: have %+v", extra)
+	}
+	// Clearing the payload must restore the canonical encoding
+	state.SetAccountExtra(addr, nil)
+	if root := state.IntermediateRoot(false); root != plainRoot {
+		t.Errorf("root mismatch after clearing payload: have %x, want %x", root, plainRoot)
+	}
+}
+
+func TestAccountExtrasRLP(t *testing.T) {
+	RegisterAccountExtras(&testAccountExtra{})
+	defer func() { accountExtraType = nil }()
+
+	acc := Account{Nonce: 1, Balance: big.NewInt(2), CodeHash: emptyCodeHash, extra: &testAccountExtra{IsMultiCoin: true}}
+	enc, err := rlp.EncodeToBytes(acc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec Account
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Nonce != 1 || dec.Balance.Int64() != 2 || !bytes.Equal(dec.CodeHash, emptyCodeHash) || !reflect.DeepEqual(dec.extra, acc.extra) {
+		t.Errorf("decoded account mismatch: have %+v", dec)
+	}
+	if err := rlp.DecodeBytes(enc[:len(enc)-1], &dec); err == nil {
+		t.Errorf("truncated account decoded")
+	}
+}
+
+// Tests that extended accounts survive state sync and are walked entirely by
+// the state iterators, which depend on decoding accounts to find their storage.
+func TestAccountExtrasSyncAndIteration(t *testing.T) {
+	RegisterAccountExtras(&testAccountExtra{})
+	defer func() { accountExtraType = nil }()
+
+	var (
+		srcDb    = NewDatabase(rawdb.NewMemoryDatabase())
+		state, _ = New(common.Hash{}, srcDb)
+		addr     = common.Address{1}
+		key      = common.Hash{1}
+	)
+	state.SetBalance(addr, big.NewInt(1))
+	state.SetState(addr, key, common.Hash{2})
+	state.SetAccountExtra(addr, &testAccountExtra{IsMultiCoin: true})
+	root, err := state.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srcDb.TrieDB().Commit(root, false)
+
+	// Sync the state into an empty database
+	dstDb := rawdb.NewMemoryDatabase()
+	sched := NewStateSync(root, dstDb, trie.NewSyncBloom(1, dstDb))
+	for queue := sched.Missing(0); len(queue) > 0; queue = sched.Missing(0) {
+		results := make([]trie.SyncResult, len(queue))
+		for i, hash := range queue {
+			data, err := srcDb.TrieDB().Node(hash)
+			if err != nil {
+				t.Fatalf("failed to retrieve node data for %x", hash)
+			}
+			results[i] = trie.SyncResult{Hash: hash, Data: data}
+		}
+		if _, index, err := sched.Process(results); err != nil {
+			t.Fatalf("failed to process result #%d: %v", index, err)
+		}
+		if index, err := sched.Commit(dstDb); err != nil {
+			t.Fatalf("failed to commit data #%d: %v", index, err)
+		}
+	}
+	synced, err := New(root, NewDatabase(dstDb))
+	if err != nil {
+		t.Fatalf("failed to open synced state: %v", err)
+	}
+	if extra := synced.GetAccountExtra(addr); !reflect.DeepEqual(extra, &testAccountExtra{IsMultiCoin: true}) {
+		t.Errorf("synced payload mismatch: have %+v", extra)
+	}
+	if value := synced.GetState(addr, key); value != (common.Hash{2}) {
+		t.Errorf("synced storage mismatch: have %x", value)
+	}
+	// Iterators must descend into the storage of extended accounts
+	storageRoot := synced.StorageTrie(addr).Hash()
+	found := false
+	for it := NewNodeIterator(synced); it.Next(); {
+		if it.Hash == storageRoot {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("storage trie %x not iterated", storageRoot)
+	}
+	state, _ = New(root, srcDb) // dumps need the preimages of the source
+	dump := state.RawDump(false, false, false)
+	if acc, ok := dump.Accounts[addr]; !ok || len(acc.Storage) != 1 || !reflect.DeepEqual(acc.Extra, &testAccountExtra{IsMultiCoin: true}) {
+		t.Errorf("dumped account mismatch: have %+v", acc)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/ava-labs/go-ethereum/common"
+)
+
+// AccountHooks are invoked as accounts come into and go out of existence, e.g.
+// to maintain auxiliary indexes of the accounts holding some asset. Any of the
+// hooks may be nil.
+//
+// Hooks are only invoked once the changes are final, i.e. when a transaction
+// is finalised or the state is committed, so changes reverted along with their
+// snapshot are never reported. An account created and deleted again between
+// two finalisations isn't reported either.
+type AccountHooks struct {
+	// Created is invoked when an account is created at an address that held
+	// none.
+	Created func(db *StateDB, addr common.Address)
+
+	// Resurrected is invoked when an account is created at an address whose
+	// previous account was deleted earlier in the lifetime of the StateDB, or
+	// was overwritten by a contract creation, starting over with empty storage.
+	Resurrected func(db *StateDB, addr common.Address)
+
+	// Deleted is invoked when an account is deleted, either because it self
+	// destructed or because it was empty and touched (EIP-158).
+	Deleted func(db *StateDB, addr common.Address)
+}
+
+// accountHooks are the hooks registered with RegisterAccountHooks, if any.
+var accountHooks *AccountHooks
+
+// RegisterAccountHooks registers hooks invoked on account lifecycle events.
+//
+// It is not safe for concurrent use and must be called during initialisation.
+// It panics if hooks are already registered.
+func RegisterAccountHooks(hooks AccountHooks) {
+	if accountHooks != nil {
+		panic("state: account hooks already registered")
+	}
+	accountHooks = &hooks
+}
+
+// accountLifecycle is the lifecycle event of a state object that is yet to be
+// reported to the account hooks.
+type accountLifecycle uint8
+
+const (
+	accountUnchanged   accountLifecycle = iota // No pending event
+	accountCreated                             // Created at an address holding no account
+	accountResurrected                         // Created at an address whose account was deleted
+	accountReplaced                            // Created over an existing account
+)
+
+// lifecycleOf returns the lifecycle event of an account created at addr,
+// overwriting prev.
+func (self *StateDB) lifecycleOf(addr common.Address, prev *stateObject) accountLifecycle {
+	if prev != nil {
+		// Recreating an account not finalised yet doesn't change its event
+		if prev.lifecycle != accountUnchanged {
+			return prev.lifecycle
+		}
+		return accountReplaced
+	}
+	if obj := self.stateObjects[addr]; obj != nil && obj.deleted {
+		return accountResurrected
+	}
+	return accountCreated
+}
+
+// reportLifecycle reports the pending lifecycle event of a state object about
+// to be written to, or deleted from, the account trie to the account hooks.
+func (self *StateDB) reportLifecycle(obj *stateObject, deleting bool) {
+	event := obj.lifecycle
+	obj.lifecycle = accountUnchanged
+
+	if accountHooks == nil {
+		return
+	}
+	switch {
+	case deleting:
+		// Skip accounts already reported deleted and those never reported created
+		if obj.deleted || event == accountCreated || event == accountResurrected {
+			return
+		}
+		if accountHooks.Deleted != nil {
+			accountHooks.Deleted(self, obj.address)
+		}
+	case event == accountCreated:
+		if accountHooks.Created != nil {
+			accountHooks.Created(self, obj.address)
+		}
+	case event == accountResurrected || event == accountReplaced:
+		if accountHooks.Resurrected != nil {
+			accountHooks.Resurrected(self, obj.address)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+)
+
+func TestAccountHooks(t *testing.T) {
+	var events []string
+	record := func(event string) func(*StateDB, common.Address) {
+		return func(db *StateDB, addr common.Address) {
+			events = append(events, fmt.Sprintf("%s %x", event, addr[:1]))
+		}
+	}
+	RegisterAccountHooks(AccountHooks{
+		Created:     record("created"),
+		Resurrected: record("resurrected"),
+		Deleted:     record("deleted"),
+	})
+	defer func() { accountHooks = nil }()
+
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()))
+	check := func(want ...string) {
+		t.Helper()
+		if !reflect.DeepEqual(events, want) {
+			t.Errorf("events mismatch: have %q, want %q", events, want)
+		}
+		events = nil
+	}
+	// Reverted creations must not be reported
+	snap := state.Snapshot()
+	state.SetBalance(common.Address{1}, big.NewInt(1))
+	state.RevertToSnapshot(snap)
+	state.SetBalance(common.Address{2}, big.NewInt(1))
+	state.Finalise(true)
+	check("created 02")
+
+	// Accounts created and deleted within a transaction must not be reported
+	state.SetBalance(common.Address{3}, big.NewInt(1))
+	state.Suicide(common.Address{3})
+	state.Finalise(true)
+	check()
+
+	// Deleted accounts may be resurrected
+	state.Suicide(common.Address{2})
+	state.Finalise(true)
+	check("deleted 02")
+	state.SetBalance(common.Address{2}, big.NewInt(2))
+	state.Finalise(true)
+	check("resurrected 02")
+
+	// Contract creations over existing accounts start them over
+	state.CreateAccount(common.Address{2})
+	state.SetNonce(common.Address{2}, 1)
+	state.Finalise(true)
+	check("resurrected 02")
+
+	// Changes made since the last finalisation are reported on commit
+	state.AddBalance(common.Address{2}, new(big.Int))
+	state.SetBalance(common.Address{4}, big.NewInt(1))
+	state.Suicide(common.Address{2})
+	if _, err := state.Commit(true); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(events) // commit order is random
+	check("created 04", "deleted 02")
+}
+
+func TestRegisterAccountHooksTwice(t *testing.T) {
+	RegisterAccountHooks(AccountHooks{})
+	defer func() { accountHooks = nil }()
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic")
+		}
+	}()
+	RegisterAccountHooks(AccountHooks{})
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/types"
+)
+
+// BalanceObserver is invoked on every change of the ether balance of an
+// account, with the balances before and after the change. Changes undone by
+// reverting to a snapshot are reported again, with the BalanceChangeRevert
+// reason, so that observers see the net flows without diffing the state.
+//
+// Observers must not modify the state or the balances passed to them.
+type BalanceObserver func(addr common.Address, prev, new *big.Int, reason types.BalanceChangeReason)
+
+// balanceObserver is the observer registered with RegisterBalanceObserver, if
+// any.
+var balanceObserver BalanceObserver
+
+// RegisterBalanceObserver registers the observer invoked on balance changes.
+//
+// It is not safe for concurrent use and must be called during initialisation.
+// It panics if an observer is already registered.
+func RegisterBalanceObserver(observer BalanceObserver) {
+	if balanceObserver != nil {
+		panic("state: balance observer already registered")
+	}
+	balanceObserver = observer
+}
+
+// observeBalance reports a change of the account's balance from prev to its
+// current balance to the balance observer, if any.
+func (s *stateObject) observeBalance(prev *big.Int, reason types.BalanceChangeReason) {
+	if balanceObserver != nil && prev.Cmp(s.data.Balance) != 0 {
+		balanceObserver(s.address, prev, s.data.Balance, reason)
+	}
+}
+
+// AddBalanceWithReason adds amount to the account associated with addr,
+// reporting the change to the balance observer with the given reason.
+func (self *StateDB) AddBalanceWithReason(addr common.Address, amount *big.Int, reason types.BalanceChangeReason) {
+	stateObject := self.GetOrNewStateObject(addr)
+	if stateObject != nil {
+		stateObject.addBalance(amount, reason)
+	}
+}
+
+// SubBalanceWithReason subtracts amount from the account associated with addr,
+// reporting the change to the balance observer with the given reason.
+func (self *StateDB) SubBalanceWithReason(addr common.Address, amount *big.Int, reason types.BalanceChangeReason) {
+	stateObject := self.GetOrNewStateObject(addr)
+	if stateObject != nil {
+		stateObject.subBalance(amount, reason)
+	}
+}
+
+// SetBalanceWithReason sets the balance of the account associated with addr,
+// reporting the change to the balance observer with the given reason.
+func (self *StateDB) SetBalanceWithReason(addr common.Address, amount *big.Int, reason types.BalanceChangeReason) {
+	stateObject := self.GetOrNewStateObject(addr)
+	if stateObject != nil {
+		stateObject.updateBalance(amount, reason)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/core/types"
+)
+
+func TestBalanceObserver(t *testing.T) {
+	var changes []string
+	RegisterBalanceObserver(func(addr common.Address, prev, new *big.Int, reason types.BalanceChangeReason) {
+		changes = append(changes, fmt.Sprintf("%x %v->%v %v", addr[:1], prev, new, reason))
+	})
+	defer func() { balanceObserver = nil }()
+
+	var (
+		state, _ = New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()))
+		a, b     = common.Address{1}, common.Address{2}
+	)
+	state.AddBalanceWithReason(a, big.NewInt(10), types.BalanceChangeGenesis)
+	snap := state.Snapshot()
+	state.SubBalanceWithReason(a, big.NewInt(3), types.BalanceChangeTransfer)
+	state.AddBalanceWithReason(b, big.NewInt(3), types.BalanceChangeTransfer)
+	state.AddBalance(b, new(big.Int)) // no change, not reported
+	state.RevertToSnapshot(snap)
+	state.SetBalance(b, big.NewInt(5))
+	state.Suicide(b)
+
+	want := []string{
+		"01 0->10 genesis",
+		"01 10->7 transfer",
+		"02 0->3 transfer",
+		"02 3->0 revert",
+		"01 7->10 revert",
+		"02 0->5 unspecified",
+		"02 5->0 suicide",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes mismatch:\nhave %q\nwant %q", changes, want)
+	}
+}
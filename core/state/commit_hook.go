@@ -0,0 +1,98 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/trie"
+)
+
+// CommitHook is invoked by StateDB.Commit once the account trie is committed
+// to the trie database, with the new state root. It lets chain extensions
+// commit auxiliary data that must stay consistent with the state, e.g. the
+// root of an atomic trie.
+//
+// Blobs inserted into the trie database and referenced from the state root,
+// like contract code, are garbage collected along with the state and flushed
+// in the same database batch as it by trie.Database.Commit. References from
+// the root require it to be held in memory by the trie database, i.e. it must
+// be neither the empty root nor a root already flushed to disk.
+//
+// An error returned by the hook is returned by StateDB.Commit.
+type CommitHook func(db *StateDB, root common.Hash, triedb *trie.Database) error
+
+// commitHook is the hook registered with RegisterCommitHook, if any.
+var commitHook CommitHook
+
+// RegisterCommitHook registers the hook invoked on every state commit.
+//
+// It is not safe for concurrent use and must be called during initialisation.
+// It panics if a hook is already registered.
+func RegisterCommitHook(hook CommitHook) {
+	if commitHook != nil {
+		panic("state: commit hook already registered")
+	}
+	commitHook = hook
+}
+
+// IntermediateRootHook is invoked by StateDB.FinaliseTransaction with the root
+// of the account trie once the state is finalised after every transaction,
+// regardless of the fork. It lets chain extensions observe the root, or fold an
+// auxiliary commitment kept along with the state, e.g. the root of a side trie,
+// into it.
+//
+// The returned root goes into pre-Byzantium transaction receipts in place of
+// the account trie root, so hooks that only observe it must return it
+// unchanged. Later receipts carry no root and it is discarded. Block headers,
+// StateDB.Commit and New keep using the account trie root, as states are looked
+// up by it.
+type IntermediateRootHook func(db *StateDB, root common.Hash) common.Hash
+
+// intermediateRootHook is the hook registered with
+// RegisterIntermediateRootHook, if any.
+var intermediateRootHook IntermediateRootHook
+
+// RegisterIntermediateRootHook registers the hook invoked on the intermediate
+// root after every transaction.
+//
+// It is not safe for concurrent use and must be called during initialisation.
+// It panics if a hook is already registered.
+func RegisterIntermediateRootHook(hook IntermediateRootHook) {
+	if intermediateRootHook != nil {
+		panic("state: intermediate root hook already registered")
+	}
+	intermediateRootHook = hook
+}
+
+// FinaliseTransaction finalises the state once a transaction is applied. If
+// withRoot is set or an IntermediateRootHook is registered, it computes the
+// intermediate root like IntermediateRoot and passes it through the hook. The
+// resulting root is returned if withRoot is set, the zero hash otherwise.
+func (s *StateDB) FinaliseTransaction(deleteEmptyObjects, withRoot bool) common.Hash {
+	if !withRoot && intermediateRootHook == nil {
+		s.Finalise(deleteEmptyObjects)
+		return common.Hash{}
+	}
+	root := s.IntermediateRoot(deleteEmptyObjects)
+	if intermediateRootHook != nil {
+		root = intermediateRootHook(s, root)
+	}
+	if !withRoot {
+		return common.Hash{}
+	}
+	return root
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/crypto"
+	"github.com/ava-labs/go-ethereum/trie"
+)
+
+func TestCommitHook(t *testing.T) {
+	var (
+		blob     = []byte("atomic trie root")
+		hash     = crypto.Keccak256Hash(blob)
+		errHook  = errors.New("hook failed")
+		fail     bool
+		hookRoot common.Hash
+	)
+	RegisterCommitHook(func(db *StateDB, root common.Hash, triedb *trie.Database) error {
+		if fail {
+			return errHook
+		}
+		hookRoot = root
+		triedb.InsertBlob(hash, blob)
+		triedb.Reference(hash, root)
+		return nil
+	})
+	defer func() { commitHook = nil }()
+
+	var (
+		diskdb   = rawdb.NewMemoryDatabase()
+		db       = NewDatabase(diskdb)
+		state, _ = New(common.Hash{}, db)
+	)
+	state.SetBalance(common.Address{1}, big.NewInt(1))
+	root, err := state.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hookRoot != root {
+		t.Errorf("hook root mismatch: have %x, want %x", hookRoot, root)
+	}
+	// Referenced data must be flushed along with the state
+	if has, _ := diskdb.Has(hash[:]); has {
+		t.Fatalf("data flushed before the state")
+	}
+	if err := db.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	if enc, _ := diskdb.Get(hash[:]); string(enc) != string(blob) {
+		t.Errorf("flushed data mismatch: have %q, want %q", enc, blob)
+	}
+	// Hook failures must fail the commit
+	fail = true
+	state.SetBalance(common.Address{2}, big.NewInt(1))
+	if _, err := state.Commit(false); err != errHook {
+		t.Errorf("commit error mismatch: have %v, want %v", err, errHook)
+	}
+}
+
+func TestIntermediateRootHook(t *testing.T) {
+	var (
+		state, _ = New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()))
+		addr     = common.Address{1}
+		side     = common.Hash{0xaa}
+		txIndex  int
+	)
+	state.SetBalance(addr, big.NewInt(1))
+	plainRoot := state.IntermediateRoot(false)
+
+	RegisterIntermediateRootHook(func(db *StateDB, root common.Hash) common.Hash {
+		txIndex = db.TxIndex()
+		return crypto.Keccak256Hash(root[:], side[:])
+	})
+	defer func() { intermediateRootHook = nil }()
+
+	state.Prepare(common.Hash{}, common.Hash{}, 3)
+	if have, want := state.FinaliseTransaction(false, true), crypto.Keccak256Hash(plainRoot[:], side[:]); have != want {
+		t.Errorf("folded root mismatch: have %x, want %x", have, want)
+	}
+	// Roots must be passed to the hook even if not asked for
+	txIndex = 0
+	if root := state.FinaliseTransaction(false, false); root != (common.Hash{}) {
+		t.Errorf("unrequested root returned: %x", root)
+	}
+	if root := state.IntermediateRoot(false); root != plainRoot {
+		t.Errorf("intermediate root mismatch: have %x, want %x", root, plainRoot)
+	}
+	if txIndex != 3 {
+		t.Errorf("transaction index mismatch: have %d, want 3", txIndex)
+	}
+	// Committed roots must remain the account trie root
+	root, err := state.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != plainRoot {
+		t.Errorf("committed root mismatch: have %x, want %x", root, plainRoot)
+	}
+}
@@ -19,6 +19,7 @@ package state
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 
 	"github.com/ava-labs/go-ethereum/common"
 	"github.com/ava-labs/go-ethereum/common/hexutil"
@@ -35,8 +36,10 @@ type DumpAccount struct {
 	CodeHash  string                 `json:"codeHash"`
 	Code      string                 `json:"code,omitempty"`
 	Storage   map[common.Hash]string `json:"storage,omitempty"`
-	Address   *common.Address        `json:"address,omitempty"` // Address only present in iterative (line-by-line) mode
-	SecureKey hexutil.Bytes          `json:"key,omitempty"`     // If we don't have address, we can output the key
+	MultiCoin map[common.Hash]string `json:"multiCoin,omitempty"` // Multicoin balances by storage key, including the multicoin flag
+	Extra     interface{}            `json:"extra,omitempty"`     // Payload registered with RegisterAccountExtras
+	Address   *common.Address        `json:"address,omitempty"`   // Address only present in iterative (line-by-line) mode
+	SecureKey hexutil.Bytes          `json:"key,omitempty"`       // If we don't have address, we can output the key
 
 }
 
@@ -71,6 +74,8 @@ func (self iterativeDump) onAccount(addr common.Address, account DumpAccount) {
 		CodeHash:  account.CodeHash,
 		Code:      account.Code,
 		Storage:   account.Storage,
+		MultiCoin: account.MultiCoin,
+		Extra:     account.Extra,
 		SecureKey: account.SecureKey,
 		Address:   nil,
 	}
@@ -102,6 +107,7 @@ func (self *StateDB) dump(c collector, excludeCode, excludeStorage, excludeMissi
 			Nonce:    data.Nonce,
 			Root:     common.Bytes2Hex(data.Root[:]),
 			CodeHash: common.Bytes2Hex(data.CodeHash),
+			Extra:    data.extra,
 		}
 		if emptyAddress == addr {
 			// Preimage missing
@@ -114,8 +120,12 @@ func (self *StateDB) dump(c collector, excludeCode, excludeStorage, excludeMissi
 		if !excludeCode {
 			account.Code = common.Bytes2Hex(obj.Code(self.db))
 		}
-		if !excludeStorage {
-			account.Storage = make(map[common.Hash]string)
+		// Multicoin balances are kept in storage, but dumped even if storage
+		// is excluded, as they're part of the account's funds.
+		if !excludeStorage || multiCoinEnabled {
+			if !excludeStorage {
+				account.Storage = make(map[common.Hash]string)
+			}
 			storageIt := trie.NewIterator(obj.getTrie(self.db).NodeIterator(nil))
 			for storageIt.Next() {
 				_, content, _, err := rlp.Split(storageIt.Value)
@@ -123,7 +133,18 @@ func (self *StateDB) dump(c collector, excludeCode, excludeStorage, excludeMissi
 					log.Error("Failed to decode the value returned by iterator", "error", err)
 					continue
 				}
-				account.Storage[common.BytesToHash(self.trie.GetKey(storageIt.Key))] = common.Bytes2Hex(content)
+				key := common.BytesToHash(self.trie.GetKey(storageIt.Key))
+				switch {
+				case !multiCoinEnabled || normalizeStateKey(key) == key:
+					if !excludeStorage {
+						account.Storage[key] = common.Bytes2Hex(content)
+					}
+				default:
+					if account.MultiCoin == nil {
+						account.MultiCoin = make(map[common.Hash]string)
+					}
+					account.MultiCoin[key] = new(big.Int).SetBytes(content).String()
+				}
 			}
 		}
 		c.onAccount(addr, account)
@@ -20,6 +20,7 @@ import (
 	"math/big"
 
 	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/types"
 )
 
 // journalEntry is a modification entry in the state change journal that can be
@@ -153,8 +154,10 @@ func (ch resetObjectChange) dirtied() *common.Address {
 func (ch suicideChange) revert(s *StateDB) {
 	obj := s.getStateObject(*ch.account)
 	if obj != nil {
+		prev := obj.Balance()
 		obj.suicided = ch.prev
 		obj.setBalance(ch.prevbalance)
+		obj.observeBalance(prev, types.BalanceChangeRevert)
 	}
 }
 
@@ -172,7 +175,10 @@ func (ch touchChange) dirtied() *common.Address {
 }
 
 func (ch balanceChange) revert(s *StateDB) {
-	s.getStateObject(*ch.account).setBalance(ch.prev)
+	obj := s.getStateObject(*ch.account)
+	prev := obj.Balance()
+	obj.setBalance(ch.prev)
+	obj.observeBalance(prev, types.BalanceChangeRevert)
 }
 
 func (ch balanceChange) dirtied() *common.Address {
@@ -0,0 +1,52 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/ava-labs/go-ethereum/common"
+)
+
+// customChange is the journal entry of a modification made by a chain
+// extension, see AppendJournalEntry.
+type customChange struct {
+	undo    func()
+	account *common.Address
+}
+
+func (ch customChange) revert(s *StateDB) {
+	ch.undo()
+}
+
+func (ch customChange) dirtied() *common.Address {
+	return ch.account
+}
+
+// AppendJournalEntry records a modification of auxiliary state kept by a chain
+// extension, so that it's reverted along with the state: revert is invoked if
+// a snapshot taken before the modification is reverted to. Entries are
+// discarded once the transaction is finalised.
+//
+// If dirtied is not nil, the account with the given address is considered
+// modified, like by any other state change, e.g. subjecting it to EIP-158
+// removal if empty.
+func (self *StateDB) AppendJournalEntry(revert func(), dirtied *common.Address) {
+	if dirtied != nil {
+		addr := *dirtied
+		dirtied = &addr
+	}
+	self.journal.append(customChange{undo: revert, account: dirtied})
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+)
+
+func TestAppendJournalEntry(t *testing.T) {
+	var (
+		state, _ = New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()))
+		addr     = common.Address{1}
+		counter  int
+	)
+	increment := func() {
+		counter++
+		state.AppendJournalEntry(func() { counter-- }, &addr)
+	}
+	increment()
+	snap := state.Snapshot()
+	increment()
+	increment()
+	state.RevertToSnapshot(snap)
+	if counter != 1 {
+		t.Errorf("counter mismatch after revert: have %d, want 1", counter)
+	}
+	// Dirtied accounts must be treated as modified
+	state.SetBalance(addr, big.NewInt(1))
+	state.Finalise(false)
+	state.SubBalance(addr, big.NewInt(1))
+	state.Finalise(false)
+
+	increment()
+	state.Finalise(true)
+	if state.Exist(addr) {
+		t.Errorf("dirtied empty account not removed")
+	}
+	// Finalised entries must not be reverted
+	state.RevertToSnapshot(state.Snapshot())
+	if counter != 2 {
+		t.Errorf("counter mismatch after finalisation: have %d, want 2", counter)
+	}
+}
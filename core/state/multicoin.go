@@ -0,0 +1,130 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+
+	"github.com/ava-labs/go-ethereum/common"
+)
+
+// multiCoinEnabled reports whether EnableMultiCoin was called.
+var multiCoinEnabled bool
+
+// EnableMultiCoin enables balances of native assets other than ether, kept in
+// the storage of the holding accounts. To keep them out of reach of contract
+// code, the storage keys accessed through GetState, SetState and friends then
+// have the lowest bit of their first byte cleared, while the keys of balances
+// have it set. Coin IDs are thus only distinguished up to that bit, and the
+// coin IDs 0x00 and 0x01 followed by zeros are reserved.
+//
+// Accounts holding multicoin balances are never considered empty, even once
+// the balances are spent, so that they aren't removed under EIP-158.
+//
+// EnableMultiCoin changes the storage layout of every account and must be
+// called during initialisation, before any state is accessed. It panics if
+// called more than once.
+func EnableMultiCoin() {
+	if multiCoinEnabled {
+		panic("state: multicoin balances already enabled")
+	}
+	multiCoinEnabled = true
+}
+
+// normalizeCoinID returns the storage key of the balance of the given coin.
+func normalizeCoinID(coinID common.Hash) common.Hash {
+	coinID[0] |= 0x01
+	return coinID
+}
+
+// normalizeStateKey returns the storage key accessed for the given key by
+// contract code, keeping it apart from the keys of multicoin balances.
+func normalizeStateKey(key common.Hash) common.Hash {
+	if multiCoinEnabled {
+		key[0] &^= 0x01
+	}
+	return key
+}
+
+// multiCoinFlagKey is the storage key marking accounts that hold, or held,
+// multicoin balances.
+var multiCoinFlagKey = normalizeCoinID(common.Hash{})
+
+// multiCoinFlag is the value stored at multiCoinFlagKey.
+var multiCoinFlag = common.BigToHash(common.Big1)
+
+// multiCoin returns whether the account holds, or held, multicoin balances.
+func (s *stateObject) multiCoin() bool {
+	return multiCoinEnabled && s.GetState(s.db.db, multiCoinFlagKey) == multiCoinFlag
+}
+
+// enableMultiCoin marks the account as holding multicoin balances.
+func (s *stateObject) enableMultiCoin() {
+	if !s.multiCoin() {
+		s.SetState(s.db.db, multiCoinFlagKey, multiCoinFlag)
+	}
+}
+
+// balanceMultiCoin returns the account's balance of the given coin.
+func (s *stateObject) balanceMultiCoin(coinID common.Hash) *big.Int {
+	return s.GetState(s.db.db, normalizeCoinID(coinID)).Big()
+}
+
+// setBalanceMultiCoin sets the account's balance of the given coin.
+func (s *stateObject) setBalanceMultiCoin(coinID common.Hash, amount *big.Int) {
+	s.enableMultiCoin()
+	s.SetState(s.db.db, normalizeCoinID(coinID), common.BigToHash(amount))
+}
+
+// GetBalanceMultiCoin retrieves the balance of the given coin held by the given
+// address, or 0 if the account doesn't exist. Multicoin balances must have been
+// enabled with EnableMultiCoin.
+func (self *StateDB) GetBalanceMultiCoin(addr common.Address, coinID common.Hash) *big.Int {
+	stateObject := self.getStateObject(addr)
+	if stateObject != nil {
+		return stateObject.balanceMultiCoin(coinID)
+	}
+	return common.Big0
+}
+
+// AddBalanceMultiCoin adds amount of the given coin to the account associated
+// with addr. Like all storage modifications, it is reverted along with the
+// snapshot it was made in.
+func (self *StateDB) AddBalanceMultiCoin(addr common.Address, coinID common.Hash, amount *big.Int) {
+	stateObject := self.GetOrNewStateObject(addr)
+	if stateObject == nil {
+		return
+	}
+	// EIP158: Touch empty accounts like ether transfers do
+	if amount.Sign() == 0 {
+		if stateObject.empty() {
+			stateObject.touch()
+		}
+		return
+	}
+	stateObject.setBalanceMultiCoin(coinID, new(big.Int).Add(stateObject.balanceMultiCoin(coinID), amount))
+}
+
+// SubBalanceMultiCoin subtracts amount of the given coin from the account
+// associated with addr. The caller must ensure the balance covers the amount.
+func (self *StateDB) SubBalanceMultiCoin(addr common.Address, coinID common.Hash, amount *big.Int) {
+	stateObject := self.GetOrNewStateObject(addr)
+	if stateObject == nil || amount.Sign() == 0 {
+		return
+	}
+	stateObject.setBalanceMultiCoin(coinID, new(big.Int).Sub(stateObject.balanceMultiCoin(coinID), amount))
+}
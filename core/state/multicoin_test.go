@@ -0,0 +1,116 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+)
+
+func TestMultiCoinBalances(t *testing.T) {
+	EnableMultiCoin()
+	defer func() { multiCoinEnabled = false }()
+
+	var (
+		db       = NewDatabase(rawdb.NewMemoryDatabase())
+		state, _ = New(common.Hash{}, db)
+		addr     = common.Address{1}
+		coin     = common.Hash{0x02, 0xaa}
+	)
+	state.AddBalanceMultiCoin(addr, coin, big.NewInt(100))
+
+	// Changes must be reverted along with their snapshot
+	snap := state.Snapshot()
+	state.SubBalanceMultiCoin(addr, coin, big.NewInt(30))
+	if balance := state.GetBalanceMultiCoin(addr, coin); balance.Int64() != 70 {
+		t.Errorf("balance mismatch: have %v, want 70", balance)
+	}
+	state.RevertToSnapshot(snap)
+	if balance := state.GetBalanceMultiCoin(addr, coin); balance.Int64() != 100 {
+		t.Errorf("balance mismatch after revert: have %v, want 100", balance)
+	}
+	// Contract code must not be able to reach the balances
+	state.SetState(addr, normalizeCoinID(coin), common.BigToHash(big.NewInt(1000)))
+	if balance := state.GetBalanceMultiCoin(addr, coin); balance.Int64() != 100 {
+		t.Errorf("balance modified through storage: have %v, want 100", balance)
+	}
+	if value := state.GetState(addr, coin); value != common.BigToHash(big.NewInt(1000)) {
+		t.Errorf("normalized storage mismatch: have %x", value)
+	}
+	// Accounts holding multicoin balances must survive EIP-158 clearing,
+	// even once the balances are spent
+	state.SubBalanceMultiCoin(addr, coin, big.NewInt(100))
+	state.Finalise(true)
+	if state.Empty(addr) || !state.Exist(addr) {
+		t.Fatalf("multicoin account considered empty")
+	}
+	root, err := state.Commit(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, _ = New(root, db)
+	if state.Empty(addr) {
+		t.Errorf("multicoin account considered empty after commit")
+	}
+	if balance := state.GetBalanceMultiCoin(addr, coin); balance.Sign() != 0 {
+		t.Errorf("balance mismatch after commit: have %v, want 0", balance)
+	}
+	// Plain accounts are still cleared
+	state.AddBalanceMultiCoin(common.Address{2}, coin, new(big.Int))
+	state.Finalise(true)
+	if state.Exist(common.Address{2}) {
+		t.Errorf("touched empty account not cleared")
+	}
+}
+
+func TestMultiCoinDump(t *testing.T) {
+	EnableMultiCoin()
+	defer func() { multiCoinEnabled = false }()
+
+	var (
+		db       = NewDatabase(rawdb.NewMemoryDatabase())
+		state, _ = New(common.Hash{}, db)
+		addr     = common.Address{1}
+		coin     = common.Hash{0x02, 0xaa}
+		key      = common.Hash{0x03}
+	)
+	state.AddBalanceMultiCoin(addr, coin, big.NewInt(100))
+	state.SetState(addr, key, common.Hash{0x04})
+	root, err := state.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, _ = New(root, db)
+
+	// Balances must be kept apart from storage, and dumped even without it
+	want := map[common.Hash]string{normalizeCoinID(coin): "100", multiCoinFlagKey: "1"}
+	acc := state.RawDump(true, false, false).Accounts[addr]
+	if !reflect.DeepEqual(acc.MultiCoin, want) {
+		t.Errorf("dumped balances mismatch: have %v, want %v", acc.MultiCoin, want)
+	}
+	if len(acc.Storage) != 1 || acc.Storage[normalizeStateKey(key)] == "" {
+		t.Errorf("dumped storage mismatch: have %v", acc.Storage)
+	}
+	acc = state.RawDump(true, true, false).Accounts[addr]
+	if !reflect.DeepEqual(acc.MultiCoin, want) || acc.Storage != nil {
+		t.Errorf("dumped account without storage mismatch: have %+v", acc)
+	}
+}
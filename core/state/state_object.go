@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/types"
 	"github.com/ava-labs/go-ethereum/crypto"
 	"github.com/ava-labs/go-ethereum/metrics"
 	"github.com/ava-labs/go-ethereum/rlp"
@@ -89,11 +90,13 @@ type stateObject struct {
 	dirtyCode bool // true if the code was updated
 	suicided  bool
 	deleted   bool
+
+	lifecycle accountLifecycle // Lifecycle event not yet reported to the account hooks
 }
 
 // empty returns whether the account is considered empty.
 func (s *stateObject) empty() bool {
-	return s.data.Nonce == 0 && s.data.Balance.Sign() == 0 && bytes.Equal(s.data.CodeHash, emptyCodeHash)
+	return s.data.Nonce == 0 && s.data.Balance.Sign() == 0 && bytes.Equal(s.data.CodeHash, emptyCodeHash) && !s.multiCoin()
 }
 
 // Account is the Ethereum consensus representation of accounts.
@@ -103,6 +106,8 @@ type Account struct {
 	Balance  *big.Int
 	Root     common.Hash // merkle root of the storage trie
 	CodeHash []byte
+
+	extra interface{} // Payload registered with RegisterAccountExtras, if any
 }
 
 // newObject creates a state object.
@@ -312,6 +317,10 @@ func (s *stateObject) CommitTrie(db Database) error {
 // AddBalance removes amount from c's balance.
 // It is used to add funds to the destination account of a transfer.
 func (s *stateObject) AddBalance(amount *big.Int) {
+	s.addBalance(amount, types.BalanceChangeUnspecified)
+}
+
+func (s *stateObject) addBalance(amount *big.Int, reason types.BalanceChangeReason) {
 	// EIP158: We must check emptiness for the objects such that the account
 	// clearing (0,0,0 objects) can take effect.
 	if amount.Sign() == 0 {
@@ -321,24 +330,34 @@ func (s *stateObject) AddBalance(amount *big.Int) {
 
 		return
 	}
-	s.SetBalance(new(big.Int).Add(s.Balance(), amount))
+	s.updateBalance(new(big.Int).Add(s.Balance(), amount), reason)
 }
 
 // SubBalance removes amount from c's balance.
 // It is used to remove funds from the origin account of a transfer.
 func (s *stateObject) SubBalance(amount *big.Int) {
+	s.subBalance(amount, types.BalanceChangeUnspecified)
+}
+
+func (s *stateObject) subBalance(amount *big.Int, reason types.BalanceChangeReason) {
 	if amount.Sign() == 0 {
 		return
 	}
-	s.SetBalance(new(big.Int).Sub(s.Balance(), amount))
+	s.updateBalance(new(big.Int).Sub(s.Balance(), amount), reason)
 }
 
 func (s *stateObject) SetBalance(amount *big.Int) {
+	s.updateBalance(amount, types.BalanceChangeUnspecified)
+}
+
+func (s *stateObject) updateBalance(amount *big.Int, reason types.BalanceChangeReason) {
+	prev := new(big.Int).Set(s.data.Balance)
 	s.db.journal.append(balanceChange{
 		account: &s.address,
-		prev:    new(big.Int).Set(s.data.Balance),
+		prev:    prev,
 	})
 	s.setBalance(amount)
+	s.observeBalance(prev, reason)
 }
 
 func (s *stateObject) setBalance(amount *big.Int) {
@@ -350,6 +369,7 @@ func (s *stateObject) ReturnGas(gas *big.Int) {}
 
 func (s *stateObject) deepCopy(db *StateDB) *stateObject {
 	stateObject := newObject(db, s.address, s.data)
+	stateObject.data.extra = copyAccountExtra(s.data.extra)
 	if s.trie != nil {
 		stateObject.trie = db.db.CopyTrie(s.trie)
 	}
@@ -359,6 +379,7 @@ func (s *stateObject) deepCopy(db *StateDB) *stateObject {
 	stateObject.suicided = s.suicided
 	stateObject.dirtyCode = s.dirtyCode
 	stateObject.deleted = s.deleted
+	stateObject.lifecycle = s.lifecycle
 	return stateObject
 }
 
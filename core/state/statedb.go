@@ -87,6 +87,12 @@ type StateDB struct {
 
 	preimages map[common.Hash][]byte
 
+	// Transient storage of the current transaction (EIP-1153)
+	transientStorage map[common.Address]Storage
+
+	// Receipt of the implicit messages executed at the block boundaries
+	implicitReceipt *types.Receipt
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -148,6 +154,7 @@ func (self *StateDB) Reset(root common.Hash) error {
 	self.logs = make(map[common.Hash][]*types.Log)
 	self.logSize = 0
 	self.preimages = make(map[common.Hash][]byte)
+	self.implicitReceipt = nil
 	self.clearJournalAndRefund()
 	return nil
 }
@@ -282,7 +289,7 @@ func (self *StateDB) GetCodeHash(addr common.Address) common.Hash {
 func (self *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
-		return stateObject.GetState(self.db, hash)
+		return stateObject.GetState(self.db, normalizeStateKey(hash))
 	}
 	return common.Hash{}
 }
@@ -301,6 +308,7 @@ func (self *StateDB) GetStorageProof(a common.Address, key common.Hash) ([][]byt
 	if trie == nil {
 		return proof, errors.New("storage trie for requested address does not exist")
 	}
+	key = normalizeStateKey(key)
 	err := trie.Prove(crypto.Keccak256(key.Bytes()), 0, &proof)
 	return [][]byte(proof), err
 }
@@ -309,7 +317,7 @@ func (self *StateDB) GetStorageProof(a common.Address, key common.Hash) ([][]byt
 func (self *StateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
-		return stateObject.GetCommittedState(self.db, hash)
+		return stateObject.GetCommittedState(self.db, normalizeStateKey(hash))
 	}
 	return common.Hash{}
 }
@@ -382,7 +390,7 @@ func (self *StateDB) SetCode(addr common.Address, code []byte) {
 func (self *StateDB) SetState(addr common.Address, key, value common.Hash) {
 	stateObject := self.GetOrNewStateObject(addr)
 	if stateObject != nil {
-		stateObject.SetState(self.db, key, value)
+		stateObject.SetState(self.db, normalizeStateKey(key), value)
 	}
 }
 
@@ -405,13 +413,15 @@ func (self *StateDB) Suicide(addr common.Address) bool {
 	if stateObject == nil {
 		return false
 	}
+	prevbalance := new(big.Int).Set(stateObject.Balance())
 	self.journal.append(suicideChange{
 		account:     &addr,
 		prev:        stateObject.suicided,
-		prevbalance: new(big.Int).Set(stateObject.Balance()),
+		prevbalance: prevbalance,
 	})
 	stateObject.markSuicided()
 	stateObject.data.Balance = new(big.Int)
+	stateObject.observeBalance(prevbalance, types.BalanceChangeSuicide)
 
 	return true
 }
@@ -498,6 +508,7 @@ func (self *StateDB) createObject(addr common.Address) (newobj, prev *stateObjec
 	prev = self.getStateObject(addr)
 	newobj = newObject(self, addr, Account{})
 	newobj.setNonce(0) // sets the object to dirty
+	newobj.lifecycle = self.lifecycleOf(addr, prev)
 	if prev == nil {
 		self.journal.append(createObjectChange{account: &addr})
 	} else {
@@ -599,6 +610,8 @@ func (self *StateDB) Copy() *StateDB {
 	for hash, preimage := range self.preimages {
 		state.preimages[hash] = preimage
 	}
+	state.transientStorage = self.copyTransientStorage()
+	state.implicitReceipt = self.implicitReceipt
 	return state
 }
 
@@ -647,8 +660,10 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 		}
 
 		if stateObject.suicided || (deleteEmptyObjects && stateObject.empty()) {
+			s.reportLifecycle(stateObject, true)
 			s.deleteStateObject(stateObject)
 		} else {
+			s.reportLifecycle(stateObject, false)
 			stateObject.updateRoot(s.db)
 			s.updateStateObject(stateObject)
 		}
@@ -672,11 +687,27 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 }
 
 // Prepare sets the current transaction hash and index and block hash which is
-// used when the EVM emits new state logs.
+// used when the EVM emits new state logs. It also discards the transient storage
+// of the previous transaction.
 func (self *StateDB) Prepare(thash, bhash common.Hash, ti int) {
 	self.thash = thash
 	self.bhash = bhash
 	self.txIndex = ti
+	self.transientStorage = nil
+}
+
+// ImplicitReceipt returns the receipt of the implicit messages executed at the
+// boundaries of the block the state belongs to, or nil if none were executed.
+// It is stored along with the block.
+func (self *StateDB) ImplicitReceipt() *types.Receipt {
+	return self.implicitReceipt
+}
+
+// SetImplicitReceipt sets the receipt of the implicit messages executed at the
+// boundaries of the block the state belongs to. The receipt is shared between
+// copies of the state and must not be modified once set.
+func (self *StateDB) SetImplicitReceipt(receipt *types.Receipt) {
+	self.implicitReceipt = receipt
 }
 
 func (s *StateDB) clearJournalAndRefund() {
@@ -699,8 +730,11 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 		case stateObject.suicided || (isDirty && deleteEmptyObjects && stateObject.empty()):
 			// If the object has been removed, don't bother syncing it
 			// and just mark it for deletion in the trie.
+			s.reportLifecycle(stateObject, true)
 			s.deleteStateObject(stateObject)
 		case isDirty:
+			s.reportLifecycle(stateObject, false)
+
 			// Write any contract code associated with the state object
 			if stateObject.code != nil && stateObject.dirtyCode {
 				s.db.TrieDB().InsertBlob(common.BytesToHash(stateObject.CodeHash()), stateObject.code)
@@ -733,5 +767,8 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 		}
 		return nil
 	})
+	if err == nil && commitHook != nil {
+		err = commitHook(s, root, s.db.TrieDB())
+	}
 	return root, err
 }
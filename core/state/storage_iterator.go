@@ -0,0 +1,91 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/crypto"
+	"github.com/ava-labs/go-ethereum/rlp"
+	"github.com/ava-labs/go-ethereum/trie"
+)
+
+// IterateStorage calls cb with up to limit non-empty storage slots of the
+// account associated with addr, in ascending order of the hashes of their keys,
+// starting at the given key hash. It returns the key hash to resume from, or
+// nil once all slots were visited.
+//
+// Unlike ForEachStorage, it includes modifications not yet committed to the
+// storage trie and doesn't rely on the preimages of keys, so its results are
+// deterministic and it may be used by consensus code, e.g. stateful
+// precompiles enumerating their own storage. Slots are identified by the hash
+// of their key only.
+func (self *StateDB) IterateStorage(addr common.Address, start common.Hash, limit int, cb func(keyHash, value common.Hash)) (*common.Hash, error) {
+	stateObject := self.getStateObject(addr)
+	if stateObject == nil {
+		return nil, nil
+	}
+	// Gather the modifications at or after the start, overriding the trie
+	var (
+		dirty   = make(map[common.Hash]common.Hash)
+		pending []common.Hash
+	)
+	for key, value := range stateObject.dirtyStorage {
+		hash := crypto.Keccak256Hash(key[:])
+		if bytes.Compare(hash[:], start[:]) >= 0 {
+			dirty[hash] = value
+			pending = append(pending, hash)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return bytes.Compare(pending[i][:], pending[j][:]) < 0
+	})
+	// Merge the modifications into the slots of the trie
+	var (
+		it      = trie.NewIterator(stateObject.getTrie(self.db).NodeIterator(start[:]))
+		hasNext = it.Next()
+		visited int
+	)
+	for hasNext || len(pending) > 0 {
+		var hash, value common.Hash
+		if len(pending) > 0 && (!hasNext || bytes.Compare(pending[0][:], it.Key) <= 0) {
+			hash, value = pending[0], dirty[pending[0]]
+			if hasNext && bytes.Equal(hash[:], it.Key) {
+				hasNext = it.Next()
+			}
+			pending = pending[1:]
+		} else {
+			_, content, _, err := rlp.Split(it.Value)
+			if err != nil {
+				return nil, err
+			}
+			hash, value = common.BytesToHash(it.Key), common.BytesToHash(content)
+			hasNext = it.Next()
+		}
+		if value == (common.Hash{}) {
+			continue
+		}
+		if visited == limit {
+			return &hash, nil
+		}
+		cb(hash, value)
+		visited++
+	}
+	return nil, it.Err
+}
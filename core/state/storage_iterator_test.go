@@ -0,0 +1,96 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/crypto"
+)
+
+func TestIterateStorage(t *testing.T) {
+	var (
+		db       = NewDatabase(rawdb.NewMemoryDatabase())
+		state, _ = New(common.Hash{}, db)
+		addr     = common.Address{1}
+		want     = make(map[common.Hash]common.Hash)
+	)
+	set := func(key, value byte) {
+		state.SetState(addr, common.Hash{key}, common.Hash{value})
+		hash := crypto.Keccak256Hash(common.Hash{key}.Bytes())
+		if value == 0 {
+			delete(want, hash)
+		} else {
+			want[hash] = common.Hash{value}
+		}
+	}
+	for i := byte(1); i <= 20; i++ {
+		set(i, i)
+	}
+	root, _ := state.Commit(false)
+	state, _ = New(root, db)
+
+	// Overlay committed slots with uncommitted modifications
+	set(21, 21)
+	set(3, 33)
+	set(4, 0)
+	set(5, 0)
+
+	var hashes []common.Hash
+	for hash := range want {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+
+	for _, limit := range []int{1, 3, 100} {
+		var (
+			have  []common.Hash
+			start common.Hash
+		)
+		for batches := 0; ; batches++ {
+			if batches > len(want) {
+				t.Fatalf("limit %d: iteration not terminating", limit)
+			}
+			var visited int
+			next, err := state.IterateStorage(addr, start, limit, func(hash, value common.Hash) {
+				if value != want[hash] {
+					t.Errorf("limit %d: value mismatch for %x: have %x, want %x", limit, hash, value, want[hash])
+				}
+				have = append(have, hash)
+				visited++
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if visited > limit {
+				t.Errorf("limit %d: visited %d slots", limit, visited)
+			}
+			if next == nil {
+				break
+			}
+			start = *next
+		}
+		if !reflect.DeepEqual(have, hashes) {
+			t.Errorf("limit %d: slots mismatch: have %d, want %d", limit, len(have), len(hashes))
+		}
+	}
+}
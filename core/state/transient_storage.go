@@ -0,0 +1,82 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/ava-labs/go-ethereum/common"
+)
+
+// transientStorageChange is the journal entry of a transient storage write.
+type transientStorageChange struct {
+	account       *common.Address
+	key, prevalue common.Hash
+}
+
+func (ch transientStorageChange) revert(s *StateDB) {
+	s.setTransientState(*ch.account, ch.key, ch.prevalue)
+}
+
+func (ch transientStorageChange) dirtied() *common.Address {
+	return nil
+}
+
+// GetTransientState retrieves a value from the transient storage of the given
+// account (EIP-1153), which is discarded at the end of every transaction.
+func (self *StateDB) GetTransientState(addr common.Address, key common.Hash) common.Hash {
+	return self.transientStorage[addr][key]
+}
+
+// SetTransientState sets a value in the transient storage of the given account.
+// Like all state modifications, it is reverted along with its snapshot.
+func (self *StateDB) SetTransientState(addr common.Address, key, value common.Hash) {
+	prev := self.GetTransientState(addr, key)
+	if prev == value {
+		return
+	}
+	self.journal.append(transientStorageChange{
+		account:  &addr,
+		key:      key,
+		prevalue: prev,
+	})
+	self.setTransientState(addr, key, value)
+}
+
+func (self *StateDB) setTransientState(addr common.Address, key, value common.Hash) {
+	if value == (common.Hash{}) {
+		delete(self.transientStorage[addr], key)
+		return
+	}
+	if self.transientStorage == nil {
+		self.transientStorage = make(map[common.Address]Storage)
+	}
+	if self.transientStorage[addr] == nil {
+		self.transientStorage[addr] = make(Storage)
+	}
+	self.transientStorage[addr][key] = value
+}
+
+// copyTransientStorage returns a deep copy of the transient storage.
+func (self *StateDB) copyTransientStorage() map[common.Address]Storage {
+	if self.transientStorage == nil {
+		return nil
+	}
+	cpy := make(map[common.Address]Storage, len(self.transientStorage))
+	for addr, storage := range self.transientStorage {
+		cpy[addr] = storage.Copy()
+	}
+	return cpy
+}
@@ -0,0 +1,54 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+)
+
+func TestTransientStorage(t *testing.T) {
+	var (
+		state, _ = New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()))
+		addr     = common.Address{1}
+		key      = common.Hash{2}
+		value    = common.Hash{3}
+	)
+	snap := state.Snapshot()
+	state.SetTransientState(addr, key, value)
+	if have := state.GetTransientState(addr, key); have != value {
+		t.Fatalf("value mismatch: have %x, want %x", have, value)
+	}
+	if have := state.Copy().GetTransientState(addr, key); have != value {
+		t.Errorf("copied value mismatch: have %x, want %x", have, value)
+	}
+	state.RevertToSnapshot(snap)
+	if have := state.GetTransientState(addr, key); have != (common.Hash{}) {
+		t.Errorf("value not reverted: have %x", have)
+	}
+	state.SetTransientState(addr, key, value)
+	state.Prepare(common.Hash{}, common.Hash{}, 1)
+	if have := state.GetTransientState(addr, key); have != (common.Hash{}) {
+		t.Errorf("value not discarded by next transaction: have %x", have)
+	}
+	// Transient storage must never reach the persistent state
+	if state.Exist(addr) {
+		t.Errorf("account created by transient storage write")
+	}
+}
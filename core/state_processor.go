@@ -23,7 +23,6 @@ import (
 	"github.com/ava-labs/go-ethereum/core/state"
 	"github.com/ava-labs/go-ethereum/core/types"
 	"github.com/ava-labs/go-ethereum/core/vm"
-	"github.com/ava-labs/go-ethereum/crypto"
 	"github.com/ava-labs/go-ethereum/params"
 )
 
@@ -46,6 +45,86 @@ func NewStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consen
 	}
 }
 
+// ProcessorHooks run chain specific logic at block and transaction boundaries,
+// e.g. reading fee configs, settling block gas costs, applying upgrades or
+// storing predicate results. The block hooks are invoked by every block
+// producer and processor in this package, and must be invoked by other block
+// producers through ApplyBlockPreamble and ApplyBlockEnd. Any of the hooks may be
+// nil, and an error returned by any of them renders the block invalid.
+type ProcessorHooks struct {
+	// BlockStart is invoked before the transactions of a block are applied,
+	// after any hard-fork specific state mutations.
+	BlockStart func(config *params.ChainConfig, header *types.Header, statedb *state.StateDB) error
+
+	// BlockEnd is invoked once all transactions of a block are applied, with
+	// their receipts, before the consensus engine finalizes the block.
+	BlockEnd func(config *params.ChainConfig, header *types.Header, statedb *state.StateDB, receipts types.Receipts) error
+
+	// TxStart is invoked by ApplyTransaction before a transaction is applied,
+	// with the message it was converted to.
+	TxStart func(config *params.ChainConfig, header *types.Header, tx *types.Transaction, msg Message, statedb *state.StateDB) error
+
+	// TxEnd is invoked by ApplyTransaction once a transaction is applied, with
+	// its receipt, e.g. to set the receipt's extras. State changes made by the
+	// hook are finalised along with the following transaction or the block.
+	TxEnd func(config *params.ChainConfig, header *types.Header, tx *types.Transaction, msg Message, statedb *state.StateDB, receipt *types.Receipt) error
+}
+
+// processorHooks are the hooks registered with RegisterProcessorHooks, if any.
+var processorHooks ProcessorHooks
+
+// processorHooksRegistered reports whether RegisterProcessorHooks was called.
+var processorHooksRegistered bool
+
+// RegisterProcessorHooks registers hooks invoked at block boundaries.
+//
+// It is not safe for concurrent use and must be called during initialisation.
+// It panics if hooks are already registered.
+func RegisterProcessorHooks(hooks ProcessorHooks) {
+	if processorHooksRegistered {
+		panic("core: processor hooks already registered")
+	}
+	processorHooks, processorHooksRegistered = hooks, true
+}
+
+// ApplyBlockStart invokes the registered BlockStart hook, if any. An error
+// renders the block invalid.
+func ApplyBlockStart(config *params.ChainConfig, header *types.Header, statedb *state.StateDB) error {
+	if processorHooks.BlockStart == nil {
+		return nil
+	}
+	return processorHooks.BlockStart(config, header, statedb)
+}
+
+// ApplyBlockEnd invokes the registered BlockEnd hook, if any. An error renders
+// the block invalid.
+func ApplyBlockEnd(config *params.ChainConfig, header *types.Header, statedb *state.StateDB, receipts types.Receipts) error {
+	if processorHooks.BlockEnd == nil {
+		return nil
+	}
+	return processorHooks.BlockEnd(config, header, statedb, receipts)
+}
+
+// ApplyBlockPreamble applies the state changes preceding the transactions of the
+// block with the given header on top of the state of its parent: hard-fork
+// specific mutations, scheduled state upgrades, the BlockStart hook and the
+// implicit messages of the block start. Everything executing the transactions
+// of a block, e.g. block producers and tracers, must apply it first, so that
+// they run against the same state as during block processing. The author is
+// passed on to the implicit messages, see ApplyTransaction.
+func ApplyBlockPreamble(config *params.ChainConfig, bc ChainContext, author *common.Address, parent, header *types.Header, statedb *state.StateDB, cfg vm.Config) error {
+	// Mutate the block and state according to any hard-fork specs
+	if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(header.Number) == 0 {
+		misc.ApplyDAOHardFork(statedb)
+	}
+	ApplyStateUpgrades(config, parent.Time, header, statedb)
+	if err := ApplyBlockStart(config, header, statedb); err != nil {
+		return err
+	}
+	ApplyImplicitBlockStart(config, bc, author, header, statedb, cfg)
+	return nil
+}
+
 // Process processes the state changes according to the Ethereum rules by running
 // the transaction messages using the statedb and applying any rewards to both
 // the processor (coinbase) and any included uncles.
@@ -59,11 +138,19 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		usedGas  = new(uint64)
 		header   = block.Header()
 		allLogs  []*types.Log
-		gp       = new(GasPool).AddGas(block.GasLimit())
+		gp       = NewBlockGasPool(p.config, header)
 	)
-	// Mutate the block and state according to any hard-fork specs
-	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0 {
-		misc.ApplyDAOHardFork(statedb)
+	parent := p.bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, nil, 0, consensus.ErrUnknownAncestor
+	}
+	if err := ApplyBlockPreamble(p.config, p.bc, nil, parent, header, statedb, cfg); err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Collect the opcode statistics of this block alone if requested
+	if cfg.OpcodeHistograms {
+		cfg.OpcodeStats = new(vm.OpcodeStats)
 	}
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions() {
@@ -75,9 +162,19 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		receipts = append(receipts, receipt)
 		allLogs = append(allLogs, receipt.Logs...)
 	}
+	if err := ValidateGasDimensions(p.config, header, gp); err != nil {
+		return nil, nil, 0, err
+	}
+	ApplyImplicitBlockEnd(p.config, p.bc, nil, header, statedb, cfg)
+	if err := ApplyBlockEnd(p.config, header, statedb, receipts); err != nil {
+		return nil, nil, 0, err
+	}
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
 	p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles())
 
+	if cfg.OpcodeHistograms {
+		cfg.OpcodeStats.Report()
+	}
 	return receipts, allLogs, *usedGas, nil
 }
 
@@ -91,32 +188,54 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 		return nil, 0, err
 	}
 	// Create a new context to be used in the EVM environment
-	context := NewEVMContext(msg, header, bc, author)
+	context := NewTransactionContext(config, msg, header, bc, author)
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
 	vmenv := vm.NewEVM(context, statedb, config, cfg)
-	// Apply the transaction to the current state (included in the env)
-	_, gas, failed, err := ApplyMessage(vmenv, msg, gp)
+
+	receipt, result, err := ApplyTransactionMessage(vmenv, gp, statedb, header, tx, msg, usedGas)
 	if err != nil {
 		return nil, 0, err
 	}
+	return receipt, result.UsedGas, nil
+}
+
+// ApplyTransactionMessage applies a transaction like ApplyTransaction, but
+// executes the given message created from it in the given EVM, e.g. set up by a
+// tracer converting transactions itself. The EVM must operate on the given state
+// database. It additionally returns the result of the execution of the message.
+func ApplyTransactionMessage(vmenv *vm.EVM, gp *GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, msg Message, usedGas *uint64) (*types.Receipt, *ExecutionResult, error) {
+	config := vmenv.ChainConfig()
+	if processorHooks.TxStart != nil {
+		if err := processorHooks.TxStart(config, header, tx, msg, statedb); err != nil {
+			return nil, nil, err
+		}
+	}
+	// Apply the transaction to the current state (included in the env)
+	result, err := ApplyMessageResult(vmenv, msg, gp)
+	if err != nil {
+		return nil, nil, err
+	}
 	// Update the state with pending changes
 	var root []byte
 	if config.IsByzantium(header.Number) {
-		statedb.Finalise(true)
+		statedb.FinaliseTransaction(true, false)
 	} else {
-		root = statedb.IntermediateRoot(config.IsEIP158(header.Number)).Bytes()
+		root = statedb.FinaliseTransaction(config.IsEIP158(header.Number), true).Bytes()
 	}
-	*usedGas += gas
+	*usedGas += result.UsedGas
 
 	// Create a new receipt for the transaction, storing the intermediate root and gas used by the tx
 	// based on the eip phase, we're passing whether the root touch-delete accounts.
-	receipt := types.NewReceipt(root, failed, *usedGas)
+	receipt := types.NewReceipt(root, result.Failed(), *usedGas)
 	receipt.TxHash = tx.Hash()
-	receipt.GasUsed = gas
+	receipt.GasUsed = result.UsedGas
+	if result.Extra != nil {
+		receipt.SetExtraPayload(result.Extra)
+	}
 	// if the transaction created a contract, store the creation address in the receipt.
 	if msg.To() == nil {
-		receipt.ContractAddress = crypto.CreateAddress(vmenv.Context.Origin, tx.Nonce())
+		receipt.ContractAddress = types.CreateAddress(vmenv.Context.Origin, tx.Nonce())
 	}
 	// Set the receipt logs and create a bloom for filtering
 	receipt.Logs = statedb.GetLogs(tx.Hash())
@@ -125,5 +244,10 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 	receipt.BlockNumber = header.Number
 	receipt.TransactionIndex = uint(statedb.TxIndex())
 
-	return receipt, gas, err
+	if processorHooks.TxEnd != nil {
+		if err := processorHooks.TxEnd(config, header, tx, msg, statedb, receipt); err != nil {
+			return nil, nil, err
+		}
+	}
+	return receipt, result, nil
 }
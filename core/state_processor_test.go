@@ -0,0 +1,221 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/consensus/ethash"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/core/state"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
+	"github.com/ava-labs/go-ethereum/crypto"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+func TestProcessorHooks(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		hooked  = common.HexToAddress("0xb10c")
+		errHook = errors.New("hook failed")
+		fail    bool
+		engine  = ethash.NewFaker()
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}, hooked: {Balance: big.NewInt(1)}}}
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	RegisterProcessorHooks(ProcessorHooks{
+		BlockStart: func(config *params.ChainConfig, header *types.Header, statedb *state.StateDB) error {
+			statedb.SetState(hooked, common.Hash{}, common.BigToHash(header.Number))
+			return nil
+		},
+		BlockEnd: func(config *params.ChainConfig, header *types.Header, statedb *state.StateDB, receipts types.Receipts) error {
+			if fail {
+				return errHook
+			}
+			statedb.SetState(hooked, common.Hash{1}, common.BigToHash(big.NewInt(int64(len(receipts)))))
+			return nil
+		},
+	})
+	defer func() { processorHooks, processorHooksRegistered = ProcessorHooks{}, false }()
+
+	db := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 3, func(i int, b *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{1}, big.NewInt(1), params.TxGas, nil, nil), signer, key)
+		b.AddTx(tx)
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+	chain, err := NewBlockChain(diskdb, nil, gspec.Config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	// Hooked state changes must be covered by the state roots of the blocks
+	if _, err := chain.InsertChain(blocks[:2]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	statedb, _ := chain.State()
+	if number := statedb.GetState(hooked, common.Hash{}); number != common.BigToHash(big.NewInt(2)) {
+		t.Errorf("block start state mismatch: have %x, want 2", number)
+	}
+	if count := statedb.GetState(hooked, common.Hash{1}); count != common.BigToHash(big.NewInt(1)) {
+		t.Errorf("block end state mismatch: have %x, want 1", count)
+	}
+	// Hook failures must render blocks invalid
+	fail = true
+	if _, err := chain.InsertChain(blocks[2:]); err != errHook {
+		t.Errorf("insertion error mismatch: have %v, want %v", err, errHook)
+	}
+}
+
+func TestProcessorTxHooks(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		hooked  = common.HexToAddress("0xb10c")
+		errHook = errors.New("hook failed")
+		senders []common.Address
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}, hooked: {Balance: big.NewInt(1)}}}
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	RegisterProcessorHooks(ProcessorHooks{
+		TxStart: func(config *params.ChainConfig, header *types.Header, tx *types.Transaction, msg Message, statedb *state.StateDB) error {
+			if tx.Value().Sign() == 0 {
+				return errHook
+			}
+			senders = append(senders, msg.From())
+			return nil
+		},
+		TxEnd: func(config *params.ChainConfig, header *types.Header, tx *types.Transaction, msg Message, statedb *state.StateDB, receipt *types.Receipt) error {
+			statedb.SetState(hooked, receipt.TxHash, common.BigToHash(new(big.Int).SetUint64(receipt.GasUsed)))
+			return nil
+		},
+	})
+	defer func() { processorHooks, processorHooksRegistered = ProcessorHooks{}, false }()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		genesis = gspec.MustCommit(db)
+		statedb = NewTestStateDB(gspec.Alloc)
+		header  = &types.Header{Number: big.NewInt(1), GasLimit: genesis.GasLimit(), Difficulty: big.NewInt(1)}
+		usedGas uint64
+	)
+	tx, _ := types.SignTx(types.NewTransaction(0, common.Address{1}, big.NewInt(1), params.TxGas, nil, nil), signer, key)
+	statedb.Prepare(tx.Hash(), common.Hash{}, 0)
+	receipt, _, err := ApplyTransaction(gspec.Config, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, &usedGas, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	if len(senders) != 1 || senders[0] != addr {
+		t.Errorf("hooked senders mismatch: have %x, want [%x]", senders, addr)
+	}
+	if gas := statedb.GetState(hooked, tx.Hash()); gas != common.BigToHash(new(big.Int).SetUint64(receipt.GasUsed)) {
+		t.Errorf("hooked receipt mismatch: have %x, want %d", gas, receipt.GasUsed)
+	}
+	// Hook failures must fail the transaction
+	tx, _ = types.SignTx(types.NewTransaction(1, common.Address{1}, new(big.Int), params.TxGas, nil, nil), signer, key)
+	if _, _, err := ApplyTransaction(gspec.Config, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, &usedGas, vm.Config{}); err != errHook {
+		t.Errorf("error mismatch: have %v, want %v", err, errHook)
+	}
+}
+
+// The state hooks can't be unregistered outside of package state, so the tests
+// of this package register the intermediate root hook once, consulting this
+// fold if set.
+var (
+	testIntermediateRootHookOnce sync.Once
+	intermediateRootFold         func(root common.Hash) common.Hash
+)
+
+func TestIntermediateRootHookImport(t *testing.T)          { testIntermediateRootHookImport(t, false) }
+func TestIntermediateRootHookImportByzantium(t *testing.T) { testIntermediateRootHookImport(t, true) }
+
+func testIntermediateRootHookImport(t *testing.T, byzantium bool) {
+	testIntermediateRootHookOnce.Do(func() {
+		state.RegisterIntermediateRootHook(func(db *state.StateDB, root common.Hash) common.Hash {
+			if intermediateRootFold == nil {
+				return root
+			}
+			return intermediateRootFold(root)
+		})
+	})
+	var (
+		side  = common.Hash{0xaa}
+		folds int
+	)
+	intermediateRootFold = func(root common.Hash) common.Hash {
+		folds++
+		return crypto.Keccak256Hash(root[:], side[:])
+	}
+	defer func() { intermediateRootFold = nil }()
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		engine = ethash.NewFaker()
+		config = &params.ChainConfig{ChainID: big.NewInt(1), HomesteadBlock: big.NewInt(0), EIP150Block: big.NewInt(0), EIP155Block: big.NewInt(0), EIP158Block: big.NewInt(0), Ethash: new(params.EthashConfig)}
+		gspec  = &Genesis{Config: config, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		signer = types.NewEIP155Signer(config.ChainID)
+	)
+	if byzantium {
+		config.ByzantiumBlock = big.NewInt(0)
+	}
+	db := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, receipts := GenerateChain(config, genesis, engine, db, 2, func(i int, b *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{1}, big.NewInt(1), params.TxGas, nil, nil), signer, key)
+		b.AddTx(tx)
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+	chain, err := NewBlockChain(diskdb, nil, config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	// Blocks must be importable on top of their predecessors, with the hook
+	// invoked for every transaction and the folded roots in pre-Byzantium
+	// receipts only
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if folds != 2*len(blocks) {
+		t.Errorf("fold count mismatch: have %d, want %d", folds, 2*len(blocks))
+	}
+	for i, block := range blocks {
+		if _, err := chain.StateAt(block.Root()); err != nil {
+			t.Errorf("block %d: state unavailable: %v", i, err)
+		}
+		stored := chain.GetReceiptsByHash(block.Hash())
+		if len(stored) != 1 || !bytes.Equal(stored[0].PostState, receipts[i][0].PostState) {
+			t.Errorf("block %d: receipt root mismatch: have %v, want %x", i, stored, receipts[i][0].PostState)
+		}
+		if byzantium && len(receipts[i][0].PostState) != 0 {
+			t.Errorf("block %d: root in post-Byzantium receipt: %x", i, receipts[i][0].PostState)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"math/big"
 
 	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/types"
 	"github.com/ava-labs/go-ethereum/core/vm"
 	"github.com/ava-labs/go-ethereum/log"
 	"github.com/ava-labs/go-ethereum/params"
@@ -54,6 +55,8 @@ type StateTransition struct {
 	gas        uint64
 	gasPrice   *big.Int
 	initialGas uint64
+	floorGas   uint64
+	system     bool
 	value      *big.Int
 	data       []byte
 	state      vm.StateDB
@@ -112,6 +115,209 @@ func IntrinsicGas(data []byte, contractCreation, isEIP155 bool, isEIP2028 bool)
 	return gas, nil
 }
 
+// IntrinsicGasHook adjusts the intrinsic gas of a message computed by
+// IntrinsicGas under the given rules, e.g. to charge for data verified outside
+// of the EVM. It is given a read-only view of the state the message is executed
+// or validated against. The type of the transaction a message was created from
+// is available through the TxType method of types.Message.
+type IntrinsicGasHook func(msg Message, rules params.Rules, state vm.StateReader, gas uint64) (uint64, error)
+
+// intrinsicGasHook is the hook registered with RegisterIntrinsicGasHook, if any.
+var intrinsicGasHook IntrinsicGasHook
+
+// RegisterIntrinsicGasHook registers a hook adjusting the intrinsic gas charged
+// for messages, both when they are executed and when transactions are admitted
+// into the transaction pools. As gas estimation executes messages, it follows
+// the adjusted intrinsic gas.
+//
+// RegisterIntrinsicGasHook is not safe for concurrent use and must be called
+// during initialisation. It panics if called more than once.
+func RegisterIntrinsicGasHook(hook IntrinsicGasHook) {
+	if intrinsicGasHook != nil {
+		panic("core: intrinsic gas hook already registered")
+	}
+	intrinsicGasHook = hook
+}
+
+// AdjustIntrinsicGas returns the intrinsic gas of a message computed by
+// IntrinsicGas, adjusted by the registered hook, if any.
+func AdjustIntrinsicGas(msg Message, rules params.Rules, state vm.StateReader, gas uint64) (uint64, error) {
+	if intrinsicGasHook == nil {
+		return gas, nil
+	}
+	return intrinsicGasHook(msg, rules, state, gas)
+}
+
+// FloorGasHook returns the minimum amount of gas charged for a message under
+// the given rules, regardless of the gas used by its execution, e.g. a per-byte
+// floor on the cost of calldata in the style of EIP-7623. Unlike the intrinsic
+// gas, the floor is only charged once execution and refunds are done, if the
+// message used less.
+type FloorGasHook func(msg Message, rules params.Rules) (uint64, error)
+
+// floorGasHook is the hook registered with RegisterFloorGasHook, if any.
+var floorGasHook FloorGasHook
+
+// RegisterFloorGasHook registers a hook computing the floor gas of messages.
+// Messages whose gas limit is below their floor are rejected, both when they
+// are executed and when transactions are admitted into the transaction pools.
+// As gas estimation executes messages, its estimates cover the floor.
+//
+// RegisterFloorGasHook is not safe for concurrent use and must be called
+// during initialisation. It panics if called more than once.
+func RegisterFloorGasHook(hook FloorGasHook) {
+	if floorGasHook != nil {
+		panic("core: floor gas hook already registered")
+	}
+	floorGasHook = hook
+}
+
+// FloorGas returns the floor gas of a message computed by the registered hook,
+// or zero if none is registered.
+func FloorGas(msg Message, rules params.Rules) (uint64, error) {
+	if floorGasHook == nil {
+		return 0, nil
+	}
+	return floorGasHook(msg, rules)
+}
+
+// Fee is the fee paid for the execution of a message.
+type Fee struct {
+	GasUsed  uint64   // Gas used by the message, net of refunds
+	GasPrice *big.Int // Price paid per gas
+	Amount   *big.Int // Total fee, i.e. GasUsed * GasPrice
+}
+
+// FeePaymentHook pays the fee of an executed message, replacing the default
+// payment of the entire fee to the coinbase, e.g. to burn part of it, pay it to
+// a fee collector or split it. The fee was already deducted from the balance
+// of the sender, so any part of it not credited by the hook is burnt. The hook
+// is given the EVM the message was executed in, providing the state, coinbase
+// and chain rules.
+type FeePaymentHook func(evm *vm.EVM, msg Message, fee Fee)
+
+// feePaymentHook is the hook registered with RegisterFeePaymentHook, if any.
+var feePaymentHook FeePaymentHook
+
+// RegisterFeePaymentHook registers a hook paying the fees of executed messages.
+//
+// RegisterFeePaymentHook is not safe for concurrent use and must be called
+// during initialisation. It panics if called more than once.
+func RegisterFeePaymentHook(hook FeePaymentHook) {
+	if feePaymentHook != nil {
+		panic("core: fee payment hook already registered")
+	}
+	feePaymentHook = hook
+}
+
+// ExecutionResult is the result of the execution of a message by the EVM.
+type ExecutionResult struct {
+	UsedGas    uint64 // Gas used by the message, net of refunds
+	Err        error  // Error returned by the EVM, nil if execution succeeded
+	ReturnData []byte // Data returned by the EVM, e.g. the revert reason
+
+	// Extra is chain specific metadata set by the ExecutionResultHook, if any.
+	// It is carried by the receipt of the transaction the message was created
+	// from, so it must be of the type registered with types.RegisterReceiptExtras.
+	Extra interface{}
+}
+
+// Failed reports whether the execution of the message failed.
+func (result *ExecutionResult) Failed() bool {
+	return result.Err != nil
+}
+
+// ExecutionResultHook post-processes the result of an executed message before
+// it is turned into a receipt or returned by RPC calls, e.g. to translate EVM
+// errors into chain specific ones or to annotate the result with metadata. It
+// is given the EVM the message was executed in. Turning a successful execution
+// into a failed one or vice versa changes the status of receipts, so the hook
+// must be deterministic. If a successful execution is turned into a failed one,
+// its state changes are reverted like those of any failed execution, keeping
+// the gas used and the fee paid; the opposite only changes the status.
+type ExecutionResultHook func(evm *vm.EVM, msg Message, result *ExecutionResult)
+
+// executionResultHook is the hook registered with RegisterExecutionResultHook,
+// if any.
+var executionResultHook ExecutionResultHook
+
+// RegisterExecutionResultHook registers a hook post-processing the results of
+// executed messages.
+//
+// RegisterExecutionResultHook is not safe for concurrent use and must be
+// called during initialisation. It panics if called more than once.
+func RegisterExecutionResultHook(hook ExecutionResultHook) {
+	if executionResultHook != nil {
+		panic("core: execution result hook already registered")
+	}
+	executionResultHook = hook
+}
+
+// SystemMessage is a Message that may be a system message, e.g. injected by
+// block producers to update the fee configuration or attest bridge transfers.
+// System messages are executed in the EVM like any other message, but their
+// nonce is neither checked nor incremented, and their sender is not charged
+// for gas nor paid refunds. They still draw their gas from the block's gas
+// pool. System messages can't create contracts, as the address of a created
+// contract is derived from the nonce of its creator. They are created by
+// types.NewSystemMessage, or from system transactions, see types.SystemTxData.
+type SystemMessage interface {
+	Message
+	IsSystem() bool
+}
+
+// isSystemMessage reports whether msg is a system message.
+func isSystemMessage(msg Message) bool {
+	sys, ok := msg.(SystemMessage)
+	return ok && sys.IsSystem()
+}
+
+// SystemMessageHook authorises the execution of a system message in the given
+// EVM, e.g. by checking its sender and recipient, returning an error if it must
+// be rejected. It is only called for system messages.
+type SystemMessageHook func(evm *vm.EVM, msg Message) error
+
+// systemMessageHook is the hook registered with RegisterSystemMessageHook, if
+// any.
+var systemMessageHook SystemMessageHook
+
+// RegisterSystemMessageHook registers a hook authorising system messages. All
+// system messages are rejected with ErrSystemMessageNotAllowed if none is
+// registered.
+//
+// RegisterSystemMessageHook is not safe for concurrent use and must be called
+// during initialisation. It panics if called more than once.
+func RegisterSystemMessageHook(hook SystemMessageHook) {
+	if systemMessageHook != nil {
+		panic("core: system message hook already registered")
+	}
+	systemMessageHook = hook
+}
+
+// AccessListMessage is a Message carrying an access list, whose predicates are
+// charged for on top of the intrinsic gas of the message.
+type AccessListMessage interface {
+	Message
+	AccessList() types.AccessList
+}
+
+// predicateGas adds the gas charged for verifying the predicates of the message
+// to its intrinsic gas.
+func (st *StateTransition) predicateGas(gas uint64) (uint64, error) {
+	msg, ok := st.msg.(AccessListMessage)
+	if !ok {
+		return gas, nil
+	}
+	predicateGas, err := st.evm.PredicateGas(msg.AccessList())
+	if err != nil {
+		return 0, err
+	}
+	if math.MaxUint64-gas < predicateGas {
+		return 0, vm.ErrOutOfGas
+	}
+	return gas + predicateGas, nil
+}
+
 // NewStateTransition initialises and returns a new state transition object.
 func NewStateTransition(evm *vm.EVM, msg Message, gp *GasPool) *StateTransition {
 	return &StateTransition{
@@ -122,6 +328,7 @@ func NewStateTransition(evm *vm.EVM, msg Message, gp *GasPool) *StateTransition
 		value:    msg.Value(),
 		data:     msg.Data(),
 		state:    evm.StateDB,
+		system:   isSystemMessage(msg),
 	}
 }
 
@@ -136,6 +343,13 @@ func ApplyMessage(evm *vm.EVM, msg Message, gp *GasPool) ([]byte, uint64, bool,
 	return NewStateTransition(evm, msg, gp).TransitionDb()
 }
 
+// ApplyMessageResult applies the given message like ApplyMessage, but returns
+// the entire result of its execution, as post-processed by the registered
+// ExecutionResultHook.
+func ApplyMessageResult(evm *vm.EVM, msg Message, gp *GasPool) (*ExecutionResult, error) {
+	return NewStateTransition(evm, msg, gp).Execute()
+}
+
 // to returns the recipient of the message.
 func (st *StateTransition) to() common.Address {
 	if st.msg == nil || st.msg.To() == nil /* contract creation */ {
@@ -155,7 +369,7 @@ func (st *StateTransition) useGas(amount uint64) error {
 
 func (st *StateTransition) buyGas() error {
 	mgval := new(big.Int).Mul(new(big.Int).SetUint64(st.msg.Gas()), st.gasPrice)
-	if st.state.GetBalance(st.msg.From()).Cmp(mgval) < 0 {
+	if !st.system && st.state.GetBalance(st.msg.From()).Cmp(mgval) < 0 {
 		return errInsufficientBalanceForGas
 	}
 	if err := st.gp.SubGas(st.msg.Gas()); err != nil {
@@ -164,11 +378,26 @@ func (st *StateTransition) buyGas() error {
 	st.gas += st.msg.Gas()
 
 	st.initialGas = st.msg.Gas()
-	st.state.SubBalance(st.msg.From(), mgval)
+	if !st.system {
+		st.state.SubBalanceWithReason(st.msg.From(), mgval, types.BalanceChangeGasBuy)
+	}
 	return nil
 }
 
 func (st *StateTransition) preCheck() error {
+	// System messages must be authorised, and skip the nonce check.
+	if st.system {
+		if st.msg.To() == nil {
+			return ErrSystemMessageCreation
+		}
+		if systemMessageHook == nil {
+			return ErrSystemMessageNotAllowed
+		}
+		if err := systemMessageHook(st.evm, st.msg); err != nil {
+			return err
+		}
+		return st.buyGas()
+	}
 	// Make sure this transaction's nonce is correct.
 	if st.msg.CheckNonce() {
 		nonce := st.state.GetNonce(st.msg.From())
@@ -185,8 +414,24 @@ func (st *StateTransition) preCheck() error {
 // returning the result including the used gas. It returns an error if failed.
 // An error indicates a consensus issue.
 func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bool, err error) {
-	if err = st.preCheck(); err != nil {
-		return
+	result, err := st.Execute()
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return result.ReturnData, result.UsedGas, result.Failed(), nil
+}
+
+// Execute transitions the state like TransitionDb, but returns the entire result
+// of the execution, as post-processed by the registered ExecutionResultHook.
+func (st *StateTransition) Execute() (*ExecutionResult, error) {
+	// The gas dimensions are charged once the message is executed, so keep the
+	// revision to revert to if they are exhausted, rendering the message invalid
+	initial := -1
+	if len(gasDimensions) > 0 {
+		initial = st.state.Snapshot()
+	}
+	if err := st.preCheck(); err != nil {
+		return nil, err
 	}
 	msg := st.msg
 	sender := vm.AccountRef(msg.From())
@@ -197,24 +442,45 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	// Pay intrinsic gas
 	gas, err := IntrinsicGas(st.data, contractCreation, homestead, istanbul)
 	if err != nil {
-		return nil, 0, false, err
+		return nil, err
+	}
+	if gas, err = AdjustIntrinsicGas(msg, st.evm.Rules(), st.evm.ReadOnlyState(), gas); err != nil {
+		return nil, err
+	}
+	if gas, err = st.predicateGas(gas); err != nil {
+		return nil, err
 	}
 	if err = st.useGas(gas); err != nil {
-		return nil, 0, false, err
+		return nil, err
+	}
+	if st.floorGas, err = FloorGas(msg, st.evm.Rules()); err != nil {
+		return nil, err
+	}
+	if st.initialGas < st.floorGas {
+		return nil, ErrFloorDataGas
 	}
 
 	var (
 		evm = st.evm
+		ret []byte
 		// vm errors do not effect consensus and are therefor
 		// not assigned to err, except for insufficient balance
 		// error.
 		vmerr error
+		// snapshot is the revision to revert to if the execution result hook
+		// fails a successful execution.
+		snapshot = -1
 	)
+	if executionResultHook != nil {
+		snapshot = st.state.Snapshot()
+	}
 	if contractCreation {
 		ret, _, st.gas, vmerr = evm.Create(sender, st.data, st.gas, st.value)
 	} else {
 		// Increment the nonce for the next transaction
-		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
+		if !st.system {
+			st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
+		}
 		ret, st.gas, vmerr = evm.Call(sender, st.to(), st.data, st.gas, st.value)
 	}
 	if vmerr != nil {
@@ -223,15 +489,39 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		// sufficient balance to make the transfer happen. The first
 		// balance transfer may never fail.
 		if vmerr == vm.ErrInsufficientBalance {
-			return nil, 0, false, vmerr
+			return nil, vmerr
 		}
 	}
+	if err = chargeGasDimensions(st.gp, evm, msg, st.gasUsed()); err != nil {
+		// Undo the message and return all gas to the block, as it is invalid
+		st.state.RevertToSnapshot(initial)
+		st.gp.AddGas(st.initialGas)
+		return nil, err
+	}
 	st.refundGas()
-	st.state.AddBalance(st.evm.Coinbase, new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice))
 
-	return ret, st.gasUsed(), vmerr != nil, err
+	result := &ExecutionResult{
+		UsedGas:    st.gasUsed(),
+		Err:        vmerr,
+		ReturnData: ret,
+	}
+	if executionResultHook != nil {
+		executionResultHook(evm, msg, result)
+		if vmerr == nil && result.Failed() {
+			// Revert the execution, keeping the nonce incremented by contract
+			// creations like failed ones do
+			nonce := st.state.GetNonce(msg.From())
+			st.state.RevertToSnapshot(snapshot)
+			st.state.SetNonce(msg.From(), nonce)
+		}
+	}
+	st.returnGas()
+	st.payFee()
+
+	return result, nil
 }
 
+// refundGas applies the refund counter and the floor gas to the remaining gas.
 func (st *StateTransition) refundGas() {
 	// Apply refund counter, capped to half of the used gas.
 	refund := st.gasUsed() / 2
@@ -240,15 +530,43 @@ func (st *StateTransition) refundGas() {
 	}
 	st.gas += refund
 
+	// Charge the floor gas if execution used less.
+	if st.gasUsed() < st.floorGas {
+		st.gas = st.initialGas - st.floorGas
+	}
+}
+
+// returnGas returns the remaining gas to the sender and the block.
+func (st *StateTransition) returnGas() {
 	// Return ETH for remaining gas, exchanged at the original rate.
-	remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
-	st.state.AddBalance(st.msg.From(), remaining)
+	if !st.system {
+		remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
+		st.state.AddBalanceWithReason(st.msg.From(), remaining, types.BalanceChangeGasRefund)
+	}
 
 	// Also return remaining gas to the block gas counter so it is
 	// available for the next transaction.
 	st.gp.AddGas(st.gas)
 }
 
+// payFee pays the fee of the message to the coinbase, unless a fee payment hook
+// is registered. System messages pay no fee.
+func (st *StateTransition) payFee() {
+	if st.system {
+		return
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice)
+	if feePaymentHook == nil {
+		st.state.AddBalanceWithReason(st.evm.Coinbase, fee, types.BalanceChangeFee)
+		return
+	}
+	feePaymentHook(st.evm, st.msg, Fee{
+		GasUsed:  st.gasUsed(),
+		GasPrice: new(big.Int).Set(st.gasPrice),
+		Amount:   fee,
+	})
+}
+
 // gasUsed returns the amount of gas used up by the state transition.
 func (st *StateTransition) gasUsed() uint64 {
 	return st.initialGas - st.gas
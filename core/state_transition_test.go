@@ -0,0 +1,318 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/state"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
+	"github.com/ava-labs/go-ethereum/crypto"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+func TestFeePaymentHook(t *testing.T) {
+	var (
+		key, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr      = crypto.PubkeyToAddress(key.PublicKey)
+		coinbase  = common.HexToAddress("0xc014ba5e")
+		collector = common.HexToAddress("0xfee")
+		paid      Fee
+	)
+	RegisterFeePaymentHook(func(evm *vm.EVM, msg Message, fee Fee) {
+		paid = fee
+		evm.StateDB.AddBalance(collector, new(big.Int).Div(fee.Amount, big.NewInt(2)))
+	})
+	defer func() { feePaymentHook = nil }()
+
+	var (
+		statedb = NewTestStateDB(GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}})
+		header  = &types.Header{Number: big.NewInt(1), GasLimit: params.GenesisGasLimit, Difficulty: big.NewInt(1)}
+		signer  = types.NewEIP155Signer(params.TestChainConfig.ChainID)
+		usedGas uint64
+	)
+	tx, _ := types.SignTx(types.NewTransaction(0, common.Address{1}, new(big.Int), params.TxGas, big.NewInt(10), nil), signer, key)
+	statedb.Prepare(tx.Hash(), common.Hash{}, 0)
+	if _, _, err := ApplyTransaction(params.TestChainConfig, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, &usedGas, vm.Config{}); err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	if paid.GasUsed != params.TxGas || paid.GasPrice.Int64() != 10 || paid.Amount.Int64() != 10*int64(params.TxGas) {
+		t.Errorf("fee mismatch: have %+v", paid)
+	}
+	if balance := statedb.GetBalance(coinbase); balance.Sign() != 0 {
+		t.Errorf("coinbase paid: have %v, want 0", balance)
+	}
+	if balance := statedb.GetBalance(collector); balance.Int64() != 5*int64(params.TxGas) {
+		t.Errorf("collector balance mismatch: have %v, want %d", balance, 5*params.TxGas)
+	}
+	if balance := statedb.GetBalance(addr); balance.Cmp(new(big.Int).Sub(big.NewInt(params.Ether), paid.Amount)) != 0 {
+		t.Errorf("sender balance mismatch: have %v", balance)
+	}
+}
+
+func TestFloorGasHook(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		data   = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+		floor  = params.TxGas + 100*uint64(len(data))
+	)
+	RegisterFloorGasHook(func(msg Message, rules params.Rules) (uint64, error) {
+		return params.TxGas + 100*uint64(len(msg.Data())), nil
+	})
+	defer func() { floorGasHook = nil }()
+
+	var (
+		header = &types.Header{Number: big.NewInt(1), GasLimit: params.GenesisGasLimit, Difficulty: big.NewInt(1)}
+		signer = types.NewEIP155Signer(params.TestChainConfig.ChainID)
+	)
+	apply := func(gas uint64) (uint64, *big.Int, error) {
+		statedb := NewTestStateDB(GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}})
+		tx, _ := types.SignTx(types.NewTransaction(0, common.Address{1}, new(big.Int), gas, big.NewInt(1), data), signer, key)
+		statedb.Prepare(tx.Hash(), common.Hash{}, 0)
+
+		var usedGas uint64
+		_, _, err := ApplyTransaction(params.TestChainConfig, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, &usedGas, vm.Config{})
+		return usedGas, new(big.Int).Sub(big.NewInt(params.Ether), statedb.GetBalance(addr)), err
+	}
+	// Messages below the floor must be rejected, even if covering the intrinsic gas
+	if _, _, err := apply(floor - 1); err != ErrFloorDataGas {
+		t.Errorf("error mismatch below floor: have %v, want %v", err, ErrFloorDataGas)
+	}
+	// Messages using less than the floor must be charged the floor
+	used, paid, err := apply(2 * floor)
+	if err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	if used != floor {
+		t.Errorf("gas used mismatch: have %d, want %d", used, floor)
+	}
+	if paid.Uint64() != floor {
+		t.Errorf("fee mismatch: have %v, want %d", paid, floor)
+	}
+}
+
+func TestSystemMessage(t *testing.T) {
+	var (
+		system   = common.HexToAddress("0x5157e4")
+		contract = common.HexToAddress("0xc0de")
+		header   = &types.Header{Number: big.NewInt(1), GasLimit: params.GenesisGasLimit, Difficulty: big.NewInt(1)}
+		errDeny  = errors.New("sender not allowed")
+	)
+	apply := func(msg types.Message) (*state.StateDB, *GasPool, error) {
+		statedb := NewTestStateDB(GenesisAlloc{
+			system:   {Nonce: 5, Balance: new(big.Int)},
+			contract: {Code: []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE)}, Balance: new(big.Int)},
+		})
+		gp := new(GasPool).AddGas(header.GasLimit)
+		evm := vm.NewEVM(NewEVMContext(msg, header, nil, &common.Address{}), statedb, params.TestChainConfig, vm.Config{})
+		_, _, _, err := ApplyMessage(evm, msg, gp)
+		return statedb, gp, err
+	}
+	msg := types.NewSystemMessage(system, &contract, new(big.Int), 100000, nil)
+
+	// System messages must be rejected unless authorised by the hook
+	if _, _, err := apply(msg); err != ErrSystemMessageNotAllowed {
+		t.Fatalf("error mismatch without hook: have %v, want %v", err, ErrSystemMessageNotAllowed)
+	}
+	RegisterSystemMessageHook(func(evm *vm.EVM, msg Message) error {
+		if msg.From() != system {
+			return errDeny
+		}
+		return nil
+	})
+	defer func() { systemMessageHook = nil }()
+
+	if _, _, err := apply(types.NewSystemMessage(common.Address{1}, &contract, new(big.Int), 100000, nil)); err != errDeny {
+		t.Fatalf("error mismatch for denied sender: have %v, want %v", err, errDeny)
+	}
+	// System messages must not create contracts, even if authorised
+	statedb, gp, err := apply(types.NewSystemMessage(system, nil, new(big.Int), 100000, nil))
+	if err != ErrSystemMessageCreation {
+		t.Fatalf("error mismatch for creation: have %v, want %v", err, ErrSystemMessageCreation)
+	}
+	if nonce := statedb.GetNonce(system); nonce != 5 {
+		t.Errorf("nonce mismatch after creation: have %d, want 5", nonce)
+	}
+	if gp.Gas() != header.GasLimit {
+		t.Errorf("gas pool charged for creation: have %d, want %d", gp.Gas(), header.GasLimit)
+	}
+	// Authorised system messages must execute without touching nonce or balance
+	statedb, gp, err = apply(msg)
+	if err != nil {
+		t.Fatalf("failed to apply system message: %v", err)
+	}
+	if value := statedb.GetState(contract, common.Hash{}); value != common.BigToHash(big.NewInt(1)) {
+		t.Errorf("storage mismatch: have %x, want 1", value)
+	}
+	if nonce := statedb.GetNonce(system); nonce != 5 {
+		t.Errorf("nonce mismatch: have %d, want 5", nonce)
+	}
+	if balance := statedb.GetBalance(system); balance.Sign() != 0 {
+		t.Errorf("balance mismatch: have %v, want 0", balance)
+	}
+	if gp.Gas() >= header.GasLimit {
+		t.Errorf("gas pool not charged: have %d", gp.Gas())
+	}
+}
+
+func TestExecutionResultHook(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		reverter = common.HexToAddress("0xbad")
+		storer   = common.HexToAddress("0x5707e")
+		errChain = errors.New("chain specific failure")
+	)
+	RegisterExecutionResultHook(func(evm *vm.EVM, msg Message, result *ExecutionResult) {
+		if vm.IsRevert(result.Err) || (msg.To() != nil && *msg.To() == storer) {
+			result.Err = errChain
+		}
+	})
+	defer func() { executionResultHook = nil }()
+
+	var (
+		statedb = NewTestStateDB(GenesisAlloc{
+			addr:     {Balance: big.NewInt(params.Ether)},
+			reverter: {Code: []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT)}, Balance: new(big.Int)},
+			storer:   {Code: []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE)}, Balance: new(big.Int)},
+		})
+		header = &types.Header{Number: big.NewInt(1), GasLimit: params.GenesisGasLimit, Difficulty: big.NewInt(1)}
+		msg    = types.NewMessage(addr, &reverter, 0, new(big.Int), 100000, new(big.Int), nil, false)
+		evm    = vm.NewEVM(NewEVMContext(msg, header, nil, &common.Address{}), statedb, params.TestChainConfig, vm.Config{})
+	)
+	result, err := ApplyMessageResult(evm, msg, new(GasPool).AddGas(header.GasLimit))
+	if err != nil {
+		t.Fatalf("failed to apply message: %v", err)
+	}
+	if result.Err != errChain {
+		t.Errorf("error mismatch: have %v, want %v", result.Err, errChain)
+	}
+	// Receipts must follow the post-processed result
+	var (
+		signer  = types.NewEIP155Signer(params.TestChainConfig.ChainID)
+		usedGas uint64
+	)
+	tx, _ := types.SignTx(types.NewTransaction(1, reverter, new(big.Int), 100000, new(big.Int), nil), signer, key)
+	statedb.Prepare(tx.Hash(), common.Hash{}, 0)
+	receipt, _, err := ApplyTransaction(params.TestChainConfig, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, &usedGas, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusFailed || receipt.GasUsed != usedGas {
+		t.Errorf("receipt mismatch: have status %d, gas %d, want status %d, gas %d", receipt.Status, receipt.GasUsed, types.ReceiptStatusFailed, usedGas)
+	}
+	// Successful executions failed by the hook must be reverted, but charged
+	balance := statedb.GetBalance(addr)
+	msg = types.NewMessage(addr, &storer, 2, big.NewInt(5), 100000, big.NewInt(1), nil, true)
+	if result, err = ApplyMessageResult(evm, msg, new(GasPool).AddGas(header.GasLimit)); err != nil {
+		t.Fatalf("failed to apply message: %v", err)
+	}
+	if result.Err != errChain {
+		t.Errorf("error mismatch: have %v, want %v", result.Err, errChain)
+	}
+	if value := statedb.GetState(storer, common.Hash{}); value != (common.Hash{}) {
+		t.Errorf("storage not reverted: have %x", value)
+	}
+	if value := statedb.GetBalance(storer); value.Sign() != 0 {
+		t.Errorf("value transfer not reverted: have %v", value)
+	}
+	if nonce := statedb.GetNonce(addr); nonce != 3 {
+		t.Errorf("nonce mismatch: have %d, want 3", nonce)
+	}
+	if paid := new(big.Int).Sub(balance, statedb.GetBalance(addr)); paid.Uint64() != result.UsedGas || result.UsedGas == 0 {
+		t.Errorf("fee mismatch: have %v, want %d", paid, result.UsedGas)
+	}
+}
+
+// The EVM hooks can't be unregistered outside of package vm, so the tests of
+// this package register them once, consulting these vetoes if set.
+var (
+	testEVMHooksOnce sync.Once
+	transferVeto     func(from, to common.Address, value *big.Int) error
+	creationVeto     func(creation *vm.ContractCreation) error
+)
+
+// registerTestEVMHooks registers the EVM hooks consulting the test vetoes.
+func registerTestEVMHooks() {
+	testEVMHooksOnce.Do(func() {
+		vm.RegisterEVMHooks(vm.EVMHooks{
+			CheckTransfer: func(evm *vm.EVM, from, to common.Address, value *big.Int) error {
+				if transferVeto == nil {
+					return nil
+				}
+				return transferVeto(from, to, value)
+			},
+			CanCreateContract: func(evm *vm.EVM, creation *vm.ContractCreation) error {
+				if creationVeto == nil {
+					return nil
+				}
+				return creationVeto(creation)
+			},
+		})
+	})
+}
+
+// testRejectedCreationReplay checks that a creation transaction rejected by
+// one of the test vetoes is included as failed and can't be applied again.
+func testRejectedCreationReplay(t *testing.T, value *big.Int) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		statedb = NewTestStateDB(GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}})
+		header  = &types.Header{Number: big.NewInt(1), GasLimit: params.GenesisGasLimit, Difficulty: big.NewInt(1)}
+		signer  = types.NewEIP155Signer(params.TestChainConfig.ChainID)
+		usedGas uint64
+	)
+	tx, _ := types.SignTx(types.NewContractCreation(0, value, 100000, big.NewInt(1), []byte{byte(vm.STOP)}), signer, key)
+	for i := 0; i < 2; i++ {
+		statedb.Prepare(tx.Hash(), common.Hash{}, i)
+		receipt, _, err := ApplyTransaction(params.TestChainConfig, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, &usedGas, vm.Config{})
+		switch {
+		case i == 0 && err != nil:
+			t.Fatalf("failed to apply rejected creation: %v", err)
+		case i == 0 && receipt.Status != types.ReceiptStatusFailed:
+			t.Errorf("rejected creation succeeded")
+		case i == 1 && err != ErrNonceTooLow:
+			t.Errorf("replay error mismatch: have %v, want %v", err, ErrNonceTooLow)
+		}
+	}
+	if nonce := statedb.GetNonce(addr); nonce != 1 {
+		t.Errorf("sender nonce mismatch: have %d, want 1", nonce)
+	}
+}
+
+func TestRejectedTransferCreationReplay(t *testing.T) {
+	registerTestEVMHooks()
+	transferVeto = func(from, to common.Address, value *big.Int) error { return errors.New("transfer rejected") }
+	defer func() { transferVeto = nil }()
+
+	testRejectedCreationReplay(t, big.NewInt(1))
+}
+
+func TestRejectedCreationReplay(t *testing.T) {
+	registerTestEVMHooks()
+	creationVeto = func(creation *vm.ContractCreation) error { return errors.New("creation rejected") }
+	defer func() { creationVeto = nil }()
+
+	testRejectedCreationReplay(t, new(big.Int))
+}
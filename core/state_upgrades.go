@@ -0,0 +1,70 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/state"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+// ApplyStateUpgrades applies the state upgrades scheduled by the chain config
+// that are activated by the block of the given header, following a block with
+// the given parent timestamp. Like hard-fork specific state mutations, they
+// are applied before any transaction of the block, by every block producer and
+// processor in this package; other block producers must call it themselves.
+func ApplyStateUpgrades(config *params.ChainConfig, parentTime uint64, header *types.Header, statedb *state.StateDB) {
+	for _, upgrade := range config.StateUpgradesBetween(parentTime, header.Time) {
+		// Apply the modifications in a deterministic order
+		addrs := make([]common.Address, 0, len(upgrade.Accounts))
+		for addr := range upgrade.Accounts {
+			addrs = append(addrs, addr)
+		}
+		sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+
+		for _, addr := range addrs {
+			applyStateUpgradeAccount(statedb, addr, upgrade.Accounts[addr])
+		}
+	}
+}
+
+// applyStateUpgradeAccount applies the modifications of a single account by a
+// state upgrade.
+func applyStateUpgradeAccount(statedb *state.StateDB, addr common.Address, account params.StateUpgradeAccount) {
+	if !statedb.Exist(addr) {
+		statedb.CreateAccount(addr)
+	}
+	if account.BalanceChange != nil {
+		statedb.AddBalanceWithReason(addr, (*big.Int)(account.BalanceChange), types.BalanceChangeUpgrade)
+	}
+	if account.Code != nil {
+		// Contracts start with a nonce of one (EIP-161)
+		if statedb.GetNonce(addr) == 0 {
+			statedb.SetNonce(addr, 1)
+		}
+		statedb.SetCode(addr, account.Code)
+	}
+	// Storage keys don't need sorting, as slots are independent
+	for key, value := range account.Storage {
+		statedb.SetState(addr, key, value)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/common/math"
+	"github.com/ava-labs/go-ethereum/consensus/ethash"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/core/vm"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+func TestStateUpgrades(t *testing.T) {
+	var (
+		upgraded = common.HexToAddress("0xc0ffee")
+		code     = hexutil.Bytes{byte(vm.STOP)}
+		config   = *params.TestChainConfig
+		engine   = ethash.NewFaker()
+	)
+	// Generated blocks are 10 seconds apart, so the upgrade activates at block 2
+	config.StateUpgrades = []params.StateUpgrade{{
+		Timestamp: 15,
+		Accounts: map[common.Address]params.StateUpgradeAccount{
+			upgraded: {
+				Code:          code,
+				Storage:       map[common.Hash]common.Hash{{1}: {2}},
+				BalanceChange: (*math.HexOrDecimal256)(big.NewInt(100)),
+			},
+		},
+	}}
+	gspec := &Genesis{Config: &config, Alloc: GenesisAlloc{upgraded: {Balance: big.NewInt(1)}}}
+
+	db := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 3, nil)
+
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+	chain, err := NewBlockChain(diskdb, nil, gspec.Config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	// The upgrade must not be applied before its activation
+	if _, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	statedb, _ := chain.State()
+	if balance := statedb.GetBalance(upgraded); balance.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("balance mismatch before activation: have %v, want 1", balance)
+	}
+	// The upgrade must be applied exactly once, by the first block past it
+	if _, err := chain.InsertChain(blocks[1:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	statedb, _ = chain.State()
+	if balance := statedb.GetBalance(upgraded); balance.Cmp(big.NewInt(101)) != 0 {
+		t.Errorf("balance mismatch: have %v, want 101", balance)
+	}
+	if have := statedb.GetCode(upgraded); !bytes.Equal(have, code) {
+		t.Errorf("code mismatch: have %x, want %x", have, code)
+	}
+	if nonce := statedb.GetNonce(upgraded); nonce != 1 {
+		t.Errorf("nonce mismatch: have %d, want 1", nonce)
+	}
+	if value := statedb.GetState(upgraded, common.Hash{1}); value != (common.Hash{2}) {
+		t.Errorf("storage mismatch: have %x, want %x", value, common.Hash{2})
+	}
+}
+
+func TestSetupGenesisStateUpgradeRewind(t *testing.T) {
+	var (
+		config = *params.TestChainConfig
+		engine = ethash.NewFaker()
+		gspec  = &Genesis{Config: &config}
+	)
+	db := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 3, nil)
+
+	chain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+
+	// Scheduling an upgrade in the past must rewind to the last block before it
+	newcfg := *gspec.Config
+	newcfg.StateUpgrades = []params.StateUpgrade{{Timestamp: 15}}
+	_, _, err = SetupGenesisBlock(db, &Genesis{Config: &newcfg})
+	compatErr, ok := err.(*params.ConfigCompatError)
+	if !ok {
+		t.Fatalf("expected compatibility error, got %v", err)
+	}
+	if compatErr.RewindTo != 1 {
+		t.Errorf("rewind block mismatch: have %d, want 1", compatErr.RewindTo)
+	}
+}
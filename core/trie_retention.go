@@ -0,0 +1,87 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/log"
+	"github.com/ava-labs/go-ethereum/trie"
+)
+
+// TrieRetentionHook returns the roots of the auxiliary tries that chain
+// extensions wrote to the trie database of the state along with the block of
+// the given header, e.g. from a state.CommitHook. The block chain retains the
+// returned tries in step with the state of the block: they are pinned in memory
+// as long as the state is, flushed to disk whenever it is and released once it
+// is garbage collected. Archive nodes flush them right away.
+//
+// The hook is consulted again when the state of a block is flushed, so it must
+// return the same roots for the same header. Tries with a lifetime of their own
+// can instead be pinned and released directly through trie.Database.Reference
+// and trie.Database.Dereference.
+type TrieRetentionHook func(header *types.Header) []common.Hash
+
+// trieRetentionHook is the hook registered with RegisterTrieRetentionHook, if
+// any.
+var trieRetentionHook TrieRetentionHook
+
+// RegisterTrieRetentionHook registers the hook reporting the auxiliary tries to
+// retain along with the state of blocks.
+//
+// It is not safe for concurrent use and must be called during initialisation.
+// It panics if a hook is already registered.
+func RegisterTrieRetentionHook(hook TrieRetentionHook) {
+	if trieRetentionHook != nil {
+		panic("core: trie retention hook already registered")
+	}
+	trieRetentionHook = hook
+}
+
+// retainedTries returns the roots of the auxiliary tries to retain along with
+// the state of the block of the given header.
+func retainedTries(header *types.Header) []common.Hash {
+	if trieRetentionHook == nil {
+		return nil
+	}
+	return trieRetentionHook(header)
+}
+
+// commitRetainedTries flushes the auxiliary tries retained along with the state
+// of the block of the given header to disk.
+func commitRetainedTries(triedb *trie.Database, header *types.Header, report bool) error {
+	for _, root := range retainedTries(header) {
+		if err := triedb.Commit(root, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pinRetainedTries pins the auxiliary tries retained along with the state of
+// the given block in memory, queueing them for garbage collection along with
+// the state.
+func (bc *BlockChain) pinRetainedTries(triedb *trie.Database, block *types.Block) {
+	for _, root := range retainedTries(block.Header()) {
+		if root == (common.Hash{}) {
+			log.Error("Ignoring empty retained trie root", "number", block.Number(), "hash", block.Hash())
+			continue
+		}
+		triedb.Reference(root, common.Hash{})
+		bc.triegc.Push(root, -int64(block.NumberU64()))
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/consensus/ethash"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
+	"github.com/ava-labs/go-ethereum/params"
+	"github.com/ava-labs/go-ethereum/trie"
+)
+
+func TestTrieRetentionHook(t *testing.T) {
+	var (
+		engine  = ethash.NewFaker()
+		db      = rawdb.NewMemoryDatabase()
+		genesis = new(Genesis).MustCommit(db)
+	)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, engine, db, TriesInMemory+2, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{1}) })
+
+	diskdb := rawdb.NewMemoryDatabase()
+	new(Genesis).MustCommit(diskdb)
+	chain, err := NewBlockChain(diskdb, nil, params.TestChainConfig, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	// Write an auxiliary trie for every block
+	triedb := chain.stateCache.TrieDB()
+	roots := make(map[uint64]common.Hash)
+	for _, block := range blocks {
+		tr, _ := trie.New(common.Hash{}, triedb)
+		number := make([]byte, 8)
+		binary.BigEndian.PutUint64(number, block.NumberU64())
+		tr.Update([]byte("number"), number)
+		roots[block.NumberU64()], _ = tr.Commit(nil)
+	}
+	RegisterTrieRetentionHook(func(header *types.Header) []common.Hash {
+		return []common.Hash{roots[header.Number.Uint64()]}
+	})
+	defer func() { trieRetentionHook = nil }()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Tries must be released along with the state of their block
+	if _, err := triedb.Node(roots[1]); err == nil {
+		t.Errorf("trie of block 1 retained after garbage collection")
+	}
+	head := blocks[len(blocks)-1].NumberU64()
+	if _, err := triedb.Node(roots[head]); err != nil {
+		t.Errorf("trie of head block released: %v", err)
+	}
+	// Tries must be flushed along with the state of their block
+	chain.Stop()
+	if ok, _ := diskdb.Has(roots[head].Bytes()); !ok {
+		t.Errorf("trie of head block not flushed")
+	}
+	if ok, _ := diskdb.Has(roots[1].Bytes()); ok {
+		t.Errorf("trie of block 1 flushed")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/ava-labs/go-ethereum/core/rawdb"
 	"github.com/ava-labs/go-ethereum/core/state"
 	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
 	"github.com/ava-labs/go-ethereum/crypto"
 	"github.com/ava-labs/go-ethereum/event"
 	"github.com/ava-labs/go-ethereum/params"
@@ -258,6 +260,80 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+func TestIntrinsicGasHook(t *testing.T) {
+	defer func() { intrinsicGasHook = nil }()
+
+	RegisterIntrinsicGasHook(func(msg Message, rules params.Rules, state vm.StateReader, gas uint64) (uint64, error) {
+		return gas + 10000, nil
+	})
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(1000000))
+
+	if err := pool.AddRemote(transaction(0, params.TxGas, key)); err != ErrIntrinsicGas {
+		t.Errorf("unadjusted intrinsic gas accepted: have %v, want %v", err, ErrIntrinsicGas)
+	}
+	if err := pool.AddRemote(transaction(0, params.TxGas+10000, key)); err != nil {
+		t.Errorf("adjusted intrinsic gas rejected: %v", err)
+	}
+}
+
+// The effective gas price function can't be unregistered outside of package
+// types, so it is registered once, repricing only transactions sent to these
+// recipients.
+var (
+	testEffectiveGasPriceOnce sync.Once
+	doubledPriceRecipient     = common.Address{0xef, 0x02}
+	halvedPriceRecipient      = common.Address{0xef, 0x01}
+)
+
+// registerTestEffectiveGasPrice registers the effective gas price function
+// doubling or halving the price of transactions to the test recipients.
+func registerTestEffectiveGasPrice() {
+	testEffectiveGasPriceOnce.Do(func() {
+		types.RegisterEffectiveGasPrice(func(tx *types.Transaction) *big.Int {
+			switch to := tx.To(); {
+			case to != nil && *to == doubledPriceRecipient:
+				return new(big.Int).Mul(tx.GasPrice(), big.NewInt(2))
+			case to != nil && *to == halvedPriceRecipient:
+				return new(big.Int).Div(tx.GasPrice(), big.NewInt(2))
+			}
+			return tx.GasPrice()
+		})
+	})
+}
+
+// Tests that the balance of senders is checked against the cost of their
+// transactions at the effective gas price, as charged by execution.
+func TestTransactionEffectiveCost(t *testing.T) {
+	registerTestEffectiveGasPrice()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(150000))
+
+	doubled, _ := types.SignTx(types.NewTransaction(0, doubledPriceRecipient, new(big.Int), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	if err := pool.AddRemote(doubled); err != ErrInsufficientFunds {
+		t.Errorf("error mismatch for doubled price: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	halved, _ := types.SignTx(types.NewTransaction(0, halvedPriceRecipient, new(big.Int), 100000, big.NewInt(2), nil), types.HomesteadSigner{}, key)
+	if err := pool.AddRemote(halved); err != nil {
+		t.Errorf("halved price rejected: %v", err)
+	}
+	// The transaction must survive the cost cap of the pending list
+	<-pool.requestReset(nil, nil)
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Errorf("pool stats mismatch: have %d pending, %d queued, want 1 pending", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
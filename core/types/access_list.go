@@ -0,0 +1,65 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"github.com/ava-labs/go-ethereum/common"
+)
+
+// AccessTuple is an element of an access list: an address along with storage
+// keys. Tuples addressed to precompiles may instead carry predicates packed
+// into their storage keys, see vm.RegisterPredicater.
+type AccessTuple struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []common.Hash  `json:"storageKeys"`
+}
+
+// AccessList is the list of addresses and storage keys a transaction declares.
+type AccessList []AccessTuple
+
+// Copy creates a deep copy of the access list.
+func (al AccessList) Copy() AccessList {
+	if al == nil {
+		return nil
+	}
+	cpy := make(AccessList, len(al))
+	for i, tuple := range al {
+		cpy[i] = AccessTuple{
+			Address:     tuple.Address,
+			StorageKeys: append([]common.Hash(nil), tuple.StorageKeys...),
+		}
+	}
+	return cpy
+}
+
+// AccessListTxData is implemented by the data of custom transaction types that
+// carry an access list. Legacy transactions have none.
+type AccessListTxData interface {
+	TxData
+
+	// AccessList returns the access list of the transaction.
+	AccessList() AccessList
+}
+
+// AccessList returns the access list of the transaction, nil if its type
+// doesn't carry one.
+func (tx *Transaction) AccessList() AccessList {
+	if data, ok := tx.inner.(AccessListTxData); ok {
+		return data.AccessList()
+	}
+	return nil
+}
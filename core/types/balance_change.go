@@ -0,0 +1,64 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+// BalanceChangeReason describes why the balance of an account changed.
+type BalanceChangeReason uint8
+
+const (
+	BalanceChangeUnspecified BalanceChangeReason = iota // Reason not given by the caller
+	BalanceChangeTransfer                               // Value transferred by a transaction or message call
+	BalanceChangeGasBuy                                 // Gas bought up front by the sender of a transaction
+	BalanceChangeGasRefund                              // Unused gas refunded to the sender of a transaction
+	BalanceChangeFee                                    // Transaction fee paid to the coinbase
+	BalanceChangeReward                                 // Block or uncle reward
+	BalanceChangeSuicide                                // Balance of a self destructed account moved to its beneficiary
+	BalanceChangeGenesis                                // Allocation of the genesis block
+	BalanceChangeDAO                                    // Irregular state change of the DAO hard fork
+	BalanceChangeRevert                                 // Earlier change undone by reverting to a snapshot
+	BalanceChangeUpgrade                                // Irregular state change of a scheduled state upgrade
+)
+
+// String implements fmt.Stringer.
+func (r BalanceChangeReason) String() string {
+	switch r {
+	case BalanceChangeUnspecified:
+		return "unspecified"
+	case BalanceChangeTransfer:
+		return "transfer"
+	case BalanceChangeGasBuy:
+		return "gas buy"
+	case BalanceChangeGasRefund:
+		return "gas refund"
+	case BalanceChangeFee:
+		return "fee"
+	case BalanceChangeReward:
+		return "reward"
+	case BalanceChangeSuicide:
+		return "suicide"
+	case BalanceChangeGenesis:
+		return "genesis"
+	case BalanceChangeDAO:
+		return "dao"
+	case BalanceChangeRevert:
+		return "revert"
+	case BalanceChangeUpgrade:
+		return "upgrade"
+	default:
+		return "unknown"
+	}
+}
@@ -84,6 +84,8 @@ type Header struct {
 	Extra       []byte         `json:"extraData"        gencodec:"required"`
 	MixDigest   common.Hash    `json:"mixHash"`
 	Nonce       BlockNonce     `json:"nonce"`
+
+	extra interface{} // Chain specific payload registered with RegisterHeaderExtras, if any
 }
 
 // field type overrides for gencodec
@@ -98,8 +100,11 @@ type headerMarshaling struct {
 }
 
 // Hash returns the block hash of the header, which is simply the keccak256 hash of its
-// RLP encoding.
+// RLP encoding, less any extra header fields opted out of hashing.
 func (h *Header) Hash() common.Hash {
+	if fields, ok := h.hashFields(); ok {
+		return rlpHash(fields)
+	}
 	return rlpHash(h)
 }
 
@@ -108,7 +113,7 @@ var headerSize = common.StorageSize(reflect.TypeOf(Header{}).Size())
 // Size returns the approximate memory used by all internal contents. It is used
 // to approximate and limit the memory consumption of various caches.
 func (h *Header) Size() common.StorageSize {
-	return headerSize + common.StorageSize(len(h.Extra)+(h.Difficulty.BitLen()+h.Number.BitLen())/8)
+	return headerSize + common.StorageSize(len(h.Extra)+(h.Difficulty.BitLen()+h.Number.BitLen())/8) + extraPayloadSize(h.extra)
 }
 
 // SanityCheck checks a few basic things -- these checks are way beyond what
@@ -142,6 +147,8 @@ func rlpHash(x interface{}) (h common.Hash) {
 type Body struct {
 	Transactions []*Transaction
 	Uncles       []*Header
+
+	extra interface{} // Chain specific payload registered with RegisterBodyExtras, if any
 }
 
 // Block represents an entire block in the Ethereum blockchain.
@@ -149,6 +156,7 @@ type Block struct {
 	header       *Header
 	uncles       []*Header
 	transactions Transactions
+	extra        interface{} // Chain specific payload registered with RegisterBodyExtras, if any
 
 	// caches
 	hash atomic.Value
@@ -207,7 +215,7 @@ func NewBlock(header *Header, txs []*Transaction, uncles []*Header, receipts []*
 	if len(txs) == 0 {
 		b.header.TxHash = EmptyRootHash
 	} else {
-		b.header.TxHash = DeriveSha(Transactions(txs))
+		b.header.TxHash = DeriveTxsSha(header.Number, Transactions(txs))
 		b.transactions = make(Transactions, len(txs))
 		copy(b.transactions, txs)
 	}
@@ -253,28 +261,42 @@ func CopyHeader(h *Header) *Header {
 		cpy.Extra = make([]byte, len(h.Extra))
 		copy(cpy.Extra, h.Extra)
 	}
+	cpy.extra = copyExtraPayload(h.extra)
 	return &cpy
 }
 
 // DecodeRLP decodes the Ethereum
 func (b *Block) DecodeRLP(s *rlp.Stream) error {
-	var eb extblock
+	var (
+		eb    extblock
+		extra interface{}
+		err   error
+	)
 	_, size, _ := s.Kind()
-	if err := s.Decode(&eb); err != nil {
+	if bodyExtraType == nil {
+		err = s.Decode(&eb)
+	} else {
+		extra, err = decodeRLPWithBodyExtra(s, &eb, extblockFieldCount)
+	}
+	if err != nil {
 		return err
 	}
-	b.header, b.uncles, b.transactions = eb.Header, eb.Uncles, eb.Txs
+	b.header, b.uncles, b.transactions, b.extra = eb.Header, eb.Uncles, eb.Txs, extra
 	b.size.Store(common.StorageSize(rlp.ListSize(size)))
 	return nil
 }
 
 // EncodeRLP serializes b into the Ethereum RLP block format.
 func (b *Block) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, extblock{
+	eb := extblock{
 		Header: b.header,
 		Txs:    b.transactions,
 		Uncles: b.uncles,
-	})
+	}
+	if bodyExtraType == nil {
+		return rlp.Encode(w, eb)
+	}
+	return encodeRLPWithExtra(w, eb, bodyExtraPayload(b.extra))
 }
 
 // [deprecated by eth/63]
@@ -322,7 +344,9 @@ func (b *Block) Extra() []byte            { return common.CopyBytes(b.header.Ext
 func (b *Block) Header() *Header { return CopyHeader(b.header) }
 
 // Body returns the non-header content of the block.
-func (b *Block) Body() *Body { return &Body{b.transactions, b.uncles} }
+func (b *Block) Body() *Body {
+	return &Body{Transactions: b.transactions, Uncles: b.uncles, extra: b.extra}
+}
 
 // Size returns the true RLP encoded storage size of the block, either by encoding
 // and returning it, or returning a previsouly cached value.
@@ -365,15 +389,18 @@ func (b *Block) WithSeal(header *Header) *Block {
 		header:       &cpy,
 		transactions: b.transactions,
 		uncles:       b.uncles,
+		extra:        b.extra,
 	}
 }
 
-// WithBody returns a new block with the given transaction and uncle contents.
+// WithBody returns a new block with the given transaction and uncle contents,
+// retaining a copy of the extra body payload, if any.
 func (b *Block) WithBody(transactions []*Transaction, uncles []*Header) *Block {
 	block := &Block{
 		header:       CopyHeader(b.header),
 		transactions: make([]*Transaction, len(transactions)),
 		uncles:       make([]*Header, len(uncles)),
+		extra:        copyExtraPayload(b.extra),
 	}
 	copy(block.transactions, transactions)
 	for i := range uncles {
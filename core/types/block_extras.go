@@ -0,0 +1,238 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/ava-labs/go-ethereum/rlp"
+)
+
+// bodyExtraType is the type of the payload registered with RegisterBodyExtras,
+// if any.
+var bodyExtraType reflect.Type
+
+// RegisterBodyExtras registers the chain specific payload carried by every
+// block body in addition to its transactions and uncles, e.g. coreth's atomic
+// transactions. The payload is given as a pointer to a struct, whose exported
+// fields are RLP encoded after the uncles of both blocks and bodies. Note that
+// the payload isn't covered by the block hash unless the chain commits to it in
+// a header field.
+//
+// It is not safe for concurrent use and must be called during initialisation,
+// before any block is encoded or decoded. It panics if the payload isn't a
+// pointer to a struct or if extras are already registered.
+func RegisterBodyExtras(payload interface{}) {
+	if bodyExtraType != nil {
+		panic("types: body extras already registered")
+	}
+	typ := reflect.TypeOf(payload)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("types: body extras must be a pointer to a struct, got %T", payload))
+	}
+	bodyExtraType = typ
+}
+
+// newBodyExtra creates an empty instance of the registered body payload.
+func newBodyExtra() interface{} {
+	return reflect.New(bodyExtraType.Elem()).Interface()
+}
+
+// checkBodyExtra panics if the given payload is neither nil nor of the type
+// registered with RegisterBodyExtras.
+func checkBodyExtra(payload interface{}) {
+	if payload != nil && (bodyExtraType == nil || reflect.TypeOf(payload) != bodyExtraType) {
+		panic(fmt.Sprintf("types: body extras of type %T not registered", payload))
+	}
+}
+
+// bodyExtraPayload returns the given body payload, or an empty one if nil.
+func bodyExtraPayload(extra interface{}) interface{} {
+	if extra == nil {
+		return newBodyExtra()
+	}
+	return extra
+}
+
+// rlpBody has the fields of Body but none of its methods.
+type rlpBody Body
+
+// Number of canonical fields in the RLP lists of bodies and blocks.
+const (
+	bodyFieldCount     = 2
+	extblockFieldCount = 3
+)
+
+// ExtraPayload returns the payload registered with RegisterBodyExtras carried
+// by the body, or nil if there is none.
+func (b *Body) ExtraPayload() interface{} {
+	return b.extra
+}
+
+// SetExtraPayload sets the payload carried by the body, which must be nil or
+// of the type registered with RegisterBodyExtras. It panics otherwise.
+func (b *Body) SetExtraPayload(payload interface{}) {
+	checkBodyExtra(payload)
+	b.extra = payload
+}
+
+// EncodeRLP implements rlp.Encoder, appending the fields of the registered
+// extra payload, if any, to the transactions and uncles. Like the header's, it
+// has a value receiver.
+func (b Body) EncodeRLP(w io.Writer) error {
+	if bodyExtraType == nil {
+		return rlp.Encode(w, (*rlpBody)(&b))
+	}
+	return encodeRLPWithExtra(w, (*rlpBody)(&b), bodyExtraPayload(b.extra))
+}
+
+// DecodeRLP implements rlp.Decoder, decoding the fields following the uncles
+// into the registered extra payload, if any. Bodies without such fields, e.g.
+// those stored before the payload was registered, carry no payload.
+func (b *Body) DecodeRLP(s *rlp.Stream) error {
+	if bodyExtraType == nil {
+		return s.Decode((*rlpBody)(b))
+	}
+	extra, err := decodeRLPWithBodyExtra(s, (*rlpBody)(b), bodyFieldCount)
+	if err != nil {
+		return err
+	}
+	b.extra = extra
+	return nil
+}
+
+// decodeRLPWithBodyExtra decodes the first n fields of a list into val and
+// returns the rest decoded by DecodeBodyExtra.
+func decodeRLPWithBodyExtra(s *rlp.Stream, val interface{}, n int) (interface{}, error) {
+	var fields []rlp.RawValue
+	if err := s.Decode(&fields); err != nil {
+		return nil, err
+	}
+	if len(fields) < n {
+		return nil, fmt.Errorf("rlp: too few fields for %T: have %d, want at least %d", val, len(fields), n)
+	}
+	if err := rlp.DecodeListElements(fields[:n], val); err != nil {
+		return nil, err
+	}
+	return DecodeBodyExtra(fields[n:])
+}
+
+// DecodeBodyExtra decodes the fields following the uncles of a body, e.g. as
+// relayed by the eth protocol, into the payload registered with
+// RegisterBodyExtras. It returns nil if there are no such fields.
+func DecodeBodyExtra(fields []rlp.RawValue) (interface{}, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if bodyExtraType == nil {
+		return nil, fmt.Errorf("rlp: %d extra body fields without registered extras", len(fields))
+	}
+	extra := newBodyExtra()
+	if err := rlp.DecodeListElements(fields, extra); err != nil {
+		return nil, err
+	}
+	return extra, nil
+}
+
+// BodyExtraCommitter is implemented by extra body payloads committed to by a
+// header field, e.g. coreth's ExtDataHash, allowing NewBlockWithExtras to derive
+// the commitment along with the transaction and receipt roots.
+type BodyExtraCommitter interface {
+	CommitTo(header *Header)
+}
+
+// BodyExtraVerifier is implemented by extra body payloads that are verified
+// against the header of their block by other means than a commitment set by
+// BodyExtraCommitter.
+type BodyExtraVerifier interface {
+	VerifyAgainst(header *Header) error
+}
+
+// ErrBodyExtraMismatch is returned by VerifyBodyExtra if the extra body payload
+// doesn't yield the commitment carried by the header.
+var ErrBodyExtraMismatch = errors.New("extra body payload mismatch")
+
+// VerifyBodyExtra checks that the given extra body payload, e.g. as relayed by
+// a peer, belongs to the given header. A payload implementing
+// BodyExtraCommitter must yield the commitment the header carries and one
+// implementing BodyExtraVerifier must pass its check. A nil payload is verified
+// like an empty one, as the two are encoded alike. Payloads implementing
+// neither interface can't be verified against the header and are accepted.
+func VerifyBodyExtra(header *Header, extra interface{}) error {
+	if bodyExtraType == nil {
+		if extra != nil {
+			return fmt.Errorf("extra body payload of type %T not registered", extra)
+		}
+		return nil
+	}
+	if extra != nil && reflect.TypeOf(extra) != bodyExtraType {
+		return fmt.Errorf("extra body payload of type %T, want %v", extra, bodyExtraType)
+	}
+	extra = bodyExtraPayload(extra)
+	if committer, ok := extra.(BodyExtraCommitter); ok {
+		committed := CopyHeader(header)
+		committer.CommitTo(committed)
+		if committed.Hash() != header.Hash() {
+			return ErrBodyExtraMismatch
+		}
+	}
+	if verifier, ok := extra.(BodyExtraVerifier); ok {
+		return verifier.VerifyAgainst(header)
+	}
+	return nil
+}
+
+// NewBlockWithExtras creates a new block like NewBlock, additionally carrying
+// the given extra body payload, which must be nil or of the type registered
+// with RegisterBodyExtras. If the payload implements BodyExtraCommitter, its
+// commitment is set in the header of the block, that of an empty payload if
+// nil, matching VerifyBodyExtra. The extra header payload, if any, is copied
+// from the given header.
+func NewBlockWithExtras(header *Header, txs []*Transaction, uncles []*Header, receipts []*Receipt, extra interface{}) *Block {
+	checkBodyExtra(extra)
+
+	b := NewBlock(header, txs, uncles, receipts)
+	b.extra = extra
+	if bodyExtraType != nil {
+		if committer, ok := bodyExtraPayload(extra).(BodyExtraCommitter); ok {
+			committer.CommitTo(b.header)
+		}
+	}
+	return b
+}
+
+// ExtraPayload returns the payload registered with RegisterBodyExtras carried
+// by the block, or nil if there is none.
+func (b *Block) ExtraPayload() interface{} {
+	return b.extra
+}
+
+// WithExtraPayload returns a new block with the data from b but the extra body
+// payload replaced with the given one, which must be nil or of the type
+// registered with RegisterBodyExtras. It panics otherwise.
+func (b *Block) WithExtraPayload(payload interface{}) *Block {
+	checkBodyExtra(payload)
+	return &Block{
+		header:       b.header,
+		transactions: b.transactions,
+		uncles:       b.uncles,
+		extra:        payload,
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/rlp"
+)
+
+// testBodyExtra is a body payload modelled after coreth's atomic transactions.
+type testBodyExtra struct {
+	Version uint32
+	ExtData []byte
+}
+
+// unregisterBodyExtras removes the registered body payload, if any.
+func unregisterBodyExtras() {
+	bodyExtraType = nil
+}
+
+func TestBlockExtrasRLP(t *testing.T) {
+	RegisterBodyExtras(&testBodyExtra{})
+	defer unregisterBodyExtras()
+
+	var (
+		tx    = NewTransaction(1, common.Address{2}, common.Big1, 21000, common.Big1, nil)
+		extra = &testBodyExtra{Version: 1, ExtData: []byte{3, 4}}
+		block = NewBlock(newTestHeader(), []*Transaction{tx}, nil, nil).WithExtraPayload(extra)
+	)
+	enc, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := int(block.Size()); size != len(enc) {
+		t.Errorf("block size mismatch: have %d, want %d", size, len(enc))
+	}
+	dec := new(Block)
+	if err := rlp.DecodeBytes(enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec.ExtraPayload(), extra) {
+		t.Errorf("block payload mismatch: have %+v, want %+v", dec.ExtraPayload(), extra)
+	}
+	if dec.Hash() != block.Hash() || len(dec.Transactions()) != 1 {
+		t.Errorf("decoded block mismatch")
+	}
+	// Bodies must carry the payload through the database encoding
+	body := dec.Body()
+	if enc, err = rlp.EncodeToBytes(body); err != nil {
+		t.Fatal(err)
+	}
+	decBody := new(Body)
+	if err := rlp.DecodeBytes(enc, decBody); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decBody.ExtraPayload(), extra) {
+		t.Errorf("body payload mismatch: have %+v, want %+v", decBody.ExtraPayload(), extra)
+	}
+	rebuilt := NewBlockWithHeader(dec.Header()).WithBody(decBody.Transactions, decBody.Uncles).WithExtraPayload(decBody.ExtraPayload())
+	if reenc, _ := rlp.EncodeToBytes(rebuilt); !bytes.Equal(reenc, mustEncode(t, block)) {
+		t.Errorf("rebuilt block encoding mismatch")
+	}
+}
+
+func TestBlockExtrasUnregistered(t *testing.T) {
+	block := NewBlock(newTestHeader(), nil, nil, nil)
+	enc := mustEncode(t, block)
+	if enc2 := mustEncode(t, block.WithExtraPayload(nil)); !bytes.Equal(enc, enc2) {
+		t.Errorf("encoding changed by empty payload")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("unregistered payload accepted")
+		}
+	}()
+	block.WithExtraPayload(&testBodyExtra{})
+}
+
+func TestBlockExtrasCopy(t *testing.T) {
+	RegisterBodyExtras(&testBodyExtra{})
+	defer unregisterBodyExtras()
+
+	extra := &testBodyExtra{Version: 1, ExtData: []byte{3, 4}}
+	block := NewBlock(newTestHeader(), nil, nil, nil).WithExtraPayload(extra)
+
+	if sealed := block.WithSeal(block.Header()); !reflect.DeepEqual(sealed.ExtraPayload(), extra) {
+		t.Errorf("sealed block payload mismatch: have %+v, want %+v", sealed.ExtraPayload(), extra)
+	}
+	filled := block.WithBody(nil, nil)
+	if !reflect.DeepEqual(filled.ExtraPayload(), extra) {
+		t.Errorf("filled block payload mismatch: have %+v, want %+v", filled.ExtraPayload(), extra)
+	}
+	if filled.ExtraPayload() == block.ExtraPayload() {
+		t.Errorf("filled block shares payload with original")
+	}
+	if filled.Size() != block.Size() {
+		t.Errorf("filled block size mismatch: have %v, want %v", filled.Size(), block.Size())
+	}
+}
+
+// committedBodyExtra is a body payload committed to by the ExtDataHash header
+// field of testHeaderExtra.
+type committedBodyExtra struct {
+	ExtData []byte
+}
+
+// CommitTo implements BodyExtraCommitter.
+func (e *committedBodyExtra) CommitTo(header *Header) {
+	extra := header.ExtraPayload().(*testHeaderExtra).Copy().(*testHeaderExtra)
+	extra.ExtDataHash = rlpHash(e.ExtData)
+	header.SetExtraPayload(extra)
+}
+
+func TestNewBlockWithExtras(t *testing.T) {
+	RegisterHeaderExtras(&testHeaderExtra{})
+	defer unregisterHeaderExtras()
+	RegisterBodyExtras(&committedBodyExtra{})
+	defer unregisterBodyExtras()
+
+	var (
+		tx     = NewTransaction(1, common.Address{2}, common.Big1, 21000, common.Big1, nil)
+		header = newTestHeader()
+		extra  = &committedBodyExtra{ExtData: []byte{3, 4}}
+	)
+	header.SetExtraPayload(&testHeaderExtra{BlockGasCost: big.NewInt(5)})
+	block := NewBlockWithExtras(header, []*Transaction{tx}, nil, nil, extra)
+
+	if block.TxHash() != DeriveSha(Transactions{tx}) {
+		t.Errorf("transaction root mismatch")
+	}
+	if block.ReceiptHash() != EmptyRootHash || block.UncleHash() != EmptyUncleHash {
+		t.Errorf("empty roots mismatch")
+	}
+	have := block.Header().ExtraPayload().(*testHeaderExtra)
+	if have.ExtDataHash != rlpHash(extra.ExtData) || have.BlockGasCost.Uint64() != 5 {
+		t.Errorf("header payload mismatch: have %+v", have)
+	}
+	if header.ExtraPayload().(*testHeaderExtra).ExtDataHash != (common.Hash{}) {
+		t.Errorf("commitment set in the given header")
+	}
+	// The block must survive a round trip unchanged
+	dec := new(Block)
+	if err := rlp.DecodeBytes(mustEncode(t, block), dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Hash() != block.Hash() || !reflect.DeepEqual(dec.ExtraPayload(), extra) {
+		t.Errorf("decoded block mismatch")
+	}
+	if int(block.Size()) != len(mustEncode(t, block)) {
+		t.Errorf("block size mismatch")
+	}
+}
+
+func TestVerifyBodyExtra(t *testing.T) {
+	// Without registered extras, only the absence of a payload is valid
+	if err := VerifyBodyExtra(newTestHeader(), nil); err != nil {
+		t.Errorf("missing payload rejected: %v", err)
+	}
+	if err := VerifyBodyExtra(newTestHeader(), &committedBodyExtra{}); err == nil {
+		t.Errorf("unregistered payload accepted")
+	}
+	RegisterHeaderExtras(&testHeaderExtra{})
+	defer unregisterHeaderExtras()
+	RegisterBodyExtras(&committedBodyExtra{})
+	defer unregisterBodyExtras()
+
+	header := newTestHeader()
+	header.SetExtraPayload(&testHeaderExtra{})
+	var (
+		extra = &committedBodyExtra{ExtData: []byte{3, 4}}
+		block = NewBlockWithExtras(header, nil, nil, nil, extra)
+		empty = NewBlockWithExtras(header, nil, nil, nil, &committedBodyExtra{})
+		none  = NewBlockWithExtras(header, nil, nil, nil, nil)
+	)
+	if err := VerifyBodyExtra(block.Header(), extra); err != nil {
+		t.Errorf("committed payload rejected: %v", err)
+	}
+	if err := VerifyBodyExtra(block.Header(), &committedBodyExtra{ExtData: []byte{5}}); err != ErrBodyExtraMismatch {
+		t.Errorf("injected payload error mismatch: have %v, want %v", err, ErrBodyExtraMismatch)
+	}
+	if err := VerifyBodyExtra(block.Header(), nil); err != ErrBodyExtraMismatch {
+		t.Errorf("stripped payload error mismatch: have %v, want %v", err, ErrBodyExtraMismatch)
+	}
+	if err := VerifyBodyExtra(empty.Header(), nil); err != nil {
+		t.Errorf("missing empty payload rejected: %v", err)
+	}
+	// A block without payload commits to an empty one
+	if none.Hash() != empty.Hash() {
+		t.Errorf("payloadless block hash mismatch: have %x, want %x", none.Hash(), empty.Hash())
+	}
+	if err := VerifyBodyExtra(none.Header(), nil); err != nil {
+		t.Errorf("missing payload of payloadless block rejected: %v", err)
+	}
+	if err := VerifyBodyExtra(none.Header(), &committedBodyExtra{}); err != nil {
+		t.Errorf("empty payload of payloadless block rejected: %v", err)
+	}
+	if err := VerifyBodyExtra(block.Header(), &testBodyExtra{}); err == nil {
+		t.Errorf("payload of foreign type accepted")
+	}
+}
+
+func mustEncode(t *testing.T, val interface{}) []byte {
+	t.Helper()
+	enc, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enc
+}
+
+func TestBlockExtrasMissing(t *testing.T) {
+	// Encode a body and block before the payload is registered, as stored by
+	// earlier versions or relayed by peers without the payload
+	tx := NewTransaction(1, common.Address{2}, common.Big1, 21000, common.Big1, nil)
+	block := NewBlock(newTestHeader(), []*Transaction{tx}, nil, nil)
+	blockEnc, bodyEnc := mustEncode(t, block), mustEncode(t, block.Body())
+
+	RegisterBodyExtras(&testBodyExtra{})
+	defer unregisterBodyExtras()
+
+	body := new(Body)
+	if err := rlp.DecodeBytes(bodyEnc, body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Transactions) != 1 || body.ExtraPayload() != nil {
+		t.Errorf("body mismatch: have %d txs and payload %+v", len(body.Transactions), body.ExtraPayload())
+	}
+	dec := new(Block)
+	if err := rlp.DecodeBytes(blockEnc, dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Hash() != block.Hash() || dec.ExtraPayload() != nil {
+		t.Errorf("block mismatch: have payload %+v", dec.ExtraPayload())
+	}
+	if extra, err := DecodeBodyExtra(nil); extra != nil || err != nil {
+		t.Errorf("empty payload mismatch: have %+v, %v", extra, err)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/crypto"
+)
+
+// ContractAddressHooks allow chains built on top of go-ethereum to observe and
+// override the derivation of contract addresses, e.g. to partition the address
+// space or to deploy system contracts at fixed addresses. Each hook receives
+// the upstream address as derived, and returns the address to use instead.
+// Both hooks are optional and must be deterministic, as they affect consensus.
+type ContractAddressHooks struct {
+	// Create derives the address of contracts created by CREATE and contract
+	// creation transactions.
+	Create func(caller common.Address, nonce uint64, derived common.Address) common.Address
+
+	// Create2 derives the address of contracts created by CREATE2.
+	Create2 func(caller common.Address, salt common.Hash, initCodeHash []byte, derived common.Address) common.Address
+}
+
+// contractAddressHooks are the hooks installed by RegisterContractAddressHooks.
+var (
+	contractAddressHooks           ContractAddressHooks
+	contractAddressHooksRegistered bool
+)
+
+// RegisterContractAddressHooks installs the hooks deriving contract addresses.
+// It is not safe for concurrent use and must be called during initialisation.
+// It panics if called more than once.
+func RegisterContractAddressHooks(hooks ContractAddressHooks) {
+	if contractAddressHooksRegistered {
+		panic("types: contract address hooks already registered")
+	}
+	contractAddressHooks, contractAddressHooksRegistered = hooks, true
+}
+
+// CreateAddress returns the address of the contract created by the given caller
+// with the given nonce, through either CREATE or a contract creation
+// transaction. Anything predicting contract addresses must use it instead of
+// crypto.CreateAddress to honour the registered hooks.
+func CreateAddress(caller common.Address, nonce uint64) common.Address {
+	addr := crypto.CreateAddress(caller, nonce)
+	if contractAddressHooks.Create != nil {
+		addr = contractAddressHooks.Create(caller, nonce, addr)
+	}
+	return addr
+}
+
+// CreateAddress2 returns the address of the contract created by the given caller
+// through CREATE2, honouring the registered hooks.
+func CreateAddress2(caller common.Address, salt common.Hash, initCodeHash []byte) common.Address {
+	addr := crypto.CreateAddress2(caller, salt, initCodeHash)
+	if contractAddressHooks.Create2 != nil {
+		addr = contractAddressHooks.Create2(caller, salt, initCodeHash, addr)
+	}
+	return addr
+}
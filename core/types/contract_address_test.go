@@ -0,0 +1,63 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/crypto"
+)
+
+func TestContractAddressHooks(t *testing.T) {
+	defer func() { contractAddressHooks, contractAddressHooksRegistered = ContractAddressHooks{}, false }()
+
+	var (
+		caller   = common.HexToAddress("0xca11e7")
+		salt     = common.HexToHash("0x5a17")
+		initHash = crypto.Keccak256([]byte{0x00})
+		system   = common.HexToAddress("0x0100000000000000000000000000000000000000")
+	)
+	if have, want := CreateAddress(caller, 1), crypto.CreateAddress(caller, 1); have != want {
+		t.Fatalf("upstream CREATE address mismatch: have %x, want %x", have, want)
+	}
+	RegisterContractAddressHooks(ContractAddressHooks{
+		// Deploy the first contract of the caller at a fixed address and
+		// partition the CREATE2 address space by setting the top byte.
+		Create: func(from common.Address, nonce uint64, derived common.Address) common.Address {
+			if from == caller && nonce == 0 {
+				return system
+			}
+			return derived
+		},
+		Create2: func(from common.Address, salt common.Hash, initCodeHash []byte, derived common.Address) common.Address {
+			derived[0] = 0xaa
+			return derived
+		},
+	})
+	if have := CreateAddress(caller, 0); have != system {
+		t.Errorf("overridden CREATE address mismatch: have %x, want %x", have, system)
+	}
+	if have, want := CreateAddress(caller, 1), crypto.CreateAddress(caller, 1); have != want {
+		t.Errorf("observed CREATE address mismatch: have %x, want %x", have, want)
+	}
+	want := crypto.CreateAddress2(caller, salt, initHash)
+	want[0] = 0xaa
+	if have := CreateAddress2(caller, salt, initHash); have != want {
+		t.Errorf("CREATE2 address mismatch: have %x, want %x", have, want)
+	}
+}
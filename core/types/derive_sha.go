@@ -18,6 +18,7 @@ package types
 
 import (
 	"bytes"
+	"math/big"
 
 	"github.com/ava-labs/go-ethereum/common"
 	"github.com/ava-labs/go-ethereum/rlp"
@@ -39,3 +40,52 @@ func DeriveSha(list DerivableList) common.Hash {
 	}
 	return trie.Hash()
 }
+
+// TxDeriveEncoder returns the encoding of a transaction hashed into the
+// transactions root of the block with the given number, reporting false to
+// fall back to the canonical encoding. It allows chains whose historical
+// transactions roots were derived differently, e.g. before a fork, to keep
+// validating them.
+type TxDeriveEncoder func(number *big.Int, tx *Transaction) ([]byte, bool)
+
+// txDeriveEncoder is the encoder registered with RegisterTxDeriveEncoder, if any.
+var txDeriveEncoder TxDeriveEncoder
+
+// RegisterTxDeriveEncoder registers the encoder of transactions hashed into
+// the transactions roots derived by DeriveTxsSha.
+//
+// RegisterTxDeriveEncoder is not safe for concurrent use and must be called
+// during initialisation. It panics if called more than once.
+func RegisterTxDeriveEncoder(encoder TxDeriveEncoder) {
+	if txDeriveEncoder != nil {
+		panic("types: transaction derive encoder already registered")
+	}
+	txDeriveEncoder = encoder
+}
+
+// derivableTxs is the DerivableList of the transactions of a block, encoded by
+// the registered TxDeriveEncoder.
+type derivableTxs struct {
+	number *big.Int
+	txs    Transactions
+}
+
+func (d derivableTxs) Len() int { return len(d.txs) }
+
+func (d derivableTxs) GetRlp(i int) []byte {
+	if enc, ok := txDeriveEncoder(d.number, d.txs[i]); ok {
+		return enc
+	}
+	return d.txs.GetRlp(i)
+}
+
+// DeriveTxsSha derives the transactions root of the block with the given
+// number. Unless a TxDeriveEncoder is registered, it is DeriveSha of the
+// transactions, hashing the canonical encoding of each, type byte included for
+// custom transaction types.
+func DeriveTxsSha(number *big.Int, txs Transactions) common.Hash {
+	if txDeriveEncoder == nil {
+		return DeriveSha(txs)
+	}
+	return DeriveSha(derivableTxs{number: number, txs: txs})
+}
@@ -50,7 +50,7 @@ func (h Header) MarshalJSON() ([]byte, error) {
 	enc.MixDigest = h.MixDigest
 	enc.Nonce = h.Nonce
 	enc.Hash = h.Hash()
-	return json.Marshal(&enc)
+	return marshalHeaderJSON(&enc, &h)
 }
 
 // UnmarshalJSON unmarshals from JSON.
@@ -134,5 +134,5 @@ func (h *Header) UnmarshalJSON(input []byte) error {
 	if dec.Nonce != nil {
 		h.Nonce = *dec.Nonce
 	}
-	return nil
+	return unmarshalHeaderExtraJSON(input, h)
 }
@@ -35,7 +35,7 @@ func (l Log) MarshalJSON() ([]byte, error) {
 	enc.BlockHash = l.BlockHash
 	enc.Index = hexutil.Uint(l.Index)
 	enc.Removed = l.Removed
-	return json.Marshal(&enc)
+	return marshalLogJSON(&enc, &l)
 }
 
 // UnmarshalJSON unmarshals from JSON.
@@ -88,5 +88,5 @@ func (l *Log) UnmarshalJSON(input []byte) error {
 	if dec.Removed != nil {
 		l.Removed = *dec.Removed
 	}
-	return nil
+	return unmarshalLogExtraJSON(input, l)
 }
@@ -40,7 +40,7 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 	enc.BlockHash = r.BlockHash
 	enc.BlockNumber = (*hexutil.Big)(r.BlockNumber)
 	enc.TransactionIndex = hexutil.Uint(r.TransactionIndex)
-	return json.Marshal(&enc)
+	return marshalReceiptJSON(&enc, &r)
 }
 
 // UnmarshalJSON unmarshals from JSON.
@@ -100,5 +100,5 @@ func (r *Receipt) UnmarshalJSON(input []byte) error {
 	if dec.TransactionIndex != nil {
 		r.TransactionIndex = uint(*dec.TransactionIndex)
 	}
-	return nil
+	return unmarshalReceiptExtraJSON(input, r)
 }
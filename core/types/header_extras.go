@@ -0,0 +1,365 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/rlp"
+)
+
+var (
+	// headerExtraType is the type of the payload registered with
+	// RegisterHeaderExtras, if any.
+	headerExtraType reflect.Type
+
+	// headerExtraOptOut is set if some of the encoded payload fields aren't
+	// covered by the header hash.
+	headerExtraOptOut bool
+
+	// headerExtraActive reports whether a header carries the extra payload, or
+	// is nil if all headers do.
+	headerExtraActive func(*Header) bool
+)
+
+// RegisterHeaderExtras registers the chain specific payload carried by every
+// header in addition to the canonical fields, e.g. coreth's ExtDataHash and
+// BlockGasCost. The payload is given as a pointer to a struct, whose exported
+// fields are RLP encoded after the canonical header fields and JSON encoded
+// alongside them; the JSON keys must not collide with the header's.
+//
+// The encoded fields are covered by the header hash and the seal hashes of the
+// consensus engines. Non-consensus annotations can opt out by tagging their
+// field with `hash:"-"`, leaving the hash different from that of the RLP
+// encoding.
+//
+// The payload of individual headers is accessed with the ExtraPayload and
+// SetExtraPayload methods of Header.
+//
+// It is not safe for concurrent use and must be called during initialisation,
+// before any header is encoded or decoded. It panics if the payload isn't a
+// pointer to a struct or if extras are already registered.
+func RegisterHeaderExtras(payload interface{}) {
+	if headerExtraType != nil {
+		panic("types: header extras already registered")
+	}
+	typ := reflect.TypeOf(payload)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("types: header extras must be a pointer to a struct, got %T", payload))
+	}
+	headerExtraType = typ
+
+	for i := 0; i < typ.Elem().NumField(); i++ {
+		if field := typ.Elem().Field(i); encodedField(field) && field.Tag.Get("hash") == "-" {
+			headerExtraOptOut = true
+		}
+	}
+}
+
+// RegisterHeaderExtrasActivation registers the predicate determining which
+// headers carry the extra payload registered with RegisterHeaderExtras, e.g.
+// the ones after the network upgrade introducing the payload fields. Headers
+// for which it returns false are encoded and hashed with the canonical fields
+// only, any payload they carry being ignored, allowing the chain's history
+// from before the upgrade to be decoded. The predicate must only depend on the
+// canonical header fields, e.g. the number or time.
+//
+// It is not safe for concurrent use and must be called during initialisation,
+// after RegisterHeaderExtras. It panics if no extras are registered or if a
+// predicate is already registered.
+func RegisterHeaderExtrasActivation(active func(*Header) bool) {
+	if headerExtraType == nil {
+		panic("types: header extras not registered")
+	}
+	if headerExtraActive != nil {
+		panic("types: header extras activation already registered")
+	}
+	headerExtraActive = active
+}
+
+// hasExtras reports whether the header is encoded with an extra payload.
+func (h *Header) hasExtras() bool {
+	return headerExtraType != nil && (headerExtraActive == nil || headerExtraActive(h))
+}
+
+// encodedField reports whether a payload field is RLP encoded.
+func encodedField(field reflect.StructField) bool {
+	return field.PkgPath == "" && field.Tag.Get("rlp") != "-"
+}
+
+// newHeaderExtra creates an empty instance of the registered header payload.
+func newHeaderExtra() interface{} {
+	return reflect.New(headerExtraType.Elem()).Interface()
+}
+
+// ExtraPayload returns the payload registered with RegisterHeaderExtras carried
+// by the header, or nil if there is none. The supported Go versions predate
+// type parameters, so callers assert the payload to the registered type.
+func (h *Header) ExtraPayload() interface{} {
+	return h.extra
+}
+
+// SetExtraPayload sets the payload carried by the header, which must be of the
+// type registered with RegisterHeaderExtras. It panics otherwise.
+func (h *Header) SetExtraPayload(payload interface{}) {
+	if headerExtraType == nil || reflect.TypeOf(payload) != headerExtraType {
+		panic(fmt.Sprintf("types: header extras of type %T not registered", payload))
+	}
+	h.extra = payload
+}
+
+// extraPayload returns the payload carried by the header, or an empty one if
+// it has none.
+func (h *Header) extraPayload() interface{} {
+	if h.extra == nil {
+		return newHeaderExtra()
+	}
+	return h.extra
+}
+
+// ExtraHashFields returns the values of the fields of the header's extra payload
+// covered by the header hash, or nil if the header carries no extras. Consensus
+// engines append them to the fields of their seal hashes.
+func (h *Header) ExtraHashFields() []interface{} {
+	if !h.hasExtras() {
+		return nil
+	}
+	var (
+		payload = reflect.ValueOf(h.extraPayload()).Elem()
+		fields  []interface{}
+	)
+	for i := 0; i < payload.NumField(); i++ {
+		if field := headerExtraType.Elem().Field(i); !encodedField(field) || field.Tag.Get("hash") == "-" {
+			continue
+		}
+		fields = append(fields, payload.Field(i).Interface())
+	}
+	return fields
+}
+
+// hashFields returns the header fields covered by its hash, if they differ
+// from the ones RLP encoded.
+func (h *Header) hashFields() ([]interface{}, bool) {
+	if !headerExtraOptOut || !h.hasExtras() {
+		return nil, false
+	}
+	canonical, err := rlp.ListElements((*rlpHeader)(h))
+	if err != nil {
+		return nil, false
+	}
+	fields := make([]interface{}, 0, len(canonical)+headerExtraType.Elem().NumField())
+	for _, field := range canonical {
+		fields = append(fields, field)
+	}
+	return append(fields, h.ExtraHashFields()...), true
+}
+
+// PayloadCopier is implemented by extra header and body payloads that hold
+// reference types, e.g. big integers or slices, allowing them to be deep copied
+// along with the header or block carrying them. Payloads not implementing it
+// are copied field by field.
+type PayloadCopier interface {
+	Copy() interface{}
+}
+
+// PayloadSizer is implemented by extra header payloads to report their
+// approximate memory size. The size of payloads not implementing it is
+// approximated by that of their RLP encoding.
+type PayloadSizer interface {
+	Size() common.StorageSize
+}
+
+// copyExtraPayload returns a copy of an extra header or body payload.
+func copyExtraPayload(payload interface{}) interface{} {
+	if payload == nil {
+		return nil
+	}
+	if copier, ok := payload.(PayloadCopier); ok {
+		return copier.Copy()
+	}
+	val := reflect.ValueOf(payload)
+	cpy := reflect.New(val.Type().Elem())
+	cpy.Elem().Set(val.Elem())
+	return cpy.Interface()
+}
+
+// extraPayloadSize returns the approximate memory size of an extra header
+// payload.
+func extraPayloadSize(payload interface{}) common.StorageSize {
+	if payload == nil {
+		return 0
+	}
+	if sizer, ok := payload.(PayloadSizer); ok {
+		return sizer.Size()
+	}
+	c := writeCounter(0)
+	rlp.Encode(&c, payload)
+	return common.StorageSize(c)
+}
+
+// rlpHeader has the fields of Header but none of its methods, allowing the
+// canonical fields to be encoded without recursing into EncodeRLP.
+type rlpHeader Header
+
+// headerFieldCount is the number of canonical header fields in the RLP list.
+var headerFieldCount = func() int {
+	var n int
+	typ := reflect.TypeOf(Header{})
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).PkgPath == "" {
+			n++
+		}
+	}
+	return n
+}()
+
+// EncodeRLP implements rlp.Encoder, appending the fields of the registered
+// extra payload, if the header carries one, to the canonical header fields. It
+// has a value receiver so that unaddressable headers remain encodable.
+func (h Header) EncodeRLP(w io.Writer) error {
+	if !h.hasExtras() {
+		return rlp.Encode(w, (*rlpHeader)(&h))
+	}
+	return encodeRLPWithExtra(w, (*rlpHeader)(&h), h.extraPayload())
+}
+
+// DecodeRLP implements rlp.Decoder, decoding the fields following the canonical
+// header fields into the registered extra payload, if the header carries one.
+func (h *Header) DecodeRLP(s *rlp.Stream) error {
+	if headerExtraType == nil {
+		return s.Decode((*rlpHeader)(h))
+	}
+	var fields []rlp.RawValue
+	if err := s.Decode(&fields); err != nil {
+		return err
+	}
+	if len(fields) < headerFieldCount {
+		return fmt.Errorf("rlp: too few fields for %T: have %d, want at least %d", h, len(fields), headerFieldCount)
+	}
+	if err := rlp.DecodeListElements(fields[:headerFieldCount], (*rlpHeader)(h)); err != nil {
+		return err
+	}
+	h.extra = nil
+	if !h.hasExtras() {
+		if len(fields) > headerFieldCount {
+			return fmt.Errorf("rlp: extra fields in %T before activation: have %d, want %d", h, len(fields), headerFieldCount)
+		}
+		return nil
+	}
+	extra := newHeaderExtra()
+	if err := rlp.DecodeListElements(fields[headerFieldCount:], extra); err != nil {
+		return err
+	}
+	h.extra = extra
+	return nil
+}
+
+// encodeRLPWithExtra encodes a list made up of the fields of val followed by
+// the fields of extra, both of which must be encoded as lists.
+func encodeRLPWithExtra(w io.Writer, val interface{}, extra interface{}) error {
+	fields, err := rlp.ListElements(val)
+	if err != nil {
+		return err
+	}
+	extras, err := rlp.ListElements(extra)
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, append(fields, extras...))
+}
+
+// decodeRLPWithExtra decodes the first n fields of a list into val and the rest
+// into extra.
+func decodeRLPWithExtra(s *rlp.Stream, val interface{}, n int, extra interface{}) error {
+	var fields []rlp.RawValue
+	if err := s.Decode(&fields); err != nil {
+		return err
+	}
+	if len(fields) < n {
+		return fmt.Errorf("rlp: too few fields for %T: have %d, want at least %d", val, len(fields), n)
+	}
+	if err := rlp.DecodeListElements(fields[:n], val); err != nil {
+		return err
+	}
+	return rlp.DecodeListElements(fields[n:], extra)
+}
+
+// marshalHeaderJSON encodes the JSON object of a header's canonical fields,
+// merged with the fields of its extra payload, if any.
+func marshalHeaderJSON(fields interface{}, h *Header) ([]byte, error) {
+	enc, err := json.Marshal(fields)
+	if err != nil || !h.hasExtras() {
+		return enc, err
+	}
+	return mergeJSONObject(enc, h.extraPayload())
+}
+
+// mergeJSONObject merges the fields of the JSON object encoding of an extra
+// payload into the given JSON object.
+func mergeJSONObject(enc []byte, payload interface{}) ([]byte, error) {
+	extra, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(extra, []byte("{")) || !bytes.HasPrefix(enc, []byte("{")) {
+		return nil, fmt.Errorf("types: extras of type %T not encoded as a JSON object", payload)
+	}
+	if extra = bytes.TrimSpace(extra[1:]); len(extra) == 1 {
+		return enc, nil // empty object, nothing to merge
+	}
+	merged := make([]byte, 0, len(enc)+len(extra))
+	merged = append(merged, enc[:len(enc)-1]...)
+	merged = append(merged, ',')
+	return append(merged, extra...), nil
+}
+
+// ExtraJSONFields returns the JSON encoded fields of the header's extra payload
+// keyed by their JSON names, or nil if the header carries no extras. It allows
+// RPC marshallers building header objects field by field to include them.
+func (h *Header) ExtraJSONFields() (map[string]json.RawMessage, error) {
+	if !h.hasExtras() {
+		return nil, nil
+	}
+	enc, err := json.Marshal(h.extraPayload())
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(enc, &fields); err != nil {
+		return nil, fmt.Errorf("types: header extras of type %T not encoded as a JSON object", h.extra)
+	}
+	return fields, nil
+}
+
+// unmarshalHeaderExtraJSON decodes the extra payload, if any, of a header from
+// its JSON object. The canonical fields must already be decoded.
+func unmarshalHeaderExtraJSON(input []byte, h *Header) error {
+	if h.extra = nil; !h.hasExtras() {
+		return nil
+	}
+	extra := newHeaderExtra()
+	if err := json.Unmarshal(input, extra); err != nil {
+		return err
+	}
+	h.extra = extra
+	return nil
+}
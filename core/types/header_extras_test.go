@@ -0,0 +1,306 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/rlp"
+)
+
+// testHeaderExtra is a header payload modelled after coreth's extra fields.
+type testHeaderExtra struct {
+	ExtDataHash  common.Hash `json:"extDataHash"`
+	BlockGasCost *big.Int    `json:"blockGasCost"`
+}
+
+// Copy implements PayloadCopier.
+func (e *testHeaderExtra) Copy() interface{} {
+	cpy := *e
+	if e.BlockGasCost != nil {
+		cpy.BlockGasCost = new(big.Int).Set(e.BlockGasCost)
+	}
+	return &cpy
+}
+
+// unregisterHeaderExtras removes the registered header payload, if any.
+func unregisterHeaderExtras() {
+	headerExtraType, headerExtraOptOut, headerExtraActive = nil, false, nil
+}
+
+func newTestHeader() *Header {
+	return &Header{
+		ParentHash: common.Hash{1},
+		Difficulty: big.NewInt(2),
+		Number:     big.NewInt(3),
+		GasLimit:   4,
+		Time:       5,
+		Extra:      []byte{6},
+	}
+}
+
+func TestHeaderExtrasRLP(t *testing.T) {
+	plain, err := rlp.EncodeToBytes(newTestHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterHeaderExtras(&testHeaderExtra{})
+	defer unregisterHeaderExtras()
+
+	header := newTestHeader()
+	header.SetExtraPayload(&testHeaderExtra{ExtDataHash: common.Hash{7}, BlockGasCost: big.NewInt(8)})
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The payload fields must be appended to the canonical ones
+	var fields []rlp.RawValue
+	if err := rlp.DecodeBytes(enc, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != headerFieldCount+2 {
+		t.Fatalf("field count mismatch: have %d, want %d", len(fields), headerFieldCount+2)
+	}
+	var canonical []rlp.RawValue
+	if err := rlp.DecodeBytes(plain, &canonical); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fields[:headerFieldCount], canonical) {
+		t.Errorf("canonical fields mismatch")
+	}
+	dec := new(Header)
+	if err := rlp.DecodeBytes(enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec, header) {
+		t.Errorf("decoded header mismatch: have %+v, want %+v", dec, header)
+	}
+	// Headers without a payload are encoded with an empty one
+	if enc, err = rlp.EncodeToBytes(newTestHeader()); err != nil {
+		t.Fatal(err)
+	}
+	if err := rlp.DecodeBytes(enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	if extra := dec.ExtraPayload().(*testHeaderExtra); extra.ExtDataHash != (common.Hash{}) || extra.BlockGasCost.Sign() != 0 {
+		t.Errorf("empty payload mismatch: have %+v", extra)
+	}
+	// Headers lacking the payload fields must be rejected
+	if err := rlp.DecodeBytes(plain, dec); err == nil {
+		t.Errorf("header without payload fields decoded")
+	}
+}
+
+func TestHeaderExtrasJSON(t *testing.T) {
+	RegisterHeaderExtras(&testHeaderExtra{})
+	defer unregisterHeaderExtras()
+
+	header := newTestHeader()
+	header.SetExtraPayload(&testHeaderExtra{ExtDataHash: common.Hash{7}, BlockGasCost: big.NewInt(8)})
+	enc, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(enc, &fields); err != nil {
+		t.Fatalf("invalid JSON %s: %v", enc, err)
+	}
+	for _, key := range []string{"parentHash", "hash", "extDataHash", "blockGasCost"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("field %q missing from %s", key, enc)
+		}
+	}
+	dec := new(Header)
+	if err := json.Unmarshal(enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec, header) {
+		t.Errorf("decoded header mismatch: have %+v, want %+v", dec, header)
+	}
+	extras, err := header.ExtraJSONFields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(extras) != 2 || string(extras["blockGasCost"]) != "8" {
+		t.Errorf("extra JSON fields mismatch: have %s", extras)
+	}
+}
+
+func TestRegisterHeaderExtrasPanics(t *testing.T) {
+	defer unregisterHeaderExtras()
+
+	for _, payload := range []interface{}{nil, testHeaderExtra{}, new(int)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("payload %T registered", payload)
+				}
+			}()
+			RegisterHeaderExtras(payload)
+		}()
+	}
+	RegisterHeaderExtras(&testHeaderExtra{})
+	defer func() {
+		if recover() == nil {
+			t.Errorf("header extras registered twice")
+		}
+	}()
+	RegisterHeaderExtras(&testHeaderExtra{})
+}
+
+// annotatedHeaderExtra is a header payload with a non-consensus annotation.
+type annotatedHeaderExtra struct {
+	ExtDataHash common.Hash
+	Annotation  uint64 `hash:"-"`
+}
+
+func TestHeaderExtrasHash(t *testing.T) {
+	plain := newTestHeader().Hash()
+
+	RegisterHeaderExtras(&annotatedHeaderExtra{})
+	defer unregisterHeaderExtras()
+
+	header := newTestHeader()
+	if header.Hash() == plain {
+		t.Errorf("hash not affected by empty payload fields")
+	}
+	hashes := make(map[common.Hash]bool)
+	for _, extra := range []*annotatedHeaderExtra{
+		{},
+		{ExtDataHash: common.Hash{1}},
+		{ExtDataHash: common.Hash{2}},
+	} {
+		header.SetExtraPayload(extra)
+		hashes[header.Hash()] = true
+	}
+	if len(hashes) != 3 {
+		t.Errorf("hash not affected by payload fields: %d distinct hashes, want 3", len(hashes))
+	}
+	header.SetExtraPayload(&annotatedHeaderExtra{ExtDataHash: common.Hash{1}, Annotation: 1})
+	annotated := header.Hash()
+	header.SetExtraPayload(&annotatedHeaderExtra{ExtDataHash: common.Hash{1}, Annotation: 2})
+	if header.Hash() != annotated {
+		t.Errorf("hash affected by opted out field")
+	}
+	if fields := header.ExtraHashFields(); len(fields) != 1 || fields[0] != (common.Hash{1}) {
+		t.Errorf("hashed fields mismatch: have %v", fields)
+	}
+	// The annotation must still round-trip through RLP
+	dec := new(Header)
+	if err := rlp.DecodeBytes(mustEncode(t, header), dec); err != nil {
+		t.Fatal(err)
+	}
+	if extra := dec.ExtraPayload().(*annotatedHeaderExtra); extra.Annotation != 2 {
+		t.Errorf("annotation mismatch: have %d, want 2", extra.Annotation)
+	}
+	if dec.Hash() != annotated {
+		t.Errorf("decoded header hash mismatch")
+	}
+}
+
+func TestHeaderExtrasCopy(t *testing.T) {
+	RegisterHeaderExtras(&testHeaderExtra{})
+	defer unregisterHeaderExtras()
+
+	header := newTestHeader()
+	size := header.Size()
+	header.SetExtraPayload(&testHeaderExtra{ExtDataHash: common.Hash{7}, BlockGasCost: big.NewInt(8)})
+	if header.Size() <= size {
+		t.Errorf("header size not affected by payload: have %v, want more than %v", header.Size(), size)
+	}
+	cpy := CopyHeader(header)
+	if !reflect.DeepEqual(cpy, header) {
+		t.Fatalf("header copy mismatch: have %+v, want %+v", cpy, header)
+	}
+	cpy.ExtraPayload().(*testHeaderExtra).BlockGasCost.SetUint64(9)
+	if cost := header.ExtraPayload().(*testHeaderExtra).BlockGasCost; cost.Uint64() != 8 {
+		t.Errorf("original payload modified through copy: have %v, want 8", cost)
+	}
+	// Payloads without a Copy method are copied field by field
+	unregisterHeaderExtras()
+	RegisterHeaderExtras(&annotatedHeaderExtra{})
+
+	header.SetExtraPayload(&annotatedHeaderExtra{ExtDataHash: common.Hash{1}})
+	cpy = CopyHeader(header)
+	cpy.ExtraPayload().(*annotatedHeaderExtra).ExtDataHash = common.Hash{2}
+	if hash := header.ExtraPayload().(*annotatedHeaderExtra).ExtDataHash; hash != (common.Hash{1}) {
+		t.Errorf("original payload modified through copy: have %x", hash)
+	}
+}
+
+func TestHeaderExtrasActivation(t *testing.T) {
+	var (
+		before = newTestHeader()
+		after  = newTestHeader()
+	)
+	after.Number = big.NewInt(10)
+	plain := mustEncode(t, before)
+	plainHash := before.Hash()
+
+	RegisterHeaderExtras(&testHeaderExtra{})
+	defer unregisterHeaderExtras()
+	RegisterHeaderExtrasActivation(func(h *Header) bool { return h.Number.Uint64() >= 10 })
+
+	// Headers before the activation are encoded and hashed without extras
+	before.SetExtraPayload(&testHeaderExtra{ExtDataHash: common.Hash{7}})
+	if enc := mustEncode(t, before); !reflect.DeepEqual(enc, plain) {
+		t.Errorf("inactive header encoding mismatch: have %x, want %x", enc, plain)
+	}
+	if before.Hash() != plainHash || before.ExtraHashFields() != nil {
+		t.Errorf("inactive header hash affected by payload")
+	}
+	dec := new(Header)
+	if err := rlp.DecodeBytes(plain, dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.ExtraPayload() != nil {
+		t.Errorf("inactive header decoded with payload %+v", dec.ExtraPayload())
+	}
+	if enc, err := json.Marshal(before); err != nil {
+		t.Fatal(err)
+	} else if err := json.Unmarshal(enc, dec); err != nil {
+		t.Fatal(err)
+	} else if dec.ExtraPayload() != nil || dec.Hash() != plainHash {
+		t.Errorf("inactive header JSON round trip mismatch")
+	}
+	// Headers after the activation require the extras
+	after.SetExtraPayload(&testHeaderExtra{ExtDataHash: common.Hash{7}, BlockGasCost: big.NewInt(8)})
+	enc := mustEncode(t, after)
+	if err := rlp.DecodeBytes(enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec, after) {
+		t.Errorf("active header mismatch: have %+v, want %+v", dec, after)
+	}
+	after.extra = nil
+	if err := rlp.DecodeBytes(mustEncode(t, (*rlpHeader)(after)), dec); err == nil {
+		t.Errorf("active header without payload fields decoded")
+	}
+	// Inactive headers with trailing fields must be rejected
+	active := headerExtraActive
+	headerExtraActive = nil
+	enc = mustEncode(t, before)
+	headerExtraActive = active
+
+	if err := rlp.DecodeBytes(enc, dec); err == nil {
+		t.Errorf("inactive header with payload fields decoded")
+	}
+}
@@ -53,6 +53,8 @@ type Log struct {
 	// The Removed field is true if this log was reverted due to a chain reorganisation.
 	// You must pay attention to this field if you receive logs through a filter query.
 	Removed bool `json:"removed"`
+
+	extra interface{} // Chain specific payload registered with RegisterLogExtras, if any
 }
 
 type logMarshaling struct {
@@ -102,16 +104,22 @@ func (l *Log) DecodeRLP(s *rlp.Stream) error {
 // a log including non-consensus fields.
 type LogForStorage Log
 
-// EncodeRLP implements rlp.Encoder.
+// EncodeRLP implements rlp.Encoder, appending the fields of the registered extra
+// payload, if the log carries one.
 func (l *LogForStorage) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, rlpStorageLog{
+	enc := rlpStorageLog{
 		Address: l.Address,
 		Topics:  l.Topics,
 		Data:    l.Data,
-	})
+	}
+	if l.extra == nil {
+		return rlp.Encode(w, enc)
+	}
+	return encodeRLPWithExtra(w, enc, l.extra)
 }
 
-// DecodeRLP implements rlp.Decoder.
+// DecodeRLP implements rlp.Decoder, decoding the fields following the storage
+// encoding into the registered extra payload, if any.
 //
 // Note some redundant fields(e.g. block number, tx hash etc) will be assembled later.
 func (l *LogForStorage) DecodeRLP(s *rlp.Stream) error {
@@ -119,6 +127,12 @@ func (l *LogForStorage) DecodeRLP(s *rlp.Stream) error {
 	if err != nil {
 		return err
 	}
+	if logExtraType != nil {
+		if dec, err := decodeStorageLogWithExtra(blob); err == nil {
+			*l = *dec
+			return nil
+		}
+	}
 	var dec rlpStorageLog
 	err = rlp.DecodeBytes(blob, &dec)
 	if err == nil {
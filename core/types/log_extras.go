@@ -0,0 +1,124 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/ava-labs/go-ethereum/rlp"
+)
+
+// logExtraType is the type of the payload registered with RegisterLogExtras, if
+// any.
+var logExtraType reflect.Type
+
+// RegisterLogExtras registers the chain specific metadata carried by logs, e.g.
+// an identifier of the precompile configuration emitting them. The payload is
+// given as a pointer to a struct, whose exported fields are RLP encoded after
+// the fields of the storage encoding of logs and JSON encoded alongside the
+// log fields; the JSON keys must not collide with the log's. The payload isn't
+// part of the consensus encoding of logs and thus not covered by the receipt
+// root.
+//
+// It is not safe for concurrent use and must be called during initialisation,
+// before any log is encoded or decoded. It panics if the payload isn't a
+// pointer to a struct or if extras are already registered.
+func RegisterLogExtras(payload interface{}) {
+	if logExtraType != nil {
+		panic("types: log extras already registered")
+	}
+	typ := reflect.TypeOf(payload)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("types: log extras must be a pointer to a struct, got %T", payload))
+	}
+	logExtraType = typ
+}
+
+// newLogExtra creates an empty instance of the registered log payload.
+func newLogExtra() interface{} {
+	return reflect.New(logExtraType.Elem()).Interface()
+}
+
+// ExtraPayload returns the payload registered with RegisterLogExtras carried by
+// the log, or nil if there is none.
+func (l *Log) ExtraPayload() interface{} {
+	return l.extra
+}
+
+// SetExtraPayload sets the payload carried by the log, which must be nil or of
+// the type registered with RegisterLogExtras. It panics otherwise.
+func (l *Log) SetExtraPayload(payload interface{}) {
+	if payload != nil && (logExtraType == nil || reflect.TypeOf(payload) != logExtraType) {
+		panic(fmt.Sprintf("types: log extras of type %T not registered", payload))
+	}
+	l.extra = payload
+}
+
+// logStorageFieldCount is the number of fields in the storage encoding of logs
+// preceding the extra payload.
+const logStorageFieldCount = 3
+
+// decodeStorageLogWithExtra decodes the storage encoding of a log followed by
+// the fields of the registered extra payload.
+func decodeStorageLogWithExtra(blob []byte) (*LogForStorage, error) {
+	var fields []rlp.RawValue
+	if err := rlp.DecodeBytes(blob, &fields); err != nil {
+		return nil, err
+	}
+	if len(fields) <= logStorageFieldCount {
+		return nil, fmt.Errorf("rlp: no extra fields for %T", new(LogForStorage))
+	}
+	var dec rlpStorageLog
+	if err := rlp.DecodeListElements(fields[:logStorageFieldCount], &dec); err != nil {
+		return nil, err
+	}
+	extra := newLogExtra()
+	if err := rlp.DecodeListElements(fields[logStorageFieldCount:], extra); err != nil {
+		return nil, err
+	}
+	return &LogForStorage{Address: dec.Address, Topics: dec.Topics, Data: dec.Data, extra: extra}, nil
+}
+
+// marshalLogJSON encodes the JSON object of a log's fields, merged with the
+// fields of its extra payload, if any.
+func marshalLogJSON(fields interface{}, l *Log) ([]byte, error) {
+	enc, err := json.Marshal(fields)
+	if err != nil || logExtraType == nil {
+		return enc, err
+	}
+	extra := l.extra
+	if extra == nil {
+		extra = newLogExtra()
+	}
+	return mergeJSONObject(enc, extra)
+}
+
+// unmarshalLogExtraJSON decodes the extra payload, if any, of a log from its
+// JSON object.
+func unmarshalLogExtraJSON(input []byte, l *Log) error {
+	if logExtraType == nil {
+		return nil
+	}
+	extra := newLogExtra()
+	if err := json.Unmarshal(input, extra); err != nil {
+		return err
+	}
+	l.extra = extra
+	return nil
+}
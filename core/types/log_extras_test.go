@@ -0,0 +1,117 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/rlp"
+)
+
+// testLogExtra is a log payload identifying the precompile config emitting it.
+type testLogExtra struct {
+	ConfigID uint64 `json:"configId"`
+}
+
+// unregisterLogExtras removes the registered log payload, if any.
+func unregisterLogExtras() {
+	logExtraType = nil
+}
+
+func TestLogExtrasStorage(t *testing.T) {
+	var (
+		plain   = &Log{Address: common.Address{1}, Topics: []common.Hash{{2}}, Data: []byte{3}}
+		plainRc = &Receipt{Status: ReceiptStatusSuccessful, Logs: []*Log{plain}}
+	)
+	legacy := mustEncode(t, (*ReceiptForStorage)(plainRc))
+
+	RegisterLogExtras(&testLogExtra{})
+	defer unregisterLogExtras()
+
+	log := &Log{Address: common.Address{1}, Topics: []common.Hash{{2}}, Data: []byte{3}}
+	log.SetExtraPayload(&testLogExtra{ConfigID: 4})
+	receipt := &Receipt{Status: ReceiptStatusSuccessful, Logs: []*Log{log}, Bloom: plainRc.Bloom}
+
+	// The payload must not affect the consensus encoding
+	if !bytes.Equal(mustEncode(t, log), mustEncode(t, plain)) {
+		t.Errorf("consensus encoding affected by payload")
+	}
+	dec := new(ReceiptForStorage)
+	if err := rlp.DecodeBytes(mustEncode(t, (*ReceiptForStorage)(receipt)), dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec.Logs[0], log) {
+		t.Errorf("stored log mismatch: have %+v, want %+v", dec.Logs[0], log)
+	}
+	// Logs stored without a payload must still decode
+	if err := rlp.DecodeBytes(legacy, dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Logs[0].ExtraPayload() != nil || !reflect.DeepEqual(dec.Logs[0], plain) {
+		t.Errorf("payload-less log mismatch: have %+v, want %+v", dec.Logs[0], plain)
+	}
+}
+
+func TestLogExtrasJSON(t *testing.T) {
+	RegisterLogExtras(&testLogExtra{})
+	defer unregisterLogExtras()
+
+	log := &Log{Address: common.Address{1}, Topics: []common.Hash{{2}}, Data: []byte{3}}
+	log.SetExtraPayload(&testLogExtra{ConfigID: 4})
+	enc, err := json.Marshal(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(enc, &fields); err != nil {
+		t.Fatalf("invalid JSON %s: %v", enc, err)
+	}
+	if id, ok := fields["configId"]; !ok || id != float64(4) {
+		t.Errorf("payload field mismatch in %s", enc)
+	}
+	dec := new(Log)
+	if err := json.Unmarshal(enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec, log) {
+		t.Errorf("decoded log mismatch: have %+v, want %+v", dec, log)
+	}
+}
+
+func TestRegisterLogExtrasPanics(t *testing.T) {
+	defer unregisterLogExtras()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("unregistered payload accepted")
+			}
+		}()
+		new(Log).SetExtraPayload(&testLogExtra{})
+	}()
+	RegisterLogExtras(&testLogExtra{})
+	defer func() {
+		if recover() == nil {
+			t.Errorf("log extras registered twice")
+		}
+	}()
+	RegisterLogExtras(&testLogExtra{})
+}
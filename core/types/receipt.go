@@ -26,7 +26,6 @@ import (
 
 	"github.com/ava-labs/go-ethereum/common"
 	"github.com/ava-labs/go-ethereum/common/hexutil"
-	"github.com/ava-labs/go-ethereum/crypto"
 	"github.com/ava-labs/go-ethereum/params"
 	"github.com/ava-labs/go-ethereum/rlp"
 )
@@ -66,6 +65,8 @@ type Receipt struct {
 	BlockHash        common.Hash `json:"blockHash,omitempty"`
 	BlockNumber      *big.Int    `json:"blockNumber,omitempty"`
 	TransactionIndex uint        `json:"transactionIndex"`
+
+	extra interface{} // Chain specific payload registered with RegisterReceiptExtras, if any
 }
 
 type receiptMarshaling struct {
@@ -125,22 +126,37 @@ func NewReceipt(root []byte, failed bool, cumulativeGasUsed uint64) *Receipt {
 }
 
 // EncodeRLP implements rlp.Encoder, and flattens the consensus fields of a receipt
-// into an RLP stream. If no post state is present, byzantium fork is assumed.
+// into an RLP stream, followed by the fields of the registered extra payload, if
+// any. If no post state is present, byzantium fork is assumed.
 func (r *Receipt) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, &receiptRLP{r.statusEncoding(), r.CumulativeGasUsed, r.Bloom, r.Logs})
+	enc := &receiptRLP{r.statusEncoding(), r.CumulativeGasUsed, r.Bloom, r.Logs}
+	if receiptExtraType == nil {
+		return rlp.Encode(w, enc)
+	}
+	return encodeRLPWithExtra(w, enc, r.extraPayload())
 }
 
 // DecodeRLP implements rlp.Decoder, and loads the consensus fields of a receipt
-// from an RLP stream.
+// from an RLP stream, as well as the registered extra payload, if any.
 func (r *Receipt) DecodeRLP(s *rlp.Stream) error {
-	var dec receiptRLP
-	if err := s.Decode(&dec); err != nil {
+	var (
+		dec   receiptRLP
+		extra interface{}
+		err   error
+	)
+	if receiptExtraType == nil {
+		err = s.Decode(&dec)
+	} else {
+		extra = newReceiptExtra()
+		err = decodeRLPWithExtra(s, &dec, receiptFieldCount, extra)
+	}
+	if err != nil {
 		return err
 	}
 	if err := r.setStatus(dec.PostStateOrStatus); err != nil {
 		return err
 	}
-	r.CumulativeGasUsed, r.Bloom, r.Logs = dec.CumulativeGasUsed, dec.Bloom, dec.Logs
+	r.CumulativeGasUsed, r.Bloom, r.Logs, r.extra = dec.CumulativeGasUsed, dec.Bloom, dec.Logs, extra
 	return nil
 }
 
@@ -177,7 +193,7 @@ func (r *Receipt) Size() common.StorageSize {
 	for _, log := range r.Logs {
 		size += common.StorageSize(len(log.Topics)*common.HashLength + len(log.Data))
 	}
-	return size
+	return size + extraPayloadSize(r.extra)
 }
 
 // ReceiptForStorage is a wrapper around a Receipt that flattens and parses the
@@ -185,7 +201,8 @@ func (r *Receipt) Size() common.StorageSize {
 type ReceiptForStorage Receipt
 
 // EncodeRLP implements rlp.Encoder, and flattens all content fields of a receipt
-// into an RLP stream.
+// into an RLP stream, followed by the fields of the registered extra payload, if
+// any.
 func (r *ReceiptForStorage) EncodeRLP(w io.Writer) error {
 	enc := &storedReceiptRLP{
 		PostStateOrStatus: (*Receipt)(r).statusEncoding(),
@@ -195,7 +212,10 @@ func (r *ReceiptForStorage) EncodeRLP(w io.Writer) error {
 	for i, log := range r.Logs {
 		enc.Logs[i] = (*LogForStorage)(log)
 	}
-	return rlp.Encode(w, enc)
+	if receiptExtraType == nil {
+		return rlp.Encode(w, enc)
+	}
+	return encodeRLPWithExtra(w, enc, (*Receipt)(r).extraPayload())
 }
 
 // DecodeRLP implements rlp.Decoder, and loads both consensus and implementation
@@ -208,7 +228,14 @@ func (r *ReceiptForStorage) DecodeRLP(s *rlp.Stream) error {
 	}
 	// Try decoding from the newest format for future proofness, then the older one
 	// for old nodes that just upgraded. V4 was an intermediate unreleased format so
-	// we do need to decode it, but it's not common (try last).
+	// we do need to decode it, but it's not common (try last). Receipts stored
+	// before extras were registered carry no payload.
+	if receiptExtraType != nil {
+		if err := decodeStoredReceiptRLPWithExtra(r, blob); err == nil {
+			return nil
+		}
+	}
+	r.extra = nil
 	if err := decodeStoredReceiptRLP(r, blob); err == nil {
 		return nil
 	}
@@ -314,7 +341,7 @@ func (r Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, num
 		if txs[i].To() == nil {
 			// Deriving the signer is expensive, only do if it's actually needed
 			from, _ := Sender(signer, txs[i])
-			r[i].ContractAddress = crypto.CreateAddress(from, txs[i].Nonce())
+			r[i].ContractAddress = CreateAddress(from, txs[i].Nonce())
 		}
 		// The used gas can be calculated based on previous r
 		if i == 0 {
@@ -331,6 +358,13 @@ func (r Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, num
 			r[i].Logs[j].Index = logIndex
 			logIndex++
 		}
+		// The derived fields of the extra payload are left to its deriver
+		if err := r[i].deriveExtraFields(config, txs[i]); err != nil {
+			return err
+		}
+	}
+	if receiptsDeriveHook != nil {
+		return receiptsDeriveHook(config, hash, number, txs, r)
 	}
 	return nil
 }
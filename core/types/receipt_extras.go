@@ -0,0 +1,179 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/params"
+	"github.com/ava-labs/go-ethereum/rlp"
+)
+
+// receiptExtraType is the type of the payload registered with
+// RegisterReceiptExtras, if any.
+var receiptExtraType reflect.Type
+
+// RegisterReceiptExtras registers the chain specific payload carried by every
+// receipt, e.g. fees paid to validators. The payload is given as a pointer to a
+// struct, whose exported fields are RLP encoded after the consensus fields of
+// receipts, and thus covered by the receipt root, as well as after the fields of
+// their storage encoding. They are JSON encoded alongside the receipt fields;
+// the JSON keys must not collide with the receipt's.
+//
+// Fields derived from the block and transaction rather than stored should be
+// tagged with `rlp:"-"` and filled by implementing ReceiptExtraDeriver.
+//
+// It is not safe for concurrent use and must be called during initialisation,
+// before any receipt is encoded or decoded. It panics if the payload isn't a
+// pointer to a struct or if extras are already registered.
+func RegisterReceiptExtras(payload interface{}) {
+	if receiptExtraType != nil {
+		panic("types: receipt extras already registered")
+	}
+	typ := reflect.TypeOf(payload)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("types: receipt extras must be a pointer to a struct, got %T", payload))
+	}
+	receiptExtraType = typ
+}
+
+// ReceiptExtraDeriver is implemented by extra receipt payloads with fields that
+// aren't stored but derived like the implementation fields of receipts.
+type ReceiptExtraDeriver interface {
+	// DeriveFields fills the derived fields of the payload of the receipt of
+	// the given transaction, after the receipt's own fields are derived.
+	DeriveFields(config *params.ChainConfig, receipt *Receipt, tx *Transaction) error
+}
+
+// ReceiptsDeriveHook post-processes the receipts of a block once their fields
+// are derived, e.g. to fill registered receipt extras with data the block's
+// transactions produced through precompiles. It is given the block context
+// passed to Receipts.DeriveFields.
+type ReceiptsDeriveHook func(config *params.ChainConfig, hash common.Hash, number uint64, txs Transactions, receipts Receipts) error
+
+// receiptsDeriveHook is the hook registered with RegisterReceiptsDeriveHook, if
+// any.
+var receiptsDeriveHook ReceiptsDeriveHook
+
+// RegisterReceiptsDeriveHook registers a hook run by Receipts.DeriveFields after
+// the fields of all receipts, extra payloads included, are derived. Receipts
+// read from the database and served over RPC are derived this way.
+//
+// RegisterReceiptsDeriveHook is not safe for concurrent use and must be called
+// during initialisation. It panics if called more than once.
+func RegisterReceiptsDeriveHook(hook ReceiptsDeriveHook) {
+	if receiptsDeriveHook != nil {
+		panic("types: receipts derive hook already registered")
+	}
+	receiptsDeriveHook = hook
+}
+
+// newReceiptExtra creates an empty instance of the registered receipt payload.
+func newReceiptExtra() interface{} {
+	return reflect.New(receiptExtraType.Elem()).Interface()
+}
+
+// ExtraPayload returns the payload registered with RegisterReceiptExtras
+// carried by the receipt, or nil if there is none.
+func (r *Receipt) ExtraPayload() interface{} {
+	return r.extra
+}
+
+// SetExtraPayload sets the payload carried by the receipt, which must be of the
+// type registered with RegisterReceiptExtras. It panics otherwise.
+func (r *Receipt) SetExtraPayload(payload interface{}) {
+	if receiptExtraType == nil || reflect.TypeOf(payload) != receiptExtraType {
+		panic(fmt.Sprintf("types: receipt extras of type %T not registered", payload))
+	}
+	r.extra = payload
+}
+
+// extraPayload returns the payload carried by the receipt, or an empty one if
+// it has none.
+func (r *Receipt) extraPayload() interface{} {
+	if r.extra == nil {
+		return newReceiptExtra()
+	}
+	return r.extra
+}
+
+// Number of fields in the consensus and storage encodings of receipts preceding
+// the extra payload.
+const (
+	receiptFieldCount       = 4
+	storedReceiptFieldCount = 3
+)
+
+// decodeStoredReceiptRLPWithExtra decodes the storage encoding of a receipt
+// followed by the fields of the registered extra payload.
+func decodeStoredReceiptRLPWithExtra(r *ReceiptForStorage, blob []byte) error {
+	var fields []rlp.RawValue
+	if err := rlp.DecodeBytes(blob, &fields); err != nil {
+		return err
+	}
+	if len(fields) < storedReceiptFieldCount {
+		return fmt.Errorf("rlp: too few fields for %T: have %d, want at least %d", r, len(fields), storedReceiptFieldCount)
+	}
+	extra := newReceiptExtra()
+	if err := rlp.DecodeListElements(fields[storedReceiptFieldCount:], extra); err != nil {
+		return err
+	}
+	enc, err := rlp.EncodeToBytes(fields[:storedReceiptFieldCount])
+	if err != nil {
+		return err
+	}
+	if err := decodeStoredReceiptRLP(r, enc); err != nil {
+		return err
+	}
+	r.extra = extra
+	return nil
+}
+
+// deriveExtraFields fills the derived fields of the receipt's extra payload.
+func (r *Receipt) deriveExtraFields(config *params.ChainConfig, tx *Transaction) error {
+	if deriver, ok := r.extra.(ReceiptExtraDeriver); ok {
+		return deriver.DeriveFields(config, r, tx)
+	}
+	return nil
+}
+
+// marshalReceiptJSON encodes the JSON object of a receipt's fields, merged with
+// the fields of its extra payload, if any.
+func marshalReceiptJSON(fields interface{}, r *Receipt) ([]byte, error) {
+	enc, err := json.Marshal(fields)
+	if err != nil || receiptExtraType == nil {
+		return enc, err
+	}
+	return mergeJSONObject(enc, r.extraPayload())
+}
+
+// unmarshalReceiptExtraJSON decodes the extra payload, if any, of a receipt
+// from its JSON object.
+func unmarshalReceiptExtraJSON(input []byte, r *Receipt) error {
+	if receiptExtraType == nil {
+		return nil
+	}
+	extra := newReceiptExtra()
+	if err := json.Unmarshal(input, extra); err != nil {
+		return err
+	}
+	r.extra = extra
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/params"
+	"github.com/ava-labs/go-ethereum/rlp"
+)
+
+// testReceiptExtra is a receipt payload with a stored and a derived field.
+type testReceiptExtra struct {
+	ValidatorFee *big.Int `json:"validatorFee"`
+	FeePerGas    *big.Int `json:"feePerGas" rlp:"-"`
+}
+
+// DeriveFields implements ReceiptExtraDeriver.
+func (e *testReceiptExtra) DeriveFields(config *params.ChainConfig, receipt *Receipt, tx *Transaction) error {
+	e.FeePerGas = new(big.Int).Div(e.ValidatorFee, new(big.Int).SetUint64(receipt.GasUsed))
+	return nil
+}
+
+// unregisterReceiptExtras removes the registered receipt payload, if any.
+func unregisterReceiptExtras() {
+	receiptExtraType = nil
+}
+
+func newTestReceipt() *Receipt {
+	return &Receipt{
+		Status:            ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs:              []*Log{{Address: common.Address{1}, Topics: []common.Hash{{2}}, Data: []byte{3}}},
+	}
+}
+
+func TestReceiptExtrasRLP(t *testing.T) {
+	plain := newTestReceipt()
+	plainRoot := DeriveSha(Receipts{plain})
+	stored := mustEncode(t, (*ReceiptForStorage)(plain))
+
+	RegisterReceiptExtras(&testReceiptExtra{})
+	defer unregisterReceiptExtras()
+
+	receipt := newTestReceipt()
+	receipt.SetExtraPayload(&testReceiptExtra{ValidatorFee: big.NewInt(42000)})
+
+	// The payload must be covered by the receipt root
+	if DeriveSha(Receipts{receipt}) == plainRoot {
+		t.Errorf("receipt root not affected by payload")
+	}
+	dec := new(Receipt)
+	if err := rlp.DecodeBytes(mustEncode(t, receipt), dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec.ExtraPayload(), receipt.ExtraPayload()) {
+		t.Errorf("consensus payload mismatch: have %+v, want %+v", dec.ExtraPayload(), receipt.ExtraPayload())
+	}
+	// The payload must be stored and its derived fields filled
+	decStored := new(ReceiptForStorage)
+	if err := rlp.DecodeBytes(mustEncode(t, (*ReceiptForStorage)(receipt)), decStored); err != nil {
+		t.Fatal(err)
+	}
+	tx := NewTransaction(1, common.Address{4}, common.Big1, 21000, common.Big1, nil)
+	receipts := Receipts{(*Receipt)(decStored)}
+	if err := receipts.DeriveFields(params.TestChainConfig, common.Hash{5}, 6, Transactions{tx}); err != nil {
+		t.Fatal(err)
+	}
+	extra := receipts[0].ExtraPayload().(*testReceiptExtra)
+	if extra.ValidatorFee.Uint64() != 42000 || extra.FeePerGas == nil || extra.FeePerGas.Uint64() != 2 {
+		t.Errorf("stored payload mismatch: have %+v", extra)
+	}
+	if receipts[0].Bloom != CreateBloom(Receipts{receipt}) {
+		t.Errorf("stored receipt bloom mismatch")
+	}
+	// Receipts stored without a payload must still decode
+	if err := rlp.DecodeBytes(stored, decStored); err != nil {
+		t.Fatal(err)
+	}
+	if (*Receipt)(decStored).ExtraPayload() != nil || decStored.CumulativeGasUsed != 21000 || len(decStored.Logs) != 1 {
+		t.Errorf("payload-less receipt mismatch: have %+v", decStored)
+	}
+}
+
+func TestReceiptExtrasJSON(t *testing.T) {
+	RegisterReceiptExtras(&testReceiptExtra{})
+	defer unregisterReceiptExtras()
+
+	receipt := newTestReceipt()
+	receipt.SetExtraPayload(&testReceiptExtra{ValidatorFee: big.NewInt(42000), FeePerGas: big.NewInt(2)})
+	enc, err := json.Marshal(receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(enc, &fields); err != nil {
+		t.Fatalf("invalid JSON %s: %v", enc, err)
+	}
+	for _, key := range []string{"cumulativeGasUsed", "validatorFee", "feePerGas"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("field %q missing from %s", key, enc)
+		}
+	}
+	dec := new(Receipt)
+	if err := json.Unmarshal(enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec.ExtraPayload(), receipt.ExtraPayload()) {
+		t.Errorf("decoded payload mismatch: have %+v, want %+v", dec.ExtraPayload(), receipt.ExtraPayload())
+	}
+}
+
+func TestReceiptsDeriveHook(t *testing.T) {
+	RegisterReceiptExtras(&testReceiptExtra{})
+	defer unregisterReceiptExtras()
+	defer func() { receiptsDeriveHook = nil }()
+
+	// Fill the payloads from the block context
+	RegisterReceiptsDeriveHook(func(config *params.ChainConfig, hash common.Hash, number uint64, txs Transactions, receipts Receipts) error {
+		for i, receipt := range receipts {
+			if receipt.TxHash != txs[i].Hash() || receipt.BlockHash != hash {
+				return errors.New("hook run before receipt fields were derived")
+			}
+			extra := receipt.ExtraPayload().(*testReceiptExtra)
+			extra.ValidatorFee = new(big.Int).SetUint64(number)
+		}
+		return nil
+	})
+	receipt := newTestReceipt()
+	receipt.SetExtraPayload(&testReceiptExtra{ValidatorFee: big.NewInt(42000)})
+	tx := NewTransaction(1, common.Address{4}, common.Big1, 21000, common.Big1, nil)
+
+	receipts := Receipts{receipt}
+	if err := receipts.DeriveFields(params.TestChainConfig, common.Hash{5}, 6, Transactions{tx}); err != nil {
+		t.Fatal(err)
+	}
+	if fee := receipt.ExtraPayload().(*testReceiptExtra).ValidatorFee; fee.Uint64() != 6 {
+		t.Errorf("payload not filled by hook: have fee %v, want 6", fee)
+	}
+}
@@ -36,7 +36,8 @@ var (
 )
 
 type Transaction struct {
-	data txdata
+	data  txdata
+	inner TxData // Data of custom transaction types, nil for legacy transactions
 	// caches
 	hash atomic.Value
 	size atomic.Value
@@ -106,11 +107,20 @@ func newTransaction(nonce uint64, to *common.Address, amount *big.Int, gasLimit
 
 // ChainId returns which chain id this transaction was signed for (if at all)
 func (tx *Transaction) ChainId() *big.Int {
-	return deriveChainId(tx.data.V)
+	if tx.inner == nil {
+		return deriveChainId(tx.data.V)
+	}
+	if data, ok := tx.inner.(SignedTxData); ok {
+		return new(big.Int).Set(data.ChainID())
+	}
+	return new(big.Int)
 }
 
 // Protected returns whether the transaction is protected from replay protection.
 func (tx *Transaction) Protected() bool {
+	if tx.inner != nil {
+		return true
+	}
 	return isProtectedV(tx.data.V)
 }
 
@@ -123,24 +133,45 @@ func isProtectedV(V *big.Int) bool {
 	return true
 }
 
-// EncodeRLP implements rlp.Encoder
+// EncodeRLP implements rlp.Encoder, wrapping the canonical encoding of typed
+// transactions in an RLP string.
 func (tx *Transaction) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, &tx.data)
+	if tx.inner == nil {
+		return rlp.Encode(w, &tx.data)
+	}
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, enc)
 }
 
 // DecodeRLP implements rlp.Decoder
 func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
-	_, size, _ := s.Kind()
-	err := s.Decode(&tx.data)
+	kind, size, err := s.Kind()
+	switch {
+	case err != nil:
+		return err
+	case kind == rlp.List:
+		tx.inner = nil
+		err = s.Decode(&tx.data)
+	default:
+		var b []byte
+		if b, err = s.Bytes(); err == nil {
+			err = tx.decodeTyped(b)
+		}
+	}
 	if err == nil {
 		tx.size.Store(common.StorageSize(rlp.ListSize(size)))
 	}
-
 	return err
 }
 
 // MarshalJSON encodes the web3 RPC transaction format.
 func (tx *Transaction) MarshalJSON() ([]byte, error) {
+	if tx.inner != nil {
+		return tx.marshalTypedJSON()
+	}
 	hash := tx.Hash()
 	data := tx.data
 	data.Hash = &hash
@@ -149,6 +180,9 @@ func (tx *Transaction) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON decodes the web3 RPC transaction format.
 func (tx *Transaction) UnmarshalJSON(input []byte) error {
+	if typed, err := tx.unmarshalTypedJSON(input); typed || err != nil {
+		return err
+	}
 	var dec txdata
 	if err := dec.UnmarshalJSON(input); err != nil {
 		return err
@@ -172,30 +206,63 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 	return nil
 }
 
-func (tx *Transaction) Data() []byte       { return common.CopyBytes(tx.data.Payload) }
-func (tx *Transaction) Gas() uint64        { return tx.data.GasLimit }
-func (tx *Transaction) GasPrice() *big.Int { return new(big.Int).Set(tx.data.Price) }
-func (tx *Transaction) Value() *big.Int    { return new(big.Int).Set(tx.data.Amount) }
-func (tx *Transaction) Nonce() uint64      { return tx.data.AccountNonce }
+func (tx *Transaction) Data() []byte       { return common.CopyBytes(tx.txData().Data()) }
+func (tx *Transaction) Gas() uint64        { return tx.txData().Gas() }
+func (tx *Transaction) GasPrice() *big.Int { return new(big.Int).Set(tx.txData().GasPrice()) }
+func (tx *Transaction) Value() *big.Int    { return new(big.Int).Set(tx.txData().Value()) }
+func (tx *Transaction) Nonce() uint64      { return tx.txData().Nonce() }
 func (tx *Transaction) CheckNonce() bool   { return true }
 
+// EffectiveGasPriceFunc computes the price per gas effectively paid by a
+// transaction, e.g. to account for tips defined by a custom transaction type.
+// The price must depend on the transaction alone, as transaction pools keep
+// transactions ordered by it.
+type EffectiveGasPriceFunc func(tx *Transaction) *big.Int
+
+// effectiveGasPriceFn is the function registered with
+// RegisterEffectiveGasPrice, if any.
+var effectiveGasPriceFn EffectiveGasPriceFunc
+
+// RegisterEffectiveGasPrice registers the function computing the effective gas
+// price of transactions, which is charged when they are executed and used to
+// price and order them in transaction pools and blocks being mined.
+//
+// RegisterEffectiveGasPrice is not safe for concurrent use and must be called
+// during initialisation. It panics if called more than once.
+func RegisterEffectiveGasPrice(fn EffectiveGasPriceFunc) {
+	if effectiveGasPriceFn != nil {
+		panic("types: effective gas price function already registered")
+	}
+	effectiveGasPriceFn = fn
+}
+
+// EffectiveGasPrice returns the price per gas effectively paid by the
+// transaction, its gas price unless overridden by RegisterEffectiveGasPrice.
+func (tx *Transaction) EffectiveGasPrice() *big.Int {
+	if effectiveGasPriceFn == nil {
+		return tx.GasPrice()
+	}
+	return new(big.Int).Set(effectiveGasPriceFn(tx))
+}
+
 // To returns the recipient address of the transaction.
 // It returns nil if the transaction is a contract creation.
 func (tx *Transaction) To() *common.Address {
-	if tx.data.Recipient == nil {
-		return nil
-	}
-	to := *tx.data.Recipient
-	return &to
+	return copyAddressPtr(tx.txData().To())
 }
 
-// Hash hashes the RLP encoding of tx.
-// It uniquely identifies the transaction.
+// Hash hashes the canonical encoding of tx, the RLP encoding for legacy
+// transactions. It uniquely identifies the transaction.
 func (tx *Transaction) Hash() common.Hash {
 	if hash := tx.hash.Load(); hash != nil {
 		return hash.(common.Hash)
 	}
-	v := rlpHash(tx)
+	var v common.Hash
+	if tx.inner == nil {
+		v = rlpHash(tx)
+	} else {
+		v = tx.typedHash()
+	}
 	tx.hash.Store(v)
 	return v
 }
@@ -207,7 +274,7 @@ func (tx *Transaction) Size() common.StorageSize {
 		return size.(common.StorageSize)
 	}
 	c := writeCounter(0)
-	rlp.Encode(&c, &tx.data)
+	rlp.Encode(&c, tx)
 	tx.size.Store(common.StorageSize(c))
 	return common.StorageSize(c)
 }
@@ -218,14 +285,21 @@ func (tx *Transaction) Size() common.StorageSize {
 //
 // XXX Rename message to something less arbitrary?
 func (tx *Transaction) AsMessage(s Signer) (Message, error) {
+	data := tx.txData()
 	msg := Message{
-		nonce:      tx.data.AccountNonce,
-		gasLimit:   tx.data.GasLimit,
-		gasPrice:   new(big.Int).Set(tx.data.Price),
-		to:         tx.data.Recipient,
-		amount:     tx.data.Amount,
-		data:       tx.data.Payload,
+		nonce:      data.Nonce(),
+		gasLimit:   data.Gas(),
+		gasPrice:   tx.EffectiveGasPrice(),
+		to:         data.To(),
+		amount:     data.Value(),
+		data:       data.Data(),
 		checkNonce: true,
+		txType:     tx.Type(),
+		accessList: tx.AccessList(),
+	}
+	if sys, ok := data.(SystemTxData); ok && sys.IsSystemTx() {
+		msg.checkNonce = false
+		msg.isSystem = true
 	}
 
 	var err error
@@ -240,22 +314,29 @@ func (tx *Transaction) WithSignature(signer Signer, sig []byte) (*Transaction, e
 	if err != nil {
 		return nil, err
 	}
+	if tx.inner != nil {
+		inner := tx.inner.Copy()
+		inner.SetSignatureValues(v, r, s)
+		return &Transaction{inner: inner}, nil
+	}
 	cpy := &Transaction{data: tx.data}
 	cpy.data.R, cpy.data.S, cpy.data.V = r, s, v
 	return cpy, nil
 }
 
-// Cost returns amount + gasprice * gaslimit.
+// Cost returns amount + gasprice * gaslimit, charging the effective gas price
+// like execution does, see EffectiveGasPrice.
 func (tx *Transaction) Cost() *big.Int {
-	total := new(big.Int).Mul(tx.data.Price, new(big.Int).SetUint64(tx.data.GasLimit))
-	total.Add(total, tx.data.Amount)
+	data := tx.txData()
+	total := new(big.Int).Mul(tx.EffectiveGasPrice(), new(big.Int).SetUint64(data.Gas()))
+	total.Add(total, data.Value())
 	return total
 }
 
 // RawSignatureValues returns the V, R, S signature values of the transaction.
 // The return values should not be modified by the caller.
 func (tx *Transaction) RawSignatureValues() (v, r, s *big.Int) {
-	return tx.data.V, tx.data.R, tx.data.S
+	return tx.txData().RawSignatureValues()
 }
 
 // Transactions is a Transaction slice type for basic sorting.
@@ -269,7 +350,7 @@ func (s Transactions) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 
 // GetRlp implements Rlpable and returns the i'th element of s in rlp.
 func (s Transactions) GetRlp(i int) []byte {
-	enc, _ := rlp.EncodeToBytes(s[i])
+	enc, _ := s[i].MarshalBinary()
 	return enc
 }
 
@@ -297,16 +378,18 @@ func TxDifference(a, b Transactions) Transactions {
 type TxByNonce Transactions
 
 func (s TxByNonce) Len() int           { return len(s) }
-func (s TxByNonce) Less(i, j int) bool { return s[i].data.AccountNonce < s[j].data.AccountNonce }
+func (s TxByNonce) Less(i, j int) bool { return s[i].Nonce() < s[j].Nonce() }
 func (s TxByNonce) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 
 // TxByPrice implements both the sort and the heap interface, making it useful
 // for all at once sorting as well as individually adding and removing elements.
 type TxByPrice Transactions
 
-func (s TxByPrice) Len() int           { return len(s) }
-func (s TxByPrice) Less(i, j int) bool { return s[i].data.Price.Cmp(s[j].data.Price) > 0 }
-func (s TxByPrice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s TxByPrice) Len() int { return len(s) }
+func (s TxByPrice) Less(i, j int) bool {
+	return s[i].EffectiveGasPrice().Cmp(s[j].EffectiveGasPrice()) > 0
+}
+func (s TxByPrice) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 
 func (s *TxByPrice) Push(x interface{}) {
 	*s = append(*s, x.(*Transaction))
@@ -394,6 +477,9 @@ type Message struct {
 	gasPrice   *big.Int
 	data       []byte
 	checkNonce bool
+	txType     byte
+	accessList AccessList
+	isSystem   bool
 }
 
 func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, checkNonce bool) Message {
@@ -409,6 +495,20 @@ func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *b
 	}
 }
 
+// NewSystemMessage creates a system message, which is executed without checking
+// the nonce of the sender nor charging it for gas, see core.SystemMessageHook.
+func NewSystemMessage(from common.Address, to *common.Address, amount *big.Int, gasLimit uint64, data []byte) Message {
+	return Message{
+		from:     from,
+		to:       to,
+		amount:   amount,
+		gasLimit: gasLimit,
+		gasPrice: new(big.Int),
+		data:     data,
+		isSystem: true,
+	}
+}
+
 func (m Message) From() common.Address { return m.from }
 func (m Message) To() *common.Address  { return m.to }
 func (m Message) GasPrice() *big.Int   { return m.gasPrice }
@@ -417,3 +517,15 @@ func (m Message) Gas() uint64          { return m.gasLimit }
 func (m Message) Nonce() uint64        { return m.nonce }
 func (m Message) Data() []byte         { return m.data }
 func (m Message) CheckNonce() bool     { return m.checkNonce }
+
+// TxType returns the type of the transaction the message was created from,
+// LegacyTxType for messages not created from one.
+func (m Message) TxType() byte { return m.txType }
+
+// AccessList returns the access list of the transaction the message was created
+// from, nil if it has none.
+func (m Message) AccessList() AccessList { return m.accessList }
+
+// IsSystem reports whether the message is a system message, either created by
+// NewSystemMessage or from a system transaction, see SystemTxData.
+func (m Message) IsSystem() bool { return m.isSystem }
@@ -0,0 +1,95 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/crypto"
+)
+
+// SignedTxData is implemented by the data of custom transaction types that are
+// signed by their sender, defining their signing rules. Such transactions are
+// signed and recovered by the EIP155Signer of their chain, and thus by the
+// signers returned by MakeSigner once EIP-155 is active. Their V signature
+// value is the recovery id, 0 or 1, rather than an EIP-155 encoded one.
+//
+// Custom transactions whose data doesn't implement it, e.g. ones not signed by
+// an account, can't be signed and have no sender.
+type SignedTxData interface {
+	TxData
+
+	// ChainID returns the id of the chain the transaction is valid on.
+	ChainID() *big.Int
+
+	// SigHash returns the hash to be signed by the sender. It must commit to
+	// the chain id and type of the transaction, as well as all of its fields
+	// other than the signature values.
+	SigHash() common.Hash
+}
+
+// signedTxData returns the signing rules of a typed transaction.
+func signedTxData(tx *Transaction) (SignedTxData, error) {
+	data, ok := tx.inner.(SignedTxData)
+	if !ok {
+		return nil, ErrTxTypeNotSupported
+	}
+	return data, nil
+}
+
+// typedSender returns the sender of a typed transaction.
+func (s EIP155Signer) typedSender(tx *Transaction) (common.Address, error) {
+	data, err := signedTxData(tx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if data.ChainID().Cmp(s.chainId) != 0 {
+		return common.Address{}, ErrInvalidChainId
+	}
+	v, r, sv := data.RawSignatureValues()
+	return recoverPlain(data.SigHash(), r, sv, new(big.Int).Add(v, big.NewInt(27)), true)
+}
+
+// typedSignatureValues returns the signature values of a typed transaction.
+func (s EIP155Signer) typedSignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	data, err := signedTxData(tx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if data.ChainID().Cmp(s.chainId) != 0 {
+		return nil, nil, nil, ErrInvalidChainId
+	}
+	if len(sig) != crypto.SignatureLength {
+		panic(fmt.Sprintf("wrong size for signature: got %d, want %d", len(sig), crypto.SignatureLength))
+	}
+	R = new(big.Int).SetBytes(sig[:32])
+	S = new(big.Int).SetBytes(sig[32:64])
+	V = new(big.Int).SetBytes([]byte{sig[64]})
+	return R, S, V, nil
+}
+
+// typedHash returns the hash to be signed by the sender of a typed transaction,
+// or the zero hash if it has none.
+func (s EIP155Signer) typedHash(tx *Transaction) common.Hash {
+	data, err := signedTxData(tx)
+	if err != nil {
+		return common.Hash{}
+	}
+	return data.SigHash()
+}
@@ -172,6 +172,44 @@ func TestTransactionPriceNonceSort(t *testing.T) {
 	}
 }
 
+// Tests that transactions are priced and ordered by their effective gas price
+// if one is registered.
+func TestEffectiveGasPrice(t *testing.T) {
+	defer func() { effectiveGasPriceFn = nil }()
+
+	// Price transactions by their value, ignoring their gas price
+	RegisterEffectiveGasPrice(func(tx *Transaction) *big.Int {
+		return tx.Value()
+	})
+	var (
+		signer = HomesteadSigner{}
+		groups = make(map[common.Address]Transactions)
+	)
+	for i := 0; i < 5; i++ {
+		key, _ := crypto.GenerateKey()
+		tx, _ := SignTx(NewTransaction(0, common.Address{}, big.NewInt(int64(i)), 100, big.NewInt(int64(10-i)), nil), signer, key)
+		groups[crypto.PubkeyToAddress(key.PublicKey)] = Transactions{tx}
+	}
+	txset := NewTransactionsByPriceAndNonce(signer, groups)
+	for want := int64(4); want >= 0; want-- {
+		tx := txset.Peek()
+		if tx == nil {
+			t.Fatalf("missing transaction with effective price %d", want)
+		}
+		if price := tx.EffectiveGasPrice(); price.Int64() != want {
+			t.Errorf("ordering mismatch: have effective price %v, want %d", price, want)
+		}
+		msg, err := tx.AsMessage(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.GasPrice().Int64() != want {
+			t.Errorf("message price mismatch: have %v, want %d", msg.GasPrice(), want)
+		}
+		txset.Shift()
+	}
+}
+
 // TestTransactionJSON tests serializing/de-serializing to/from JSON.
 func TestTransactionJSON(t *testing.T) {
 	key, err := crypto.GenerateKey()
@@ -0,0 +1,304 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/rlp"
+	"golang.org/x/crypto/sha3"
+)
+
+// LegacyTxType is the type of the original, untyped transactions.
+const LegacyTxType = 0x00
+
+// maxTxType is the largest transaction type, leaving the bytes starting an RLP
+// list free to identify legacy transactions.
+const maxTxType = 0x7f
+
+var (
+	// ErrTxTypeNotSupported is returned when decoding or signing a transaction
+	// of a type that isn't registered.
+	ErrTxTypeNotSupported = errors.New("transaction type not supported")
+
+	errEmptyTypedTx = errors.New("empty typed transaction bytes")
+)
+
+// txTypes contains the data types of the transaction types registered with
+// RegisterTxType.
+var txTypes = make(map[byte]reflect.Type)
+
+// TxData is the underlying data of a transaction of a custom type, e.g. coreth's
+// atomic transactions. Its implementation must be a pointer to a struct, which
+// is RLP and JSON encoded as the payload of the transaction.
+type TxData interface {
+	// TxType returns the type byte identifying the transaction type.
+	TxType() byte
+
+	// Copy creates a deep copy of the data.
+	Copy() TxData
+
+	Nonce() uint64
+	GasPrice() *big.Int
+	Gas() uint64
+	To() *common.Address // nil means contract creation
+	Value() *big.Int
+	Data() []byte
+
+	// RawSignatureValues returns the signature values of the transaction, which
+	// must not be nil.
+	RawSignatureValues() (v, r, s *big.Int)
+
+	// SetSignatureValues sets the signature values of the transaction.
+	SetSignatureValues(v, r, s *big.Int)
+}
+
+// SystemTxData is the data of a custom transaction type whose transactions may
+// be system transactions, e.g. injected by block producers to update the fee
+// configuration. Transactions reporting IsSystemTx are converted to system
+// messages by AsMessage, see Message.IsSystem.
+type SystemTxData interface {
+	TxData
+	IsSystemTx() bool
+}
+
+// RegisterTxType registers a custom transaction type, identified by the type
+// byte of the given data. Transactions of the type are encoded following
+// EIP-2718: their canonical encoding, used by the transaction trie and hash, is
+// the type byte followed by the RLP encoding of the data, which is wrapped in
+// an RLP string within blocks and network messages.
+//
+// It is not safe for concurrent use and must be called during initialisation,
+// before any transaction is decoded. It panics if the data isn't a pointer to a
+// struct, if the type byte is out of range or already registered.
+func RegisterTxType(data TxData) {
+	typ := reflect.TypeOf(data)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("types: transaction data must be a pointer to a struct, got %T", data))
+	}
+	id := data.TxType()
+	if id == LegacyTxType || id > maxTxType {
+		panic(fmt.Sprintf("types: invalid transaction type %#x", id))
+	}
+	if _, ok := txTypes[id]; ok {
+		panic(fmt.Sprintf("types: transaction type %#x already registered", id))
+	}
+	txTypes[id] = typ
+}
+
+// newTxData creates empty data of the given registered transaction type.
+func newTxData(id byte) (TxData, error) {
+	typ, ok := txTypes[id]
+	if !ok {
+		return nil, ErrTxTypeNotSupported
+	}
+	return reflect.New(typ.Elem()).Interface().(TxData), nil
+}
+
+// NewTx creates a new transaction of a registered custom type, carrying a copy
+// of the given data. It panics if the type isn't registered.
+func NewTx(inner TxData) *Transaction {
+	if typ, ok := txTypes[inner.TxType()]; !ok || reflect.TypeOf(inner) != typ {
+		panic(fmt.Sprintf("types: transaction type %#x of %T not registered", inner.TxType(), inner))
+	}
+	return &Transaction{inner: inner.Copy()}
+}
+
+// Type returns the type of the transaction, LegacyTxType for untyped ones.
+func (tx *Transaction) Type() byte {
+	if tx.inner == nil {
+		return LegacyTxType
+	}
+	return tx.inner.TxType()
+}
+
+// txData returns the underlying data of the transaction.
+func (tx *Transaction) txData() TxData {
+	if tx.inner == nil {
+		return &tx.data
+	}
+	return tx.inner
+}
+
+// MarshalBinary returns the canonical encoding of the transaction: the RLP
+// encoding for legacy transactions, the type byte followed by the RLP encoding
+// of the data for typed ones.
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	if tx.inner == nil {
+		return rlp.EncodeToBytes(&tx.data)
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(tx.inner.TxType())
+	if err := rlp.Encode(&buf, tx.inner); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes the canonical encoding of a transaction.
+func (tx *Transaction) UnmarshalBinary(b []byte) error {
+	if len(b) > 0 && b[0] > maxTxType {
+		var data txdata
+		if err := rlp.DecodeBytes(b, &data); err != nil {
+			return err
+		}
+		*tx = Transaction{data: data}
+		tx.size.Store(common.StorageSize(len(b)))
+		return nil
+	}
+	return tx.decodeTyped(b)
+}
+
+// decodeTyped decodes the canonical encoding of a typed transaction.
+func (tx *Transaction) decodeTyped(b []byte) error {
+	if len(b) == 0 {
+		return errEmptyTypedTx
+	}
+	inner, err := newTxData(b[0])
+	if err != nil {
+		return err
+	}
+	if err := rlp.DecodeBytes(b[1:], inner); err != nil {
+		return err
+	}
+	*tx = Transaction{inner: inner}
+	return nil
+}
+
+// typedHash returns the hash of the canonical encoding of a typed transaction.
+func (tx *Transaction) typedHash() (h common.Hash) {
+	hw := sha3.NewLegacyKeccak256()
+	hw.Write([]byte{tx.inner.TxType()})
+	rlp.Encode(hw, tx.inner)
+	hw.Sum(h[:0])
+	return h
+}
+
+// marshalTypedJSON encodes the JSON object of a typed transaction, the fields
+// of its data merged with its type and hash.
+func (tx *Transaction) marshalTypedJSON() ([]byte, error) {
+	enc, err := json.Marshal(struct {
+		Type hexutil.Uint64 `json:"type"`
+		Hash common.Hash    `json:"hash"`
+	}{hexutil.Uint64(tx.inner.TxType()), tx.Hash()})
+	if err != nil {
+		return nil, err
+	}
+	return mergeJSONObject(enc, tx.inner)
+}
+
+// ExtraJSONFields returns the JSON encoded fields of the data of a typed
+// transaction keyed by their JSON names, or nil for legacy transactions. It
+// allows RPC marshallers building transaction objects field by field to include
+// the fields specific to custom types.
+func (tx *Transaction) ExtraJSONFields() (map[string]json.RawMessage, error) {
+	if tx.inner == nil {
+		return nil, nil
+	}
+	enc, err := json.Marshal(tx.inner)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(enc, &fields); err != nil {
+		return nil, fmt.Errorf("types: transaction data of type %T not encoded as a JSON object", tx.inner)
+	}
+	return fields, nil
+}
+
+// unmarshalTypedJSON decodes the JSON object of a transaction if it's typed,
+// reporting whether it is.
+func (tx *Transaction) unmarshalTypedJSON(input []byte) (bool, error) {
+	var dec struct {
+		Type *hexutil.Uint64 `json:"type"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return false, err
+	}
+	if dec.Type == nil || *dec.Type == LegacyTxType {
+		return false, nil
+	}
+	if *dec.Type > maxTxType {
+		return true, ErrTxTypeNotSupported
+	}
+	inner, err := newTxData(byte(*dec.Type))
+	if err != nil {
+		return true, err
+	}
+	if err := json.Unmarshal(input, inner); err != nil {
+		return true, err
+	}
+	*tx = Transaction{inner: inner}
+	return true, nil
+}
+
+// txdata implements TxData for legacy transactions.
+
+func (tx *txdata) TxType() byte { return LegacyTxType }
+
+func (tx *txdata) Copy() TxData {
+	return &txdata{
+		AccountNonce: tx.AccountNonce,
+		Price:        copyBig(tx.Price),
+		GasLimit:     tx.GasLimit,
+		Recipient:    copyAddressPtr(tx.Recipient),
+		Amount:       copyBig(tx.Amount),
+		Payload:      common.CopyBytes(tx.Payload),
+		V:            copyBig(tx.V),
+		R:            copyBig(tx.R),
+		S:            copyBig(tx.S),
+	}
+}
+
+func (tx *txdata) Nonce() uint64       { return tx.AccountNonce }
+func (tx *txdata) GasPrice() *big.Int  { return tx.Price }
+func (tx *txdata) Gas() uint64         { return tx.GasLimit }
+func (tx *txdata) To() *common.Address { return tx.Recipient }
+func (tx *txdata) Value() *big.Int     { return tx.Amount }
+func (tx *txdata) Data() []byte        { return tx.Payload }
+
+func (tx *txdata) RawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *txdata) SetSignatureValues(v, r, s *big.Int) {
+	tx.V, tx.R, tx.S = v, r, s
+}
+
+// copyBig copies a big integer, returning zero for nil.
+func copyBig(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(x)
+}
+
+// copyAddressPtr copies an optional address.
+func copyAddressPtr(a *common.Address) *common.Address {
+	if a == nil {
+		return nil
+	}
+	cpy := *a
+	return &cpy
+}
@@ -0,0 +1,360 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/crypto"
+	"github.com/ava-labs/go-ethereum/params"
+	"github.com/ava-labs/go-ethereum/rlp"
+	"github.com/ava-labs/go-ethereum/trie"
+)
+
+// testTxType is the type byte of testTxData.
+const testTxType = 0x10
+
+// testTxData is a custom transaction type carrying an extra field.
+type testTxData struct {
+	AccountNonce uint64          `json:"nonce"`
+	Price        *big.Int        `json:"gasPrice"`
+	GasLimit     uint64          `json:"gas"`
+	Recipient    *common.Address `json:"to" rlp:"nil"`
+	Amount       *big.Int        `json:"value"`
+	Payload      hexutil.Bytes   `json:"input"`
+	BlockchainID common.Hash     `json:"blockchainId"`
+
+	V, R, S *big.Int
+}
+
+func newTestTxData() *testTxData {
+	return &testTxData{
+		AccountNonce: 1,
+		Price:        big.NewInt(2),
+		GasLimit:     21000,
+		Recipient:    &common.Address{3},
+		Amount:       big.NewInt(4),
+		Payload:      []byte{5},
+		BlockchainID: common.Hash{6},
+		V:            new(big.Int),
+		R:            new(big.Int),
+		S:            new(big.Int),
+	}
+}
+
+func (tx *testTxData) TxType() byte { return testTxType }
+
+func (tx *testTxData) Copy() TxData {
+	cpy := *tx
+	cpy.Price, cpy.Amount = new(big.Int).Set(tx.Price), new(big.Int).Set(tx.Amount)
+	cpy.V, cpy.R, cpy.S = new(big.Int).Set(tx.V), new(big.Int).Set(tx.R), new(big.Int).Set(tx.S)
+	cpy.Payload = common.CopyBytes(tx.Payload)
+	return &cpy
+}
+
+func (tx *testTxData) Nonce() uint64       { return tx.AccountNonce }
+func (tx *testTxData) GasPrice() *big.Int  { return tx.Price }
+func (tx *testTxData) Gas() uint64         { return tx.GasLimit }
+func (tx *testTxData) To() *common.Address { return tx.Recipient }
+func (tx *testTxData) Value() *big.Int     { return tx.Amount }
+func (tx *testTxData) Data() []byte        { return tx.Payload }
+
+func (tx *testTxData) RawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *testTxData) SetSignatureValues(v, r, s *big.Int) {
+	tx.V, tx.R, tx.S = v, r, s
+}
+
+// unregisterTxTypes removes all registered custom transaction types.
+func unregisterTxTypes() {
+	txTypes = make(map[byte]reflect.Type)
+}
+
+func TestTxTypeRLP(t *testing.T) {
+	RegisterTxType(&testTxData{})
+	defer unregisterTxTypes()
+
+	tx := NewTx(newTestTxData())
+	if tx.Type() != testTxType || tx.Nonce() != 1 || tx.Gas() != 21000 || *tx.To() != (common.Address{3}) {
+		t.Fatalf("transaction accessors mismatch")
+	}
+	if cost := tx.Cost(); cost.Uint64() != 2*21000+4 {
+		t.Errorf("cost mismatch: have %v", cost)
+	}
+	// The canonical encoding is prefixed with the type
+	bin, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bin[0] != testTxType || !bytes.Equal(bin[1:], mustEncode(t, newTestTxData())) {
+		t.Errorf("canonical encoding mismatch: %x", bin)
+	}
+	if tx.Hash() != crypto.Keccak256Hash(bin) {
+		t.Errorf("hash mismatch")
+	}
+	// Typed transactions must round-trip through blocks alongside legacy ones
+	legacy := NewTransaction(7, common.Address{8}, common.Big1, 21000, common.Big1, nil)
+	block := NewBlock(newTestHeader(), []*Transaction{legacy, tx}, nil, nil)
+	enc := mustEncode(t, block)
+	dec := new(Block)
+	if err := rlp.DecodeBytes(enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	txs := dec.Transactions()
+	if len(txs) != 2 || txs[0].Hash() != legacy.Hash() || txs[1].Hash() != tx.Hash() {
+		t.Fatalf("decoded transactions mismatch")
+	}
+	if !reflect.DeepEqual(txs[1].inner, tx.inner) {
+		t.Errorf("decoded data mismatch: have %+v, want %+v", txs[1].inner, tx.inner)
+	}
+	if txs[1].Size() != tx.Size() {
+		t.Errorf("size mismatch: have %v, want %v", txs[1].Size(), tx.Size())
+	}
+	if DeriveSha(dec.Transactions()) != block.TxHash() {
+		t.Errorf("transaction root mismatch")
+	}
+	// Unregistered types must be rejected
+	unregisterTxTypes()
+	if err := rlp.DecodeBytes(enc, dec); err != ErrTxTypeNotSupported {
+		t.Errorf("unregistered type decoding error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+func TestTxTypeDeriveSha(t *testing.T) {
+	RegisterTxType(&testTxData{})
+	defer unregisterTxTypes()
+
+	var (
+		legacy = NewTransaction(7, common.Address{8}, common.Big1, 21000, common.Big1, nil)
+		typed  = NewTx(newTestTxData())
+		txs    = Transactions{legacy, typed}
+		number = big.NewInt(10)
+	)
+	// Typed transactions must be hashed into the root by their canonical encoding
+	bin, _ := typed.MarshalBinary()
+	want := new(trie.Trie)
+	want.Update(mustEncode(t, uint(0)), mustEncode(t, legacy))
+	want.Update(mustEncode(t, uint(1)), bin)
+	if root := DeriveTxsSha(number, txs); root != want.Hash() {
+		t.Errorf("root mismatch: have %x, want %x", root, want.Hash())
+	}
+	// Registered encoders must be consulted, falling back to the canonical one
+	defer func() { txDeriveEncoder = nil }()
+	RegisterTxDeriveEncoder(func(number *big.Int, tx *Transaction) ([]byte, bool) {
+		if tx.Type() == LegacyTxType || number.Uint64() >= 10 {
+			return nil, false
+		}
+		return mustEncode(t, tx.inner), true
+	})
+	if root := DeriveTxsSha(number, txs); root != want.Hash() {
+		t.Errorf("root mismatch after fork: have %x, want %x", root, want.Hash())
+	}
+	want.Update(mustEncode(t, uint(1)), mustEncode(t, typed.inner))
+	if root := DeriveTxsSha(big.NewInt(9), txs); root != want.Hash() {
+		t.Errorf("root mismatch before fork: have %x, want %x", root, want.Hash())
+	}
+	if header := NewBlock(&Header{Number: big.NewInt(9)}, txs, nil, nil).Header(); header.TxHash != want.Hash() {
+		t.Errorf("block root mismatch: have %x, want %x", header.TxHash, want.Hash())
+	}
+}
+
+func TestTxTypeJSON(t *testing.T) {
+	RegisterTxType(&testTxData{})
+	defer unregisterTxTypes()
+
+	tx := NewTx(newTestTxData())
+	enc, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(enc, &fields); err != nil {
+		t.Fatalf("invalid JSON %s: %v", enc, err)
+	}
+	for key, want := range map[string]interface{}{"type": "0x10", "hash": tx.Hash().Hex(), "nonce": float64(1)} {
+		if fields[key] != want {
+			t.Errorf("field %q mismatch: have %v, want %v", key, fields[key], want)
+		}
+	}
+	if _, ok := fields["blockchainId"]; !ok {
+		t.Errorf("custom field missing from %s", enc)
+	}
+	extra, err := tx.ExtraJSONFields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(extra["blockchainId"]) != `"`+(common.Hash{6}).Hex()+`"` {
+		t.Errorf("extra JSON fields mismatch: have %s", extra)
+	}
+	dec := new(Transaction)
+	if err := json.Unmarshal(enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Errorf("decoded transaction mismatch")
+	}
+	// Legacy transactions must be unaffected
+	legacy := NewTransaction(7, common.Address{8}, common.Big1, 21000, common.Big1, nil)
+	if enc, err = json.Marshal(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Type() != LegacyTxType || dec.Hash() != legacy.Hash() {
+		t.Errorf("decoded legacy transaction mismatch")
+	}
+	if extra, _ := legacy.ExtraJSONFields(); extra != nil {
+		t.Errorf("legacy transaction has extra JSON fields: %s", extra)
+	}
+}
+
+func TestTxTypeLegacySigners(t *testing.T) {
+	RegisterTxType(&testTxData{})
+	defer unregisterTxTypes()
+
+	tx := NewTx(newTestTxData())
+	for _, signer := range []Signer{NewEIP155Signer(common.Big1), HomesteadSigner{}, FrontierSigner{}} {
+		if _, err := Sender(signer, tx); err != ErrTxTypeNotSupported {
+			t.Errorf("%T: sender error mismatch: have %v, want %v", signer, err, ErrTxTypeNotSupported)
+		}
+	}
+}
+
+// testSignedTxType is the type byte of testSignedTxData.
+const testSignedTxType = 0x11
+
+// testSignedTxData is a custom transaction type signed by its sender.
+type testSignedTxData struct {
+	ChainId *big.Int
+	Fields  testTxData
+}
+
+func (tx *testSignedTxData) TxType() byte { return testSignedTxType }
+
+func (tx *testSignedTxData) Copy() TxData {
+	return &testSignedTxData{
+		ChainId: new(big.Int).Set(tx.ChainId),
+		Fields:  *tx.Fields.Copy().(*testTxData),
+	}
+}
+
+func (tx *testSignedTxData) Nonce() uint64       { return tx.Fields.Nonce() }
+func (tx *testSignedTxData) GasPrice() *big.Int  { return tx.Fields.GasPrice() }
+func (tx *testSignedTxData) Gas() uint64         { return tx.Fields.Gas() }
+func (tx *testSignedTxData) To() *common.Address { return tx.Fields.To() }
+func (tx *testSignedTxData) Value() *big.Int     { return tx.Fields.Value() }
+func (tx *testSignedTxData) Data() []byte        { return tx.Fields.Data() }
+
+func (tx *testSignedTxData) RawSignatureValues() (v, r, s *big.Int) {
+	return tx.Fields.RawSignatureValues()
+}
+
+func (tx *testSignedTxData) SetSignatureValues(v, r, s *big.Int) {
+	tx.Fields.SetSignatureValues(v, r, s)
+}
+
+func (tx *testSignedTxData) ChainID() *big.Int { return tx.ChainId }
+
+func (tx *testSignedTxData) SigHash() common.Hash {
+	f := &tx.Fields
+	return rlpHash([]interface{}{
+		tx.TxType(),
+		tx.ChainId,
+		f.AccountNonce,
+		f.Price,
+		f.GasLimit,
+		f.Recipient,
+		f.Amount,
+		f.Payload,
+		f.BlockchainID,
+	})
+}
+
+func TestTxTypeSigning(t *testing.T) {
+	RegisterTxType(&testSignedTxData{})
+	defer unregisterTxTypes()
+
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		signer = MakeSigner(params.TestChainConfig, common.Big1)
+		tx     = NewTx(&testSignedTxData{ChainId: params.TestChainConfig.ChainID, Fields: *newTestTxData()})
+	)
+	signed, err := SignTx(tx, signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _, _ := signed.RawSignatureValues(); v.Uint64() > 1 {
+		t.Errorf("signature V value not a recovery id: %v", v)
+	}
+	if from, err := Sender(signer, signed); err != nil || from != addr {
+		t.Errorf("sender mismatch: have %x, %v, want %x", from, err, addr)
+	}
+	if chainID := signed.ChainId(); chainID.Cmp(params.TestChainConfig.ChainID) != 0 {
+		t.Errorf("chain id mismatch: have %v, want %v", chainID, params.TestChainConfig.ChainID)
+	}
+	// The sender must survive the canonical encoding
+	bin, err := signed.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := new(Transaction)
+	if err := dec.UnmarshalBinary(bin); err != nil {
+		t.Fatal(err)
+	}
+	if from, err := Sender(signer, dec); err != nil || from != addr {
+		t.Errorf("decoded sender mismatch: have %x, %v, want %x", from, err, addr)
+	}
+	// Signers of other chains and pre-EIP-155 ones must reject it
+	if _, err := Sender(NewEIP155Signer(big.NewInt(1337)), dec); err != ErrInvalidChainId {
+		t.Errorf("foreign chain sender error mismatch: have %v, want %v", err, ErrInvalidChainId)
+	}
+	if _, err := Sender(HomesteadSigner{}, dec); err != ErrTxTypeNotSupported {
+		t.Errorf("homestead sender error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+func TestRegisterTxTypePanics(t *testing.T) {
+	defer unregisterTxTypes()
+
+	for _, data := range []TxData{nil, new(txdata)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("data %T registered", data)
+				}
+			}()
+			RegisterTxType(data)
+		}()
+	}
+	RegisterTxType(&testTxData{})
+	defer func() {
+		if recover() == nil {
+			t.Errorf("transaction type registered twice")
+		}
+	}()
+	RegisterTxType(&testTxData{})
+}
@@ -59,6 +59,8 @@ type Contract struct {
 
 	Gas   uint64
 	value *big.Int
+
+	callType OpCode // Type of the message call executing the contract
 }
 
 // NewContract returns a new contract environment for the execution of EVM.
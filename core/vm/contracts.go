@@ -21,12 +21,14 @@ import (
 	"encoding/binary"
 	"errors"
 	"math/big"
+	"time"
 
 	"github.com/ava-labs/go-ethereum/common"
 	"github.com/ava-labs/go-ethereum/common/math"
 	"github.com/ava-labs/go-ethereum/crypto"
 	"github.com/ava-labs/go-ethereum/crypto/blake2b"
 	"github.com/ava-labs/go-ethereum/crypto/bn256"
+	"github.com/ava-labs/go-ethereum/metrics"
 	"github.com/ava-labs/go-ethereum/params"
 	"golang.org/x/crypto/ripemd160"
 )
@@ -84,6 +86,32 @@ func RunPrecompiledContract(p PrecompiledContract, input []byte, contract *Contr
 	return nil, ErrOutOfGas
 }
 
+// runPrecompiledContract runs a precompiled contract on behalf of the EVM,
+// providing stateful precompiles with their execution environment.
+func runPrecompiledContract(evm *EVM, p PrecompiledContract, input []byte, contract *Contract, readOnly bool) (ret []byte, err error) {
+	if metrics.Enabled && contract.CodeAddr != nil {
+		start, available := time.Now(), contract.Gas
+		defer func() {
+			meters(*contract.CodeAddr).update(available-contract.Gas, time.Since(start), err)
+		}()
+	}
+	if r, ok := p.(DelegateCallRefuser); ok && contract.callType == DELEGATECALL && r.RefuseDelegateCall() {
+		return nil, ErrDelegateCallRefused
+	}
+	gas := p.RequiredGas(input)
+	if !contract.UseGas(gas) {
+		return nil, ErrOutOfGas
+	}
+	switch p := p.(type) {
+	case DynamicGasPrecompiledContract:
+		return runDynamicGas(p, newPrecompileEnvironment(evm, contract, readOnly), input)
+	case StatefulPrecompiledContract:
+		return p.RunStateful(newPrecompileEnvironment(evm, contract, readOnly), input)
+	default:
+		return p.Run(input)
+	}
+}
+
 // ECRECOVER implemented as a native contract.
 type ecrecover struct{}
 
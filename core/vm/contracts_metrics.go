@@ -0,0 +1,70 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/metrics"
+)
+
+// precompileMeters are the metrics collected for a single precompile address,
+// registered as vm/precompile/<address>/{calls,failures,gas,time}.
+type precompileMeters struct {
+	calls    metrics.Meter // Number of invocations
+	failures metrics.Meter // Number of invocations returning an error, reverts included
+	gas      metrics.Meter // Gas consumed, including the up front RequiredGas
+	time     metrics.Timer // Wall clock execution time
+}
+
+var (
+	precompileMetersLock   sync.Mutex
+	precompileMetersByAddr = make(map[common.Address]*precompileMeters)
+)
+
+// meters returns the metrics of the precompile at the given address, registering
+// them on first use.
+func meters(addr common.Address) *precompileMeters {
+	precompileMetersLock.Lock()
+	defer precompileMetersLock.Unlock()
+
+	if m, ok := precompileMetersByAddr[addr]; ok {
+		return m
+	}
+	prefix := fmt.Sprintf("vm/precompile/%x/", addr)
+	m := &precompileMeters{
+		calls:    metrics.NewRegisteredMeter(prefix+"calls", nil),
+		failures: metrics.NewRegisteredMeter(prefix+"failures", nil),
+		gas:      metrics.NewRegisteredMeter(prefix+"gas", nil),
+		time:     metrics.NewRegisteredTimer(prefix+"time", nil),
+	}
+	precompileMetersByAddr[addr] = m
+	return m
+}
+
+// update records a single invocation of the precompile.
+func (m *precompileMeters) update(gas uint64, elapsed time.Duration, err error) {
+	m.calls.Mark(1)
+	if err != nil {
+		m.failures.Mark(1)
+	}
+	m.gas.Mark(int64(gas))
+	m.time.Update(elapsed)
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/metrics"
+)
+
+func TestPrecompileMetrics(t *testing.T) {
+	defer func(enabled bool) { metrics.Enabled = enabled }(metrics.Enabled)
+	metrics.Enabled = true
+
+	precompile := common.HexToAddress("0x0100000000000000000000000000000000000000")
+	defer installPrecompile(precompile, &statefulPrecompile{
+		gas: 100,
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			env.UseGas(50)
+			if len(input) > 0 {
+				return nil, errors.New("failed")
+			}
+			return nil, nil
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	for _, input := range [][]byte{nil, nil, {1}} {
+		evm.Call(AccountRef(common.Address{}), precompile, input, 100000, new(big.Int))
+	}
+	m := meters(precompile)
+	if calls := m.calls.Count(); calls != 3 {
+		t.Errorf("call count mismatch: have %d, want 3", calls)
+	}
+	if failures := m.failures.Count(); failures != 1 {
+		t.Errorf("failure count mismatch: have %d, want 1", failures)
+	}
+	if gas := m.gas.Count(); gas != 450 {
+		t.Errorf("gas mismatch: have %d, want 450", gas)
+	}
+	if count := m.time.Count(); count != 3 {
+		t.Errorf("timer count mismatch: have %d, want 3", count)
+	}
+}
@@ -0,0 +1,417 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/math"
+	"github.com/ava-labs/go-ethereum/crypto"
+)
+
+// RegisteredPrecompile describes a precompiled contract installed by a chain
+// built on top of go-ethereum. The contract is active at its address for all
+// blocks with a timestamp in [Activation, Deactivation).
+//
+// If PrefixLength is non-zero the contract answers an entire address range
+// instead: every address sharing the first PrefixLength bytes with Address.
+// The precompile at an address is resolved by the following precedence rules:
+//
+//  1. a precompile registered at exactly that address
+//  2. the default Ethereum precompile at that address
+//  3. the range precompile with the longest matching prefix
+//
+// Instead of a Contract, a precompile registered at a single address may set
+// Wrap to decorate the default Ethereum precompile at that address, e.g. to add
+// metrics or extra validation before falling through to the original contract.
+// Wrap is invoked once for every default precompile set during registration,
+// receiving nil for sets without a precompile at the address. If it returns
+// nil, no precompile is active at the address under the respective rules.
+// Decorators embedding the wrapped contract must implement DelegateCallRefuser
+// themselves to forward its refusal of delegate calls, if any.
+type RegisteredPrecompile struct {
+	Address      common.Address
+	PrefixLength int
+	Contract     PrecompiledContract
+	Wrap         func(PrecompiledContract) PrecompiledContract
+
+	Activation   uint64 // Block timestamp from which the contract is active (0 = genesis)
+	Deactivation uint64 // Block timestamp from which the contract is inactive again (0 = never)
+
+	// Gas optionally declares the cost of the contract's functions. Any gas
+	// it prescribes is charged on top of the contract's own RequiredGas.
+	Gas PrecompileGasSchedule
+
+	// Code optionally opts the precompile into reporting non-empty code to the
+	// EXTCODESIZE, EXTCODECOPY and EXTCODEHASH opcodes, so that contracts that
+	// check for code before calling (e.g. Solidity's extcodesize guard) accept
+	// it. The code itself is never executed and no state is modified. While a
+	// precompile upgrade is in effect at the address, the code reported by the
+	// upgraded contract applies instead, see CodeReporter.
+	Code []byte
+
+	wrapped  map[PrecompiledContract]PrecompiledContract // Wrapped contracts by default contract
+	codeHash common.Hash                                 // Hash of the reported code
+}
+
+// PrecompileGasSchedule declares the gas cost of a precompile's functions, keyed
+// by their 4 byte ABI selector. Calls to functions missing from the schedule
+// are only charged the contract's RequiredGas.
+type PrecompileGasSchedule map[[4]byte]FunctionGas
+
+// FunctionGas is the gas cost of a single precompile function, calculated as
+//
+//	Base + PerWord * words(input) + PerItem * Items(input)
+//
+// where words(input) is the number of 32 byte words in the input, selector
+// included, rounded up.
+type FunctionGas struct {
+	Base    uint64 // Constant cost of every call
+	PerWord uint64 // Cost per 32 byte input word
+	PerItem uint64 // Cost per item reported by Items
+
+	// Items counts the number of items (e.g. array elements) in the input to
+	// be charged PerItem each. It must be set if and only if PerItem is.
+	Items func(input []byte) uint64
+}
+
+// validate checks that the gas schedule is well formed.
+func (s PrecompileGasSchedule) validate() error {
+	for selector, gas := range s {
+		if (gas.PerItem != 0) != (gas.Items != nil) {
+			return fmt.Errorf("function %x: per item gas and item counter must be set together", selector)
+		}
+	}
+	return nil
+}
+
+// requiredGas returns the gas prescribed by the schedule for the given input.
+// Costs saturate at the maximum uint64 value instead of overflowing.
+func (s PrecompileGasSchedule) requiredGas(input []byte) uint64 {
+	if len(input) < 4 {
+		return 0
+	}
+	var selector [4]byte
+	copy(selector[:], input)
+
+	gas, ok := s[selector]
+	if !ok {
+		return 0
+	}
+	var items uint64
+	if gas.Items != nil {
+		items = gas.Items(input)
+	}
+	wordGas, overflow := math.SafeMul(toWordSize(uint64(len(input))), gas.PerWord)
+	if overflow {
+		return math.MaxUint64
+	}
+	itemGas, overflow := math.SafeMul(items, gas.PerItem)
+	if overflow {
+		return math.MaxUint64
+	}
+	cost, overflow := math.SafeAdd(gas.Base, wordGas)
+	if overflow {
+		return math.MaxUint64
+	}
+	if cost, overflow = math.SafeAdd(cost, itemGas); overflow {
+		return math.MaxUint64
+	}
+	return cost
+}
+
+// apply wraps the contract to charge the gas declared by the schedule, if any.
+func (s PrecompileGasSchedule) apply(p PrecompiledContract) PrecompiledContract {
+	if s == nil || p == nil {
+		return p
+	}
+	return &scheduledPrecompile{PrecompiledContract: p, schedule: s}
+}
+
+// scheduledPrecompile wraps a registered precompile to charge the gas declared
+// by its schedule before the contract is run.
+type scheduledPrecompile struct {
+	PrecompiledContract
+	schedule PrecompileGasSchedule
+}
+
+func (p *scheduledPrecompile) RequiredGas(input []byte) uint64 {
+	cost, overflow := math.SafeAdd(p.PrecompiledContract.RequiredGas(input), p.schedule.requiredGas(input))
+	if overflow {
+		return math.MaxUint64
+	}
+	return cost
+}
+
+// RefuseDelegateCall forwards to the wrapped contract, so that a gas schedule
+// doesn't lift its refusal of delegate calls.
+func (p *scheduledPrecompile) RefuseDelegateCall() bool {
+	r, ok := p.PrecompiledContract.(DelegateCallRefuser)
+	return ok && r.RefuseDelegateCall()
+}
+
+func (p *scheduledPrecompile) RunStateful(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+	switch p := p.PrecompiledContract.(type) {
+	case DynamicGasPrecompiledContract:
+		return runDynamicGas(p, env, input)
+	case StatefulPrecompiledContract:
+		return p.RunStateful(env, input)
+	default:
+		return p.Run(input)
+	}
+}
+
+// activeAt returns whether the precompile is active at the given timestamp.
+func (p *RegisteredPrecompile) activeAt(time uint64) bool {
+	return p.Activation <= time && (p.Deactivation == 0 || time < p.Deactivation)
+}
+
+// matches returns whether the precompile answers the given address.
+func (p *RegisteredPrecompile) matches(addr common.Address) bool {
+	if p.PrefixLength == 0 {
+		return p.Address == addr
+	}
+	return bytes.Equal(p.Address[:p.PrefixLength], addr[:p.PrefixLength])
+}
+
+// overlaps returns whether two precompiles answer the same addresses with
+// intersecting activation windows.
+func (p *RegisteredPrecompile) overlaps(other *RegisteredPrecompile) bool {
+	if p.PrefixLength != other.PrefixLength || !p.matches(other.Address) {
+		return false
+	}
+	if p.Deactivation != 0 && p.Deactivation <= other.Activation {
+		return false
+	}
+	if other.Deactivation != 0 && other.Deactivation <= p.Activation {
+		return false
+	}
+	return true
+}
+
+// registeredPrecompiles contains all precompiles registered at a single address
+// and registeredRanges all those answering an address range, ordered by prefix
+// length from longest to shortest. Both are only modified during initialisation,
+// so no locking is needed for lookups.
+var (
+	registeredPrecompiles = make(map[common.Address][]*RegisteredPrecompile)
+	registeredRanges      []*RegisteredPrecompile
+)
+
+// defaultPrecompileSets contains all sets of default Ethereum precompiles.
+var defaultPrecompileSets = []map[common.Address]PrecompiledContract{
+	PrecompiledContractsHomestead,
+	PrecompiledContractsByzantium,
+	PrecompiledContractsIstanbul,
+}
+
+// RegisterPrecompile installs a precompiled contract for a window of block
+// timestamps. While active, a registered precompile takes precedence over any
+// default Ethereum precompile at the same address. The same address may be
+// registered several times, e.g. to reconfigure a contract at an upgrade, as
+// long as the activation windows don't overlap.
+//
+// RegisterPrecompile is not safe for concurrent use and must be called during
+// initialisation, before any EVM is created. It panics on invalid or
+// conflicting registrations.
+func RegisterPrecompile(p RegisteredPrecompile) {
+	if (p.Contract == nil) == (p.Wrap == nil) {
+		panic(fmt.Sprintf("vm: precompile at %x must set exactly one of Contract and Wrap", p.Address))
+	}
+	if p.Deactivation != 0 && p.Deactivation <= p.Activation {
+		panic(fmt.Sprintf("vm: precompile at %x deactivated (%d) before activation (%d)", p.Address, p.Deactivation, p.Activation))
+	}
+	if p.PrefixLength < 0 || p.PrefixLength >= common.AddressLength {
+		panic(fmt.Sprintf("vm: invalid prefix length %d for precompile at %x", p.PrefixLength, p.Address))
+	}
+	if p.PrefixLength != 0 && p.Wrap != nil {
+		panic(fmt.Sprintf("vm: range precompile at %x can't wrap default precompiles", p.Address))
+	}
+	others := registeredPrecompiles[p.Address]
+	if p.PrefixLength != 0 {
+		others = registeredRanges
+	}
+	for _, other := range others {
+		if p.overlaps(other) {
+			panic(fmt.Sprintf("vm: overlapping precompile registrations at %x", p.Address))
+		}
+	}
+	if p.Gas != nil {
+		if err := p.Gas.validate(); err != nil {
+			panic(fmt.Sprintf("vm: invalid gas schedule for precompile at %x: %v", p.Address, err))
+		}
+	}
+	if len(p.Code) > 0 {
+		p.Code = common.CopyBytes(p.Code)
+		p.codeHash = crypto.Keccak256Hash(p.Code)
+	}
+	if p.Wrap != nil {
+		p.wrapped = make(map[PrecompiledContract]PrecompiledContract)
+		for _, set := range defaultPrecompileSets {
+			if _, ok := p.wrapped[set[p.Address]]; !ok {
+				p.wrapped[set[p.Address]] = p.Gas.apply(p.Wrap(set[p.Address]))
+			}
+		}
+	} else {
+		p.Contract = p.Gas.apply(p.Contract)
+	}
+	if p.PrefixLength != 0 {
+		registeredRanges = append(registeredRanges, &p)
+		sort.SliceStable(registeredRanges, func(i, j int) bool {
+			return registeredRanges[i].PrefixLength > registeredRanges[j].PrefixLength
+		})
+		return
+	}
+	registeredPrecompiles[p.Address] = append(registeredPrecompiles[p.Address], &p)
+}
+
+// contract returns the precompiled contract of the registration, resolving
+// wrapped precompiles against the given default set.
+func (p *RegisteredPrecompile) contract(defaults map[common.Address]PrecompiledContract) PrecompiledContract {
+	if p.wrapped != nil {
+		return p.wrapped[defaults[p.Address]]
+	}
+	return p.Contract
+}
+
+// registeredPrecompile returns the precompile registered at exactly the given
+// address that is active at the given block timestamp, if any.
+func registeredPrecompile(addr common.Address, time uint64) *RegisteredPrecompile {
+	for _, p := range registeredPrecompiles[addr] {
+		if p.activeAt(time) {
+			return p
+		}
+	}
+	return nil
+}
+
+// registeredRange returns the range precompile with the longest prefix matching
+// the given address that is active at the given block timestamp, if any.
+func registeredRange(addr common.Address, time uint64) *RegisteredPrecompile {
+	for _, p := range registeredRanges {
+		if p.activeAt(time) && p.matches(addr) {
+			return p
+		}
+	}
+	return nil
+}
+
+// registration returns the registered precompile answering the given address
+// in the block being executed, if any, following the rules of precedence
+// documented on RegisteredPrecompile.
+func (evm *EVM) registration(addr common.Address, defaults map[common.Address]PrecompiledContract) *RegisteredPrecompile {
+	return registrationAt(addr, evm.time(), defaults)
+}
+
+// registrationAt returns the registered precompile answering the given address
+// at the given block timestamp, if any.
+func registrationAt(addr common.Address, time uint64, defaults map[common.Address]PrecompiledContract) *RegisteredPrecompile {
+	if p := registeredPrecompile(addr, time); p != nil {
+		return p
+	}
+	if p, ok := defaults[addr]; ok && p != nil {
+		return nil
+	}
+	return registeredRange(addr, time)
+}
+
+// precompileCode returns the code reported for a scheduled or registered
+// precompile that opted into reporting code, if one is active at the given
+// address. Like the precompile itself, the code of a precompile upgrade in
+// effect takes precedence, so disabling upgrades leave no code to report.
+func (evm *EVM) precompileCode(addr common.Address) ([]byte, common.Hash, bool) {
+	if _, ok := evm.precompileOverrides[addr]; ok {
+		return nil, common.Hash{}, false
+	}
+	if p, ok := upgradedPrecompile(evm.chainRules, addr); ok {
+		if reporter, ok := p.(CodeReporter); ok {
+			if code := reporter.ReportedCode(); len(code) > 0 {
+				return code, crypto.Keccak256Hash(code), true
+			}
+		}
+		return nil, common.Hash{}, false
+	}
+	if len(registeredPrecompiles) == 0 && len(registeredRanges) == 0 {
+		return nil, common.Hash{}, false
+	}
+	if p := evm.registration(addr, evm.defaultPrecompiles()); p != nil && len(p.Code) > 0 {
+		return p.Code, p.codeHash, true
+	}
+	return nil, common.Hash{}, false
+}
+
+// codeSize returns the size of the code at the given address as observed by
+// the EXTCODESIZE opcode.
+func (evm *EVM) codeSize(addr common.Address) int {
+	if code, _, ok := evm.precompileCode(addr); ok {
+		return len(code)
+	}
+	return evm.StateDB.GetCodeSize(addr)
+}
+
+// code returns the code at the given address as observed by the EXTCODECOPY
+// opcode.
+func (evm *EVM) code(addr common.Address) []byte {
+	if code, _, ok := evm.precompileCode(addr); ok {
+		return code
+	}
+	return evm.StateDB.GetCode(addr)
+}
+
+// codeHash returns the code hash at the given address as observed by the
+// EXTCODEHASH opcode, i.e. zero for empty accounts.
+func (evm *EVM) codeHash(addr common.Address) common.Hash {
+	if _, hash, ok := evm.precompileCode(addr); ok {
+		return hash
+	}
+	if evm.StateDB.Empty(addr) {
+		return common.Hash{}
+	}
+	return evm.StateDB.GetCodeHash(addr)
+}
+
+// ActivePrecompiles returns the addresses of all precompiled contracts, both
+// default and registered, that are active in the block being executed. The
+// addresses are sorted in ascending order. Address ranges are not enumerated.
+func (evm *EVM) ActivePrecompiles() []common.Address {
+	candidates := make(map[common.Address]struct{})
+	for addr := range evm.defaultPrecompiles() {
+		candidates[addr] = struct{}{}
+	}
+	for addr := range registeredPrecompiles {
+		candidates[addr] = struct{}{}
+	}
+	for addr := range evm.chainRules.PrecompileUpgrades {
+		candidates[addr] = struct{}{}
+	}
+	for addr := range evm.precompileOverrides {
+		candidates[addr] = struct{}{}
+	}
+	var addrs []common.Address
+	for addr := range candidates {
+		if _, ok := evm.precompile(addr); ok {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	return addrs
+}
@@ -0,0 +1,358 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/crypto"
+)
+
+// unregisterPrecompiles drops all precompiles registered by a test.
+func unregisterPrecompiles() {
+	registeredPrecompiles = make(map[common.Address][]*RegisteredPrecompile)
+	registeredRanges = nil
+}
+
+func TestRegisteredPrecompileActivation(t *testing.T) {
+	defer unregisterPrecompiles()
+
+	var (
+		addr = common.HexToAddress("0x0200000000000000000000000000000000000000")
+		v1   = &statefulPrecompile{gas: 1}
+		v2   = &statefulPrecompile{gas: 2}
+	)
+	RegisterPrecompile(RegisteredPrecompile{Address: addr, Contract: v1, Activation: 100, Deactivation: 200})
+	RegisterPrecompile(RegisteredPrecompile{Address: addr, Contract: v2, Activation: 300})
+
+	// Shadow a default precompile for a limited time
+	ecrecoverAddr := common.BytesToAddress([]byte{1})
+	RegisterPrecompile(RegisteredPrecompile{Address: ecrecoverAddr, Contract: v1, Deactivation: 200})
+
+	tests := []struct {
+		time      uint64
+		contract  PrecompiledContract
+		ecrecover PrecompiledContract
+	}{
+		{0, nil, v1},
+		{99, nil, v1},
+		{100, v1, v1},
+		{199, v1, v1},
+		{200, nil, PrecompiledContractsByzantium[ecrecoverAddr]},
+		{299, nil, PrecompiledContractsByzantium[ecrecoverAddr]},
+		{300, v2, PrecompiledContractsByzantium[ecrecoverAddr]},
+	}
+	for _, tt := range tests {
+		evm := newStatefulTestEVM()
+		evm.Context.Time = new(big.Int).SetUint64(tt.time)
+
+		p, ok := evm.precompile(addr)
+		if ok != (tt.contract != nil) || p != tt.contract {
+			t.Errorf("time %d: precompile mismatch: have %v, want %v", tt.time, p, tt.contract)
+		}
+		if p, _ := evm.precompile(ecrecoverAddr); p != tt.ecrecover {
+			t.Errorf("time %d: ecrecover mismatch: have %v, want %v", tt.time, p, tt.ecrecover)
+		}
+		var want []common.Address
+		for i := byte(1); i <= 8; i++ {
+			want = append(want, common.BytesToAddress([]byte{i}))
+		}
+		if tt.contract != nil {
+			want = append(want, addr)
+		}
+		if have := evm.ActivePrecompiles(); !reflect.DeepEqual(have, want) {
+			t.Errorf("time %d: active precompiles mismatch: have %x, want %x", tt.time, have, want)
+		}
+	}
+}
+
+func TestRegisterPrecompileConflicts(t *testing.T) {
+	defer unregisterPrecompiles()
+
+	addr := common.HexToAddress("0x0200000000000000000000000000000000000000")
+	RegisterPrecompile(RegisteredPrecompile{Address: addr, Contract: &statefulPrecompile{}, Activation: 100, Deactivation: 200})
+
+	for i, p := range []RegisteredPrecompile{
+		{Address: addr, Contract: nil},
+		{Address: addr, Contract: &statefulPrecompile{}, Activation: 300, Deactivation: 300},
+		{Address: addr, Contract: &statefulPrecompile{}, Activation: 150},
+		{Address: addr, Contract: &statefulPrecompile{}, Deactivation: 101},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("registration %d: expected panic", i)
+				}
+			}()
+			RegisterPrecompile(p)
+		}()
+	}
+	// Adjacent windows are fine
+	RegisterPrecompile(RegisteredPrecompile{Address: addr, Contract: &statefulPrecompile{}, Deactivation: 100})
+	RegisterPrecompile(RegisteredPrecompile{Address: addr, Contract: &statefulPrecompile{}, Activation: 200})
+}
+
+func TestPrecompileGasSchedule(t *testing.T) {
+	defer unregisterPrecompiles()
+
+	var (
+		addr     = common.HexToAddress("0x0200000000000000000000000000000000000000")
+		transfer = [4]byte{0xa9, 0x05, 0x9c, 0xbb}
+		batch    = [4]byte{0x01, 0x02, 0x03, 0x04}
+	)
+	RegisterPrecompile(RegisteredPrecompile{
+		Address: addr,
+		Contract: &statefulPrecompile{
+			gas: 7,
+			run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) { return nil, nil },
+		},
+		Gas: PrecompileGasSchedule{
+			transfer: {Base: 1000, PerWord: 10},
+			batch: {Base: 500, PerItem: 100, Items: func(input []byte) uint64 {
+				return uint64(len(input)-4) / 32
+			}},
+		},
+	})
+	tests := []struct {
+		input []byte
+		gas   uint64
+	}{
+		{nil, 7},
+		{[]byte{0xff, 0xff, 0xff, 0xff}, 7},
+		{transfer[:], 7 + 1000 + 10},
+		{append(transfer[:], make([]byte, 64)...), 7 + 1000 + 30},
+		{append(batch[:], make([]byte, 96)...), 7 + 500 + 300},
+	}
+	for i, tt := range tests {
+		evm := newStatefulTestEVM()
+		_, leftOver, err := evm.Call(AccountRef(common.Address{}), addr, tt.input, 100000, new(big.Int))
+		if err != nil {
+			t.Fatalf("test %d: call failed: %v", i, err)
+		}
+		if used := 100000 - leftOver; used != tt.gas {
+			t.Errorf("test %d: gas used mismatch: have %d, want %d", i, used, tt.gas)
+		}
+	}
+	// Overflowing costs must saturate rather than wrap around
+	overflow := PrecompileGasSchedule{transfer: {Base: 1, PerWord: math.MaxUint64}}
+	if gas := overflow.requiredGas(append(transfer[:], 0)); gas != math.MaxUint64 {
+		t.Errorf("overflowing gas mismatch: have %d, want %d", gas, uint64(math.MaxUint64))
+	}
+	// Malformed schedules must be rejected at registration
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected invalid schedule to panic")
+		}
+	}()
+	RegisterPrecompile(RegisteredPrecompile{
+		Address:    addr,
+		Contract:   &statefulPrecompile{},
+		Activation: 1000,
+		Gas:        PrecompileGasSchedule{batch: {PerItem: 1}},
+	})
+}
+
+func TestPrecompileGasScheduleDelegateCall(t *testing.T) {
+	defer unregisterPrecompiles()
+
+	var (
+		addr      = common.HexToAddress("0x0200000000000000000000000000000000000000")
+		transfer  = [4]byte{0xa9, 0x05, 0x9c, 0xbb}
+		delegator = NewContract(AccountRef(common.Address{}), AccountRef(common.HexToAddress("0xc0ffee01")), new(big.Int), 100000)
+		calls     int
+	)
+	RegisterPrecompile(RegisteredPrecompile{
+		Address: addr,
+		Contract: &refusingPrecompile{statefulPrecompile{
+			run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) { calls++; return nil, nil },
+		}},
+		Gas: PrecompileGasSchedule{transfer: {Base: 1000}},
+	})
+	// Scheduled precompiles must keep refusing delegate calls
+	evm := newStatefulTestEVM()
+	if _, gas, err := evm.DelegateCall(delegator, addr, transfer[:], 100000); err != ErrDelegateCallRefused || gas != 0 {
+		t.Errorf("refused delegate call mismatch: have %v (gas %d), want %v", err, gas, ErrDelegateCallRefused)
+	}
+	if calls != 0 {
+		t.Errorf("refusing precompile run %d times", calls)
+	}
+	if _, _, err := evm.Call(AccountRef(common.Address{}), addr, transfer[:], 100000, new(big.Int)); err != nil || calls != 1 {
+		t.Errorf("call mismatch: have %v (%d calls)", err, calls)
+	}
+}
+
+func TestRegisteredPrecompileRanges(t *testing.T) {
+	defer unregisterPrecompiles()
+
+	var (
+		exact   = &statefulPrecompile{gas: 1}
+		short   = &statefulPrecompile{gas: 2}
+		long    = &statefulPrecompile{gas: 3}
+		later   = &statefulPrecompile{gas: 4}
+		ranged  = common.HexToAddress("0x0100000000000000000000000000000000000000")
+		nested  = common.HexToAddress("0x0101000000000000000000000000000000000000")
+		shorter = common.HexToAddress("0x0000000000000000000000000000000000000000")
+	)
+	RegisterPrecompile(RegisteredPrecompile{Address: ranged, PrefixLength: 1, Contract: short})
+	RegisterPrecompile(RegisteredPrecompile{Address: nested, PrefixLength: 2, Contract: long, Deactivation: 100})
+	RegisterPrecompile(RegisteredPrecompile{Address: nested, PrefixLength: 2, Contract: later, Activation: 200})
+	RegisterPrecompile(RegisteredPrecompile{Address: common.HexToAddress("0x01010000000000000000000000000000000000ff"), Contract: exact})
+	RegisterPrecompile(RegisteredPrecompile{Address: shorter, PrefixLength: 19, Contract: short})
+
+	tests := []struct {
+		addr     string
+		time     uint64
+		contract PrecompiledContract
+	}{
+		{"0x0100000000000000000000000000000000000001", 0, short},
+		{"0x01ff0000000000000000000000000000000000ff", 0, short},
+		{"0x0101000000000000000000000000000000000001", 0, long},
+		{"0x0101000000000000000000000000000000000001", 150, short},
+		{"0x0101000000000000000000000000000000000001", 200, later},
+		{"0x01010000000000000000000000000000000000ff", 0, exact},
+		{"0x0200000000000000000000000000000000000001", 0, nil},
+		// Default precompiles take precedence over ranges
+		{"0x0000000000000000000000000000000000000001", 0, PrecompiledContractsByzantium[common.BytesToAddress([]byte{1})]},
+		{"0x00000000000000000000000000000000000000ff", 0, short},
+	}
+	for _, tt := range tests {
+		evm := newStatefulTestEVM()
+		evm.Context.Time = new(big.Int).SetUint64(tt.time)
+
+		p, ok := evm.precompile(common.HexToAddress(tt.addr))
+		if ok != (tt.contract != nil) || p != tt.contract {
+			t.Errorf("%s at %d: precompile mismatch: have %v, want %v", tt.addr, tt.time, p, tt.contract)
+		}
+	}
+	// Identical ranges with overlapping windows must be rejected
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected overlapping range to panic")
+		}
+	}()
+	RegisterPrecompile(RegisteredPrecompile{Address: common.HexToAddress("0x01ff000000000000000000000000000000000000"), PrefixLength: 1, Contract: long, Activation: 1000})
+}
+
+// countingPrecompile decorates a precompile to count its invocations and halve
+// its gas cost.
+type countingPrecompile struct {
+	PrecompiledContract
+	calls *int
+}
+
+func (p *countingPrecompile) RequiredGas(input []byte) uint64 {
+	return p.PrecompiledContract.RequiredGas(input) / 2
+}
+
+func (p *countingPrecompile) Run(input []byte) ([]byte, error) {
+	*p.calls++
+	return p.PrecompiledContract.Run(input)
+}
+
+func TestRegisteredPrecompileWrap(t *testing.T) {
+	defer unregisterPrecompiles()
+
+	var (
+		sha256Addr = common.BytesToAddress([]byte{2})
+		modExpAddr = common.BytesToAddress([]byte{5})
+		calls      int
+	)
+	RegisterPrecompile(RegisteredPrecompile{
+		Address: sha256Addr,
+		Wrap: func(p PrecompiledContract) PrecompiledContract {
+			return &countingPrecompile{PrecompiledContract: p, calls: &calls}
+		},
+	})
+	// Disable modexp, but only after timestamp 100
+	RegisterPrecompile(RegisteredPrecompile{
+		Address:    modExpAddr,
+		Activation: 100,
+		Wrap:       func(p PrecompiledContract) PrecompiledContract { return nil },
+	})
+
+	evm := newStatefulTestEVM()
+	ret, leftOver, err := evm.Call(AccountRef(common.Address{}), sha256Addr, []byte("hello"), 100000, new(big.Int))
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	want := common.FromHex("0x2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")
+	if !reflect.DeepEqual(ret, want) {
+		t.Errorf("return data mismatch: have %x, want %x", ret, want)
+	}
+	if used, want := 100000-leftOver, (&sha256hash{}).RequiredGas([]byte("hello"))/2; used != want {
+		t.Errorf("gas used mismatch: have %d, want %d", used, want)
+	}
+	if calls != 1 {
+		t.Errorf("call count mismatch: have %d, want 1", calls)
+	}
+	if _, ok := evm.precompile(modExpAddr); !ok {
+		t.Errorf("modexp disabled before activation")
+	}
+	evm.Context.Time = big.NewInt(100)
+	if _, ok := evm.precompile(modExpAddr); ok {
+		t.Errorf("modexp active after being disabled")
+	}
+	for _, addr := range evm.ActivePrecompiles() {
+		if addr == modExpAddr {
+			t.Errorf("disabled modexp reported as active")
+		}
+	}
+}
+
+func TestRegisteredPrecompileCode(t *testing.T) {
+	defer unregisterPrecompiles()
+
+	var (
+		marker = []byte{0xfe}
+		exact  = common.HexToAddress("0x0200000000000000000000000000000000000000")
+		silent = common.HexToAddress("0x0300000000000000000000000000000000000000")
+		ranged = common.HexToAddress("0x04000000000000000000000000000000000000ff")
+	)
+	RegisterPrecompile(RegisteredPrecompile{Address: exact, Contract: &statefulPrecompile{}, Code: marker, Activation: 100})
+	RegisterPrecompile(RegisteredPrecompile{Address: silent, Contract: &statefulPrecompile{}})
+	RegisterPrecompile(RegisteredPrecompile{Address: ranged, PrefixLength: 1, Contract: &statefulPrecompile{}, Code: marker})
+
+	evm := newStatefulTestEVM()
+	evm.Context.Time = big.NewInt(100)
+
+	for _, addr := range []common.Address{exact, ranged} {
+		if size := evm.codeSize(addr); size != len(marker) {
+			t.Errorf("%x: code size mismatch: have %d, want %d", addr, size, len(marker))
+		}
+		if code := evm.code(addr); !reflect.DeepEqual(code, marker) {
+			t.Errorf("%x: code mismatch: have %x, want %x", addr, code, marker)
+		}
+		if hash := evm.codeHash(addr); hash != crypto.Keccak256Hash(marker) {
+			t.Errorf("%x: code hash mismatch: have %x, want %x", addr, hash, crypto.Keccak256Hash(marker))
+		}
+	}
+	// Precompiles that didn't opt in, or aren't active yet, report no code
+	if size := evm.codeSize(silent); size != 0 {
+		t.Errorf("opted out precompile reported code size %d", size)
+	}
+	if hash := evm.codeHash(silent); hash != (common.Hash{}) {
+		t.Errorf("opted out precompile reported code hash %x", hash)
+	}
+	evm.Context.Time = big.NewInt(99)
+	if size := evm.codeSize(exact); size != 0 {
+		t.Errorf("inactive precompile reported code size %d", size)
+	}
+}
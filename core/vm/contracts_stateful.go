@@ -0,0 +1,403 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+// StatefulPrecompiledContract is a native Go contract that, unlike a plain
+// PrecompiledContract, is given access to the EVM it is executing in. It is
+// installed into the precompile sets just like any other precompile; the EVM
+// invokes RunStateful instead of Run whenever the contract implements it.
+type StatefulPrecompiledContract interface {
+	PrecompiledContract
+
+	// RunStateful runs the precompiled contract within the given environment.
+	// The gas reported by RequiredGas has already been deducted.
+	RunStateful(env *PrecompileEnvironment, input []byte) ([]byte, error)
+}
+
+// DynamicGasPrecompiledContract is a precompiled contract whose gas cost can't
+// be derived from its input alone, e.g. because it depends on the state the
+// contract touches. Gas is charged in two phases: RequiredGas up front, and the
+// dynamic portion reported by RunDynamic once the contract has run. If the
+// remaining gas doesn't cover the dynamic portion the call fails with
+// ErrOutOfGas, reverting any changes made by the contract.
+type DynamicGasPrecompiledContract interface {
+	PrecompiledContract
+
+	// RunDynamic runs the precompiled contract within the given environment,
+	// returning the gas consumed on top of RequiredGas.
+	RunDynamic(env *PrecompileEnvironment, input []byte) (ret []byte, dynamicGas uint64, err error)
+}
+
+// runDynamicGas runs a dynamically priced precompile and charges the gas it
+// reports to the environment.
+func runDynamicGas(p DynamicGasPrecompiledContract, env *PrecompileEnvironment, input []byte) ([]byte, error) {
+	ret, dynamicGas, err := p.RunDynamic(env, input)
+	if !env.UseGas(dynamicGas) {
+		return nil, ErrOutOfGas
+	}
+	return ret, err
+}
+
+// PrecompileCallTracer is a Tracer that is additionally notified of the calls
+// made by stateful precompiles through PrecompileEnvironment.Call. Unlike calls
+// made by EVM code, these don't show up as opcodes executed.
+type PrecompileCallTracer interface {
+	Tracer
+	CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error
+	CaptureExit(output []byte, gasUsed uint64, err error) error
+}
+
+// DelegateCallRefuser is implemented by stateful precompiles that must not be
+// executed on behalf of another contract. DELEGATECALLs to a precompile whose
+// RefuseDelegateCall returns true fail with ErrDelegateCallRefused, consuming
+// all gas, without the precompile being run.
+type DelegateCallRefuser interface {
+	RefuseDelegateCall() bool
+}
+
+// PrecompileEnvironment provides a stateful precompile with access to the
+// surrounding execution context. An environment is only valid for the duration
+// of the RunStateful invocation it was passed to.
+type PrecompileEnvironment struct {
+	evm      *EVM
+	contract *Contract
+	readOnly bool
+	snapshot int // Revision taken on creation, the earliest one to revert to
+}
+
+// newPrecompileEnvironment creates the environment for running a precompile on
+// behalf of the given contract. The environment is read-only if either the call
+// itself is static or the calling interpreter is already in read-only mode.
+func newPrecompileEnvironment(evm *EVM, contract *Contract, readOnly bool) *PrecompileEnvironment {
+	if in, ok := evm.interpreter.(*EVMInterpreter); ok && in.readOnly {
+		readOnly = true
+	}
+	return &PrecompileEnvironment{
+		evm:      evm,
+		contract: contract,
+		readOnly: readOnly,
+		snapshot: evm.StateDB.Snapshot(),
+	}
+}
+
+// CallType returns the opcode of the message call executing the precompile:
+// CALL, CALLCODE, DELEGATECALL or STATICCALL. Transactions are reported as
+// CALLs.
+//
+// Like EVM code, a precompile executed by CALLCODE or DELEGATECALL runs in the
+// context of the calling contract: Self is the calling contract, whose state
+// the precompile acts on. A DELEGATECALL additionally inherits the caller and
+// value of the calling contract. Precompiles that can't safely act on behalf
+// of other contracts should check the call type or implement
+// DelegateCallRefuser.
+func (env *PrecompileEnvironment) CallType() OpCode {
+	return env.contract.callType
+}
+
+// Self returns the address the precompile is executing as. This is the
+// precompile's own address unless it was invoked by CALLCODE or DELEGATECALL,
+// see CallType.
+func (env *PrecompileEnvironment) Self() common.Address {
+	return env.contract.Address()
+}
+
+// Caller returns the address of the account that called the precompile, or
+// that of the calling contract's caller for a DELEGATECALL.
+func (env *PrecompileEnvironment) Caller() common.Address {
+	return env.contract.Caller()
+}
+
+// CodeAddress returns the address of the precompile itself, regardless of the
+// call type.
+func (env *PrecompileEnvironment) CodeAddress() common.Address {
+	if env.contract.CodeAddr == nil {
+		return env.Self()
+	}
+	return *env.contract.CodeAddr
+}
+
+// Value returns the amount of wei sent along with the call. By the time the
+// precompile runs the value has already been transferred to its address. In a
+// read-only environment the value is always zero, as static calls can't carry
+// value.
+func (env *PrecompileEnvironment) Value() *big.Int {
+	if env.contract.Value() == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(env.contract.Value())
+}
+
+// ReadOnly returns whether the precompile is executing in a static context, in
+// which case it must not modify any state.
+func (env *PrecompileEnvironment) ReadOnly() bool {
+	return env.readOnly
+}
+
+// Gas returns the amount of gas still available to the precompile.
+func (env *PrecompileEnvironment) Gas() uint64 {
+	return env.contract.Gas
+}
+
+// UseGas attempts to deduct the given amount of gas from the precompile's
+// allowance, reporting whether enough gas was available. Whatever is left
+// over when the precompile returns is refunded to its caller. A precompile
+// that runs out of gas should return ErrOutOfGas.
+func (env *PrecompileEnvironment) UseGas(gas uint64) bool {
+	return env.contract.UseGas(gas)
+}
+
+// ChainConfig returns the configuration of the chain being executed. It must
+// not be modified.
+func (env *PrecompileEnvironment) ChainConfig() *params.ChainConfig {
+	return env.evm.chainConfig
+}
+
+// Rules returns the chain rules in effect for the block being executed.
+func (env *PrecompileEnvironment) Rules() params.Rules {
+	return env.evm.chainRules
+}
+
+// BlockNumber returns the number of the block being executed.
+func (env *PrecompileEnvironment) BlockNumber() *big.Int {
+	return new(big.Int).Set(env.evm.BlockNumber)
+}
+
+// Time returns the timestamp of the block being executed.
+func (env *PrecompileEnvironment) Time() *big.Int {
+	return new(big.Int).Set(env.evm.Time)
+}
+
+// Coinbase returns the beneficiary of the block being executed.
+func (env *PrecompileEnvironment) Coinbase() common.Address {
+	return env.evm.Coinbase
+}
+
+// GasLimit returns the gas limit of the block being executed.
+func (env *PrecompileEnvironment) GasLimit() uint64 {
+	return env.evm.Context.GasLimit
+}
+
+// Difficulty returns the difficulty of the block being executed.
+func (env *PrecompileEnvironment) Difficulty() *big.Int {
+	return new(big.Int).Set(env.evm.Difficulty)
+}
+
+// BlockHash returns the hash of the given ancestor of the block being executed,
+// with the same semantics as the BLOCKHASH opcode: only the 256 most recent
+// blocks are available and the zero hash is returned for any other number.
+func (env *PrecompileEnvironment) BlockHash(number uint64) common.Hash {
+	current := env.evm.BlockNumber
+	if !current.IsUint64() || number >= current.Uint64() || current.Uint64()-number > 256 {
+		return common.Hash{}
+	}
+	return env.evm.GetHash(number)
+}
+
+// GasPrice returns the gas price of the transaction being executed.
+func (env *PrecompileEnvironment) GasPrice() *big.Int {
+	return new(big.Int).Set(env.evm.GasPrice)
+}
+
+// Origin returns the sender of the transaction being executed.
+func (env *PrecompileEnvironment) Origin() common.Address {
+	return env.evm.Origin
+}
+
+// Predicates returns the outcomes of verifying the predicates addressed to the
+// precompile at the given address by the transaction being executed, in access
+// list order. Predicates are only verified when transactions included in blocks
+// are executed, e.g. processed or traced, not for calls such as eth_call and
+// eth_estimateGas.
+func (env *PrecompileEnvironment) Predicates(addr common.Address) []PredicateResult {
+	return env.evm.Predicates[addr]
+}
+
+// StateDB returns the state database for the precompile to read and modify,
+// or nil if the environment is read-only. ReadOnlyState is always available.
+// Accesses are reported to a PrecompileStateTracer, if tracing.
+func (env *PrecompileEnvironment) StateDB() StateDB {
+	if env.readOnly {
+		return nil
+	}
+	return env.stateDB()
+}
+
+// ReadOnlyState returns a read-only view of the state database.
+func (env *PrecompileEnvironment) ReadOnlyState() StateReader {
+	return NewStateReader(env.stateDB())
+}
+
+// IterateStorage calls cb with up to limit non-empty slots of the storage of
+// the account the precompile acts on, see Self, in ascending order of their key
+// hashes from start on. It returns the key hash to resume from, or nil once all
+// slots were visited. Bounding the iteration lets precompiles charge gas for
+// each batch of slots, e.g. to enumerate an allow list kept in their storage;
+// as only key hashes are reported, such lists must store their keys in the
+// values.
+func (env *PrecompileEnvironment) IterateStorage(start common.Hash, limit int, cb func(keyHash, value common.Hash)) (*common.Hash, error) {
+	return env.stateDB().IterateStorage(env.Self(), start, limit, cb)
+}
+
+// Snapshot creates a revision of the state that later modifications made by the
+// precompile can be rolled back to with RevertToSnapshot.
+func (env *PrecompileEnvironment) Snapshot() int {
+	return env.evm.StateDB.Snapshot()
+}
+
+// RevertToSnapshot rolls back all state changes, including emitted logs, made
+// since the given revision was taken. Reverting to a revision taken outside of
+// the current precompile invocation is not allowed and panics, like reverting
+// to an invalid revision does.
+func (env *PrecompileEnvironment) RevertToSnapshot(revid int) {
+	if revid < env.snapshot {
+		panic(fmt.Errorf("revision id %v predates the precompile invocation", revid))
+	}
+	env.evm.StateDB.RevertToSnapshot(revid)
+}
+
+// Log emits a log from the precompile's address, equivalent to the LOG0 to
+// LOG4 opcodes. Logs can't be emitted from a read-only environment.
+func (env *PrecompileEnvironment) Log(topics []common.Hash, data []byte) error {
+	if env.readOnly {
+		return errWriteProtection
+	}
+	if len(topics) > 4 {
+		return ErrTooManyLogTopics
+	}
+	env.evm.StateDB.AddLog(&types.Log{
+		Address: env.Self(),
+		Topics:  topics,
+		Data:    data,
+		// This is a non-consensus field, but assigned here because
+		// core/state doesn't know the current block number.
+		BlockNumber: env.evm.BlockNumber.Uint64(),
+	})
+	return nil
+}
+
+// Call invokes the contract at addr with the precompile as the caller. The gas
+// passed to the callee is deducted from the precompile's own allowance and any
+// gas left over is returned to it afterwards. If the environment is read-only
+// the call is performed as a STATICCALL and must not transfer value.
+func (env *PrecompileEnvironment) Call(addr common.Address, input []byte, gas uint64, value *big.Int) ([]byte, error) {
+	if value == nil {
+		value = new(big.Int)
+	}
+	if env.readOnly && value.Sign() != 0 {
+		return nil, errWriteProtection
+	}
+	if !env.contract.UseGas(gas) {
+		return nil, ErrOutOfGas
+	}
+	// The precompile occupies a call frame of its own, so anything it calls
+	// runs one level deeper than the precompile itself.
+	env.evm.depth++
+	defer func() { env.evm.depth-- }()
+
+	var (
+		ret      []byte
+		leftOver uint64
+		err      error
+	)
+	tracer, traced := env.evm.vmConfig.Tracer.(PrecompileCallTracer)
+	traced = traced && env.evm.vmConfig.Debug
+	if env.readOnly {
+		if traced {
+			tracer.CaptureEnter(STATICCALL, env.Self(), addr, input, gas, new(big.Int))
+		}
+		ret, leftOver, err = env.evm.StaticCall(env.contract, addr, input, gas)
+	} else {
+		if traced {
+			tracer.CaptureEnter(CALL, env.Self(), addr, input, gas, value)
+		}
+		ret, leftOver, err = env.evm.Call(env.contract, addr, input, gas, value)
+	}
+	if traced {
+		tracer.CaptureExit(ret, gas-leftOver, err)
+	}
+	env.contract.Gas += leftOver
+
+	return ret, err
+}
+
+// Revert returns the given data as revert data from the precompile. The values
+// are meant to be returned from RunStateful directly: the call is reverted like
+// by the REVERT opcode, handing the data to the caller and refunding its
+// remaining gas, instead of failing with an opaque VM error.
+func (env *PrecompileEnvironment) Revert(data []byte) ([]byte, error) {
+	return data, errExecutionReverted
+}
+
+// RevertWithReason reverts the precompile with a Solidity compatible revert
+// reason, i.e. the ABI encoding of Error(reason). See Revert for details.
+func (env *PrecompileEnvironment) RevertWithReason(reason string) ([]byte, error) {
+	return env.Revert(EncodeRevertReason(reason))
+}
+
+// revertSelector is the 4 byte ABI selector of Error(string).
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// EncodeRevertReason ABI encodes the given reason as Error(string), the format
+// Solidity uses for require and revert messages.
+func EncodeRevertReason(reason string) []byte {
+	data := make([]byte, 4+32+32+toWordSize(uint64(len(reason)))*32)
+	copy(data, revertSelector)
+	data[4+31] = 0x20
+	binary.BigEndian.PutUint64(data[4+32+24:], uint64(len(reason)))
+	copy(data[4+64:], reason)
+	return data
+}
+
+// IsRevert returns whether the given execution error signals a revert, in which
+// case any data returned alongside it is revert data rather than output.
+func IsRevert(err error) bool {
+	if _, ok := err.(*RevertError); ok {
+		return true
+	}
+	return err == errExecutionReverted
+}
+
+// RunPrecompile runs the precompiled contract active at addr with the given
+// input, allowing precompiles to be composed without duplicating the EVM's
+// dispatch logic. Unlike Call, no new call frame is entered: no value is
+// transferred and no snapshot is taken. The invoked precompile executes as
+// addr, with the calling precompile as its caller, and its gas is paid out of
+// the calling precompile's allowance.
+func (env *PrecompileEnvironment) RunPrecompile(addr common.Address, input []byte) ([]byte, error) {
+	p, ok := env.evm.precompile(addr)
+	if !ok {
+		return nil, ErrNoPrecompiledContract
+	}
+	contract := NewContract(env.contract, AccountRef(addr), new(big.Int), env.contract.Gas)
+	contract.callType = CALL
+	contract.SetCallCode(&addr, common.Hash{}, nil)
+
+	ret, err := runPrecompiledContract(env.evm, p, input, contract, env.readOnly)
+	env.contract.Gas = contract.Gas
+
+	return ret, err
+}
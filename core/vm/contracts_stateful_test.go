@@ -0,0 +1,816 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/core/state"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+// statefulPrecompile is a test precompile whose behaviour is defined by a
+// closure over its environment.
+type statefulPrecompile struct {
+	gas uint64
+	run func(env *PrecompileEnvironment, input []byte) ([]byte, error)
+}
+
+func (p *statefulPrecompile) RequiredGas(input []byte) uint64  { return p.gas }
+func (p *statefulPrecompile) Run(input []byte) ([]byte, error) { return nil, nil }
+
+func (p *statefulPrecompile) RunStateful(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+	return p.run(env, input)
+}
+
+// installPrecompile adds a precompile to every precompile set, returning a
+// function that removes it again.
+func installPrecompile(addr common.Address, p PrecompiledContract) func() {
+	for _, set := range defaultPrecompileSets {
+		set[addr] = p
+	}
+	return func() {
+		for _, set := range defaultPrecompileSets {
+			delete(set, addr)
+		}
+	}
+}
+
+// newStatefulTestEVM creates an EVM over a fresh in-memory state.
+func newStatefulTestEVM() *EVM {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	vmctx := Context{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: big.NewInt(0),
+	}
+	return NewEVM(vmctx, statedb, params.AllEthashProtocolChanges, Config{})
+}
+
+func TestPrecompileEnvironmentCall(t *testing.T) {
+	var (
+		precompile = common.HexToAddress("0x0100000000000000000000000000000000000000")
+		target     = common.HexToAddress("0xc0ffee")
+		caller     = common.HexToAddress("0xca11e7")
+	)
+	defer installPrecompile(precompile, &statefulPrecompile{
+		gas: 100,
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			return env.Call(target, input, 50000, nil)
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	// Stores CALLER into slot 0 and returns 0x2a as a 32 byte word
+	evm.StateDB.SetCode(target, hexutil.MustDecode("0x33600055602a60005260206000f3"))
+
+	ret, gas, err := evm.Call(AccountRef(caller), precompile, nil, 100000, new(big.Int))
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if want := common.LeftPadBytes([]byte{0x2a}, 32); !bytes.Equal(ret, want) {
+		t.Errorf("return data mismatch: have %x, want %x", ret, want)
+	}
+	if have := evm.StateDB.GetState(target, common.Hash{}); have != precompile.Hash() {
+		t.Errorf("callee saw wrong caller: have %x, want %x", have, precompile.Hash())
+	}
+	if used := 100000 - gas; used <= 100 || used >= 50000+100 {
+		t.Errorf("unexpected gas usage %d", used)
+	}
+
+	// Static calls into the precompile must propagate into the nested call
+	evm = newStatefulTestEVM()
+	evm.StateDB.SetCode(target, hexutil.MustDecode("0x33600055602a60005260206000f3"))
+
+	if _, _, err := evm.StaticCall(AccountRef(caller), precompile, nil, 100000); err != errWriteProtection {
+		t.Errorf("static call error mismatch: have %v, want %v", err, errWriteProtection)
+	}
+	if have := evm.StateDB.GetState(target, common.Hash{}); have != (common.Hash{}) {
+		t.Errorf("static call modified state: %x", have)
+	}
+}
+
+func TestPrecompileEnvironmentCallDepth(t *testing.T) {
+	precompile := common.HexToAddress("0x0100000000000000000000000000000000000000")
+
+	var depths []int
+	defer installPrecompile(precompile, &statefulPrecompile{
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			depths = append(depths, env.evm.depth)
+			if len(depths) < 3 {
+				return env.Call(precompile, nil, env.contract.Gas, nil)
+			}
+			return nil, nil
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	if _, _, err := evm.Call(AccountRef(common.Address{}), precompile, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	for i, depth := range depths {
+		if depth != i {
+			t.Errorf("call %d: depth mismatch: have %d, want %d", i, depth, i)
+		}
+	}
+}
+
+func TestPrecompileEnvironmentStateDB(t *testing.T) {
+	var (
+		precompile = common.HexToAddress("0x0100000000000000000000000000000000000000")
+		slot       = common.HexToHash("0x01")
+	)
+	defer installPrecompile(precompile, &statefulPrecompile{
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			if _, ok := env.ReadOnlyState().(StateDB); ok {
+				t.Errorf("read-only state exposes mutating methods")
+			}
+			stored := env.ReadOnlyState().GetState(env.Self(), slot)
+			if db := env.StateDB(); db != nil {
+				db.SetState(env.Self(), slot, common.BytesToHash(input))
+			} else if !env.ReadOnly() {
+				t.Errorf("writable environment without state database")
+			}
+			return stored.Bytes(), nil
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	if _, _, err := evm.Call(AccountRef(common.Address{}), precompile, []byte{0x2a}, 100000, new(big.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if have, want := evm.StateDB.GetState(precompile, slot), common.BytesToHash([]byte{0x2a}); have != want {
+		t.Fatalf("slot mismatch after call: have %x, want %x", have, want)
+	}
+	ret, _, err := evm.StaticCall(AccountRef(common.Address{}), precompile, []byte{0x2b}, 100000)
+	if err != nil {
+		t.Fatalf("static call failed: %v", err)
+	}
+	if have, want := common.BytesToHash(ret), common.BytesToHash([]byte{0x2a}); have != want {
+		t.Errorf("static read mismatch: have %x, want %x", have, want)
+	}
+	if have, want := evm.StateDB.GetState(precompile, slot), common.BytesToHash([]byte{0x2a}); have != want {
+		t.Errorf("static call modified state: have %x, want %x", have, want)
+	}
+}
+
+func TestPrecompileEnvironmentLog(t *testing.T) {
+	var (
+		precompile = common.HexToAddress("0x0100000000000000000000000000000000000000")
+		topic      = common.HexToHash("0x1234")
+		logErr     error
+	)
+	defer installPrecompile(precompile, &statefulPrecompile{
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			logErr = env.Log([]common.Hash{topic}, input)
+			return nil, nil
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	evm.BlockNumber = big.NewInt(7)
+	statedb := evm.StateDB.(*state.StateDB)
+	statedb.Prepare(common.HexToHash("0xabcd"), common.Hash{}, 0)
+
+	if _, _, err := evm.Call(AccountRef(common.Address{}), precompile, []byte{0x2a}, 100000, new(big.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if logErr != nil {
+		t.Fatalf("failed to emit log: %v", logErr)
+	}
+	logs := statedb.GetLogs(common.HexToHash("0xabcd"))
+	if len(logs) != 1 {
+		t.Fatalf("log count mismatch: have %d, want 1", len(logs))
+	}
+	if log := logs[0]; log.Address != precompile || len(log.Topics) != 1 || log.Topics[0] != topic || !bytes.Equal(log.Data, []byte{0x2a}) || log.BlockNumber != 7 {
+		t.Errorf("log mismatch: %+v", log)
+	}
+	// Logging from a static context must be rejected
+	if _, _, err := evm.StaticCall(AccountRef(common.Address{}), precompile, nil, 100000); err != nil {
+		t.Fatalf("static call failed: %v", err)
+	}
+	if logErr != errWriteProtection {
+		t.Errorf("static log error mismatch: have %v, want %v", logErr, errWriteProtection)
+	}
+	if logs := statedb.GetLogs(common.HexToHash("0xabcd")); len(logs) != 1 {
+		t.Errorf("log count mismatch after static call: have %d, want 1", len(logs))
+	}
+}
+
+func TestPrecompileEnvironmentContext(t *testing.T) {
+	precompile := common.HexToAddress("0x0100000000000000000000000000000000000000")
+
+	var env *PrecompileEnvironment
+	defer installPrecompile(precompile, &statefulPrecompile{
+		run: func(e *PrecompileEnvironment, input []byte) ([]byte, error) {
+			env = e
+			return nil, nil
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	evm.Context.BlockNumber = big.NewInt(7)
+	evm.Context.Time = big.NewInt(1234)
+	evm.Context.Coinbase = common.HexToAddress("0xc0")
+	evm.Context.GasPrice = big.NewInt(25)
+	evm.Context.Origin = common.HexToAddress("0x0a")
+
+	if _, _, err := evm.Call(AccountRef(common.Address{}), precompile, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if have := env.BlockNumber(); have.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("block number mismatch: have %v, want 7", have)
+	}
+	if have := env.Time(); have.Cmp(big.NewInt(1234)) != 0 {
+		t.Errorf("time mismatch: have %v, want 1234", have)
+	}
+	if have := env.Coinbase(); have != common.HexToAddress("0xc0") {
+		t.Errorf("coinbase mismatch: have %x", have)
+	}
+	if have := env.GasPrice(); have.Cmp(big.NewInt(25)) != 0 {
+		t.Errorf("gas price mismatch: have %v, want 25", have)
+	}
+	if have := env.Origin(); have != common.HexToAddress("0x0a") {
+		t.Errorf("origin mismatch: have %x", have)
+	}
+	if have := env.ChainConfig(); have != params.AllEthashProtocolChanges {
+		t.Errorf("chain config mismatch: have %v", have)
+	}
+	if rules := env.Rules(); !rules.IsByzantium || rules.IsIstanbul {
+		t.Errorf("rules mismatch: have %+v", rules)
+	}
+	// Returned values must not alias the EVM context
+	env.BlockNumber().SetUint64(8)
+	if evm.Context.BlockNumber.Uint64() != 7 {
+		t.Errorf("block number modified through environment")
+	}
+}
+
+func TestPrecompileEnvironmentUseGas(t *testing.T) {
+	precompile := common.HexToAddress("0x0100000000000000000000000000000000000000")
+
+	defer installPrecompile(precompile, &statefulPrecompile{
+		gas: 100,
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			// Charge 1000 gas per input byte on top of the static cost
+			for range input {
+				if !env.UseGas(1000) {
+					return nil, ErrOutOfGas
+				}
+			}
+			return nil, nil
+		},
+	})()
+
+	tests := []struct {
+		input    []byte
+		gas      uint64
+		leftOver uint64
+		err      error
+	}{
+		{nil, 5000, 4900, nil},
+		{[]byte{1, 2, 3}, 5000, 1900, nil},
+		{[]byte{1, 2, 3, 4, 5}, 5000, 0, ErrOutOfGas},
+	}
+	for i, tt := range tests {
+		evm := newStatefulTestEVM()
+		_, leftOver, err := evm.Call(AccountRef(common.Address{}), precompile, tt.input, tt.gas, new(big.Int))
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		if leftOver != tt.leftOver {
+			t.Errorf("test %d: left over gas mismatch: have %d, want %d", i, leftOver, tt.leftOver)
+		}
+	}
+}
+
+func TestPrecompileEnvironmentSnapshot(t *testing.T) {
+	precompile := common.HexToAddress("0x0100000000000000000000000000000000000000")
+
+	defer installPrecompile(precompile, &statefulPrecompile{
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			db := env.StateDB()
+			db.SetState(env.Self(), common.HexToHash("0x01"), common.HexToHash("0x01"))
+
+			snap := env.Snapshot()
+			db.SetState(env.Self(), common.HexToHash("0x01"), common.HexToHash("0x02"))
+			db.SetState(env.Self(), common.HexToHash("0x02"), common.HexToHash("0x02"))
+			env.RevertToSnapshot(snap)
+
+			return nil, nil
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	if _, _, err := evm.Call(AccountRef(common.Address{}), precompile, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if have := evm.StateDB.GetState(precompile, common.HexToHash("0x01")); have != common.HexToHash("0x01") {
+		t.Errorf("slot 1 mismatch: have %x, want %x", have, common.HexToHash("0x01"))
+	}
+	if have := evm.StateDB.GetState(precompile, common.HexToHash("0x02")); have != (common.Hash{}) {
+		t.Errorf("slot 2 mismatch: have %x, want empty", have)
+	}
+}
+
+func TestPrecompileEnvironmentSnapshotOutside(t *testing.T) {
+	precompile := common.HexToAddress("0x0100000000000000000000000000000000000000")
+
+	var snap int
+	defer installPrecompile(precompile, &statefulPrecompile{
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			env.RevertToSnapshot(snap)
+			return nil, nil
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	snap = evm.StateDB.Snapshot()
+	evm.StateDB.SetState(precompile, common.HexToHash("0x01"), common.HexToHash("0x01"))
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("revert to revision outside of invocation accepted")
+		}
+		if have := evm.StateDB.GetState(precompile, common.HexToHash("0x01")); have != common.HexToHash("0x01") {
+			t.Errorf("slot 1 mismatch: have %x, want %x", have, common.HexToHash("0x01"))
+		}
+	}()
+	evm.Call(AccountRef(common.Address{}), precompile, nil, 100000, new(big.Int))
+}
+
+func TestPrecompileEnvironmentValue(t *testing.T) {
+	precompile := common.HexToAddress("0x0100000000000000000000000000000000000000")
+
+	var value *big.Int
+	defer installPrecompile(precompile, &statefulPrecompile{
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			value = env.Value()
+			return nil, nil
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	if _, _, err := evm.Call(AccountRef(common.Address{}), precompile, nil, 100000, big.NewInt(42)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if value.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("value mismatch: have %v, want 42", value)
+	}
+	if _, _, err := evm.StaticCall(AccountRef(common.Address{}), precompile, nil, 100000); err != nil {
+		t.Fatalf("static call failed: %v", err)
+	}
+	if value.Sign() != 0 {
+		t.Errorf("static call value mismatch: have %v, want 0", value)
+	}
+}
+
+func TestPrecompileEnvironmentRunPrecompile(t *testing.T) {
+	var (
+		precompile = common.HexToAddress("0x0100000000000000000000000000000000000000")
+		sha256     = common.BytesToAddress([]byte{2})
+		missing    = common.BytesToAddress([]byte{0xff})
+		missingErr error
+	)
+	defer installPrecompile(precompile, &statefulPrecompile{
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			_, missingErr = env.RunPrecompile(missing, input)
+			return env.RunPrecompile(sha256, input)
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	ret, leftOver, err := evm.Call(AccountRef(common.Address{}), precompile, []byte("hello"), 100000, new(big.Int))
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	want := hexutil.MustDecode("0x2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")
+	if !bytes.Equal(ret, want) {
+		t.Errorf("return data mismatch: have %x, want %x", ret, want)
+	}
+	if used, want := 100000-leftOver, (&sha256hash{}).RequiredGas([]byte("hello")); used != want {
+		t.Errorf("gas used mismatch: have %d, want %d", used, want)
+	}
+	if missingErr != ErrNoPrecompiledContract {
+		t.Errorf("missing precompile error mismatch: have %v, want %v", missingErr, ErrNoPrecompiledContract)
+	}
+}
+
+func TestPrecompileEnvironmentBlockHash(t *testing.T) {
+	precompile := common.HexToAddress("0x0100000000000000000000000000000000000000")
+
+	var env *PrecompileEnvironment
+	defer installPrecompile(precompile, &statefulPrecompile{
+		run: func(e *PrecompileEnvironment, input []byte) ([]byte, error) {
+			env = e
+			return nil, nil
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	evm.Context.BlockNumber = big.NewInt(1000)
+	evm.Context.GetHash = func(n uint64) common.Hash {
+		return common.BigToHash(new(big.Int).SetUint64(n + 1))
+	}
+	if _, _, err := evm.Call(AccountRef(common.Address{}), precompile, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	tests := []struct {
+		number uint64
+		hash   common.Hash
+	}{
+		{0, common.Hash{}},
+		{743, common.Hash{}},
+		{744, common.BigToHash(big.NewInt(745))},
+		{999, common.BigToHash(big.NewInt(1000))},
+		{1000, common.Hash{}},
+		{1001, common.Hash{}},
+	}
+	for _, tt := range tests {
+		if have := env.BlockHash(tt.number); have != tt.hash {
+			t.Errorf("block %d: hash mismatch: have %x, want %x", tt.number, have, tt.hash)
+		}
+	}
+}
+
+// dynamicPrecompile is a test precompile charging 5000 gas for every storage
+// slot addressed by an input byte that is already set.
+type dynamicPrecompile struct{}
+
+func (p *dynamicPrecompile) RequiredGas(input []byte) uint64  { return 100 }
+func (p *dynamicPrecompile) Run(input []byte) ([]byte, error) { return nil, nil }
+
+func (p *dynamicPrecompile) RunDynamic(env *PrecompileEnvironment, input []byte) ([]byte, uint64, error) {
+	var gas uint64
+	for _, b := range input {
+		if env.ReadOnlyState().GetState(env.Self(), common.BytesToHash([]byte{b})) != (common.Hash{}) {
+			gas += 5000
+		}
+	}
+	env.StateDB().SetState(env.Self(), common.Hash{}, common.HexToHash("0x01"))
+	return nil, gas, nil
+}
+
+func TestDynamicGasPrecompile(t *testing.T) {
+	precompile := common.HexToAddress("0x0100000000000000000000000000000000000000")
+	defer installPrecompile(precompile, &dynamicPrecompile{})()
+
+	tests := []struct {
+		input    []byte
+		gas      uint64
+		leftOver uint64
+		err      error
+	}{
+		{[]byte{1, 2}, 20000, 14900, nil},
+		{[]byte{1, 2, 3}, 20000, 9900, nil},
+		{[]byte{1, 2, 3}, 10000, 0, ErrOutOfGas},
+	}
+	for i, tt := range tests {
+		evm := newStatefulTestEVM()
+		evm.StateDB.SetState(precompile, common.BytesToHash([]byte{3}), common.HexToHash("0x01"))
+		evm.StateDB.SetState(precompile, common.BytesToHash([]byte{4}), common.HexToHash("0x01"))
+
+		_, leftOver, err := evm.Call(AccountRef(common.Address{}), precompile, append(tt.input, 4), tt.gas, new(big.Int))
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		if leftOver != tt.leftOver {
+			t.Errorf("test %d: left over gas mismatch: have %d, want %d", i, leftOver, tt.leftOver)
+		}
+		// Running out of gas must revert the changes made by the contract
+		if written := evm.StateDB.GetState(precompile, common.Hash{}) != (common.Hash{}); written != (err == nil) {
+			t.Errorf("test %d: state written: %v, error: %v", i, written, err)
+		}
+	}
+}
+
+func TestPrecompileEnvironmentRevert(t *testing.T) {
+	precompile := common.HexToAddress("0x0100000000000000000000000000000000000000")
+	defer installPrecompile(precompile, &statefulPrecompile{
+		gas: 100,
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			env.StateDB().SetState(env.Self(), common.Hash{}, common.Hash{1})
+			env.UseGas(1000)
+			return env.RevertWithReason("Hello")
+		},
+	})()
+
+	evm := newStatefulTestEVM()
+	ret, gas, err := evm.Call(AccountRef(common.Address{}), precompile, nil, 100000, new(big.Int))
+	if !IsRevert(err) {
+		t.Fatalf("error mismatch: have %v, want revert", err)
+	}
+	// Solidity's encoding of revert("Hello")
+	want := hexutil.MustDecode("0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000005" +
+		"48656c6c6f000000000000000000000000000000000000000000000000000000")
+	if !bytes.Equal(ret, want) {
+		t.Errorf("revert data mismatch: have %x, want %x", ret, want)
+	}
+	if gas != 100000-100-1000 {
+		t.Errorf("leftover gas mismatch: have %d, want %d", gas, 100000-100-1000)
+	}
+	if state := evm.StateDB.GetState(precompile, common.Hash{}); state != (common.Hash{}) {
+		t.Errorf("state change not reverted: have %x", state)
+	}
+}
+
+// refusingPrecompile is a stateful test precompile refusing delegate calls.
+type refusingPrecompile struct {
+	statefulPrecompile
+}
+
+func (p *refusingPrecompile) RefuseDelegateCall() bool { return true }
+
+func TestPrecompileEnvironmentDelegateCall(t *testing.T) {
+	var (
+		precompile = common.HexToAddress("0x0100000000000000000000000000000000000000")
+		refusing   = common.HexToAddress("0x0100000000000000000000000000000000000001")
+		proxy      = common.HexToAddress("0xc0ffee01")
+		caller     = common.HexToAddress("0xca11e7")
+
+		callType           OpCode
+		self, from, parent common.Address
+	)
+	run := func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+		callType, self, from, parent = env.CallType(), env.Self(), env.Caller(), env.CodeAddress()
+		return nil, nil
+	}
+	defer installPrecompile(precompile, &statefulPrecompile{run: run})()
+	defer installPrecompile(refusing, &refusingPrecompile{statefulPrecompile{run: run}})()
+
+	evm := newStatefulTestEVM()
+	if _, _, err := evm.Call(AccountRef(caller), precompile, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if callType != CALL || self != precompile || from != caller || parent != precompile {
+		t.Errorf("call environment mismatch: %v, self %x, caller %x, code %x", callType, self, from, parent)
+	}
+	delegator := NewContract(AccountRef(caller), AccountRef(proxy), new(big.Int), 100000)
+	if _, _, err := evm.DelegateCall(delegator, precompile, nil, 100000); err != nil {
+		t.Fatalf("delegate call failed: %v", err)
+	}
+	if callType != DELEGATECALL || self != proxy || from != caller || parent != precompile {
+		t.Errorf("delegate call environment mismatch: %v, self %x, caller %x, code %x", callType, self, from, parent)
+	}
+	if _, _, err := evm.StaticCall(AccountRef(caller), refusing, nil, 100000); err != nil || callType != STATICCALL {
+		t.Errorf("static call mismatch: have %v (%v)", err, callType)
+	}
+	if _, gas, err := evm.DelegateCall(delegator, refusing, nil, 100000); err != ErrDelegateCallRefused || gas != 0 {
+		t.Errorf("refused delegate call mismatch: have %v (gas %d), want %v", err, gas, ErrDelegateCallRefused)
+	}
+}
+
+// frameTracer is a test tracer recording the calls made by precompiles.
+type frameTracer struct {
+	*StructLogger
+	frames []string
+}
+
+func (t *frameTracer) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	t.frames = append(t.frames, fmt.Sprintf("enter %v %x->%x gas=%d", typ, from, to, gas))
+	return nil
+}
+
+func (t *frameTracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	t.frames = append(t.frames, fmt.Sprintf("exit %x used=%d err=%v", output, gasUsed, err))
+	return nil
+}
+
+func TestPrecompileEnvironmentCallTracing(t *testing.T) {
+	var (
+		precompile = common.HexToAddress("0x0100000000000000000000000000000000000000")
+		target     = common.HexToAddress("0xc0ffee")
+	)
+	defer installPrecompile(precompile, &statefulPrecompile{
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			return env.Call(target, input, 50000, nil)
+		},
+	})()
+	tracer := &frameTracer{StructLogger: NewStructLogger(nil)}
+
+	evm := newStatefulTestEVM()
+	evm = NewEVM(evm.Context, evm.StateDB, evm.chainConfig, Config{Debug: true, Tracer: tracer})
+	// PUSH1 0x2a, PUSH1 0, MSTORE8, PUSH1 1, PUSH1 0, RETURN
+	evm.StateDB.SetCode(target, hexutil.MustDecode("0x602a60005360016000f3"))
+
+	if _, _, err := evm.Call(AccountRef(common.Address{}), precompile, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	want := []string{
+		fmt.Sprintf("enter CALL %x->%x gas=50000", precompile, target),
+		"exit 2a used=18 err=<nil>",
+	}
+	if !reflect.DeepEqual(tracer.frames, want) {
+		t.Errorf("traced frames mismatch: have %v, want %v", tracer.frames, want)
+	}
+	if len(tracer.StructLogs()) != 6 {
+		t.Errorf("nested call steps not traced: have %d, want 6", len(tracer.StructLogs()))
+	}
+}
+
+// stateTracer is a test tracer recording the state accessed by precompiles,
+// along with the storage values preceding the access.
+type stateTracer struct {
+	*StructLogger
+	accesses []string
+}
+
+func (t *stateTracer) CaptureStateAccess(env *EVM, addr common.Address, key *common.Hash) error {
+	if key == nil {
+		t.accesses = append(t.accesses, fmt.Sprintf("account %x", addr))
+	} else {
+		t.accesses = append(t.accesses, fmt.Sprintf("storage %x %x=%x", addr, *key, env.StateDB.GetState(addr, *key)))
+	}
+	return nil
+}
+
+func TestPrecompileEnvironmentStateTracing(t *testing.T) {
+	var (
+		precompile = common.HexToAddress("0x0100000000000000000000000000000000000000")
+		target     = common.HexToAddress("0xc0ffee01")
+		slot       = common.Hash{1}
+	)
+	defer installPrecompile(precompile, &statefulPrecompile{
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			env.ReadOnlyState().GetBalance(target)
+			env.StateDB().SetState(env.Self(), slot, common.Hash{0x2a})
+			env.StateDB().SetState(env.Self(), slot, common.Hash{0x2b})
+			return nil, nil
+		},
+	})()
+	tracer := &stateTracer{StructLogger: NewStructLogger(nil)}
+
+	evm := newStatefulTestEVM()
+	evm = NewEVM(evm.Context, evm.StateDB, evm.chainConfig, Config{Debug: true, Tracer: tracer})
+	if _, _, err := evm.Call(AccountRef(common.Address{}), precompile, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	want := []string{
+		fmt.Sprintf("account %x", target),
+		fmt.Sprintf("storage %x %x=%x", precompile, slot, common.Hash{}),
+		fmt.Sprintf("storage %x %x=%x", precompile, slot, common.Hash{0x2a}),
+	}
+	if !reflect.DeepEqual(tracer.accesses, want) {
+		t.Errorf("traced accesses mismatch: have %v, want %v", tracer.accesses, want)
+	}
+}
+
+func TestStateReader(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	addr := common.Address{1}
+	statedb.SetNonce(addr, 3)
+	statedb.SetState(addr, common.Hash{1}, common.Hash{2})
+	statedb.SetState(addr, common.Hash{3}, common.Hash{4})
+	statedb.Commit(false)
+
+	reader := NewStateReader(statedb)
+	if _, ok := reader.(interface {
+		SetState(common.Address, common.Hash, common.Hash)
+	}); ok {
+		t.Errorf("state reader exposes mutating methods")
+	}
+	if nonce := reader.GetNonce(addr); nonce != 3 {
+		t.Errorf("nonce mismatch: have %d, want 3", nonce)
+	}
+	storage := make(map[common.Hash]common.Hash)
+	err := reader.ForEachStorage(addr, func(key, value common.Hash) bool {
+		storage[key] = value
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[common.Hash]common.Hash{{1}: {2}, {3}: {4}}
+	if !reflect.DeepEqual(storage, want) {
+		t.Errorf("storage mismatch: have %x, want %x", storage, want)
+	}
+}
+
+func TestConcurrentStateReader(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	for i := byte(0); i < 16; i++ {
+		statedb.SetState(common.Address{i}, common.Hash{i}, common.Hash{i + 1})
+	}
+	root, _ := statedb.Commit(false)
+	reader, err := NewConcurrentStateReader(statedb.Database(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		failed = make(chan byte, 16)
+	)
+	for i := byte(0); i < 16; i++ {
+		wg.Add(1)
+		go func(i byte) {
+			defer wg.Done()
+			if !reader.Exist(common.Address{i}) || reader.GetState(common.Address{i}, common.Hash{i}) != (common.Hash{i + 1}) {
+				failed <- i
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(failed)
+	for i := range failed {
+		t.Errorf("account %d: state mismatch", i)
+	}
+}
+
+// barrierDatabase is a state database whose code reads block until the given
+// number of them are in progress at once, or a timeout passes.
+type barrierDatabase struct {
+	state.Database
+
+	parties  int32
+	inside   int32
+	released chan struct{}
+	once     sync.Once
+}
+
+func (db *barrierDatabase) ContractCode(addrHash, codeHash common.Hash) ([]byte, error) {
+	if atomic.AddInt32(&db.inside, 1) == db.parties {
+		db.once.Do(func() { close(db.released) })
+	}
+	defer atomic.AddInt32(&db.inside, -1)
+
+	select {
+	case <-db.released:
+	case <-time.After(time.Second):
+	}
+	return db.Database.ContractCode(addrHash, codeHash)
+}
+
+// overlapped returns whether all parties were inside ContractCode at once.
+func (db *barrierDatabase) overlapped() bool {
+	select {
+	case <-db.released:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestConcurrentStateReaderOverlap(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	statedb.SetCode(common.Address{1}, []byte{0x01})
+	statedb.SetCode(common.Address{2}, []byte{0x02})
+	root, _ := statedb.Commit(false)
+
+	db := &barrierDatabase{
+		Database: statedb.Database(),
+		parties:  2,
+		released: make(chan struct{}),
+	}
+	reader, err := NewConcurrentStateReader(db, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		wg    sync.WaitGroup
+		codes [2][]byte
+	)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = reader.GetCode(common.Address{byte(i + 1)})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		if want := []byte{byte(i + 1)}; !bytes.Equal(code, want) {
+			t.Errorf("account %d: code mismatch: have %x, want %x", i+1, code, want)
+		}
+	}
+	if !db.overlapped() {
+		t.Error("reads were serialised, want them to overlap")
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/types"
+)
+
+// PrecompileStateTracer is a Tracer that is additionally notified of the state
+// accessed by stateful precompiles through their environment. Unlike accesses
+// made by EVM code, these don't show up as opcodes executed. The notification
+// is delivered before the access is performed, so the state still holds the
+// values preceding any modification. The key is nil for accesses to the
+// account itself, e.g. its balance.
+type PrecompileStateTracer interface {
+	Tracer
+	CaptureStateAccess(env *EVM, addr common.Address, key *common.Hash) error
+}
+
+// stateDB returns the state database precompiles access, wrapped to notify the
+// tracer of every access if it is a PrecompileStateTracer.
+func (env *PrecompileEnvironment) stateDB() StateDB {
+	if !env.evm.vmConfig.Debug {
+		return env.evm.StateDB
+	}
+	tracer, ok := env.evm.vmConfig.Tracer.(PrecompileStateTracer)
+	if !ok {
+		return env.evm.StateDB
+	}
+	return &tracedStateDB{StateDB: env.evm.StateDB, evm: env.evm, tracer: tracer}
+}
+
+// tracedStateDB notifies a PrecompileStateTracer of the accounts and storage
+// slots accessed through it.
+type tracedStateDB struct {
+	StateDB
+	evm    *EVM
+	tracer PrecompileStateTracer
+}
+
+func (s *tracedStateDB) account(addr common.Address) {
+	s.tracer.CaptureStateAccess(s.evm, addr, nil)
+}
+
+func (s *tracedStateDB) storage(addr common.Address, key common.Hash) {
+	s.tracer.CaptureStateAccess(s.evm, addr, &key)
+}
+
+func (s *tracedStateDB) CreateAccount(addr common.Address) {
+	s.account(addr)
+	s.StateDB.CreateAccount(addr)
+}
+
+func (s *tracedStateDB) SubBalance(addr common.Address, amount *big.Int) {
+	s.account(addr)
+	s.StateDB.SubBalance(addr, amount)
+}
+
+func (s *tracedStateDB) AddBalance(addr common.Address, amount *big.Int) {
+	s.account(addr)
+	s.StateDB.AddBalance(addr, amount)
+}
+
+func (s *tracedStateDB) SubBalanceWithReason(addr common.Address, amount *big.Int, reason types.BalanceChangeReason) {
+	s.account(addr)
+	s.StateDB.SubBalanceWithReason(addr, amount, reason)
+}
+
+func (s *tracedStateDB) AddBalanceWithReason(addr common.Address, amount *big.Int, reason types.BalanceChangeReason) {
+	s.account(addr)
+	s.StateDB.AddBalanceWithReason(addr, amount, reason)
+}
+
+func (s *tracedStateDB) GetBalance(addr common.Address) *big.Int {
+	s.account(addr)
+	return s.StateDB.GetBalance(addr)
+}
+
+func (s *tracedStateDB) GetNonce(addr common.Address) uint64 {
+	s.account(addr)
+	return s.StateDB.GetNonce(addr)
+}
+
+func (s *tracedStateDB) SetNonce(addr common.Address, nonce uint64) {
+	s.account(addr)
+	s.StateDB.SetNonce(addr, nonce)
+}
+
+func (s *tracedStateDB) GetCodeHash(addr common.Address) common.Hash {
+	s.account(addr)
+	return s.StateDB.GetCodeHash(addr)
+}
+
+func (s *tracedStateDB) GetCode(addr common.Address) []byte {
+	s.account(addr)
+	return s.StateDB.GetCode(addr)
+}
+
+func (s *tracedStateDB) SetCode(addr common.Address, code []byte) {
+	s.account(addr)
+	s.StateDB.SetCode(addr, code)
+}
+
+func (s *tracedStateDB) GetCodeSize(addr common.Address) int {
+	s.account(addr)
+	return s.StateDB.GetCodeSize(addr)
+}
+
+func (s *tracedStateDB) GetCommittedState(addr common.Address, key common.Hash) common.Hash {
+	s.storage(addr, key)
+	return s.StateDB.GetCommittedState(addr, key)
+}
+
+func (s *tracedStateDB) GetState(addr common.Address, key common.Hash) common.Hash {
+	s.storage(addr, key)
+	return s.StateDB.GetState(addr, key)
+}
+
+func (s *tracedStateDB) SetState(addr common.Address, key common.Hash, value common.Hash) {
+	s.storage(addr, key)
+	s.StateDB.SetState(addr, key, value)
+}
+
+func (s *tracedStateDB) Suicide(addr common.Address) bool {
+	s.account(addr)
+	return s.StateDB.Suicide(addr)
+}
+
+func (s *tracedStateDB) HasSuicided(addr common.Address) bool {
+	s.account(addr)
+	return s.StateDB.HasSuicided(addr)
+}
+
+func (s *tracedStateDB) Exist(addr common.Address) bool {
+	s.account(addr)
+	return s.StateDB.Exist(addr)
+}
+
+func (s *tracedStateDB) Empty(addr common.Address) bool {
+	s.account(addr)
+	return s.StateDB.Empty(addr)
+}
+
+func (s *tracedStateDB) ForEachStorage(addr common.Address, cb func(common.Hash, common.Hash) bool) error {
+	s.account(addr)
+	return s.StateDB.ForEachStorage(addr, cb)
+}
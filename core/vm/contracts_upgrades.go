@@ -0,0 +1,142 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/log"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+// PrecompileModule creates a precompiled contract from the config of a scheduled
+// upgrade (see params.PrecompileUpgrade), rejecting invalid configs. A module
+// must be deterministic: the same config must always yield an equivalent
+// contract.
+type PrecompileModule func(config json.RawMessage) (PrecompiledContract, error)
+
+// CodeReporter is implemented by precompiles configured by a PrecompileModule
+// that opt into reporting non-empty code to the EXTCODESIZE, EXTCODECOPY and
+// EXTCODEHASH opcodes, like RegisteredPrecompile.Code. The code must not change
+// and is never executed.
+type CodeReporter interface {
+	ReportedCode() []byte
+}
+
+// precompileModules contains the registered precompile modules by address. It
+// is only modified during initialisation, so no locking is needed for lookups.
+var precompileModules = make(map[common.Address]PrecompileModule)
+
+// configuredPrecompiles caches the contracts created by the precompile modules,
+// keyed by the upgrade they were configured from. Keying by the contents of the
+// upgrade rather than its address in memory lets reloaded chain configs share
+// the contracts, keeping the cache bounded by the distinct upgrades scheduled.
+var configuredPrecompiles sync.Map // map[upgradeKey]PrecompiledContract
+
+// upgradeKey identifies an enabling precompile upgrade by its contents.
+type upgradeKey struct {
+	addr   common.Address
+	time   uint64
+	config string
+}
+
+// RegisterPrecompileModule makes a precompile available for scheduling through
+// the PrecompileUpgrades of a chain config. Whenever an upgrade is in effect
+// at the address, the contract configured from it takes precedence over every
+// other precompile at the address; a disabling upgrade leaves no precompile
+// active at all.
+//
+// RegisterPrecompileModule is not safe for concurrent use and must be called
+// during initialisation, before any EVM is created. It panics if a module is
+// already registered at the address.
+func RegisterPrecompileModule(addr common.Address, module PrecompileModule) {
+	if _, ok := precompileModules[addr]; ok {
+		panic(fmt.Sprintf("vm: precompile module at %x already registered", addr))
+	}
+	precompileModules[addr] = module
+}
+
+// CheckPrecompileUpgrades verifies that the precompile upgrades scheduled by a
+// chain config are well formed, target registered modules and carry configs
+// accepted by them.
+func CheckPrecompileUpgrades(config *params.ChainConfig) error {
+	if err := config.CheckPrecompileUpgrades(); err != nil {
+		return err
+	}
+	for i := range config.PrecompileUpgrades {
+		upgrade := &config.PrecompileUpgrades[i]
+		if upgrade.Disable {
+			continue
+		}
+		module, ok := precompileModules[upgrade.Address]
+		if !ok {
+			return fmt.Errorf("precompile upgrade %d: no precompile module at %x", i, upgrade.Address)
+		}
+		if _, err := module(upgrade.Config); err != nil {
+			return fmt.Errorf("precompile upgrade %d: invalid config for %x: %v", i, upgrade.Address, err)
+		}
+	}
+	return nil
+}
+
+// ConfigurePrecompile creates a contract from the given config using the
+// precompile module registered at the given address, like a precompile upgrade
+// would, e.g. to override the precompile with EVM.OverridePrecompile.
+func ConfigurePrecompile(addr common.Address, config json.RawMessage) (PrecompiledContract, error) {
+	module, ok := precompileModules[addr]
+	if !ok {
+		return nil, fmt.Errorf("no precompile module at %x", addr)
+	}
+	p, err := module(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config for %x: %v", addr, err)
+	}
+	return p, nil
+}
+
+// upgradedPrecompile returns the contract configured by the precompile upgrade
+// in effect at the given address under the given chain rules, if any. The
+// boolean reports whether an upgrade is in effect, even if it disabled the
+// precompile.
+func upgradedPrecompile(rules params.Rules, addr common.Address) (PrecompiledContract, bool) {
+	upgrade, ok := rules.PrecompileUpgrades[addr]
+	if !ok {
+		return nil, false
+	}
+	if upgrade.Disable {
+		return nil, true
+	}
+	key := upgradeKey{addr: upgrade.Address, time: upgrade.Timestamp, config: string(upgrade.Config)}
+	if p, ok := configuredPrecompiles.Load(key); ok {
+		return p.(PrecompiledContract), true
+	}
+	module, ok := precompileModules[upgrade.Address]
+	if !ok {
+		log.Error("Scheduled precompile without module", "address", addr)
+		return nil, true
+	}
+	p, err := module(upgrade.Config)
+	if err != nil {
+		log.Error("Invalid scheduled precompile config", "address", addr, "err", err)
+		return nil, true
+	}
+	configuredPrecompiles.Store(key, p)
+	return p, true
+}
@@ -0,0 +1,210 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/core/state"
+	"github.com/ava-labs/go-ethereum/crypto"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+func TestScheduledPrecompileUpgrades(t *testing.T) {
+	addr := common.HexToAddress("0x0200000000000000000000000000000000000000")
+	RegisterPrecompileModule(addr, func(config json.RawMessage) (PrecompiledContract, error) {
+		var gas uint64
+		if err := json.Unmarshal(config, &gas); err != nil {
+			return nil, err
+		}
+		return &statefulPrecompile{gas: gas}, nil
+	})
+	defer delete(precompileModules, addr)
+
+	config := *params.AllEthashProtocolChanges
+	config.PrecompileUpgrades = []params.PrecompileUpgrade{
+		{Address: addr, Timestamp: 10, Config: json.RawMessage(`1`)},
+		{Address: addr, Timestamp: 20, Config: json.RawMessage(`2`)},
+		{Address: addr, Timestamp: 30, Disable: true},
+	}
+	if err := CheckPrecompileUpgrades(&config); err != nil {
+		t.Fatalf("valid schedule rejected: %v", err)
+	}
+	tests := []struct {
+		time uint64
+		gas  uint64 // 0 = inactive
+	}{
+		{9, 0}, {10, 1}, {19, 1}, {20, 2}, {29, 2}, {30, 0},
+	}
+	for _, tt := range tests {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+		evm := NewEVM(Context{BlockNumber: new(big.Int), Time: new(big.Int).SetUint64(tt.time)}, statedb, &config, Config{})
+
+		p, ok := evm.precompile(addr)
+		if ok != (tt.gas != 0) {
+			t.Errorf("time %d: activity mismatch: have %v, want %v", tt.time, ok, tt.gas != 0)
+			continue
+		}
+		if ok && p.RequiredGas(nil) != tt.gas {
+			t.Errorf("time %d: config mismatch: have gas %d, want %d", tt.time, p.RequiredGas(nil), tt.gas)
+		}
+	}
+	// Reloaded configs must share the configured contracts
+	reloaded := config
+	reloaded.PrecompileUpgrades = append([]params.PrecompileUpgrade(nil), config.PrecompileUpgrades...)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	original, _ := NewEVM(Context{BlockNumber: new(big.Int), Time: big.NewInt(10)}, statedb, &config, Config{}).precompile(addr)
+	shared, _ := NewEVM(Context{BlockNumber: new(big.Int), Time: big.NewInt(10)}, statedb, &reloaded, Config{}).precompile(addr)
+	if original != shared {
+		t.Errorf("reloaded config configured a new contract")
+	}
+	var cached int
+	configuredPrecompiles.Range(func(key, value interface{}) bool {
+		if key.(upgradeKey).addr == addr {
+			cached++
+		}
+		return true
+	})
+	if cached != 2 {
+		t.Errorf("cached contract count mismatch: have %d, want 2", cached)
+	}
+	// Configs rejected by the module fail validation
+	config.PrecompileUpgrades = []params.PrecompileUpgrade{{Address: addr, Config: json.RawMessage(`"invalid"`)}}
+	if err := CheckPrecompileUpgrades(&config); err == nil {
+		t.Errorf("invalid config accepted")
+	}
+	config.PrecompileUpgrades = []params.PrecompileUpgrade{{Address: common.HexToAddress("0x03")}}
+	if err := CheckPrecompileUpgrades(&config); err == nil {
+		t.Errorf("upgrade without module accepted")
+	}
+}
+
+func TestOverridePrecompile(t *testing.T) {
+	addr := common.HexToAddress("0x0200000000000000000000000000000000000000")
+	RegisterPrecompileModule(addr, func(config json.RawMessage) (PrecompiledContract, error) {
+		var gas uint64
+		if err := json.Unmarshal(config, &gas); err != nil {
+			return nil, err
+		}
+		return &statefulPrecompile{gas: gas}, nil
+	})
+	defer delete(precompileModules, addr)
+
+	config := *params.AllEthashProtocolChanges
+	config.PrecompileUpgrades = []params.PrecompileUpgrade{{Address: addr, Config: json.RawMessage(`1`)}}
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	evm := NewEVM(Context{BlockNumber: new(big.Int), Time: new(big.Int)}, statedb, &config, Config{})
+
+	// Overrides take precedence over scheduled upgrades
+	p, err := ConfigurePrecompile(addr, json.RawMessage(`5`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	evm.OverridePrecompile(addr, p)
+	if p, ok := evm.precompile(addr); !ok || p.RequiredGas(nil) != 5 {
+		t.Errorf("overridden precompile mismatch: have %v, %v", p, ok)
+	}
+	evm.OverridePrecompile(addr, nil)
+	if _, ok := evm.precompile(addr); ok {
+		t.Errorf("disabled precompile still active")
+	}
+	for _, active := range evm.ActivePrecompiles() {
+		if active == addr {
+			t.Errorf("disabled precompile reported active")
+		}
+	}
+	// Overrides must not leak into other EVMs
+	evm = NewEVM(Context{BlockNumber: new(big.Int), Time: new(big.Int)}, statedb, &config, Config{})
+	if p, ok := evm.precompile(addr); !ok || p.RequiredGas(nil) != 1 {
+		t.Errorf("scheduled precompile mismatch: have %v, %v", p, ok)
+	}
+	// Invalid configs and unknown modules are rejected
+	if _, err := ConfigurePrecompile(addr, json.RawMessage(`"invalid"`)); err == nil {
+		t.Errorf("invalid config accepted")
+	}
+	if _, err := ConfigurePrecompile(common.Address{3}, nil); err == nil {
+		t.Errorf("config without module accepted")
+	}
+}
+
+// codeReportingPrecompile is a test precompile reporting code.
+type codeReportingPrecompile struct {
+	statefulPrecompile
+	code []byte
+}
+
+// ReportedCode implements CodeReporter.
+func (p *codeReportingPrecompile) ReportedCode() []byte {
+	return p.code
+}
+
+func TestScheduledPrecompileCode(t *testing.T) {
+	addr := common.HexToAddress("0x0200000000000000000000000000000000000000")
+	RegisterPrecompileModule(addr, func(config json.RawMessage) (PrecompiledContract, error) {
+		var code hexutil.Bytes
+		if err := json.Unmarshal(config, &code); err != nil {
+			return nil, err
+		}
+		return &codeReportingPrecompile{code: code}, nil
+	})
+	defer delete(precompileModules, addr)
+
+	RegisterPrecompile(RegisteredPrecompile{Address: addr, Contract: &statefulPrecompile{}, Code: []byte{0xfe}})
+	defer unregisterPrecompiles()
+
+	config := *params.AllEthashProtocolChanges
+	config.PrecompileUpgrades = []params.PrecompileUpgrade{
+		{Address: addr, Timestamp: 10, Config: json.RawMessage(`"0xfd01"`)},
+		{Address: addr, Timestamp: 20, Config: json.RawMessage(`"0x"`)},
+		{Address: addr, Timestamp: 30, Disable: true},
+	}
+	tests := []struct {
+		time uint64
+		code []byte
+	}{
+		{9, []byte{0xfe}},        // Registered precompile
+		{10, []byte{0xfd, 0x01}}, // Upgraded precompile reporting code
+		{20, nil},                // Upgraded precompile reporting none
+		{30, nil},                // Disabled precompile
+	}
+	for _, tt := range tests {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+		evm := NewEVM(Context{BlockNumber: new(big.Int), Time: new(big.Int).SetUint64(tt.time)}, statedb, &config, Config{})
+
+		if size := evm.codeSize(addr); size != len(tt.code) {
+			t.Errorf("time %d: code size mismatch: have %d, want %d", tt.time, size, len(tt.code))
+		}
+		if code := evm.code(addr); !bytes.Equal(code, tt.code) {
+			t.Errorf("time %d: code mismatch: have %x, want %x", tt.time, code, tt.code)
+		}
+		want := common.Hash{}
+		if len(tt.code) > 0 {
+			want = crypto.Keccak256Hash(tt.code)
+		}
+		if hash := evm.codeHash(addr); hash != want {
+			t.Errorf("time %d: code hash mismatch: have %x, want %x", tt.time, hash, want)
+		}
+	}
+}
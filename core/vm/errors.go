@@ -27,4 +27,14 @@ var (
 	ErrInsufficientBalance      = errors.New("insufficient balance for transfer")
 	ErrContractAddressCollision = errors.New("contract address collision")
 	ErrNoCompatibleInterpreter  = errors.New("no compatible interpreter")
+	ErrTooManyLogTopics         = errors.New("too many log topics")
+	ErrNoPrecompiledContract    = errors.New("no precompiled contract at address")
+	ErrProhibitedAddress        = errors.New("prohibited address")
+	ErrMaxInitCodeSizeExceeded  = errors.New("max initcode size exceeded")
+	ErrCreationAddressRewritten = errors.New("address of top-level creation rewritten")
+	ErrDelegateCallRefused      = errors.New("precompile refuses delegatecall")
+	ErrStorageIndexOutOfRange   = errors.New("precompile storage index out of range")
+	ErrStorageValueOverflow     = errors.New("precompile storage value overflow")
+	ErrTransientStorageDenied   = errors.New("transient storage denied")
+	ErrTransientStorageLimit    = errors.New("transient storage limit reached")
 )
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/types"
 	"github.com/ava-labs/go-ethereum/crypto"
 	"github.com/ava-labs/go-ethereum/params"
 )
@@ -43,15 +44,11 @@ type (
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
 func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, error) {
 	if contract.CodeAddr != nil {
-		precompiles := PrecompiledContractsHomestead
-		if evm.chainRules.IsByzantium {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if evm.chainRules.IsIstanbul {
-			precompiles = PrecompiledContractsIstanbul
-		}
-		if p := precompiles[*contract.CodeAddr]; p != nil {
-			return RunPrecompiledContract(p, input, contract)
+		if p, ok := evm.precompile(*contract.CodeAddr); ok {
+			if evm.hookTracer != nil {
+				evm.tracePrecompile(*contract.CodeAddr, p)
+			}
+			return runPrecompiledContract(evm, p, input, contract, readOnly)
 		}
 	}
 	for _, interpreter := range evm.interpreters {
@@ -70,6 +67,84 @@ func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, err
 	return nil, ErrNoCompatibleInterpreter
 }
 
+// defaultPrecompiles returns the set of Ethereum precompiled contracts active
+// under the current chain rules.
+func (evm *EVM) defaultPrecompiles() map[common.Address]PrecompiledContract {
+	return defaultPrecompilesFor(evm.chainRules)
+}
+
+// defaultPrecompilesFor returns the set of Ethereum precompiled contracts active
+// under the given chain rules.
+func defaultPrecompilesFor(rules params.Rules) map[common.Address]PrecompiledContract {
+	precompiles := PrecompiledContractsHomestead
+	if rules.IsByzantium {
+		precompiles = PrecompiledContractsByzantium
+	}
+	if rules.IsIstanbul {
+		precompiles = PrecompiledContractsIstanbul
+	}
+	return precompiles
+}
+
+// precompile returns the precompiled contract active at the given address in
+// the block being executed, if any.
+func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
+	if p, ok := evm.precompileOverrides[addr]; ok {
+		return p, p != nil
+	}
+	return precompileAt(evm.chainRules, evm.time(), addr)
+}
+
+// OverridePrecompile replaces the precompile active at the given address for
+// the lifetime of the EVM, taking precedence over every other precompile at
+// the address, or disables it if p is nil. It is meant for simulations such as
+// eth_call, e.g. to test administrative operations against live state under a
+// different precompile config, and must never be used for consensus.
+func (evm *EVM) OverridePrecompile(addr common.Address, p PrecompiledContract) {
+	if evm.precompileOverrides == nil {
+		evm.precompileOverrides = make(map[common.Address]PrecompiledContract)
+	}
+	evm.precompileOverrides[addr] = p
+}
+
+// precompileAt returns the precompiled contract active at the given address
+// under the given chain rules and block timestamp, if any. The rules must have
+// been created by RulesAt for the timestamp. See RegisterPrecompileModule and
+// RegisteredPrecompile for the rules of precedence between scheduled,
+// registered and default precompiles.
+func precompileAt(rules params.Rules, time uint64, addr common.Address) (PrecompiledContract, bool) {
+	if p, ok := upgradedPrecompile(rules, addr); ok {
+		return p, p != nil
+	}
+	defaults := defaultPrecompilesFor(rules)
+	if r := registrationAt(addr, time, defaults); r != nil {
+		p := r.contract(defaults)
+		return p, p != nil
+	}
+	p, ok := defaults[addr]
+	return p, ok && p != nil
+}
+
+// ReadOnlyState returns a read-only view of the EVM's state database, e.g. for
+// hooks that must inspect but not modify the state.
+func (evm *EVM) ReadOnlyState() StateReader {
+	return NewStateReader(evm.StateDB)
+}
+
+// time returns the timestamp of the block being executed.
+func (evm *EVM) time() uint64 {
+	return blockTime(evm.Time)
+}
+
+// blockTime converts a block timestamp to uint64, treating unset timestamps as
+// the genesis time.
+func blockTime(time *big.Int) uint64 {
+	if time == nil || !time.IsUint64() {
+		return 0
+	}
+	return time.Uint64()
+}
+
 // Context provides the EVM with auxiliary information. Once provided
 // it shouldn't be modified.
 type Context struct {
@@ -82,8 +157,9 @@ type Context struct {
 	GetHash GetHashFunc
 
 	// Message information
-	Origin   common.Address // Provides information for ORIGIN
-	GasPrice *big.Int       // Provides information for GASPRICE
+	Origin     common.Address   // Provides information for ORIGIN
+	GasPrice   *big.Int         // Provides information for GASPRICE
+	Predicates PredicateResults // Provides the verified predicates of the transaction
 
 	// Block information
 	Coinbase    common.Address // Provides information for COINBASE
@@ -128,6 +204,31 @@ type EVM struct {
 	// available gas is calculated in gasCall* according to the 63/64 rule and later
 	// applied in opCall*.
 	callGasTemp uint64
+	// steps and started track the opcodes executed and the start of execution
+	// for the halting hook; they are only maintained if the hook is set.
+	// halted is the error the hook aborted execution with, failing every frame
+	// once set.
+	steps   uint64
+	started time.Time
+	halted  error
+	// maxCodeSize and maxInitCodeSize are the code size limits under the
+	// chain rules, as adjusted by the rules hooks.
+	maxCodeSize     int
+	maxInitCodeSize int
+	// maxDepth is the call/create depth limit under the chain rules, as
+	// adjusted by the rules hooks.
+	maxDepth int
+	// gasForwarding is the gas forwarding rule set by the rules hooks, if any.
+	gasForwarding func(available uint64) uint64
+	// transientStorage is the transient storage policy set by the rules hooks,
+	// if any, and transientSlots the number of transient storage slots in use.
+	transientStorage *TransientStoragePolicy
+	transientSlots   int
+	// hookTracer is the configured tracer if it also records hook decisions.
+	hookTracer HookTracer
+	// precompileOverrides contains the precompiles set by OverridePrecompile,
+	// with nil disabling the precompile at the address.
+	precompileOverrides map[common.Address]PrecompiledContract
 }
 
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
@@ -138,7 +239,7 @@ func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmCon
 		StateDB:      statedb,
 		vmConfig:     vmConfig,
 		chainConfig:  chainConfig,
-		chainRules:   chainConfig.Rules(ctx.BlockNumber),
+		chainRules:   chainConfig.RulesAt(ctx.BlockNumber, blockTime(ctx.Time)),
 		interpreters: make([]Interpreter, 0, 1),
 	}
 
@@ -160,9 +261,24 @@ func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmCon
 
 	// vmConfig.EVMInterpreter will be used by EVM-C, it won't be checked here
 	// as we always want to have the built-in EVM as the failover option.
+	if tracer, ok := vmConfig.Tracer.(HookTracer); ok && vmConfig.Debug {
+		evm.hookTracer = tracer
+	}
+	evm.maxCodeSize, evm.maxInitCodeSize = maxCodeSizes(evm.chainRules)
+	evm.maxDepth = callCreateDepth(evm.chainRules)
+	if rulesHooks.GasForwarding != nil {
+		evm.gasForwarding = rulesHooks.GasForwarding(evm.chainRules)
+	}
+	if rulesHooks.TransientStorage != nil {
+		evm.transientStorage = rulesHooks.TransientStorage(evm.chainRules)
+	}
+
 	evm.interpreters = append(evm.interpreters, NewEVMInterpreter(evm, vmConfig))
 	evm.interpreter = evm.interpreters[0]
 
+	if evm.hookTracer != nil {
+		evm.traceRulesHooks()
+	}
 	return evm
 }
 
@@ -190,29 +306,41 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	frame, err := evm.beforeCall(CALL, caller, addr, input, gas, value)
+	if frame != nil {
+		gas = frame.Gas
+		defer func() { evm.afterCall(frame, ret, leftOverGas, err) }()
+	}
+	if err != nil {
+		return nil, gas, err
+	}
 
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depth > evm.maxDepth {
 		return nil, gas, ErrDepth
 	}
+	// Fail every frame once the halting hook aborted execution
+	if evm.halted != nil {
+		return nil, 0, evm.halted
+	}
 	// Fail if we're trying to transfer more than the available balance
 	if !evm.Context.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, gas, ErrInsufficientBalance
 	}
+	// Fail if we're trying to strand funds in reserved address space
+	if value.Sign() != 0 && evm.prohibitedRecipient(addr) {
+		return nil, gas, ErrProhibitedAddress
+	}
+	if err := evm.checkTransfer(caller.Address(), addr, value); err != nil {
+		return nil, gas, err
+	}
 
 	var (
 		to       = AccountRef(addr)
 		snapshot = evm.StateDB.Snapshot()
 	)
 	if !evm.StateDB.Exist(addr) {
-		precompiles := PrecompiledContractsHomestead
-		if evm.chainRules.IsByzantium {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if evm.chainRules.IsIstanbul {
-			precompiles = PrecompiledContractsIstanbul
-		}
-		if precompiles[addr] == nil && evm.chainRules.IsEIP158 && value.Sign() == 0 {
+		if _, ok := evm.precompile(addr); !ok && evm.chainRules.IsEIP158 && value.Sign() == 0 {
 			// Calling a non existing account, don't do anything, but ping the tracer
 			if evm.vmConfig.Debug && evm.depth == 0 {
 				evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
@@ -226,6 +354,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	// Initialise a new contract and set the code that is to be used by the EVM.
 	// The contract is a scoped environment for this execution context only.
 	contract := NewContract(caller, to, value, gas)
+	contract.callType = CALL
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
 	// Even if the account has no code, we need to continue because it might be a precompile
@@ -239,14 +368,17 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 			evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
 		}()
 	}
-	ret, err = run(evm, contract, input, false)
+	if err = evm.canExecute(addr); err == nil {
+		ret, err = run(evm, contract, input, false)
+	}
+	err = evm.reverted(frame, ret, err)
 
 	// When an error was returned by the EVM or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in homestead this also counts for code storage gas errors.
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if !IsRevert(err) {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -264,11 +396,23 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	frame, err := evm.beforeCall(CALLCODE, caller, addr, input, gas, value)
+	if frame != nil {
+		gas = frame.Gas
+		defer func() { evm.afterCall(frame, ret, leftOverGas, err) }()
+	}
+	if err != nil {
+		return nil, gas, err
+	}
 
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depth > evm.maxDepth {
 		return nil, gas, ErrDepth
 	}
+	// Fail every frame once the halting hook aborted execution
+	if evm.halted != nil {
+		return nil, 0, evm.halted
+	}
 	// Fail if we're trying to transfer more than the available balance
 	if !evm.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, gas, ErrInsufficientBalance
@@ -281,12 +425,16 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	// Initialise a new contract and set the code that is to be used by the EVM.
 	// The contract is a scoped environment for this execution context only.
 	contract := NewContract(caller, to, value, gas)
+	contract.callType = CALLCODE
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
-	ret, err = run(evm, contract, input, false)
+	if err = evm.canExecute(addr); err == nil {
+		ret, err = run(evm, contract, input, false)
+	}
+	err = evm.reverted(frame, ret, err)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if !IsRevert(err) {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -302,10 +450,22 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	frame, err := evm.beforeCall(DELEGATECALL, caller, addr, input, gas, nil)
+	if frame != nil {
+		gas = frame.Gas
+		defer func() { evm.afterCall(frame, ret, leftOverGas, err) }()
+	}
+	if err != nil {
+		return nil, gas, err
+	}
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depth > evm.maxDepth {
 		return nil, gas, ErrDepth
 	}
+	// Fail every frame once the halting hook aborted execution
+	if evm.halted != nil {
+		return nil, 0, evm.halted
+	}
 
 	var (
 		snapshot = evm.StateDB.Snapshot()
@@ -314,12 +474,16 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 
 	// Initialise a new contract and make initialise the delegate values
 	contract := NewContract(caller, to, nil, gas).AsDelegate()
+	contract.callType = DELEGATECALL
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
-	ret, err = run(evm, contract, input, false)
+	if err = evm.canExecute(addr); err == nil {
+		ret, err = run(evm, contract, input, false)
+	}
+	err = evm.reverted(frame, ret, err)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if !IsRevert(err) {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -334,10 +498,22 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	frame, err := evm.beforeCall(STATICCALL, caller, addr, input, gas, nil)
+	if frame != nil {
+		gas = frame.Gas
+		defer func() { evm.afterCall(frame, ret, leftOverGas, err) }()
+	}
+	if err != nil {
+		return nil, gas, err
+	}
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depth > evm.maxDepth {
 		return nil, gas, ErrDepth
 	}
+	// Fail every frame once the halting hook aborted execution
+	if evm.halted != nil {
+		return nil, 0, evm.halted
+	}
 
 	var (
 		to       = AccountRef(addr)
@@ -346,6 +522,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	// Initialise a new contract and set the code that is to be used by the EVM.
 	// The contract is a scoped environment for this execution context only.
 	contract := NewContract(caller, to, new(big.Int), gas)
+	contract.callType = STATICCALL
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
 	// We do an AddBalance of zero here, just in order to trigger a touch.
@@ -357,10 +534,13 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	// When an error was returned by the EVM or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in Homestead this also counts for code storage gas errors.
-	ret, err = run(evm, contract, input, true)
+	if err = evm.canExecute(addr); err == nil {
+		ret, err = run(evm, contract, input, true)
+	}
+	err = evm.reverted(frame, ret, err)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if !IsRevert(err) {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -379,24 +559,46 @@ func (c *codeAndHash) Hash() common.Hash {
 	return c.hash
 }
 
-// create creates a new contract using code as deployment code.
-func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64, value *big.Int, address common.Address) ([]byte, common.Address, uint64, error) {
+// create creates a new contract using code as deployment code. The type of the
+// creation and the salt of a CREATE2 are passed to the CanCreateContract hook.
+func (evm *EVM) create(typ OpCode, caller ContractRef, codeAndHash *codeAndHash, gas uint64, value *big.Int, salt *big.Int, address common.Address) ([]byte, common.Address, uint64, error) {
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depth > evm.maxDepth {
 		return nil, common.Address{}, gas, ErrDepth
 	}
+	if evm.halted != nil {
+		return nil, common.Address{}, 0, evm.halted
+	}
 	if !evm.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, common.Address{}, gas, ErrInsufficientBalance
 	}
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	evm.StateDB.SetNonce(caller.Address(), nonce+1)
 
+	// Consult the hooks only once the nonce is spent, so that rejected creation
+	// transactions can't be replayed
+	address, err := evm.canCreate(typ, caller, address, codeAndHash.code, salt, gas, value)
+	if err != nil {
+		return nil, common.Address{}, gas, err
+	}
+	if err := evm.checkTransfer(caller.Address(), address, value); err != nil {
+		return nil, common.Address{}, gas, err
+	}
+
 	// Ensure there's no existing contract already at the designated address
 	contractHash := evm.StateDB.GetCodeHash(address)
 	if evm.StateDB.GetNonce(address) != 0 || (contractHash != (common.Hash{}) && contractHash != emptyCodeHash) {
 		return nil, common.Address{}, 0, ErrContractAddressCollision
 	}
+	// Ensure the designated address isn't reserved, e.g. for precompiles
+	if IsProhibited(address) {
+		return nil, common.Address{}, 0, ErrProhibitedAddress
+	}
+	// Ensure the creation code doesn't exceed the limit set by the rules hooks
+	if evm.maxInitCodeSize != 0 && len(codeAndHash.code) > evm.maxInitCodeSize {
+		return nil, common.Address{}, 0, ErrMaxInitCodeSizeExceeded
+	}
 	// Create a new account on the state
 	snapshot := evm.StateDB.Snapshot()
 	evm.StateDB.CreateAccount(address)
@@ -422,13 +624,13 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	ret, err := run(evm, contract, nil, false)
 
 	// check whether the max code size has been exceeded
-	maxCodeSizeExceeded := evm.chainRules.IsEIP158 && len(ret) > params.MaxCodeSize
+	maxCodeSizeExceeded := evm.chainRules.IsEIP158 && len(ret) > evm.maxCodeSize
 	// if the contract creation ran successfully and no errors were returned
 	// calculate the gas required to store the code. If the code could not
 	// be stored due to not enough gas set an error and let it be handled
 	// by the error checking condition below.
 	if err == nil && !maxCodeSizeExceeded {
-		createDataGas := uint64(len(ret)) * params.CreateDataGas
+		createDataGas := evm.codeDepositGas(len(ret))
 		if contract.UseGas(createDataGas) {
 			evm.StateDB.SetCode(address, ret)
 		} else {
@@ -441,7 +643,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	// when we're in homestead this also counts for code storage gas errors.
 	if maxCodeSizeExceeded || (err != nil && (evm.chainRules.IsHomestead || err != ErrCodeStoreOutOfGas)) {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != errExecutionReverted {
+		if !IsRevert(err) {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -458,8 +660,8 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 
 // Create creates a new contract using code as deployment code.
 func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
-	contractAddr = crypto.CreateAddress(caller.Address(), evm.StateDB.GetNonce(caller.Address()))
-	return evm.create(caller, &codeAndHash{code: code}, gas, value, contractAddr)
+	contractAddr = types.CreateAddress(caller.Address(), evm.StateDB.GetNonce(caller.Address()))
+	return evm.create(CREATE, caller, &codeAndHash{code: code}, gas, value, nil, contractAddr)
 }
 
 // Create2 creates a new contract using code as deployment code.
@@ -468,9 +670,13 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 // instead of the usual sender-and-nonce-hash as the address where the contract is initialized at.
 func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *big.Int, salt *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 	codeAndHash := &codeAndHash{code: code}
-	contractAddr = crypto.CreateAddress2(caller.Address(), common.BigToHash(salt), codeAndHash.Hash().Bytes())
-	return evm.create(caller, codeAndHash, gas, endowment, contractAddr)
+	contractAddr = types.CreateAddress2(caller.Address(), common.BigToHash(salt), codeAndHash.Hash().Bytes())
+	return evm.create(CREATE2, caller, codeAndHash, gas, endowment, salt, contractAddr)
 }
 
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
+
+// Rules returns the chain rules in effect for the environment's block. The
+// precompile upgrades must not be modified.
+func (evm *EVM) Rules() params.Rules { return evm.chainRules }
@@ -33,11 +33,12 @@ const (
 // calcGas returns the actual gas cost of the call.
 //
 // The cost of gas was changed during the homestead price change HF.
-// As part of EIP 150 (TangerineWhistle), the returned gas is gas - base * 63 / 64.
-func callGas(isEip150 bool, availableGas, base uint64, callCost *big.Int) (uint64, error) {
-	if isEip150 {
+// As part of EIP 150 (TangerineWhistle), the returned gas is gas - base * 63 / 64,
+// unless the rules hooks define a different gas forwarding rule.
+func callGas(evm *EVM, availableGas, base uint64, callCost *big.Int) (uint64, error) {
+	if evm.chainRules.IsEIP150 {
 		availableGas = availableGas - base
-		gas := availableGas - availableGas/64
+		gas := evm.forwardableGas(availableGas)
 		// If the bit length exceeds 64 bit we know that the newly calculated "gas" for EIP150
 		// is smaller than the requested amount. Therefor we return the new gas instead
 		// of returning an error.
@@ -26,6 +26,7 @@ func (s StructLog) MarshalJSON() ([]byte, error) {
 		Storage       map[common.Hash]common.Hash `json:"-"`
 		Depth         int                         `json:"depth"`
 		RefundCounter uint64                      `json:"refund"`
+		Extra         map[string]interface{}      `json:"extra,omitempty"`
 		Err           error                       `json:"-"`
 		OpName        string                      `json:"opName"`
 		ErrorString   string                      `json:"error"`
@@ -46,6 +47,7 @@ func (s StructLog) MarshalJSON() ([]byte, error) {
 	enc.Storage = s.Storage
 	enc.Depth = s.Depth
 	enc.RefundCounter = s.RefundCounter
+	enc.Extra = s.Extra
 	enc.Err = s.Err
 	enc.OpName = s.OpName()
 	enc.ErrorString = s.ErrorString()
@@ -65,6 +67,7 @@ func (s *StructLog) UnmarshalJSON(input []byte) error {
 		Storage       map[common.Hash]common.Hash `json:"-"`
 		Depth         *int                        `json:"depth"`
 		RefundCounter *uint64                     `json:"refund"`
+		Extra         map[string]interface{}      `json:"extra,omitempty"`
 		Err           error                       `json:"-"`
 	}
 	var dec StructLog
@@ -104,6 +107,9 @@ func (s *StructLog) UnmarshalJSON(input []byte) error {
 	if dec.RefundCounter != nil {
 		s.RefundCounter = *dec.RefundCounter
 	}
+	if dec.Extra != nil {
+		s.Extra = dec.Extra
+	}
 	if dec.Err != nil {
 		s.Err = dec.Err
 	}
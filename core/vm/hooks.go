@@ -0,0 +1,513 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+// RulesHooks allows chains built on top of go-ethereum to adjust the behaviour
+// of the EVM under the rules of the block being executed, e.g. at their own
+// forks. Every hook is optional; leaving it unset retains the upstream
+// behaviour. Hooks must be deterministic, as they affect consensus.
+type RulesHooks struct {
+	// ConstantGas returns a sparse set of constant gas costs replacing those
+	// of existing opcodes. It is consulted whenever an interpreter's jump table
+	// is built. Overrides for opcodes that are invalid under the given rules
+	// are ignored.
+	ConstantGas func(rules params.Rules) map[OpCode]uint64
+
+	// DisabledOpCodes returns the opcodes that are invalid under the given
+	// rules, e.g. to sunset an opcode earlier than upstream. Executing one
+	// fails like any other invalid opcode, consuming all remaining gas.
+	DisabledOpCodes func(rules params.Rules) []OpCode
+
+	// MemoryGas returns the pricing of memory expansion under the given rules,
+	// or nil to retain the quadratic upstream pricing.
+	MemoryGas func(rules params.Rules) MemoryGasFunc
+
+	// MaxCodeSize returns the maximum size of deployed contract code under the
+	// given rules, replacing params.MaxCodeSize. Like the upstream limit, it
+	// is only enforced from EIP-158 on.
+	MaxCodeSize func(rules params.Rules) int
+
+	// MaxInitCodeSize returns the maximum size of contract creation code under
+	// the given rules, with zero meaning unlimited as upstream. Creations
+	// exceeding it fail without running the code, consuming all gas.
+	MaxInitCodeSize func(rules params.Rules) int
+
+	// CallCreateDepth returns the maximum depth of the call/create stack under
+	// the given rules, replacing params.CallCreateDepth.
+	CallCreateDepth func(rules params.Rules) uint64
+
+	// CanExecuteCode is asked before the code at the given address is run by
+	// a CALL, CALLCODE, DELEGATECALL or STATICCALL, precompiles included. An
+	// error denies execution, e.g. to pause a compromised system contract,
+	// failing the call with that error like an exceptional halt: state changes
+	// are reverted and the call's gas is consumed. The hook is given a
+	// read-only view of the state, e.g. to consult an on-chain allow list.
+	CanExecuteCode func(rules params.Rules, state StateReader, addr common.Address) error
+
+	// GasForwarding returns the gas forwarding rule replacing EIP-150's "all
+	// but one 64th" under the given rules, or nil to retain it. The rule
+	// returns the maximum gas a CALL variant or CREATE may pass on to its
+	// callee out of the gas available; more than available is capped. Like
+	// EIP-150, it doesn't apply before the TangerineWhistle fork.
+	GasForwarding func(rules params.Rules) func(available uint64) uint64
+
+	// CodeDepositGas returns the gas charged for storing the given number of
+	// bytes of deployed contract code under the given rules, replacing the
+	// upstream params.CreateDataGas per byte. The constant cost of CREATE and
+	// CREATE2 themselves can be replaced through ConstantGas.
+	CodeDepositGas func(rules params.Rules, codeSize int) uint64
+
+	// TransientStorage returns the policy governing EIP-1153 transient storage
+	// under the given rules, or nil to leave the TLOAD and TSTORE opcodes
+	// undefined as upstream. Their gas costs can be replaced through
+	// ConstantGas and they can be disabled again through DisabledOpCodes.
+	TransientStorage func(rules params.Rules) *TransientStoragePolicy
+}
+
+// maxCodeSizes returns the maximum sizes of deployed and creation code under
+// the given rules.
+func maxCodeSizes(rules params.Rules) (code int, initCode int) {
+	code = params.MaxCodeSize
+	if rulesHooks.MaxCodeSize != nil {
+		code = rulesHooks.MaxCodeSize(rules)
+	}
+	if rulesHooks.MaxInitCodeSize != nil {
+		initCode = rulesHooks.MaxInitCodeSize(rules)
+	}
+	return code, initCode
+}
+
+// callCreateDepth returns the maximum call/create depth under the given rules.
+func callCreateDepth(rules params.Rules) int {
+	if rulesHooks.CallCreateDepth != nil {
+		return int(rulesHooks.CallCreateDepth(rules))
+	}
+	return int(params.CallCreateDepth)
+}
+
+// canExecute runs the CanExecuteCode hook for the code at the given address.
+func (evm *EVM) canExecute(addr common.Address) error {
+	if rulesHooks.CanExecuteCode == nil {
+		return nil
+	}
+	err := rulesHooks.CanExecuteCode(evm.chainRules, evm.ReadOnlyState(), addr)
+	if evm.hookTracer != nil {
+		evm.traceHook("RulesHooks.CanExecuteCode", nil, err, addr)
+	}
+	return err
+}
+
+// codeDepositGas returns the gas charged for storing deployed contract code of
+// the given size.
+func (evm *EVM) codeDepositGas(codeSize int) uint64 {
+	if rulesHooks.CodeDepositGas != nil {
+		gas := rulesHooks.CodeDepositGas(evm.chainRules, codeSize)
+		if evm.hookTracer != nil {
+			evm.traceHook("RulesHooks.CodeDepositGas", gas, nil, codeSize)
+		}
+		return gas
+	}
+	return uint64(codeSize) * params.CreateDataGas
+}
+
+// forwardableGas returns the maximum gas that may be passed on to a callee out
+// of the given available gas, after EIP-150.
+func (evm *EVM) forwardableGas(available uint64) uint64 {
+	if evm.gasForwarding != nil {
+		if gas := evm.gasForwarding(available); gas < available {
+			return gas
+		}
+		return available
+	}
+	return available - available/64
+}
+
+// MemoryGasFunc returns the total gas cost of a call frame's memory once it
+// spans the given number of 32 byte words; expanding memory is charged the
+// difference to the total cost already paid. The cost must not decrease as
+// memory grows. Costs that can't be represented should be reported as an
+// error, failing the expansion like running out of gas.
+type MemoryGasFunc func(words uint64) (uint64, error)
+
+// rulesHooks are the hooks installed by RegisterRulesHooks.
+var (
+	rulesHooks           RulesHooks
+	rulesHooksRegistered bool
+)
+
+// RegisterRulesHooks installs the hooks adjusting the EVM's behaviour. It is not
+// safe for concurrent use and must be called during initialisation, before
+// any EVM is created. It panics if called more than once.
+func RegisterRulesHooks(hooks RulesHooks) {
+	if rulesHooksRegistered {
+		panic("vm: rules hooks already registered")
+	}
+	rulesHooks, rulesHooksRegistered = hooks, true
+}
+
+// applyRulesHooks adjusts the given jump table according to the rules hooks.
+func applyRulesHooks(evm *EVM, jt *JumpTable) {
+	if evm.transientStorage != nil {
+		enableTransientStorage(jt)
+	}
+	if rulesHooks.ConstantGas != nil {
+		overrides := rulesHooks.ConstantGas(evm.chainRules)
+		if evm.hookTracer != nil {
+			evm.traceHook("RulesHooks.ConstantGas", overrides, nil)
+		}
+		for op, gas := range overrides {
+			if jt[op].valid {
+				jt[op].constantGas = gas
+			}
+		}
+	}
+	if rulesHooks.DisabledOpCodes != nil {
+		disabled := rulesHooks.DisabledOpCodes(evm.chainRules)
+		if evm.hookTracer != nil {
+			evm.traceHook("RulesHooks.DisabledOpCodes", disabled, nil)
+		}
+		for _, op := range disabled {
+			jt[op].valid = false
+		}
+	}
+}
+
+// traceRulesHooks reports the decisions of the rules hooks resolved when the
+// EVM was created to the hook tracer.
+func (evm *EVM) traceRulesHooks() {
+	if rulesHooks.MaxCodeSize != nil {
+		evm.traceHook("RulesHooks.MaxCodeSize", evm.maxCodeSize, nil)
+	}
+	if rulesHooks.MaxInitCodeSize != nil {
+		evm.traceHook("RulesHooks.MaxInitCodeSize", evm.maxInitCodeSize, nil)
+	}
+	if rulesHooks.CallCreateDepth != nil {
+		evm.traceHook("RulesHooks.CallCreateDepth", evm.maxDepth, nil)
+	}
+	if rulesHooks.TransientStorage != nil {
+		evm.traceHook("RulesHooks.TransientStorage", evm.transientStorage, nil)
+	}
+	if rulesHooks.GasForwarding != nil {
+		evm.traceHook("RulesHooks.GasForwarding", evm.gasForwarding != nil, nil)
+	}
+	if in, ok := evm.interpreter.(*EVMInterpreter); ok && rulesHooks.MemoryGas != nil {
+		evm.traceHook("RulesHooks.MemoryGas", in.memoryGas != nil, nil)
+	}
+}
+
+// InterpreterHooks allows instrumenting and constraining the interpreter loop
+// of every EVM, independent of any tracer configured for it. Every hook is
+// optional and may be invoked concurrently by EVMs running in parallel.
+type InterpreterHooks struct {
+	// Step is invoked before every opcode is executed with the opcode, its
+	// program counter and the gas remaining before it's charged. It is meant
+	// for cheap, always-on instrumentation such as opcode frequency metrics
+	// and must not retain or block execution.
+	Step func(pc uint64, op OpCode, gas uint64)
+
+	// Halt is consulted every HaltCheckInterval opcodes with the number of
+	// opcodes executed and the wall clock time elapsed since the EVM started
+	// executing, across all call frames. Returning an error aborts execution
+	// with that error, which is charged like running out of gas: the current
+	// frame's gas is consumed and every enclosing frame fails with the error
+	// as soon as the call returns to it, without executing further opcodes.
+	// Halt can be used to enforce limits such as a maximum opcode count per
+	// transaction.
+	Halt func(evm *EVM, steps uint64, elapsed time.Duration) error
+}
+
+// HaltCheckInterval is the number of opcodes executed between consultations
+// of the InterpreterHooks.Halt hook.
+const HaltCheckInterval = 1024
+
+// interpreterHooks are the hooks installed by RegisterInterpreterHooks.
+var (
+	interpreterHooks           InterpreterHooks
+	interpreterHooksRegistered bool
+)
+
+// RegisterInterpreterHooks installs the hooks instrumenting the interpreter. It
+// is not safe for concurrent use and must be called during initialisation,
+// before any EVM is created. It panics if called more than once.
+func RegisterInterpreterHooks(hooks InterpreterHooks) {
+	if interpreterHooksRegistered {
+		panic("vm: interpreter hooks already registered")
+	}
+	interpreterHooks, interpreterHooksRegistered = hooks, true
+}
+
+// EVMHooks allows chains built on top of go-ethereum to observe and intervene
+// in the execution of every EVM. Every hook is optional and may be invoked
+// concurrently by EVMs running in parallel. Hooks must be deterministic, as
+// they affect consensus.
+type EVMHooks struct {
+	// BeforeCall is invoked before every message call, be it a CALL, CALLCODE,
+	// DELEGATECALL or STATICCALL, including those made by transactions and
+	// precompiles. Returning an error vetoes the call, failing it with that
+	// error before anything is executed. The hook may reduce the frame's gas
+	// to charge the call extra; increases are ignored.
+	BeforeCall func(evm *EVM, frame *CallFrame) error
+
+	// AfterCall is invoked after every call seen by BeforeCall, vetoed ones
+	// included, with the call's results.
+	AfterCall func(evm *EVM, frame *CallFrame, ret []byte, leftOverGas uint64, err error)
+
+	// CheckTransfer is consulted before every non-zero value transfer made by
+	// a call or contract creation, e.g. to protect precompile or system
+	// addresses. Returning an error rejects the transfer, failing the call or
+	// creation with that error without consuming any of its gas, like a
+	// transfer exceeding the sender's balance. Unlike the latter, a rejected
+	// creation still increments the nonce of the creator, so that rejected
+	// creation transactions are included as failed and can't be replayed.
+	//
+	// It is also consulted before a self-destructing contract pays out its
+	// balance to the beneficiary returned by SelfDestruct. Rejecting the
+	// payout fails the execution of the contract like any other opcode error,
+	// consuming all of its gas and leaving the contract in place.
+	CheckTransfer func(evm *EVM, from, to common.Address, value *big.Int) error
+
+	// Revert is invoked whenever a message call reverts, with the revert data,
+	// e.g. to translate the errors of well known system contracts. Returning
+	// an error fails the call with a RevertError holding it in place of the
+	// generic revert error, so that it reaches tracers and the caller of the
+	// EVM. Either way the call is reverted as usual: its state changes are
+	// rolled back and its remaining gas is refunded.
+	Revert func(evm *EVM, frame *CallFrame, data []byte) error
+
+	// CanCreateContract is consulted before every contract creation, be it by
+	// a transaction, CREATE or CREATE2. Returning an error rejects the
+	// creation, failing it with that error without consuming any of its gas.
+	// A rejected creation still increments the nonce of the creator, so that
+	// rejected creation transactions can't be replayed. The hook may change
+	// the creation's Address to deploy the contract at a different address,
+	// e.g. for deployments managed by the chain itself, except for creations
+	// by transactions, whose receipts derive the address from the nonce.
+	CanCreateContract func(evm *EVM, creation *ContractCreation) error
+
+	// SelfDestruct is invoked when a contract executes SELFDESTRUCT, before its
+	// balance is paid out to the beneficiary. It returns the account to pay
+	// the balance to instead, e.g. to keep funds out of reserved address
+	// space, or an error to fail the SELFDESTRUCT like an exceptional halt.
+	// The opcode's gas is charged for the original beneficiary.
+	SelfDestruct func(evm *EVM, addr, beneficiary common.Address, balance *big.Int) (common.Address, error)
+}
+
+// ContractCreation describes a contract creation passed to the EVMHooks.
+type ContractCreation struct {
+	Type     OpCode         // CREATE or CREATE2, the scheme the address is derived with
+	Caller   common.Address // Account creating the contract
+	Address  common.Address // Address the contract is deployed at
+	InitCode []byte         // Creation code, must not be modified
+	Salt     *big.Int       // Salt of a CREATE2, nil for CREATE
+	Gas      uint64         // Gas made available to the creation
+	Value    *big.Int       // Value endowed to the contract
+	Depth    int            // Depth of the creating frame
+}
+
+// RevertError is the error of a reverted call whose revert data has been
+// translated by the Revert hook.
+type RevertError struct {
+	Reason error  // Error returned by the Revert hook
+	Data   []byte // Revert data returned by the call
+}
+
+// Error implements the error interface.
+func (e *RevertError) Error() string {
+	return errExecutionReverted.Error() + ": " + e.Reason.Error()
+}
+
+// CallFrame describes a message call passed to the EVMHooks.
+type CallFrame struct {
+	Type   OpCode         // CALL, CALLCODE, DELEGATECALL or STATICCALL
+	Caller common.Address // Account making the call
+	Target common.Address // Account whose code is executed
+	Input  []byte         // Call data, must not be modified
+	Gas    uint64         // Gas made available to the call
+	Value  *big.Int       // Value transferred, zero for DELEGATECALL and STATICCALL
+	Depth  int            // Depth of the calling frame
+}
+
+// evmHooks are the hooks installed by RegisterEVMHooks.
+var (
+	evmHooks           EVMHooks
+	evmHooksRegistered bool
+)
+
+// RegisterEVMHooks installs the hooks intervening in EVM execution. It is not
+// safe for concurrent use and must be called during initialisation, before
+// any EVM is created. It panics if called more than once.
+func RegisterEVMHooks(hooks EVMHooks) {
+	if evmHooksRegistered {
+		panic("vm: EVM hooks already registered")
+	}
+	evmHooks, evmHooksRegistered = hooks, true
+}
+
+// beforeCall runs the BeforeCall hook for a message call, returning the frame
+// to pass to afterCall, or nil if no call hooks are registered. The frame's gas
+// is the gas to use for the call.
+func (evm *EVM) beforeCall(typ OpCode, caller ContractRef, addr common.Address, input []byte, gas uint64, value *big.Int) (*CallFrame, error) {
+	if evmHooks.BeforeCall == nil && evmHooks.AfterCall == nil && evmHooks.Revert == nil {
+		return nil, nil
+	}
+	frame := &CallFrame{
+		Type:   typ,
+		Caller: caller.Address(),
+		Target: addr,
+		Input:  input,
+		Gas:    gas,
+		Value:  new(big.Int),
+		Depth:  evm.depth,
+	}
+	if value != nil {
+		frame.Value.Set(value)
+	}
+	if evmHooks.BeforeCall == nil {
+		return frame, nil
+	}
+	var passed CallFrame
+	if evm.hookTracer != nil {
+		passed = *frame
+	}
+	err := evmHooks.BeforeCall(evm, frame)
+	if frame.Gas > gas {
+		frame.Gas = gas
+	}
+	if evm.hookTracer != nil {
+		evm.traceHook("EVMHooks.BeforeCall", frame.Gas, err, passed)
+	}
+	return frame, err
+}
+
+// checkTransfer runs the CheckTransfer hook for a value transfer.
+func (evm *EVM) checkTransfer(from, to common.Address, value *big.Int) error {
+	if evmHooks.CheckTransfer == nil || value.Sign() == 0 {
+		return nil
+	}
+	err := evmHooks.CheckTransfer(evm, from, to, value)
+	if evm.hookTracer != nil {
+		evm.traceHook("EVMHooks.CheckTransfer", nil, err, from, to, new(big.Int).Set(value))
+	}
+	return err
+}
+
+// reverted runs the Revert hook if the message call described by the frame
+// reverted, returning the error to fail the call with.
+func (evm *EVM) reverted(frame *CallFrame, ret []byte, err error) error {
+	if frame == nil || evmHooks.Revert == nil || err != errExecutionReverted {
+		return err
+	}
+	reason := evmHooks.Revert(evm, frame, ret)
+	if evm.hookTracer != nil {
+		evm.traceHook("EVMHooks.Revert", nil, reason, *frame, common.CopyBytes(ret))
+	}
+	if reason != nil {
+		return &RevertError{Reason: reason, Data: ret}
+	}
+	return err
+}
+
+// canCreate runs the CanCreateContract hook for a contract creation, returning
+// the address to deploy the contract at.
+func (evm *EVM) canCreate(typ OpCode, caller ContractRef, address common.Address, code []byte, salt *big.Int, gas uint64, value *big.Int) (common.Address, error) {
+	if evmHooks.CanCreateContract == nil {
+		return address, nil
+	}
+	creation := &ContractCreation{
+		Type:     typ,
+		Caller:   caller.Address(),
+		Address:  address,
+		InitCode: code,
+		Gas:      gas,
+		Value:    new(big.Int).Set(value),
+		Depth:    evm.depth,
+	}
+	if salt != nil {
+		creation.Salt = new(big.Int).Set(salt)
+	}
+	var passed ContractCreation
+	if evm.hookTracer != nil {
+		passed = *creation
+	}
+	err := evmHooks.CanCreateContract(evm, creation)
+	if evm.hookTracer != nil {
+		evm.traceHook("EVMHooks.CanCreateContract", creation.Address, err, passed)
+	}
+	if err != nil {
+		return common.Address{}, err
+	}
+	if evm.depth == 0 && creation.Address != address {
+		return common.Address{}, ErrCreationAddressRewritten
+	}
+	return creation.Address, nil
+}
+
+// selfDestruct runs the SelfDestruct hook for the destruction of the contract
+// at addr, returning the beneficiary to pay its balance to.
+func (evm *EVM) selfDestruct(addr, beneficiary common.Address, balance *big.Int) (common.Address, error) {
+	if evmHooks.SelfDestruct == nil {
+		return beneficiary, nil
+	}
+	payee, err := evmHooks.SelfDestruct(evm, addr, beneficiary, new(big.Int).Set(balance))
+	if evm.hookTracer != nil {
+		evm.traceHook("EVMHooks.SelfDestruct", payee, err, addr, beneficiary, new(big.Int).Set(balance))
+	}
+	return payee, err
+}
+
+// afterCall runs the AfterCall hook for a message call.
+func (evm *EVM) afterCall(frame *CallFrame, ret []byte, leftOverGas uint64, err error) {
+	if evmHooks.AfterCall != nil {
+		evmHooks.AfterCall(evm, frame, ret, leftOverGas, err)
+	}
+}
+
+// tracePrecompile reports to the hook tracer that the precompile at addr was
+// provided by an override, precompile upgrade or registration rather than
+// upstream.
+func (evm *EVM) tracePrecompile(addr common.Address, p PrecompiledContract) {
+	if _, ok := evm.precompileOverrides[addr]; ok {
+		evm.traceHook("OverridePrecompile", fmt.Sprintf("%T", p), nil, addr)
+	} else if _, ok := upgradedPrecompile(evm.chainRules, addr); ok {
+		evm.traceHook("PrecompileUpgrades", fmt.Sprintf("%T", p), nil, addr)
+	} else if evm.registration(addr, evm.defaultPrecompiles()) != nil {
+		evm.traceHook("RegisterPrecompile", fmt.Sprintf("%T", p), nil, addr)
+	}
+}
+
+// traceHook reports a hook decision to the hook tracer, which must be set.
+func (evm *EVM) traceHook(hook string, result interface{}, err error, args ...interface{}) {
+	record := &HookRecord{
+		Hook:   hook,
+		Depth:  evm.depth,
+		Args:   args,
+		Result: result,
+	}
+	if err != nil {
+		record.Err = err.Error()
+	}
+	evm.hookTracer.CaptureHook(evm, record)
+}
@@ -0,0 +1,643 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+// unregisterRulesHooks drops the rules hooks registered by a test.
+func unregisterRulesHooks() {
+	rulesHooks, rulesHooksRegistered = RulesHooks{}, false
+}
+
+// unregisterInterpreterHooks drops the interpreter hooks registered by a test.
+func unregisterInterpreterHooks() {
+	interpreterHooks, interpreterHooksRegistered = InterpreterHooks{}, false
+}
+
+// gasUsed runs the given code on a fresh test EVM and returns the gas used.
+func gasUsed(t *testing.T, code string) uint64 {
+	t.Helper()
+
+	var (
+		evm      = newStatefulTestEVM()
+		contract = common.HexToAddress("0xc0ffee")
+	)
+	evm.StateDB.SetCode(contract, hexutil.MustDecode(code))
+	_, gas, err := evm.Call(AccountRef(common.Address{}), contract, nil, 100000, new(big.Int))
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	return 100000 - gas
+}
+
+func TestConstantGasOverrides(t *testing.T) {
+	defer unregisterRulesHooks()
+
+	// PUSH1 0, SLOAD, STOP
+	code := "0x60005400"
+	if gas := gasUsed(t, code); gas != 3+params.SloadGasEIP150 {
+		t.Fatalf("upstream gas mismatch: have %d, want %d", gas, 3+params.SloadGasEIP150)
+	}
+	RegisterRulesHooks(RulesHooks{
+		ConstantGas: func(rules params.Rules) map[OpCode]uint64 {
+			if !rules.IsByzantium {
+				return nil
+			}
+			return map[OpCode]uint64{SLOAD: 1234, PUSH1: 1, 0xef: 1}
+		},
+	})
+	if gas := gasUsed(t, code); gas != 1+1234 {
+		t.Fatalf("overridden gas mismatch: have %d, want %d", gas, 1+1234)
+	}
+	// Overrides must not leak into the shared instruction sets
+	if gas := byzantiumInstructionSet[SLOAD].constantGas; gas != params.SloadGasEIP150 {
+		t.Errorf("shared instruction set modified: SLOAD gas %d", gas)
+	}
+}
+
+func TestDisabledOpCodes(t *testing.T) {
+	defer unregisterRulesHooks()
+
+	RegisterRulesHooks(RulesHooks{
+		DisabledOpCodes: func(rules params.Rules) []OpCode {
+			if !rules.IsByzantium {
+				return nil
+			}
+			return []OpCode{SELFDESTRUCT}
+		},
+	})
+	var (
+		evm      = newStatefulTestEVM()
+		contract = common.HexToAddress("0xc0ffee")
+	)
+	// PUSH1 0, SELFDESTRUCT
+	evm.StateDB.SetCode(contract, hexutil.MustDecode("0x6000ff"))
+	_, gas, err := evm.Call(AccountRef(common.Address{}), contract, nil, 100000, new(big.Int))
+	if err == nil || err.Error() != "invalid opcode 0xff" {
+		t.Fatalf("error mismatch: have %v, want invalid opcode", err)
+	}
+	if gas != 0 {
+		t.Errorf("gas not consumed: %d left", gas)
+	}
+	if evm.StateDB.HasSuicided(contract) {
+		t.Errorf("disabled opcode executed")
+	}
+}
+
+func TestStepHook(t *testing.T) {
+	defer unregisterInterpreterHooks()
+
+	type step struct {
+		pc  uint64
+		op  OpCode
+		gas uint64
+	}
+	var steps []step
+	RegisterInterpreterHooks(InterpreterHooks{
+		Step: func(pc uint64, op OpCode, gas uint64) {
+			steps = append(steps, step{pc, op, gas})
+		},
+	})
+	// PUSH1 1, PUSH1 2, ADD, STOP
+	gasUsed(t, "0x600160020100")
+
+	want := []step{{0, PUSH1, 100000}, {2, PUSH1, 99997}, {4, ADD, 99994}, {5, STOP, 99991}}
+	if !reflect.DeepEqual(steps, want) {
+		t.Errorf("steps mismatch: have %v, want %v", steps, want)
+	}
+}
+
+func TestHaltHook(t *testing.T) {
+	defer unregisterInterpreterHooks()
+
+	errTooManySteps := errors.New("too many steps")
+	RegisterInterpreterHooks(InterpreterHooks{
+		Halt: func(evm *EVM, steps uint64, elapsed time.Duration) error {
+			if steps >= 2*HaltCheckInterval {
+				return errTooManySteps
+			}
+			return nil
+		},
+	})
+	var (
+		evm      = newStatefulTestEVM()
+		contract = common.HexToAddress("0xc0ffee")
+	)
+	// JUMPDEST, PUSH1 0, JUMP: loops until halted
+	evm.StateDB.SetCode(contract, hexutil.MustDecode("0x5b600056"))
+	_, gas, err := evm.Call(AccountRef(common.Address{}), contract, nil, 10000000, new(big.Int))
+	if err != errTooManySteps {
+		t.Fatalf("error mismatch: have %v, want %v", err, errTooManySteps)
+	}
+	if gas != 0 {
+		t.Errorf("gas not consumed: %d left", gas)
+	}
+	if evm.steps != 2*HaltCheckInterval {
+		t.Errorf("halted after %d steps, want %d", evm.steps, 2*HaltCheckInterval)
+	}
+}
+
+func TestHaltHookNested(t *testing.T) {
+	defer unregisterInterpreterHooks()
+
+	errTooManySteps := errors.New("too many steps")
+	RegisterInterpreterHooks(InterpreterHooks{
+		Halt: func(evm *EVM, steps uint64, elapsed time.Duration) error {
+			if steps >= 2*HaltCheckInterval {
+				return errTooManySteps
+			}
+			return nil
+		},
+	})
+	var (
+		evm    = newStatefulTestEVM()
+		caller = common.HexToAddress("0xca11e7")
+		looper = common.HexToAddress("0xc0ffee")
+	)
+	// CALL 0xc0ffee with all gas, then JUMPDEST, PUSH1 16, JUMP: loops until halted
+	evm.StateDB.SetCode(caller, hexutil.MustDecode("0x6000600060006000600062c0ffee5af15b601056"))
+	evm.StateDB.SetCode(looper, hexutil.MustDecode("0x5b600056"))
+
+	// The halted inner frame must abort the caller right away
+	_, gas, err := evm.Call(AccountRef(common.Address{}), caller, nil, 10000000, new(big.Int))
+	if err != errTooManySteps {
+		t.Fatalf("error mismatch: have %v, want %v", err, errTooManySteps)
+	}
+	if gas != 0 {
+		t.Errorf("gas not consumed: %d left", gas)
+	}
+	if evm.steps != 2*HaltCheckInterval {
+		t.Errorf("halted after %d steps, want %d", evm.steps, 2*HaltCheckInterval)
+	}
+	if _, _, err := evm.Call(AccountRef(common.Address{}), looper, nil, 100000, new(big.Int)); err != errTooManySteps {
+		t.Errorf("call after halt error mismatch: have %v, want %v", err, errTooManySteps)
+	}
+}
+
+func TestMemoryGasOverride(t *testing.T) {
+	defer unregisterRulesHooks()
+
+	// PUSH1 0, PUSH1 0x40, MSTORE, STOP: expands memory to 3 words
+	code := "0x6000604052" + "00"
+	if gas := gasUsed(t, code); gas != 3+3+3+3*params.MemoryGas {
+		t.Fatalf("upstream gas mismatch: have %d, want %d", gas, 3+3+3+3*params.MemoryGas)
+	}
+	RegisterRulesHooks(RulesHooks{
+		MemoryGas: func(rules params.Rules) MemoryGasFunc {
+			return func(words uint64) (uint64, error) {
+				return 100 * words, nil
+			}
+		},
+	})
+	if gas := gasUsed(t, code); gas != 3+3+3+300 {
+		t.Fatalf("overridden gas mismatch: have %d, want %d", gas, 3+3+3+300)
+	}
+}
+
+func TestMaxCodeSizeOverrides(t *testing.T) {
+	defer unregisterRulesHooks()
+
+	RegisterRulesHooks(RulesHooks{
+		MaxCodeSize:     func(rules params.Rules) int { return 2 },
+		MaxInitCodeSize: func(rules params.Rules) int { return 12 },
+	})
+	caller := AccountRef(common.HexToAddress("0xca11e7"))
+
+	// Returns code of the given size: PUSH1 size, PUSH1 0, RETURN
+	for size, want := range map[byte]error{2: nil, 3: errMaxCodeSizeExceeded} {
+		evm := newStatefulTestEVM()
+		if _, _, _, err := evm.Create(caller, []byte{byte(PUSH1), size, byte(PUSH1), 0, byte(RETURN)}, 100000, new(big.Int)); err != want {
+			t.Errorf("code size %d: error mismatch: have %v, want %v", size, err, want)
+		}
+	}
+	evm := newStatefulTestEVM()
+	if _, _, gas, err := evm.Create(caller, make([]byte, 13), 100000, new(big.Int)); err != ErrMaxInitCodeSizeExceeded || gas != 0 {
+		t.Errorf("init code size error mismatch: have %v (gas %d), want %v", err, gas, ErrMaxInitCodeSizeExceeded)
+	}
+}
+
+func TestCallCreateDepthOverride(t *testing.T) {
+	defer unregisterRulesHooks()
+
+	RegisterRulesHooks(RulesHooks{
+		CallCreateDepth: func(rules params.Rules) uint64 { return 5 },
+	})
+	evm := newStatefulTestEVM()
+	for depth, want := range map[int]error{5: nil, 6: ErrDepth} {
+		evm.depth = depth
+		if _, _, err := evm.Call(AccountRef(common.Address{}), common.HexToAddress("0xc0ffee"), nil, 100000, new(big.Int)); err != want {
+			t.Errorf("depth %d: call error mismatch: have %v, want %v", depth, err, want)
+		}
+		if _, _, _, err := evm.Create(AccountRef(common.Address{}), nil, 100000, new(big.Int)); err != want {
+			t.Errorf("depth %d: create error mismatch: have %v, want %v", depth, err, want)
+		}
+	}
+}
+
+// unregisterEVMHooks drops the EVM hooks registered by a test.
+func unregisterEVMHooks() {
+	evmHooks, evmHooksRegistered = EVMHooks{}, false
+}
+
+func TestCallHooks(t *testing.T) {
+	defer unregisterEVMHooks()
+
+	var (
+		errVetoed = errors.New("vetoed")
+		proxy     = common.HexToAddress("0xc0ffee01")
+		target    = common.HexToAddress("0xc0ffee02")
+		denied    = common.HexToAddress("0xc0ffee03")
+		frames    []CallFrame
+		results   []error
+	)
+	RegisterEVMHooks(EVMHooks{
+		BeforeCall: func(evm *EVM, frame *CallFrame) error {
+			if frame.Target == denied {
+				return errVetoed
+			}
+			frame.Gas -= 1000
+			return nil
+		},
+		AfterCall: func(evm *EVM, frame *CallFrame, ret []byte, leftOverGas uint64, err error) {
+			frames = append(frames, *frame)
+			results = append(results, err)
+		},
+	})
+	evm := newStatefulTestEVM()
+	// DELEGATECALL(gas, target, 0, 0, 0, 0), STOP
+	evm.StateDB.SetCode(proxy, hexutil.MustDecode("0x600060006000600073"+common.Bytes2Hex(target.Bytes())+"5af400"))
+
+	_, gas, err := evm.Call(AccountRef(common.Address{}), proxy, nil, 100000, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if gas != 100000-1000-5*3-2-700-1000 {
+		t.Errorf("leftover gas mismatch: have %d, want %d", gas, 100000-1000-5*3-2-700-1000)
+	}
+	if len(frames) != 2 || frames[0].Type != DELEGATECALL || frames[0].Target != target || frames[0].Depth != 1 ||
+		frames[1].Type != CALL || frames[1].Target != proxy || frames[1].Value.Cmp(big.NewInt(1)) != 0 || frames[1].Gas != 99000 {
+		t.Errorf("call frames mismatch: %+v", frames)
+	}
+	if _, gas, err := evm.StaticCall(AccountRef(common.Address{}), denied, nil, 100000); err != errVetoed || gas != 100000 {
+		t.Errorf("veto mismatch: have %v (gas %d), want %v", err, gas, errVetoed)
+	}
+	if len(results) != 3 || results[2] != errVetoed {
+		t.Errorf("vetoed call not reported: %v", results)
+	}
+}
+
+func TestCheckTransferHook(t *testing.T) {
+	defer unregisterEVMHooks()
+
+	var (
+		errProtected = errors.New("protected address")
+		protected    = common.HexToAddress("0xb1ac4401e")
+		caller       = common.HexToAddress("0xca11e7")
+	)
+	RegisterEVMHooks(EVMHooks{
+		CheckTransfer: func(evm *EVM, from, to common.Address, value *big.Int) error {
+			if from == protected || to == protected {
+				return errProtected
+			}
+			return nil
+		},
+	})
+	evm := newStatefulTestEVM()
+	if _, gas, err := evm.Call(AccountRef(caller), protected, nil, 100000, big.NewInt(1)); err != errProtected || gas != 100000 {
+		t.Errorf("transfer to protected address: have %v (gas %d), want %v", err, gas, errProtected)
+	}
+	if _, _, gas, err := evm.Create(AccountRef(protected), nil, 100000, big.NewInt(1)); err != errProtected || gas != 100000 {
+		t.Errorf("creation by protected address: have %v (gas %d), want %v", err, gas, errProtected)
+	}
+	if nonce := evm.StateDB.GetNonce(protected); nonce != 1 {
+		t.Errorf("nonce of rejected creator mismatch: have %d, want 1", nonce)
+	}
+	if _, _, err := evm.Call(AccountRef(caller), protected, nil, 100000, new(big.Int)); err != nil {
+		t.Errorf("call without value failed: %v", err)
+	}
+	if _, _, err := evm.Call(AccountRef(caller), common.HexToAddress("0xc0ffee"), nil, 100000, big.NewInt(1)); err != nil {
+		t.Errorf("unprotected transfer failed: %v", err)
+	}
+	// Self-destructing contracts can't pay out their balance to protected addresses
+	destructing := common.HexToAddress("0xc0ffee01")
+	evm.StateDB.SetCode(destructing, hexutil.MustDecode("0x73"+common.Bytes2Hex(protected.Bytes())+"ff"))
+	evm.StateDB.AddBalance(destructing, big.NewInt(100))
+	if _, gas, err := evm.Call(AccountRef(caller), destructing, nil, 100000, new(big.Int)); err != errProtected || gas != 0 {
+		t.Errorf("self-destruct to protected address: have %v (gas %d), want %v", err, gas, errProtected)
+	}
+	if evm.StateDB.HasSuicided(destructing) || evm.StateDB.GetBalance(protected).Sign() != 0 {
+		t.Errorf("balance paid out to protected address")
+	}
+}
+
+func TestCanExecuteCodeHook(t *testing.T) {
+	defer unregisterRulesHooks()
+
+	var (
+		errPaused = errors.New("paused")
+		paused    = common.HexToAddress("0xc0ffee")
+	)
+	RegisterRulesHooks(RulesHooks{
+		CanExecuteCode: func(rules params.Rules, state StateReader, addr common.Address) error {
+			if addr == paused {
+				return errPaused
+			}
+			return nil
+		},
+	})
+	evm := newStatefulTestEVM()
+	evm.StateDB.SetCode(paused, hexutil.MustDecode("0x00"))
+
+	if _, gas, err := evm.Call(AccountRef(common.Address{}), paused, nil, 100000, new(big.Int)); err != errPaused || gas != 0 {
+		t.Errorf("call error mismatch: have %v (gas %d), want %v", err, gas, errPaused)
+	}
+	if _, _, err := evm.CallCode(AccountRef(common.Address{}), paused, nil, 100000, new(big.Int)); err != errPaused {
+		t.Errorf("callcode error mismatch: have %v, want %v", err, errPaused)
+	}
+	if _, _, err := evm.StaticCall(AccountRef(common.Address{}), common.HexToAddress("0xc0ffee01"), nil, 100000); err != nil {
+		t.Errorf("call to unpaused address failed: %v", err)
+	}
+}
+
+func TestGasForwardingOverride(t *testing.T) {
+	defer unregisterRulesHooks()
+
+	requested := new(big.Int).SetUint64(1 << 32)
+	if gas, _ := callGas(newStatefulTestEVM(), 6400, 0, requested); gas != 6300 {
+		t.Fatalf("upstream forwarded gas mismatch: have %d, want %d", gas, 6300)
+	}
+	RegisterRulesHooks(RulesHooks{
+		GasForwarding: func(rules params.Rules) func(uint64) uint64 {
+			return func(available uint64) uint64 { return available/2 + 5000 }
+		},
+	})
+	evm := newStatefulTestEVM()
+	for available, want := range map[uint64]uint64{6400: 6400, 20000: 15000} {
+		if gas, _ := callGas(evm, available, 0, requested); gas != want {
+			t.Errorf("available %d: forwarded gas mismatch: have %d, want %d", available, gas, want)
+		}
+	}
+	if gas, _ := callGas(evm, 20000, 0, big.NewInt(100)); gas != 100 {
+		t.Errorf("requested gas not honoured: have %d, want %d", gas, 100)
+	}
+}
+
+func TestRevertHook(t *testing.T) {
+	defer unregisterEVMHooks()
+
+	var (
+		errPaused = errors.New("system contract paused")
+		system    = common.HexToAddress("0xc0ffee01")
+		other     = common.HexToAddress("0xc0ffee02")
+		reverts   []common.Address
+	)
+	RegisterEVMHooks(EVMHooks{
+		Revert: func(evm *EVM, frame *CallFrame, data []byte) error {
+			reverts = append(reverts, frame.Target)
+			if frame.Target == system && string(data) == "\x01" {
+				return errPaused
+			}
+			return nil
+		},
+	})
+	evm := newStatefulTestEVM()
+	// MSTORE8(0, 1), REVERT(0, 1)
+	code := hexutil.MustDecode("0x600160005360016000fd")
+	evm.StateDB.SetCode(system, code)
+	evm.StateDB.SetCode(other, code)
+
+	ret, gas, err := evm.Call(AccountRef(common.Address{}), system, nil, 100000, new(big.Int))
+	if rerr, ok := err.(*RevertError); !ok || rerr.Reason != errPaused || !IsRevert(err) {
+		t.Fatalf("revert error mismatch: have %v, want %v", err, errPaused)
+	}
+	if string(ret) != "\x01" || gas == 0 {
+		t.Errorf("revert semantics lost: data %x, gas %d", ret, gas)
+	}
+	if _, _, err := evm.StaticCall(AccountRef(common.Address{}), other, nil, 100000); err != errExecutionReverted {
+		t.Errorf("untranslated revert mismatch: have %v, want %v", err, errExecutionReverted)
+	}
+	if len(reverts) != 2 || reverts[0] != system || reverts[1] != other {
+		t.Errorf("reverted frames mismatch: %v", reverts)
+	}
+}
+
+func TestCanCreateContractHook(t *testing.T) {
+	defer unregisterEVMHooks()
+
+	var (
+		errDenied = errors.New("deployer not allowed")
+		deployer  = common.HexToAddress("0xde9101e7")
+		managed   = common.HexToAddress("0x0200000000000000000000000000000000000001")
+		creations []ContractCreation
+	)
+	RegisterEVMHooks(EVMHooks{
+		CanCreateContract: func(evm *EVM, creation *ContractCreation) error {
+			creations = append(creations, *creation)
+			if creation.Caller != deployer {
+				return errDenied
+			}
+			if creation.Type == CREATE2 {
+				creation.Address = managed
+			}
+			return nil
+		},
+	})
+	evm := newStatefulTestEVM()
+	if _, _, gas, err := evm.Create(AccountRef(common.Address{}), nil, 100000, new(big.Int)); err != errDenied || gas != 100000 {
+		t.Errorf("denied creation mismatch: have %v (gas %d), want %v", err, gas, errDenied)
+	}
+	if _, addr, _, err := evm.Create(AccountRef(deployer), nil, 100000, new(big.Int)); err != nil || addr == managed {
+		t.Errorf("creation mismatch: have %v at %x", err, addr)
+	}
+	if nonce := evm.StateDB.GetNonce(common.Address{}); nonce != 1 {
+		t.Errorf("nonce of denied creator mismatch: have %d, want 1", nonce)
+	}
+	// Addresses of top-level creations must not be rewritten
+	code := hexutil.MustDecode("0x00")
+	if _, _, _, err := evm.Create2(AccountRef(deployer), code, 100000, new(big.Int), big.NewInt(7)); err != ErrCreationAddressRewritten {
+		t.Errorf("top-level rewrite mismatch: have %v, want %v", err, ErrCreationAddressRewritten)
+	}
+	evm.depth = 1
+	if _, addr, _, err := evm.Create2(AccountRef(deployer), code, 100000, new(big.Int), big.NewInt(7)); err != nil || addr != managed {
+		t.Errorf("rewritten creation mismatch: have %v at %x, want %x", err, addr, managed)
+	}
+	if len(creations) != 4 || creations[1].Type != CREATE || creations[1].Salt != nil ||
+		creations[3].Type != CREATE2 || creations[3].Salt.Int64() != 7 || !reflect.DeepEqual(creations[3].InitCode, code) {
+		t.Errorf("creations mismatch: %+v", creations)
+	}
+}
+
+func TestCodeDepositGasOverride(t *testing.T) {
+	defer unregisterRulesHooks()
+
+	// MSTORE8(0, 0), RETURN(0, 32)
+	code := hexutil.MustDecode("0x600060005360206000f3")
+	deposit := func() uint64 {
+		_, _, gas, err := newStatefulTestEVM().Create(AccountRef(common.Address{}), code, 100000, new(big.Int))
+		if err != nil {
+			t.Fatalf("creation failed: %v", err)
+		}
+		return 100000 - gas
+	}
+	upstream := deposit()
+
+	RegisterRulesHooks(RulesHooks{
+		CodeDepositGas: func(rules params.Rules, codeSize int) uint64 { return uint64(codeSize) },
+	})
+	if have, want := deposit(), upstream-32*params.CreateDataGas+32; have != want {
+		t.Errorf("creation gas mismatch: have %d, want %d", have, want)
+	}
+}
+
+func TestTransientStorageHook(t *testing.T) {
+	defer unregisterRulesHooks()
+
+	var (
+		contract = common.HexToAddress("0xc0ffee")
+		denied   = common.HexToAddress("0xdead")
+	)
+	run := func(addr common.Address, code string) (*EVM, error) {
+		evm := newStatefulTestEVM()
+		evm.StateDB.SetCode(addr, hexutil.MustDecode(code))
+		_, _, err := evm.Call(AccountRef(common.Address{}), addr, nil, 100000, new(big.Int))
+		return evm, err
+	}
+	// TSTORE(0, 42), SSTORE(0, TLOAD(0)), STOP
+	code := "0x602a60005d60005c60005500"
+	if _, err := run(contract, code); err == nil || err.Error() != "invalid opcode 0x5d" {
+		t.Fatalf("upstream error mismatch: have %v, want invalid opcode", err)
+	}
+	RegisterRulesHooks(RulesHooks{
+		TransientStorage: func(rules params.Rules) *TransientStoragePolicy {
+			return &TransientStoragePolicy{
+				Allowed:  func(addr common.Address) bool { return addr != denied },
+				MaxSlots: 1,
+			}
+		},
+	})
+	evm, err := run(contract, code)
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if have, want := evm.StateDB.GetState(contract, common.Hash{}), common.BigToHash(big.NewInt(42)); have != want {
+		t.Errorf("loaded value mismatch: have %x, want %x", have, want)
+	}
+	if _, err := run(denied, code); err != ErrTransientStorageDenied {
+		t.Errorf("denied contract error mismatch: have %v, want %v", err, ErrTransientStorageDenied)
+	}
+	// TSTORE(0, 1), TSTORE(1, 1), STOP
+	if _, err := run(contract, "0x600160005d600160015d00"); err != ErrTransientStorageLimit {
+		t.Errorf("slot limit error mismatch: have %v, want %v", err, ErrTransientStorageLimit)
+	}
+	// TSTORE(0, 1), TSTORE(0, 0), TSTORE(1, 1), STOP
+	if _, err := run(contract, "0x600160005d600060005d600160015d00"); err != nil {
+		t.Errorf("cleared slot not released: %v", err)
+	}
+}
+
+func TestSelfDestructHook(t *testing.T) {
+	defer unregisterEVMHooks()
+
+	var (
+		errBlocked = errors.New("self-destruct blocked")
+		redirected = common.HexToAddress("0xc0ffee01")
+		blocked    = common.HexToAddress("0xc0ffee02")
+		treasury   = common.HexToAddress("0x7ea5")
+		reserved   = common.HexToAddress("0x0100000000000000000000000000000000000000")
+		destructed []common.Address
+	)
+	RegisterEVMHooks(EVMHooks{
+		SelfDestruct: func(evm *EVM, addr, beneficiary common.Address, balance *big.Int) (common.Address, error) {
+			if addr == blocked {
+				return common.Address{}, errBlocked
+			}
+			destructed = append(destructed, addr)
+			if beneficiary == reserved {
+				return treasury, nil
+			}
+			return beneficiary, nil
+		},
+	})
+	evm := newStatefulTestEVM()
+	// SELFDESTRUCT(reserved)
+	code := hexutil.MustDecode("0x73" + common.Bytes2Hex(reserved.Bytes()) + "ff")
+	for _, addr := range []common.Address{redirected, blocked} {
+		evm.StateDB.SetCode(addr, code)
+		evm.StateDB.AddBalance(addr, big.NewInt(100))
+	}
+	if _, _, err := evm.Call(AccountRef(common.Address{}), redirected, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if have := evm.StateDB.GetBalance(treasury); have.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("redirected balance mismatch: have %v, want 100", have)
+	}
+	if have := evm.StateDB.GetBalance(reserved); have.Sign() != 0 {
+		t.Errorf("reserved address funded: %v", have)
+	}
+	if _, _, err := evm.Call(AccountRef(common.Address{}), blocked, nil, 100000, new(big.Int)); err != errBlocked {
+		t.Errorf("blocked self-destruct mismatch: have %v, want %v", err, errBlocked)
+	}
+	if evm.StateDB.HasSuicided(blocked) || len(destructed) != 1 || destructed[0] != redirected {
+		t.Errorf("destructions mismatch: %v", destructed)
+	}
+}
+
+func TestHookLogger(t *testing.T) {
+	defer unregisterRulesHooks()
+	defer unregisterEVMHooks()
+
+	errDenied := errors.New("denied")
+	RegisterRulesHooks(RulesHooks{
+		MaxCodeSize: func(rules params.Rules) int { return 1 << 20 },
+	})
+	RegisterEVMHooks(EVMHooks{
+		CheckTransfer: func(evm *EVM, from, to common.Address, value *big.Int) error { return errDenied },
+	})
+	var (
+		logger = NewHookLogger()
+		evm    = newStatefulTestEVM()
+	)
+	evm = NewEVM(evm.Context, evm.StateDB, evm.chainConfig, Config{Debug: true, Tracer: logger})
+	if _, _, err := evm.Call(AccountRef(common.Address{}), common.HexToAddress("0xc0ffee"), nil, 100000, big.NewInt(1)); err != errDenied {
+		t.Fatalf("call error mismatch: have %v, want %v", err, errDenied)
+	}
+	records := logger.Records()
+	if len(records) != 2 {
+		t.Fatalf("record count mismatch: have %d, want 2", len(records))
+	}
+	if records[0].Hook != "RulesHooks.MaxCodeSize" || records[0].Result != 1<<20 {
+		t.Errorf("rules hook record mismatch: %+v", records[0])
+	}
+	if records[1].Hook != "EVMHooks.CheckTransfer" || records[1].Err != errDenied.Error() || len(records[1].Args) != 3 {
+		t.Errorf("transfer hook record mismatch: %+v", records[1])
+	}
+	// Hooks must not be reported to tracers that don't ask for them
+	evm = NewEVM(evm.Context, evm.StateDB, evm.chainConfig, Config{Debug: true, Tracer: NewStructLogger(nil)})
+	if evm.hookTracer != nil {
+		t.Errorf("hook tracer set for struct logger")
+	}
+}
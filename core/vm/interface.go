@@ -18,8 +18,10 @@ package vm
 
 import (
 	"math/big"
+	"sync"
 
 	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/state"
 	"github.com/ava-labs/go-ethereum/core/types"
 )
 
@@ -31,6 +33,11 @@ type StateDB interface {
 	AddBalance(common.Address, *big.Int)
 	GetBalance(common.Address) *big.Int
 
+	// SubBalanceWithReason and AddBalanceWithReason are like SubBalance and
+	// AddBalance, additionally reporting why the balance changed.
+	SubBalanceWithReason(common.Address, *big.Int, types.BalanceChangeReason)
+	AddBalanceWithReason(common.Address, *big.Int, types.BalanceChangeReason)
+
 	GetNonce(common.Address) uint64
 	SetNonce(common.Address, uint64)
 
@@ -47,6 +54,9 @@ type StateDB interface {
 	GetState(common.Address, common.Hash) common.Hash
 	SetState(common.Address, common.Hash, common.Hash)
 
+	GetTransientState(common.Address, common.Hash) common.Hash
+	SetTransientState(common.Address, common.Hash, common.Hash)
+
 	Suicide(common.Address) bool
 	HasSuicided(common.Address) bool
 
@@ -60,21 +70,171 @@ type StateDB interface {
 	RevertToSnapshot(int)
 	Snapshot() int
 
+	// AppendJournalEntry records a modification of auxiliary state, which is
+	// undone by invoking revert if the snapshot it was made in is reverted.
+	// The account at the dirtied address, if any, is considered modified.
+	AppendJournalEntry(revert func(), dirtied *common.Address)
+
 	AddLog(*types.Log)
 	AddPreimage(common.Hash, []byte)
 
 	ForEachStorage(common.Address, func(common.Hash, common.Hash) bool) error
+	IterateStorage(addr common.Address, start common.Hash, limit int, cb func(keyHash, value common.Hash)) (*common.Hash, error)
+}
+
+// StateReader is the read-only subset of StateDB. Use NewStateReader to hand out
+// a StateDB without its mutating methods.
+type StateReader interface {
+	GetBalance(common.Address) *big.Int
+	GetNonce(common.Address) uint64
+
+	GetCodeHash(common.Address) common.Hash
+	GetCode(common.Address) []byte
+	GetCodeSize(common.Address) int
+
+	GetRefund() uint64
+
+	GetCommittedState(common.Address, common.Hash) common.Hash
+	GetState(common.Address, common.Hash) common.Hash
+
+	HasSuicided(common.Address) bool
+	Exist(common.Address) bool
+	Empty(common.Address) bool
+
+	// ForEachStorage iterates over the storage of the given account, calling
+	// the callback with every key and value until it returns false.
+	ForEachStorage(common.Address, func(common.Hash, common.Hash) bool) error
+
+	// IterateStorage calls cb with up to limit non-empty storage slots of the
+	// given account, in ascending order of their key hashes from start on. It
+	// returns the key hash to resume from, or nil once all slots were visited.
+	// Unlike ForEachStorage, it sees uncommitted modifications and doesn't
+	// rely on preimages, so it's safe to use from consensus code.
+	IterateStorage(addr common.Address, start common.Hash, limit int, cb func(keyHash, value common.Hash)) (*common.Hash, error)
+}
+
+// readOnlyState wraps a StateDB so that type assertions can't be used to get
+// back to its mutating methods.
+type readOnlyState struct {
+	StateReader
+}
+
+// NewStateReader returns a read-only view of the given state database, which
+// can't be used to modify the state, not even through type assertions.
+func NewStateReader(db StateDB) StateReader {
+	return readOnlyState{db}
+}
+
+// concurrentState serves the reads of committed state from a pool of
+// independent state databases, one per concurrent read.
+type concurrentState struct {
+	template *state.StateDB // Untouched state the pooled ones are copied from
+	pool     sync.Pool
+}
+
+// NewConcurrentStateReader returns a read-only view of the state committed with
+// the given root that is safe for concurrent use, e.g. by precompiles or hooks
+// spreading many independent reads (like batch allow list checks) over
+// goroutines. Every read is served by a state database of its own over the
+// shared trie database, which is safe for concurrent use, so reads proceed in
+// parallel. The view only sees committed state, not the uncommitted
+// modifications of the block or transaction being executed. The callbacks of
+// ForEachStorage and IterateStorage must not access the view.
+func NewConcurrentStateReader(db state.Database, root common.Hash) (StateReader, error) {
+	template, err := state.New(root, db)
+	if err != nil {
+		return nil, err
+	}
+	return &concurrentState{template: template}, nil
+}
+
+// acquire returns a state database for the exclusive use of the caller until
+// it is released.
+func (s *concurrentState) acquire() *state.StateDB {
+	if statedb, ok := s.pool.Get().(*state.StateDB); ok {
+		return statedb
+	}
+	return s.template.Copy()
+}
+
+// release returns a state database obtained from acquire to the pool.
+func (s *concurrentState) release(statedb *state.StateDB) {
+	s.pool.Put(statedb)
+}
+
+func (s *concurrentState) GetBalance(addr common.Address) *big.Int {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.GetBalance(addr)
+}
+
+func (s *concurrentState) GetNonce(addr common.Address) uint64 {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.GetNonce(addr)
+}
+
+func (s *concurrentState) GetCodeHash(addr common.Address) common.Hash {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.GetCodeHash(addr)
+}
+
+func (s *concurrentState) GetCode(addr common.Address) []byte {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.GetCode(addr)
+}
+
+func (s *concurrentState) GetCodeSize(addr common.Address) int {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.GetCodeSize(addr)
+}
+
+// GetRefund returns zero, as committed state has no refund counter.
+func (s *concurrentState) GetRefund() uint64 {
+	return 0
+}
+
+func (s *concurrentState) GetCommittedState(addr common.Address, key common.Hash) common.Hash {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.GetCommittedState(addr, key)
+}
+
+func (s *concurrentState) GetState(addr common.Address, key common.Hash) common.Hash {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.GetState(addr, key)
+}
+
+func (s *concurrentState) HasSuicided(addr common.Address) bool {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.HasSuicided(addr)
+}
+
+func (s *concurrentState) Exist(addr common.Address) bool {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.Exist(addr)
+}
+
+func (s *concurrentState) Empty(addr common.Address) bool {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.Empty(addr)
+}
+
+func (s *concurrentState) ForEachStorage(addr common.Address, cb func(common.Hash, common.Hash) bool) error {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.ForEachStorage(addr, cb)
 }
 
-// CallContext provides a basic interface for the EVM calling conventions. The EVM
-// depends on this context being implemented for doing subcalls and initialising new EVM contracts.
-type CallContext interface {
-	// Call another contract
-	Call(env *EVM, me ContractRef, addr common.Address, data []byte, gas, value *big.Int) ([]byte, error)
-	// Take another's contract code and execute within our own context
-	CallCode(env *EVM, me ContractRef, addr common.Address, data []byte, gas, value *big.Int) ([]byte, error)
-	// Same as CallCode except sender and value is propagated from parent to child scope
-	DelegateCall(env *EVM, me ContractRef, addr common.Address, data []byte, gas *big.Int) ([]byte, error)
-	// Create a new contract
-	Create(env *EVM, me ContractRef, data []byte, gas, value *big.Int) ([]byte, common.Address, error)
+func (s *concurrentState) IterateStorage(addr common.Address, start common.Hash, limit int, cb func(keyHash, value common.Hash)) (*common.Hash, error) {
+	statedb := s.acquire()
+	defer s.release(statedb)
+	return statedb.IterateStorage(addr, start, limit, cb)
 }
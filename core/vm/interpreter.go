@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"hash"
 	"sync/atomic"
+	"time"
 
 	"github.com/ava-labs/go-ethereum/common"
 	"github.com/ava-labs/go-ethereum/common/math"
@@ -39,6 +40,9 @@ type Config struct {
 	EVMInterpreter   string // External EVM interpreter options
 
 	ExtraEips []int // Additional EIPS that are to be enabled
+
+	OpcodeHistograms bool         // Enables per block opcode gas and count histograms, collected by the state processor
+	OpcodeStats      *OpcodeStats // Per-opcode gas and count accumulator, if any
 }
 
 // Interpreter is used to run Ethereum based contracts and will utilise the
@@ -83,6 +87,8 @@ type EVMInterpreter struct {
 
 	readOnly   bool   // Whether to throw on stateful modifications
 	returnData []byte // Last CALL's return data for subsequent reuse
+
+	memoryGas MemoryGasFunc // Memory expansion pricing set by the rules hooks, if any
 }
 
 // NewEVMInterpreter returns a new instance of the Interpreter.
@@ -115,13 +121,18 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 				log.Error("EIP activation failed", "eip", eip, "error", err)
 			}
 		}
+		applyRulesHooks(evm, &jt)
 		cfg.JumpTable = jt
 	}
 
-	return &EVMInterpreter{
+	in := &EVMInterpreter{
 		evm: evm,
 		cfg: cfg,
 	}
+	if rulesHooks.MemoryGas != nil {
+		in.memoryGas = rulesHooks.MemoryGas(evm.chainRules)
+	}
+	return in
 }
 
 // Run loops and evaluates the contract's code with the given input data and returns
@@ -162,7 +173,7 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 	var (
 		op    OpCode        // current opcode
 		mem   = NewMemory() // bound memory
-		stack = newstack()  // local stack
+		stack = NewStack()  // local stack
 		// For optimisation reason we're using uint64 as the program counter.
 		// It's theoretically possible to go above 2^64. The YP defines the PC
 		// to be uint256. Practically much less so feasible.
@@ -173,7 +184,14 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		gasCopy uint64 // for Tracer to log gas remaining before execution
 		logged  bool   // deferred Tracer should ignore already logged steps
 		res     []byte // result of the opcode execution function
+
+		step = interpreterHooks.Step // lightweight instrumentation hook
+		halt = interpreterHooks.Halt // custom halting condition
 	)
+	if halt != nil && in.evm.started.IsZero() {
+		in.evm.started = time.Now()
+	}
+	mem.gasCost = in.memoryGas
 	contract.Input = input
 
 	// Reclaim the stack as an int pool when the execution stops
@@ -203,12 +221,27 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		// Get the operation from the jump table and validate the stack to ensure there are
 		// enough stack items available to perform the operation.
 		op = contract.GetOp(pc)
+		if step != nil {
+			step(pc, op, contract.Gas)
+		}
+		if halt != nil {
+			// Abort every enclosing frame once execution was halted
+			if in.evm.halted != nil {
+				return nil, in.evm.halted
+			}
+			if in.evm.steps++; in.evm.steps%HaltCheckInterval == 0 {
+				if err := halt(in.evm, in.evm.steps, time.Since(in.evm.started)); err != nil {
+					in.evm.halted = err
+					return nil, err
+				}
+			}
+		}
 		operation := in.cfg.JumpTable[op]
 		if !operation.valid {
 			return nil, fmt.Errorf("invalid opcode 0x%x", int(op))
 		}
 		// Validate stack
-		if sLen := stack.len(); sLen < operation.minStack {
+		if sLen := stack.Len(); sLen < operation.minStack {
 			return nil, fmt.Errorf("stack underflow (%d <=> %d)", sLen, operation.minStack)
 		} else if sLen > operation.maxStack {
 			return nil, fmt.Errorf("stack limit reached %d (%d)", sLen, operation.maxStack)
@@ -260,6 +293,14 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		if memorySize > 0 {
 			mem.Resize(memorySize)
 		}
+		if in.cfg.OpcodeStats != nil {
+			charged := cost
+			switch op {
+			case CALL, CALLCODE, DELEGATECALL, STATICCALL:
+				charged -= in.evm.callGasTemp
+			}
+			in.cfg.OpcodeStats.record(op, charged)
+		}
 
 		if in.cfg.Debug {
 			in.cfg.Tracer.CaptureState(in.evm, pc, op, gasCopy, cost, mem, stack, contract, in.evm.depth, err)
@@ -32,14 +32,14 @@ type intPool struct {
 }
 
 func newIntPool() *intPool {
-	return &intPool{pool: newstack()}
+	return &intPool{pool: NewStack()}
 }
 
 // get retrieves a big int from the pool, allocating one if the pool is empty.
 // Note, the returned int's value is arbitrary and will not be zeroed!
 func (p *intPool) get() *big.Int {
-	if p.pool.len() > 0 {
-		return p.pool.pop()
+	if p.pool.Len() > 0 {
+		return p.pool.Pop()
 	}
 	return new(big.Int)
 }
@@ -47,8 +47,8 @@ func (p *intPool) get() *big.Int {
 // getZero retrieves a big int from the pool, setting it to zero or allocating
 // a new one if the pool is empty.
 func (p *intPool) getZero() *big.Int {
-	if p.pool.len() > 0 {
-		return p.pool.pop().SetUint64(0)
+	if p.pool.Len() > 0 {
+		return p.pool.Pop().SetUint64(0)
 	}
 	return new(big.Int)
 }
@@ -65,7 +65,7 @@ func (p *intPool) put(is ...*big.Int) {
 		if verifyPool {
 			i.Set(checkVal)
 		}
-		p.pool.push(i)
+		p.pool.Push(i)
 	}
 }
 
@@ -66,6 +66,7 @@ type StructLog struct {
 	Storage       map[common.Hash]common.Hash `json:"-"`
 	Depth         int                         `json:"depth"`
 	RefundCounter uint64                      `json:"refund"`
+	Extra         map[string]interface{}      `json:"extra,omitempty"`
 	Err           error                       `json:"-"`
 }
 
@@ -151,10 +152,10 @@ func (l *StructLogger) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost ui
 
 	// capture SSTORE opcodes and determine the changed value and store
 	// it in the local storage container.
-	if op == SSTORE && stack.len() >= 2 {
+	if op == SSTORE && stack.Len() >= 2 {
 		var (
-			value   = common.BigToHash(stack.data[stack.len()-2])
-			address = common.BigToHash(stack.data[stack.len()-1])
+			value   = common.BigToHash(stack.data[stack.Len()-2])
+			address = common.BigToHash(stack.data[stack.Len()-1])
 		)
 		l.changedValues[contract.Address()][address] = value
 	}
@@ -178,7 +179,10 @@ func (l *StructLogger) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost ui
 		storage = l.changedValues[contract.Address()].Copy()
 	}
 	// create a new snapshot of the EVM.
-	log := StructLog{pc, op, gas, cost, mem, memory.Len(), stck, storage, depth, env.StateDB.GetRefund(), err}
+	log := StructLog{pc, op, gas, cost, mem, memory.Len(), stck, storage, depth, env.StateDB.GetRefund(), nil, err}
+	if len(structLogHooks) > 0 {
+		log.Extra = structLogStepExtras(env, pc, op, contract, depth)
+	}
 
 	l.logs = append(l.logs, log)
 	return nil
@@ -0,0 +1,85 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import "fmt"
+
+// StructLogHooks allows chains built on top of go-ethereum to attach their own
+// fields to structured traces, e.g. multicoin balance changes. The fields are
+// reported under the namespace the hooks are registered with. Every hook is
+// optional; returning nil omits the field.
+type StructLogHooks struct {
+	// Step returns the extra field for a single execution step, computed
+	// before the opcode is executed.
+	Step func(env *EVM, pc uint64, op OpCode, contract *Contract, depth int) interface{}
+
+	// Transaction returns the extra field for a transaction once it has been
+	// executed in full.
+	Transaction func(env *EVM) interface{}
+}
+
+// structLogHooks are the hooks installed by RegisterStructLogHooks, keyed by
+// namespace.
+var structLogHooks = make(map[string]StructLogHooks)
+
+// RegisterStructLogHooks installs hooks adding fields under the given
+// namespace to structured traces. It is not safe for concurrent use and must
+// be called during initialisation. It panics if the namespace is already
+// taken.
+func RegisterStructLogHooks(namespace string, hooks StructLogHooks) {
+	if _, ok := structLogHooks[namespace]; ok {
+		panic(fmt.Sprintf("vm: struct log hooks for %q already registered", namespace))
+	}
+	structLogHooks[namespace] = hooks
+}
+
+// structLogStepExtras returns the extra fields of a single execution step by
+// namespace, or nil if there are none.
+func structLogStepExtras(env *EVM, pc uint64, op OpCode, contract *Contract, depth int) map[string]interface{} {
+	var extras map[string]interface{}
+	for namespace, hooks := range structLogHooks {
+		if hooks.Step == nil {
+			continue
+		}
+		if field := hooks.Step(env, pc, op, contract, depth); field != nil {
+			if extras == nil {
+				extras = make(map[string]interface{})
+			}
+			extras[namespace] = field
+		}
+	}
+	return extras
+}
+
+// StructLogExtras returns the extra fields of an executed transaction by
+// namespace, or nil if there are none. The EVM must be the one the
+// transaction was traced in.
+func StructLogExtras(env *EVM) map[string]interface{} {
+	var extras map[string]interface{}
+	for namespace, hooks := range structLogHooks {
+		if hooks.Transaction == nil {
+			continue
+		}
+		if field := hooks.Transaction(env); field != nil {
+			if extras == nil {
+				extras = make(map[string]interface{})
+			}
+			extras[namespace] = field
+		}
+	}
+	return extras
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/go-ethereum/common"
+)
+
+// HookRecord describes a single decision made by a hook registered through
+// RegisterRulesHooks, RegisterEVMHooks or the precompile registries.
+type HookRecord struct {
+	Hook   string        `json:"hook"`             // Name of the hook, e.g. "EVMHooks.BeforeCall"
+	Depth  int           `json:"depth"`            // Call depth the hook was consulted at
+	Args   []interface{} `json:"args,omitempty"`   // Arguments passed to the hook
+	Result interface{}   `json:"result,omitempty"` // Decision made by the hook, if any
+	Err    string        `json:"error,omitempty"`  // Error returned by the hook, if any
+}
+
+// HookTracer is a Tracer that is additionally notified of every hook decision
+// made while executing. Hooks resolved once per EVM, such as the RulesHooks,
+// are reported when the EVM is created. Note that reference types are actual
+// VM data structures; make copies if you need to retain them.
+type HookTracer interface {
+	Tracer
+	CaptureHook(env *EVM, record *HookRecord) error
+}
+
+// HookLogger is a HookTracer recording the hook decisions made during
+// execution, e.g. to debug the rules a chain configures. It ignores the
+// execution itself.
+type HookLogger struct {
+	records []HookRecord
+}
+
+// NewHookLogger returns a new hook decision logger.
+func NewHookLogger() *HookLogger {
+	return &HookLogger{}
+}
+
+// CaptureStart implements the Tracer interface.
+func (l *HookLogger) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState implements the Tracer interface.
+func (l *HookLogger) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureFault implements the Tracer interface.
+func (l *HookLogger) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements the Tracer interface.
+func (l *HookLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	return nil
+}
+
+// CaptureHook implements the HookTracer interface, recording the decision.
+func (l *HookLogger) CaptureHook(env *EVM, record *HookRecord) error {
+	l.records = append(l.records, *record)
+	return nil
+}
+
+// Records returns the captured hook decisions.
+func (l *HookLogger) Records() []HookRecord { return l.records }
+
+// WriteHookRecords writes hook decisions in a readable format to the given
+// writer.
+func WriteHookRecords(writer io.Writer, records []HookRecord) {
+	for _, record := range records {
+		fmt.Fprintf(writer, "%-32s depth=%d", record.Hook, record.Depth)
+		for i, arg := range record.Args {
+			fmt.Fprintf(writer, " arg%d=%v", i, arg)
+		}
+		if record.Result != nil {
+			fmt.Fprintf(writer, " result=%v", record.Result)
+		}
+		if record.Err != "" {
+			fmt.Fprintf(writer, " ERROR: %v", record.Err)
+		}
+		fmt.Fprintln(writer)
+	}
+}
@@ -64,6 +64,9 @@ func (l *JSONLogger) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint
 	if !l.cfg.DisableStack {
 		log.Stack = stack.Data()
 	}
+	if len(structLogHooks) > 0 {
+		log.Extra = structLogStepExtras(env, pc, op, contract, depth)
+	}
 	return l.encoder.Encode(log)
 }
 
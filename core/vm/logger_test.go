@@ -53,11 +53,11 @@ func TestStoreCapture(t *testing.T) {
 		env      = NewEVM(Context{}, &dummyStatedb{}, params.TestChainConfig, Config{})
 		logger   = NewStructLogger(nil)
 		mem      = NewMemory()
-		stack    = newstack()
+		stack    = NewStack()
 		contract = NewContract(&dummyContractRef{}, &dummyContractRef{}, new(big.Int), 0)
 	)
-	stack.push(big.NewInt(1))
-	stack.push(big.NewInt(0))
+	stack.Push(big.NewInt(1))
+	stack.Push(big.NewInt(0))
 	var index common.Hash
 	logger.CaptureState(env, 0, SSTORE, 0, 0, mem, stack, contract, 0, nil)
 	if len(logger.changedValues[contract.Address()]) == 0 {
@@ -68,3 +68,35 @@ func TestStoreCapture(t *testing.T) {
 		t.Errorf("expected %x, got %x", exp, logger.changedValues[contract.Address()][index])
 	}
 }
+
+func TestStructLogExtras(t *testing.T) {
+	RegisterStructLogHooks("multicoin", StructLogHooks{
+		Step: func(env *EVM, pc uint64, op OpCode, contract *Contract, depth int) interface{} {
+			if op != SSTORE {
+				return nil
+			}
+			return pc
+		},
+		Transaction: func(env *EVM) interface{} { return "done" },
+	})
+	defer delete(structLogHooks, "multicoin")
+
+	var (
+		env      = NewEVM(Context{}, &dummyStatedb{}, params.TestChainConfig, Config{})
+		logger   = NewStructLogger(nil)
+		contract = NewContract(&dummyContractRef{}, &dummyContractRef{}, new(big.Int), 0)
+	)
+	logger.CaptureState(env, 0, PUSH1, 0, 0, NewMemory(), NewStack(), contract, 1, nil)
+	logger.CaptureState(env, 7, SSTORE, 0, 0, NewMemory(), NewStack(), contract, 1, nil)
+
+	logs := logger.StructLogs()
+	if logs[0].Extra != nil {
+		t.Errorf("unexpected extra fields: %v", logs[0].Extra)
+	}
+	if pc := logs[1].Extra["multicoin"]; pc != uint64(7) {
+		t.Errorf("step field mismatch: have %v, want 7", pc)
+	}
+	if extras := StructLogExtras(env); extras["multicoin"] != "done" {
+		t.Errorf("transaction field mismatch: %v", extras)
+	}
+}
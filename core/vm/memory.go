@@ -27,6 +27,7 @@ import (
 type Memory struct {
 	store       []byte
 	lastGasCost uint64
+	gasCost     MemoryGasFunc // Custom expansion pricing, nil for the quadratic default
 }
 
 // NewMemory returns a new memory model.
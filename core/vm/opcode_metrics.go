@@ -0,0 +1,78 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/go-ethereum/metrics"
+)
+
+// OpcodeStats accumulates the number of times each opcode was executed and the
+// gas charged for it, e.g. over the transactions of a single block. The gas of
+// the call family excludes the gas forwarded to the callee, which is accounted
+// for by the opcodes executed in the sub call.
+type OpcodeStats struct {
+	Count [256]uint64 // Number of executions by opcode
+	Gas   [256]uint64 // Gas charged by opcode
+}
+
+// record accounts a single execution of the given opcode.
+func (s *OpcodeStats) record(op OpCode, cost uint64) {
+	s.Count[op]++
+	s.Gas[op] += cost
+}
+
+// opcodeHistograms are the metrics collected for a single opcode, registered
+// as vm/opcode/<name>/{count,gas}.
+type opcodeHistograms struct {
+	count metrics.Histogram // Executions per sample
+	gas   metrics.Histogram // Gas charged per sample
+}
+
+var (
+	opcodeHistogramsLock sync.Mutex
+	opcodeHistogramsByOp [256]*opcodeHistograms
+)
+
+// Report samples the accumulated statistics into the per-opcode histograms of
+// the default metrics registry and resets them. Histograms are registered the
+// first time their opcode is executed; from then on every report samples them,
+// zero included, so the histograms reflect the distribution across reports.
+func (s *OpcodeStats) Report() {
+	opcodeHistogramsLock.Lock()
+	defer opcodeHistogramsLock.Unlock()
+
+	for op := range opcodeHistogramsByOp {
+		h := opcodeHistogramsByOp[op]
+		if h == nil {
+			if s.Count[op] == 0 {
+				continue
+			}
+			prefix := fmt.Sprintf("vm/opcode/%v/", OpCode(op))
+			h = &opcodeHistograms{
+				count: metrics.NewRegisteredHistogram(prefix+"count", nil, metrics.NewExpDecaySample(1028, 0.015)),
+				gas:   metrics.NewRegisteredHistogram(prefix+"gas", nil, metrics.NewExpDecaySample(1028, 0.015)),
+			}
+			opcodeHistogramsByOp[op] = h
+		}
+		h.count.Update(int64(s.Count[op]))
+		h.gas.Update(int64(s.Gas[op]))
+	}
+	*s = OpcodeStats{}
+}
@@ -0,0 +1,81 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/metrics"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+func TestOpcodeStats(t *testing.T) {
+	defer func(enabled bool) { metrics.Enabled = enabled }(metrics.Enabled)
+	metrics.Enabled = true
+
+	var (
+		stats  = new(OpcodeStats)
+		evm    = newStatefulTestEVM()
+		caller = common.HexToAddress("0xc0ffee01")
+		callee = common.HexToAddress("0xc0ffee02")
+	)
+	evm = NewEVM(evm.Context, evm.StateDB, evm.chainConfig, Config{OpcodeStats: stats})
+
+	// PUSH1 0 (x4), PUSH4 callee, PUSH2 0xffff, STATICCALL, STOP
+	evm.StateDB.SetCode(caller, hexutil.MustDecode("0x600060006000600063c0ffee0261fffffa00"))
+	// PUSH1 0, SLOAD, STOP
+	evm.StateDB.SetCode(callee, hexutil.MustDecode("0x60005400"))
+	if _, _, err := evm.Call(AccountRef(common.Address{}), caller, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	for _, test := range []struct {
+		op         OpCode
+		count, gas uint64
+	}{
+		{PUSH1, 5, 5 * GasFastestStep},
+		{PUSH4, 1, GasFastestStep},
+		{PUSH2, 1, GasFastestStep},
+		{STATICCALL, 1, params.CallGasEIP150},
+		{SLOAD, 1, params.SloadGasEIP150},
+		{STOP, 2, 0},
+		{ADD, 0, 0},
+	} {
+		if count := stats.Count[test.op]; count != test.count {
+			t.Errorf("%v: count mismatch: have %d, want %d", test.op, count, test.count)
+		}
+		if gas := stats.Gas[test.op]; gas != test.gas {
+			t.Errorf("%v: gas mismatch: have %d, want %d", test.op, gas, test.gas)
+		}
+	}
+	stats.Report()
+	if *stats != (OpcodeStats{}) {
+		t.Errorf("statistics not reset after report")
+	}
+	h, ok := metrics.DefaultRegistry.Get("vm/opcode/SLOAD/gas").(metrics.Histogram)
+	if !ok {
+		t.Fatalf("SLOAD gas histogram not registered")
+	}
+	if count, max := h.Count(), h.Max(); count != 1 || max != int64(params.SloadGasEIP150) {
+		t.Errorf("SLOAD gas histogram mismatch: have %d samples of max %d, want 1 of %d", count, max, params.SloadGasEIP150)
+	}
+	if metrics.DefaultRegistry.Get("vm/opcode/ADD/gas") != nil {
+		t.Errorf("histogram registered for unexecuted opcode")
+	}
+}
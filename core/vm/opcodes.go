@@ -121,6 +121,13 @@ const (
 	JUMPDEST
 )
 
+// 0x5c range - transient storage, only defined if enabled through the rules
+// hooks (EIP-1153).
+const (
+	TLOAD  OpCode = 0x5c
+	TSTORE OpCode = 0x5d
+)
+
 // 0x60 range.
 const (
 	PUSH1 OpCode = 0x60 + iota
@@ -297,6 +304,8 @@ var opCodeToString = map[OpCode]string{
 	MSIZE:    "MSIZE",
 	GAS:      "GAS",
 	JUMPDEST: "JUMPDEST",
+	TLOAD:    "TLOAD",
+	TSTORE:   "TSTORE",
 
 	// 0x60 range - push.
 	PUSH1:  "PUSH1",
@@ -462,6 +471,8 @@ var stringToOp = map[string]OpCode{
 	"MSIZE":          MSIZE,
 	"GAS":            GAS,
 	"JUMPDEST":       JUMPDEST,
+	"TLOAD":          TLOAD,
+	"TSTORE":         TSTORE,
 	"PUSH1":          PUSH1,
 	"PUSH2":          PUSH2,
 	"PUSH3":          PUSH3,
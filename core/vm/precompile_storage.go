@@ -0,0 +1,283 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/crypto"
+)
+
+// PrecompileStorage is a namespaced key/value view of the storage of the
+// account a stateful precompile acts on, see PrecompileEnvironment.Self. Keys
+// are hashed together with the namespace and layout version into storage
+// slots, so that distinct namespaces, versions and keys never collide.
+//
+// Storage accesses are not charged for, precompiles must charge for them
+// themselves. Modifications fail with an error in read-only environments.
+type PrecompileStorage struct {
+	env    *PrecompileEnvironment
+	prefix []byte // Layout version followed by the encoded namespaces
+}
+
+// Storage returns a view of the precompile's storage within the given
+// namespace. The layout version is mixed into every slot, so bumping it moves
+// the data to fresh slots, e.g. when migrating to an incompatible layout.
+func (env *PrecompileEnvironment) Storage(namespace string, version uint8) *PrecompileStorage {
+	return &PrecompileStorage{
+		env:    env,
+		prefix: appendStorageKeyPart([]byte{version}, []byte(namespace)),
+	}
+}
+
+// NewPrecompileStorage returns a view of the storage of the precompile at addr
+// within the given namespace like PrecompileEnvironment.Storage, but outside of
+// any precompile execution, e.g. to initialise the storage of a precompile at
+// genesis or in tests.
+func NewPrecompileStorage(statedb StateDB, addr common.Address, namespace string, version uint8) *PrecompileStorage {
+	env := &PrecompileEnvironment{
+		evm:      &EVM{StateDB: statedb},
+		contract: NewContract(AccountRef(addr), AccountRef(addr), nil, 0),
+	}
+	return env.Storage(namespace, version)
+}
+
+// Namespace returns a view of the storage within the given namespace nested
+// within the namespace of s.
+func (s *PrecompileStorage) Namespace(name string) *PrecompileStorage {
+	prefix := make([]byte, len(s.prefix), len(s.prefix)+4+len(name))
+	copy(prefix, s.prefix)
+	return &PrecompileStorage{
+		env:    s.env,
+		prefix: appendStorageKeyPart(prefix, []byte(name)),
+	}
+}
+
+// StorageKey packs the given parts into a single key, e.g. the keys of a
+// nested mapping. Parts are length prefixed, so distinct sequences of parts
+// always result in distinct keys.
+func StorageKey(parts ...[]byte) []byte {
+	var key []byte
+	for _, part := range parts {
+		key = appendStorageKeyPart(key, part)
+	}
+	return key
+}
+
+// appendStorageKeyPart appends the length prefixed part to key.
+func appendStorageKeyPart(key []byte, part []byte) []byte {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(part)))
+	return append(append(key, size[:]...), part...)
+}
+
+// Slot returns the storage slot holding the value of the given key.
+func (s *PrecompileStorage) Slot(key []byte) common.Hash {
+	return crypto.Keccak256Hash(s.prefix, appendStorageKeyPart(nil, key))
+}
+
+// Get returns the value stored under the given key.
+func (s *PrecompileStorage) Get(key []byte) common.Hash {
+	return s.env.stateDB().GetState(s.env.Self(), s.Slot(key))
+}
+
+// Set stores the value under the given key.
+func (s *PrecompileStorage) Set(key []byte, value common.Hash) error {
+	if s.env.readOnly {
+		return errWriteProtection
+	}
+	s.env.stateDB().SetState(s.env.Self(), s.Slot(key), value)
+	return nil
+}
+
+// GetBig returns the unsigned integer stored under the given key.
+func (s *PrecompileStorage) GetBig(key []byte) *big.Int {
+	return s.Get(key).Big()
+}
+
+// SetBig stores the unsigned integer under the given key. It fails if the value
+// is negative or doesn't fit into 256 bits.
+func (s *PrecompileStorage) SetBig(key []byte, value *big.Int) error {
+	if value.Sign() < 0 || value.BitLen() > 256 {
+		return ErrStorageValueOverflow
+	}
+	return s.Set(key, common.BigToHash(value))
+}
+
+// GetUint64 returns the integer stored under the given key, truncated to 64
+// bits.
+func (s *PrecompileStorage) GetUint64(key []byte) uint64 {
+	value := s.Get(key)
+	return binary.BigEndian.Uint64(value[common.HashLength-8:])
+}
+
+// SetUint64 stores the integer under the given key.
+func (s *PrecompileStorage) SetUint64(key []byte, value uint64) error {
+	var enc common.Hash
+	binary.BigEndian.PutUint64(enc[common.HashLength-8:], value)
+	return s.Set(key, enc)
+}
+
+// GetAddress returns the address stored under the given key.
+func (s *PrecompileStorage) GetAddress(key []byte) common.Address {
+	return common.BytesToAddress(s.Get(key).Bytes())
+}
+
+// SetAddress stores the address under the given key.
+func (s *PrecompileStorage) SetAddress(key []byte, addr common.Address) error {
+	return s.Set(key, addr.Hash())
+}
+
+// GetBool returns the flag stored under the given key.
+func (s *PrecompileStorage) GetBool(key []byte) bool {
+	return s.Get(key) != (common.Hash{})
+}
+
+// SetBool stores the flag under the given key.
+func (s *PrecompileStorage) SetBool(key []byte, flag bool) error {
+	var enc common.Hash
+	if flag {
+		enc[common.HashLength-1] = 1
+	}
+	return s.Set(key, enc)
+}
+
+// GetBytes returns the byte slice stored under the given key, or nil if none
+// is stored.
+func (s *PrecompileStorage) GetBytes(key []byte) []byte {
+	size := s.GetUint64(key)
+	if size == 0 {
+		return nil
+	}
+	var (
+		chunks = s.Namespace(string(key))
+		data   = make([]byte, 0, size)
+	)
+	for i := uint64(0); uint64(len(data)) < size; i++ {
+		chunk := chunks.Get(indexKey(i))
+		if rest := size - uint64(len(data)); rest < common.HashLength {
+			data = append(data, chunk[:rest]...)
+		} else {
+			data = append(data, chunk[:]...)
+		}
+	}
+	return data
+}
+
+// SetBytes stores the byte slice under the given key, occupying one slot for
+// its length and one slot per 32 bytes of data, which are kept in the nested
+// namespace named after the key. Slots left over by a previous, longer value
+// are cleared.
+func (s *PrecompileStorage) SetBytes(key []byte, data []byte) error {
+	prev := s.GetUint64(key)
+	if err := s.SetUint64(key, uint64(len(data))); err != nil {
+		return err
+	}
+	var (
+		chunks = s.Namespace(string(key))
+		i      uint64
+	)
+	for ; i*common.HashLength < uint64(len(data)); i++ {
+		var chunk common.Hash
+		copy(chunk[:], data[i*common.HashLength:])
+		if err := chunks.Set(indexKey(i), chunk); err != nil {
+			return err
+		}
+	}
+	for ; i*common.HashLength < prev; i++ {
+		if err := chunks.Set(indexKey(i), common.Hash{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StorageList is a list of values kept in precompile storage, which unlike
+// the keys of a PrecompileStorage can be iterated.
+type StorageList struct {
+	storage *PrecompileStorage
+}
+
+// List returns the list with the given name within the namespace of s, which
+// is kept in the nested namespace of the same name.
+func (s *PrecompileStorage) List(name string) *StorageList {
+	return &StorageList{storage: s.Namespace(name)}
+}
+
+// indexKey returns the key of the i-th element of a list, or the i-th chunk of
+// a byte slice. The length of lists is stored under the nil key.
+func indexKey(i uint64) []byte {
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], i)
+	return key[:]
+}
+
+// Len returns the number of elements of the list.
+func (l *StorageList) Len() uint64 {
+	return l.storage.GetUint64(nil)
+}
+
+// Get returns the i-th element of the list.
+func (l *StorageList) Get(i uint64) (common.Hash, error) {
+	if i >= l.Len() {
+		return common.Hash{}, ErrStorageIndexOutOfRange
+	}
+	return l.storage.Get(indexKey(i)), nil
+}
+
+// Set replaces the i-th element of the list.
+func (l *StorageList) Set(i uint64, value common.Hash) error {
+	if i >= l.Len() {
+		return ErrStorageIndexOutOfRange
+	}
+	return l.storage.Set(indexKey(i), value)
+}
+
+// Append adds an element to the end of the list.
+func (l *StorageList) Append(value common.Hash) error {
+	size := l.Len()
+	if err := l.storage.SetUint64(nil, size+1); err != nil {
+		return err
+	}
+	return l.storage.Set(indexKey(size), value)
+}
+
+// Pop removes the last element of the list and returns it.
+func (l *StorageList) Pop() (common.Hash, error) {
+	size := l.Len()
+	if size == 0 {
+		return common.Hash{}, ErrStorageIndexOutOfRange
+	}
+	value := l.storage.Get(indexKey(size - 1))
+	if err := l.storage.SetUint64(nil, size-1); err != nil {
+		return common.Hash{}, err
+	}
+	return value, l.storage.Set(indexKey(size-1), common.Hash{})
+}
+
+// ForEach invokes fn on the elements of the list in order, until it returns
+// false.
+func (l *StorageList) ForEach(fn func(i uint64, value common.Hash) bool) {
+	size := l.Len()
+	for i := uint64(0); i < size; i++ {
+		if !fn(i, l.storage.Get(indexKey(i))) {
+			return
+		}
+	}
+}
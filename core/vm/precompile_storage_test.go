@@ -0,0 +1,117 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/state"
+)
+
+func TestPrecompileStorage(t *testing.T) {
+	defer unregisterPrecompiles()
+
+	var (
+		addr = common.Address{3}
+		evm  = newStatefulTestEVM()
+		run  func(env *PrecompileEnvironment)
+	)
+	RegisterPrecompile(RegisteredPrecompile{Address: addr, Contract: &statefulPrecompile{
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			run(env)
+			return nil, nil
+		},
+	}})
+	call := func(fn func(env *PrecompileEnvironment)) {
+		run = fn
+		if _, _, err := evm.Call(AccountRef(common.Address{}), addr, nil, 100000, new(big.Int)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	long := bytes.Repeat([]byte{0xaa}, 70)
+	call(func(env *PrecompileEnvironment) {
+		s := env.Storage("test", 1)
+		s.SetUint64([]byte("count"), 7)
+		s.SetAddress(StorageKey([]byte("owner"), common.Address{1}.Bytes()), common.Address{2})
+		if err := s.SetBig([]byte("big"), new(big.Int).Lsh(common.Big1, 256)); err != ErrStorageValueOverflow {
+			t.Errorf("overflow error mismatch: have %v, want %v", err, ErrStorageValueOverflow)
+		}
+		s.SetBytes([]byte("data"), long)
+		s.SetBytes([]byte("data"), []byte{1, 2, 3})
+
+		list := s.List("list")
+		for i := byte(1); i <= 3; i++ {
+			list.Append(common.Hash{i})
+		}
+		if value, err := list.Pop(); err != nil || value != (common.Hash{3}) {
+			t.Errorf("popped value mismatch: have %x, %v", value, err)
+		}
+		if _, err := list.Get(2); err != ErrStorageIndexOutOfRange {
+			t.Errorf("out of range error mismatch: have %v", err)
+		}
+		// Namespaces and versions must be kept apart
+		if value := env.Storage("test", 2).GetUint64([]byte("count")); value != 0 {
+			t.Errorf("value leaked into other version: %d", value)
+		}
+		if value := env.Storage("other", 1).GetUint64([]byte("count")); value != 0 {
+			t.Errorf("value leaked into other namespace: %d", value)
+		}
+	})
+	call(func(env *PrecompileEnvironment) {
+		s := env.Storage("test", 1)
+		if value := s.GetUint64([]byte("count")); value != 7 {
+			t.Errorf("count mismatch: have %d, want 7", value)
+		}
+		if owner := s.GetAddress(StorageKey([]byte("owner"), common.Address{1}.Bytes())); owner != (common.Address{2}) {
+			t.Errorf("owner mismatch: have %x", owner)
+		}
+		if data := s.GetBytes([]byte("data")); !bytes.Equal(data, []byte{1, 2, 3}) {
+			t.Errorf("bytes mismatch: have %x", data)
+		}
+		var values []common.Hash
+		s.List("list").ForEach(func(i uint64, value common.Hash) bool {
+			values = append(values, value)
+			return true
+		})
+		if len(values) != 2 || values[0] != (common.Hash{1}) || values[1] != (common.Hash{2}) {
+			t.Errorf("list mismatch: have %x", values)
+		}
+	})
+	// Shrinking byte slices must clear the slots left over
+	evm.StateDB.(*state.StateDB).Finalise(false)
+
+	var slots int
+	evm.StateDB.ForEachStorage(addr, func(key, value common.Hash) bool {
+		slots++
+		return true
+	})
+	if want := 1 + 1 + 1 + 1 + 3; slots != want { // count, owner, data length and chunk, list
+		t.Errorf("slot count mismatch: have %d, want %d", slots, want)
+	}
+	// Read-only environments must refuse modifications
+	run = func(env *PrecompileEnvironment) {
+		if err := env.Storage("test", 1).SetBool([]byte("flag"), true); err != errWriteProtection {
+			t.Errorf("read-only error mismatch: have %v, want %v", err, errWriteProtection)
+		}
+	}
+	if _, _, err := evm.StaticCall(AccountRef(common.Address{}), addr, nil, 100000); err != nil {
+		t.Fatal(err)
+	}
+}
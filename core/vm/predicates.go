@@ -0,0 +1,156 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/math"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+// PredicateContext provides predicate verification with the block the
+// transaction is validated or included in.
+type PredicateContext struct {
+	ChainConfig *params.ChainConfig
+	Header      *types.Header
+}
+
+// Predicater verifies the predicates addressed to a precompile. A predicate is
+// carried by an access list tuple addressed to the precompile, packed into the
+// tuple's storage keys; it is verified before the transaction is executed, e.g.
+// to check signatures over data imported from another chain.
+//
+// A predicate failing verification doesn't invalidate its transaction: the
+// outcome is exposed to the precompile, see PrecompileEnvironment.Predicates,
+// which decides how to treat it.
+type Predicater interface {
+	// PredicateGas returns the gas charged for verifying a predicate, on top
+	// of the intrinsic gas of its transaction. An error invalidates the
+	// transaction.
+	PredicateGas(predicate []byte) (uint64, error)
+
+	// VerifyPredicate verifies a predicate in the context of a block.
+	VerifyPredicate(ctx *PredicateContext, predicate []byte) error
+}
+
+// predicaters contains the registered predicaters by precompile address. It is
+// only modified during initialisation, so no locking is needed for lookups.
+var predicaters = make(map[common.Address]Predicater)
+
+// RegisterPredicater registers the predicater for the precompile at the given
+// address. Access list tuples addressed to the precompile are treated as
+// predicates whenever a precompile is active at the address; before its
+// activation and after its deactivation, they are plain access list tuples.
+//
+// RegisterPredicater is not safe for concurrent use and must be called during
+// initialisation. It panics if a predicater is already registered at the
+// address.
+func RegisterPredicater(addr common.Address, p Predicater) {
+	if _, ok := predicaters[addr]; ok {
+		panic(fmt.Sprintf("vm: predicater at %x already registered", addr))
+	}
+	predicaters[addr] = p
+}
+
+// PredicateResult is the outcome of verifying a predicate.
+type PredicateResult struct {
+	Predicate []byte
+	Err       error // Nil if the predicate was verified
+}
+
+// PredicateResults contains the outcomes of verifying the predicates of a
+// transaction by precompile address, in access list order.
+type PredicateResults map[common.Address][]PredicateResult
+
+// predicate packs the storage keys of an access list tuple into a predicate.
+func predicate(tuple *types.AccessTuple) []byte {
+	b := make([]byte, 0, len(tuple.StorageKeys)*common.HashLength)
+	for _, key := range tuple.StorageKeys {
+		b = append(b, key[:]...)
+	}
+	return b
+}
+
+// predicaterAt returns the predicater registered at the given address, if the
+// precompile at the address is active under the given rules and timestamp.
+func predicaterAt(rules params.Rules, time uint64, addr common.Address) Predicater {
+	p, ok := predicaters[addr]
+	if !ok {
+		return nil
+	}
+	if _, active := precompileAt(rules, time, addr); !active {
+		return nil
+	}
+	return p
+}
+
+// PredicateGas returns the gas charged for verifying the predicates in the
+// given access list in the block being executed.
+func (evm *EVM) PredicateGas(list types.AccessList) (uint64, error) {
+	if len(predicaters) == 0 {
+		return 0, nil
+	}
+	var total uint64
+	for i := range list {
+		p := predicaterAt(evm.chainRules, evm.time(), list[i].Address)
+		if p == nil {
+			continue
+		}
+		gas, err := p.PredicateGas(predicate(&list[i]))
+		if err != nil {
+			return 0, err
+		}
+		var overflow bool
+		if total, overflow = math.SafeAdd(total, gas); overflow {
+			return 0, errGasUintOverflow
+		}
+	}
+	return total, nil
+}
+
+// VerifyPredicates verifies the predicates in the access list of a transaction
+// validated or included in the block of the given context. It returns nil if
+// the access list contains no predicates.
+func VerifyPredicates(ctx *PredicateContext, list types.AccessList) PredicateResults {
+	if len(predicaters) == 0 || len(list) == 0 {
+		return nil
+	}
+	var (
+		time    = ctx.Header.Time
+		rules   = ctx.ChainConfig.RulesAt(ctx.Header.Number, time)
+		results PredicateResults
+	)
+	for i := range list {
+		addr := list[i].Address
+		p := predicaterAt(rules, time, addr)
+		if p == nil {
+			continue
+		}
+		if results == nil {
+			results = make(PredicateResults)
+		}
+		pred := predicate(&list[i])
+		results[addr] = append(results[addr], PredicateResult{
+			Predicate: pred,
+			Err:       p.VerifyPredicate(ctx, pred),
+		})
+	}
+	return results
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+// testPredicater accepts predicates consisting of a single all-ones key.
+type testPredicater struct{}
+
+var errTestPredicate = errors.New("invalid test predicate")
+
+func (testPredicater) PredicateGas(predicate []byte) (uint64, error) {
+	if len(predicate) == 0 {
+		return 0, errTestPredicate
+	}
+	return uint64(len(predicate)), nil
+}
+
+func (testPredicater) VerifyPredicate(ctx *PredicateContext, predicate []byte) error {
+	if !bytes.Equal(predicate, bytes.Repeat([]byte{0xff}, common.HashLength)) {
+		return errTestPredicate
+	}
+	return nil
+}
+
+// unregisterPredicaters drops all predicaters registered by a test.
+func unregisterPredicaters() {
+	predicaters = make(map[common.Address]Predicater)
+}
+
+func TestVerifyPredicates(t *testing.T) {
+	defer unregisterPrecompiles()
+	defer unregisterPredicaters()
+
+	addr := common.HexToAddress("0x0200000000000000000000000000000000000000")
+	RegisterPrecompile(RegisteredPrecompile{Address: addr, Contract: &statefulPrecompile{}, Activation: 100})
+	RegisterPredicater(addr, testPredicater{})
+
+	var (
+		valid   = common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+		invalid = common.HexToHash("0x01")
+		list    = types.AccessList{
+			{Address: addr, StorageKeys: []common.Hash{valid}},
+			{Address: common.Address{1}, StorageKeys: []common.Hash{invalid}},
+			{Address: addr, StorageKeys: []common.Hash{valid, invalid}},
+		}
+	)
+	// Before the precompile's activation, tuples addressed to it are no predicates
+	ctx := &PredicateContext{
+		ChainConfig: params.AllEthashProtocolChanges,
+		Header:      &types.Header{Number: big.NewInt(1), Time: 99},
+	}
+	if results := VerifyPredicates(ctx, list); results != nil {
+		t.Errorf("predicates verified before activation: %v", results)
+	}
+	ctx.Header.Time = 100
+	results := VerifyPredicates(ctx, list)
+	if len(results) != 1 || len(results[addr]) != 2 {
+		t.Fatalf("results mismatch: have %v", results)
+	}
+	if res := results[addr][0]; res.Err != nil || !bytes.Equal(res.Predicate, valid[:]) {
+		t.Errorf("valid predicate mismatch: have %x, %v", res.Predicate, res.Err)
+	}
+	if res := results[addr][1]; res.Err != errTestPredicate || len(res.Predicate) != 2*common.HashLength {
+		t.Errorf("invalid predicate mismatch: have %x, %v", res.Predicate, res.Err)
+	}
+	// Predicates must be charged for and exposed to the precompile
+	evm := newStatefulTestEVM()
+	evm.Context.Time = big.NewInt(100)
+	evm.Context.Predicates = results
+	if gas, err := evm.PredicateGas(list); err != nil || gas != 3*common.HashLength {
+		t.Errorf("predicate gas mismatch: have %d, %v, want %d", gas, err, 3*common.HashLength)
+	}
+	if _, err := evm.PredicateGas(types.AccessList{{Address: addr}}); err != errTestPredicate {
+		t.Errorf("empty predicate error mismatch: have %v, want %v", err, errTestPredicate)
+	}
+	var seen []PredicateResult
+	RegisterPrecompile(RegisteredPrecompile{Address: common.Address{3}, Contract: &statefulPrecompile{
+		run: func(env *PrecompileEnvironment, input []byte) ([]byte, error) {
+			seen = env.Predicates(addr)
+			return nil, nil
+		},
+	}})
+	if _, _, err := evm.Call(AccountRef(common.Address{}), common.Address{3}, nil, 100000, new(big.Int)); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 2 || seen[0].Err != nil || seen[1].Err == nil {
+		t.Errorf("predicates exposed to precompile mismatch: have %v", seen)
+	}
+}
+
+func TestRegisterPredicaterTwice(t *testing.T) {
+	defer unregisterPredicaters()
+
+	RegisterPredicater(common.Address{2}, testPredicater{})
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic")
+		}
+	}()
+	RegisterPredicater(common.Address{2}, testPredicater{})
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ava-labs/go-ethereum/common"
+)
+
+// prohibitedRange is a range of addresses sharing a common prefix.
+type prohibitedRange struct {
+	prefix common.Address
+	length int
+}
+
+// prohibitedRanges contains all address ranges reserved by ProhibitAddresses. It
+// is only modified during initialisation, so no locking is needed for lookups.
+var prohibitedRanges []prohibitedRange
+
+// ProhibitAddresses reserves every address sharing the first prefixLength bytes
+// with prefix, e.g. the address space set aside for current and future chain
+// specific precompiles. A prefix length of common.AddressLength reserves the
+// single given address. The EVM refuses to
+//
+//   - transfer value to a reserved address, including as part of a transaction
+//     or as the balance of a self-destructing contract, unless a precompile is
+//     active at the address
+//   - deploy a contract at a reserved address via CREATE, CREATE2 or a contract
+//     creation transaction
+//
+// failing the respective call or creation with ErrProhibitedAddress.
+//
+// ProhibitAddresses is not safe for concurrent use and must be called during
+// initialisation, before any EVM is created.
+func ProhibitAddresses(prefix common.Address, prefixLength int) {
+	if prefixLength <= 0 || prefixLength > common.AddressLength {
+		panic(fmt.Sprintf("vm: invalid prefix length %d for prohibited addresses %x", prefixLength, prefix))
+	}
+	prohibitedRanges = append(prohibitedRanges, prohibitedRange{prefix: prefix, length: prefixLength})
+}
+
+// IsProhibited returns whether the given address was reserved by ProhibitAddresses.
+func IsProhibited(addr common.Address) bool {
+	for _, r := range prohibitedRanges {
+		if bytes.Equal(r.prefix[:r.length], addr[:r.length]) {
+			return true
+		}
+	}
+	return false
+}
+
+// prohibitedRecipient returns whether value must not be sent to the given
+// address in the block being executed.
+func (evm *EVM) prohibitedRecipient(addr common.Address) bool {
+	if !IsProhibited(addr) {
+		return false
+	}
+	_, ok := evm.precompile(addr)
+	return !ok
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/crypto"
+)
+
+func TestProhibitedAddresses(t *testing.T) {
+	defer func() { prohibitedRanges = nil }()
+	defer unregisterPrecompiles()
+
+	var (
+		caller     = common.HexToAddress("0xca11e7")
+		reserved   = common.HexToAddress("0x0200000000000000000000000000000000000001")
+		precompile = common.HexToAddress("0x0200000000000000000000000000000000000002")
+		deployed   = crypto.CreateAddress(caller, 0)
+	)
+	ProhibitAddresses(reserved, 1)
+	ProhibitAddresses(deployed, common.AddressLength)
+	RegisterPrecompile(RegisteredPrecompile{Address: precompile, Contract: &statefulPrecompile{
+		run: func(*PrecompileEnvironment, []byte) ([]byte, error) { return nil, nil },
+	}})
+
+	if !IsProhibited(reserved) || !IsProhibited(deployed) {
+		t.Fatalf("reserved addresses not prohibited")
+	}
+	if IsProhibited(common.HexToAddress("0x0300000000000000000000000000000000000001")) {
+		t.Fatalf("unreserved address prohibited")
+	}
+	evm := newStatefulTestEVM()
+
+	// Value transfers into the reserved range fail, unless a precompile takes them
+	if _, gas, err := evm.Call(AccountRef(caller), reserved, nil, 1000, big.NewInt(1)); err != ErrProhibitedAddress || gas != 1000 {
+		t.Errorf("value transfer error mismatch: have %v (gas %d), want %v", err, gas, ErrProhibitedAddress)
+	}
+	if _, _, err := evm.Call(AccountRef(caller), reserved, nil, 1000, new(big.Int)); err != nil {
+		t.Errorf("plain call failed: %v", err)
+	}
+	if _, _, err := evm.Call(AccountRef(caller), precompile, nil, 1000, big.NewInt(1)); err != nil {
+		t.Errorf("value transfer to precompile failed: %v", err)
+	}
+	// Self-destructing contracts can't pay out their balance to reserved addresses
+	destructing := common.HexToAddress("0xc0ffee")
+	evm.StateDB.SetCode(destructing, hexutil.MustDecode("0x73"+common.Bytes2Hex(reserved.Bytes())+"ff"))
+	evm.StateDB.AddBalance(destructing, big.NewInt(100))
+	if _, _, err := evm.Call(AccountRef(caller), destructing, nil, 100000, new(big.Int)); err != ErrProhibitedAddress {
+		t.Errorf("self-destruct error mismatch: have %v, want %v", err, ErrProhibitedAddress)
+	}
+	if evm.StateDB.HasSuicided(destructing) || evm.StateDB.GetBalance(reserved).Sign() != 0 {
+		t.Errorf("balance paid out to reserved address")
+	}
+	// Contracts can't be deployed at reserved addresses
+	if _, _, _, err := evm.Create(AccountRef(caller), nil, 1000, new(big.Int)); err != ErrProhibitedAddress {
+		t.Errorf("deployment error mismatch: have %v, want %v", err, ErrProhibitedAddress)
+	}
+	if _, _, _, err := evm.Create(AccountRef(caller), nil, 1000, new(big.Int)); err != nil {
+		t.Errorf("deployment at unreserved address failed: %v", err)
+	}
+}
@@ -28,7 +28,8 @@ type Stack struct {
 	data []*big.Int
 }
 
-func newstack() *Stack {
+// NewStack returns a new, empty stack with room for the maximum stack depth.
+func NewStack() *Stack {
 	return &Stack{data: make([]*big.Int, 0, 1024)}
 }
 
@@ -37,7 +38,9 @@ func (st *Stack) Data() []*big.Int {
 	return st.data
 }
 
-func (st *Stack) push(d *big.Int) {
+// Push pushes an item onto the stack. The stack takes ownership of the item,
+// which must not be modified by the caller afterwards.
+func (st *Stack) Push(d *big.Int) {
 	// NOTE push limit (1024) is checked in baseCheck
 	//stackItem := new(big.Int).Set(d)
 	//st.data = append(st.data, stackItem)
@@ -47,35 +50,41 @@ func (st *Stack) pushN(ds ...*big.Int) {
 	st.data = append(st.data, ds...)
 }
 
-func (st *Stack) pop() (ret *big.Int) {
+// Pop removes the top item from the stack and returns it. The caller takes
+// ownership of the item and may modify or reuse it.
+func (st *Stack) Pop() (ret *big.Int) {
 	ret = st.data[len(st.data)-1]
 	st.data = st.data[:len(st.data)-1]
 	return
 }
 
-func (st *Stack) len() int {
+// Len returns the number of items on the stack.
+func (st *Stack) Len() int {
 	return len(st.data)
 }
 
-func (st *Stack) swap(n int) {
-	st.data[st.len()-n], st.data[st.len()-1] = st.data[st.len()-1], st.data[st.len()-n]
+// Swap exchanges the top item with the n'th item from the top, with the top
+// item being the first.
+func (st *Stack) Swap(n int) {
+	st.data[st.Len()-n], st.data[st.Len()-1] = st.data[st.Len()-1], st.data[st.Len()-n]
 }
 
 func (st *Stack) dup(pool *intPool, n int) {
-	st.push(pool.get().Set(st.data[st.len()-n]))
+	st.Push(pool.get().Set(st.data[st.Len()-n]))
 }
 
-func (st *Stack) peek() *big.Int {
-	return st.data[st.len()-1]
+// Peek returns the top item of the stack without removing it.
+func (st *Stack) Peek() *big.Int {
+	return st.data[st.Len()-1]
 }
 
 // Back returns the n'th item in stack
 func (st *Stack) Back(n int) *big.Int {
-	return st.data[st.len()-n-1]
+	return st.data[st.Len()-n-1]
 }
 
 func (st *Stack) require(n int) error {
-	if st.len() < n {
+	if st.Len() < n {
 		return fmt.Errorf("stack underflow (%d <=> %d)", len(st.data), n)
 	}
 	return nil
@@ -0,0 +1,110 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/params"
+)
+
+// TransientStoragePolicy enables EIP-1153 transient storage, i.e. the TLOAD
+// and TSTORE opcodes, and restricts its use. See RulesHooks.TransientStorage.
+type TransientStoragePolicy struct {
+	// Allowed reports whether the contract at the given address may use
+	// transient storage, e.g. to restrict it to an address range; nil allows
+	// every contract. TLOAD and TSTORE executed by other contracts fail like
+	// an exceptional halt, consuming all gas.
+	Allowed func(addr common.Address) bool
+
+	// MaxSlots limits the number of non-zero transient storage slots, across
+	// all contracts, held at any point of a transaction, with zero meaning
+	// unlimited. A TSTORE exceeding it fails like an exceptional halt.
+	MaxSlots int
+}
+
+// enableTransientStorage defines the TLOAD and TSTORE opcodes in the given jump
+// table (EIP-1153).
+func enableTransientStorage(jt *JumpTable) {
+	jt[TLOAD] = operation{
+		execute:     opTload,
+		constantGas: params.TloadGasEIP1153,
+		minStack:    minStack(1, 1),
+		maxStack:    maxStack(1, 1),
+		valid:       true,
+	}
+	jt[TSTORE] = operation{
+		execute:     opTstore,
+		constantGas: params.TstoreGasEIP1153,
+		minStack:    minStack(2, 0),
+		maxStack:    maxStack(2, 0),
+		writes:      true,
+		valid:       true,
+	}
+}
+
+func opTload(pc *uint64, interpreter *EVMInterpreter, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	if !interpreter.evm.transientStorageAllowed(contract.Address()) {
+		return nil, ErrTransientStorageDenied
+	}
+	loc := stack.Peek()
+	val := interpreter.evm.StateDB.GetTransientState(contract.Address(), common.BigToHash(loc))
+	loc.SetBytes(val.Bytes())
+	return nil, nil
+}
+
+func opTstore(pc *uint64, interpreter *EVMInterpreter, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	if !interpreter.evm.transientStorageAllowed(contract.Address()) {
+		return nil, ErrTransientStorageDenied
+	}
+	loc := common.BigToHash(stack.Pop())
+	val := stack.Pop()
+	err := interpreter.evm.setTransientState(contract.Address(), loc, common.BigToHash(val))
+
+	interpreter.intPool.put(val)
+	return nil, err
+}
+
+// transientStorageAllowed reports whether the contract at the given address may
+// use transient storage under the policy of the rules hooks.
+func (evm *EVM) transientStorageAllowed(addr common.Address) bool {
+	return evm.transientStorage.Allowed == nil || evm.transientStorage.Allowed(addr)
+}
+
+// setTransientState writes to transient storage, enforcing the slot limit of
+// the transient storage policy. The number of slots in use is journalled, so
+// that it's restored along with the state on reverts.
+func (evm *EVM) setTransientState(addr common.Address, key, value common.Hash) error {
+	var (
+		prev  = evm.StateDB.GetTransientState(addr, key)
+		delta int
+	)
+	switch {
+	case prev == (common.Hash{}) && value != (common.Hash{}):
+		delta = 1
+	case prev != (common.Hash{}) && value == (common.Hash{}):
+		delta = -1
+	}
+	if max := evm.transientStorage.MaxSlots; max > 0 && evm.transientSlots+delta > max {
+		return ErrTransientStorageLimit
+	}
+	if delta != 0 {
+		evm.transientSlots += delta
+		evm.StateDB.AppendJournalEntry(func() { evm.transientSlots -= delta }, nil)
+	}
+	evm.StateDB.SetTransientState(addr, key, value)
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/core"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
+	"github.com/ava-labs/go-ethereum/core/state"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
+)
+
+// ReplayConfig holds extra parameters to replay functions.
+type ReplayConfig struct {
+	Reexec      *uint64
+	RecordHooks bool // Whether to report the hook decisions made during the replay
+}
+
+// ReplayResult is the outcome of re-executing a historical transaction with the
+// currently registered hooks, next to the outcome recorded in its receipt.
+type ReplayResult struct {
+	Gas         uint64          `json:"gas"`
+	Failed      bool            `json:"failed"`
+	ReturnValue hexutil.Bytes   `json:"returnValue"`
+	Logs        []*types.Log    `json:"logs"`
+	Hooks       []vm.HookRecord `json:"hooks,omitempty"`
+
+	RecordedGas    uint64       `json:"recordedGas"`
+	RecordedFailed bool         `json:"recordedFailed"`
+	RecordedLogs   []*types.Log `json:"recordedLogs"`
+
+	// Diverged is set if the gas used, the status or the logs of the replay
+	// differ from the recorded ones.
+	Diverged bool `json:"diverged"`
+}
+
+// ReplayTransaction re-executes the given transaction on top of the state it
+// was originally executed against, using the hooks and precompiles registered
+// in the running node. Comparing the outcome with the recorded one allows the
+// validation of rule changes against historical chain data.
+func (api *PrivateDebugAPI) ReplayTransaction(ctx context.Context, hash common.Hash, config *ReplayConfig) (*ReplayResult, error) {
+	tx, blockHash, _, index := rawdb.ReadTransaction(api.eth.ChainDb(), hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %#x not found", hash)
+	}
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	msg, vmctx, statedb, err := api.computeTxEnv(blockHash, int(index), reexec)
+	if err != nil {
+		return nil, err
+	}
+	receipts := api.eth.blockchain.GetReceiptsByHash(blockHash)
+	if int(index) >= len(receipts) {
+		return nil, fmt.Errorf("receipt of transaction %#x not found", hash)
+	}
+	statedb.Prepare(hash, blockHash, int(index))
+
+	result, err := api.replayTransaction(tx, api.eth.blockchain.GetHeaderByHash(blockHash), msg, vmctx, statedb, config)
+	if err != nil {
+		return nil, err
+	}
+	receipt := receipts[index]
+	result.RecordedGas = receipt.GasUsed
+	result.RecordedFailed = receipt.Status == types.ReceiptStatusFailed
+	result.RecordedLogs = receipt.Logs
+	result.Diverged = result.Gas != result.RecordedGas || result.Failed != result.RecordedFailed || !logsEqual(result.Logs, result.RecordedLogs)
+
+	return result, nil
+}
+
+// replayTransaction executes the message created from a transaction of the
+// block with the given header on top of the given state, like block processing
+// does, using the hooks and precompiles registered in the running node. The
+// state must be prepared for the transaction. The recorded fields of the result
+// are left empty.
+func (api *PrivateDebugAPI) replayTransaction(tx *types.Transaction, header *types.Header, message core.Message, vmctx vm.Context, statedb *state.StateDB, config *ReplayConfig) (*ReplayResult, error) {
+	var (
+		cfg   vm.Config
+		hooks *vm.HookLogger
+	)
+	if config != nil && config.RecordHooks {
+		hooks = vm.NewHookLogger()
+		cfg = vm.Config{Debug: true, Tracer: hooks}
+	}
+	vmenv := vm.NewEVM(vmctx, statedb, api.eth.blockchain.Config(), cfg)
+
+	receipt, executed, err := core.ApplyTransactionMessage(vmenv, core.NewBlockGasPool(api.eth.blockchain.Config(), header), statedb, header, tx, message, new(uint64))
+	if err != nil {
+		return nil, fmt.Errorf("replay failed: %v", err)
+	}
+	result := &ReplayResult{
+		Gas:         executed.UsedGas,
+		Failed:      executed.Failed(),
+		ReturnValue: executed.ReturnData,
+		Logs:        receipt.Logs,
+	}
+	if hooks != nil {
+		result.Hooks = hooks.Records()
+	}
+	return result, nil
+}
+
+// logsEqual reports whether two sets of logs carry the same content, ignoring
+// the positional metadata derived from the chain.
+func logsEqual(a, b []*types.Log) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Address != b[i].Address || !bytes.Equal(a[i].Data, b[i].Data) || len(a[i].Topics) != len(b[i].Topics) {
+			return false
+		}
+		for j := range a[i].Topics {
+			if a[i].Topics[j] != b[i].Topics[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -201,21 +201,30 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 
 			// Fetch and execute the next block trace tasks
 			for task := range tasks {
-				signer := types.MakeSigner(api.eth.blockchain.Config(), task.block.Number())
-
+				var (
+					signer = types.MakeSigner(api.eth.blockchain.Config(), task.block.Number())
+					header = task.block.Header()
+					txs    = task.block.Transactions()
+				)
+				// Apply the state changes preceding the transactions
+				if err := api.applyBlockPreamble(task.block, task.statedb); err != nil {
+					for i := range task.results {
+						task.results[i] = &txTraceResult{Error: err.Error()}
+					}
+					log.Warn("Tracing failed", "block", task.block.NumberU64(), "err", err)
+					txs = nil
+				}
 				// Trace all the transactions contained within
-				for i, tx := range task.block.Transactions() {
-					msg, _ := tx.AsMessage(signer)
-					vmctx := core.NewEVMContext(msg, task.block.Header(), api.eth.blockchain, nil)
+				for i, tx := range txs {
+					msg, vmctx := api.txTraceContext(tx, signer, header)
+					task.statedb.Prepare(tx.Hash(), task.block.Hash(), i)
 
-					res, err := api.traceTx(ctx, msg, vmctx, task.statedb, config)
+					res, err := api.traceTx(ctx, tx, header, msg, vmctx, task.statedb, config)
 					if err != nil {
 						task.results[i] = &txTraceResult{Error: err.Error()}
 						log.Warn("Tracing failed", "hash", tx.Hash(), "block", task.block.NumberU64(), "err", err)
 						break
 					}
-					// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
-					task.statedb.Finalise(api.eth.blockchain.Config().IsEIP158(task.block.Number()))
 					task.results[i] = &txTraceResult{Result: res}
 				}
 				// Stream the result back to the user or abort on teardown
@@ -458,9 +467,15 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 	if err != nil {
 		return nil, err
 	}
+	if err := api.applyBlockPreamble(block, statedb); err != nil {
+		return nil, err
+	}
 	// Execute all the transaction contained within the block concurrently
 	var (
-		signer = types.MakeSigner(api.eth.blockchain.Config(), block.Number())
+		signer  = types.MakeSigner(api.eth.blockchain.Config(), block.Number())
+		header  = block.Header()
+		gp      = core.NewBlockGasPool(api.eth.blockchain.Config(), header)
+		usedGas uint64
 
 		txs     = block.Transactions()
 		results = make([]*txTraceResult, len(txs))
@@ -479,10 +494,11 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 
 			// Fetch and execute the next transaction trace tasks
 			for task := range jobs {
-				msg, _ := txs[task.index].AsMessage(signer)
-				vmctx := core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
+				tx := txs[task.index]
+				msg, vmctx := api.txTraceContext(tx, signer, header)
+				task.statedb.Prepare(tx.Hash(), block.Hash(), task.index)
 
-				res, err := api.traceTx(ctx, msg, vmctx, task.statedb, config)
+				res, err := api.traceTx(ctx, tx, header, msg, vmctx, task.statedb, config)
 				if err != nil {
 					results[task.index] = &txTraceResult{Error: err.Error()}
 					continue
@@ -498,17 +514,14 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 		jobs <- &txTraceTask{statedb: statedb.Copy(), index: i}
 
 		// Generate the next state snapshot fast without tracing
-		msg, _ := tx.AsMessage(signer)
-		vmctx := core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
+		msg, vmctx := api.txTraceContext(tx, signer, header)
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
 
 		vmenv := vm.NewEVM(vmctx, statedb, api.eth.blockchain.Config(), vm.Config{})
-		if _, _, _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+		if _, _, err := core.ApplyTransactionMessage(vmenv, gp, statedb, header, tx, msg, &usedGas); err != nil {
 			failed = err
 			break
 		}
-		// Finalize the state so any modifications are written to the trie
-		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
-		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
 	}
 	close(jobs)
 	pend.Wait()
@@ -546,6 +559,9 @@ func (api *PrivateDebugAPI) standardTraceBlockToFile(ctx context.Context, block
 	if err != nil {
 		return nil, err
 	}
+	if err := api.applyBlockPreamble(block, statedb); err != nil {
+		return nil, err
+	}
 	// Retrieve the tracing configurations, or use default values
 	var (
 		logConfig vm.LogConfig
@@ -561,14 +577,17 @@ func (api *PrivateDebugAPI) standardTraceBlockToFile(ctx context.Context, block
 
 	// Execute transaction, either tracing all or just the requested one
 	var (
-		signer = types.MakeSigner(api.eth.blockchain.Config(), block.Number())
-		dumps  []string
+		signer  = types.MakeSigner(api.eth.blockchain.Config(), block.Number())
+		header  = block.Header()
+		gp      = core.NewBlockGasPool(api.eth.blockchain.Config(), header)
+		usedGas uint64
+		dumps   []string
 	)
 	for i, tx := range block.Transactions() {
 		// Prepare the trasaction for un-traced execution
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
 		var (
-			msg, _ = tx.AsMessage(signer)
-			vmctx  = core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
+			msg, vmctx = api.txTraceContext(tx, signer, header)
 
 			vmConf vm.Config
 			dump   *os.File
@@ -596,7 +615,7 @@ func (api *PrivateDebugAPI) standardTraceBlockToFile(ctx context.Context, block
 		}
 		// Execute the transaction and flush any traces to disk
 		vmenv := vm.NewEVM(vmctx, statedb, api.eth.blockchain.Config(), vmConf)
-		_, _, _, err = core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
+		_, _, err = core.ApplyTransactionMessage(vmenv, gp, statedb, header, tx, msg, &usedGas)
 		if writer != nil {
 			writer.Flush()
 		}
@@ -607,10 +626,6 @@ func (api *PrivateDebugAPI) standardTraceBlockToFile(ctx context.Context, block
 		if err != nil {
 			return dumps, err
 		}
-		// Finalize the state so any modifications are written to the trie
-		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
-		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
-
 		// If we've traced the transaction we were looking for, abort
 		if tx.Hash() == txHash {
 			break
@@ -715,14 +730,17 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	if err != nil {
 		return nil, err
 	}
+	statedb.Prepare(hash, blockHash, int(index))
+
 	// Trace the transaction and return
-	return api.traceTx(ctx, msg, vmctx, statedb, config)
+	return api.traceTx(ctx, tx, api.eth.blockchain.GetHeaderByHash(blockHash), msg, vmctx, statedb, config)
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
-// executes the given message in the provided environment. The return value will
-// be tracer dependent.
-func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, vmctx vm.Context, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
+// executes the given message created from a transaction of the block with the
+// given header in the provided environment, like block processing does. The
+// return value will be tracer dependent.
+func (api *PrivateDebugAPI) traceTx(ctx context.Context, tx *types.Transaction, header *types.Header, message core.Message, vmctx vm.Context, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
 	// Assemble the structured logger or the JavaScript tracer
 	var (
 		tracer vm.Tracer
@@ -737,15 +755,22 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 				return nil, err
 			}
 		}
-		// Constuct the JavaScript tracer to execute with
-		if tracer, err = tracers.New(*config.Tracer); err != nil {
-			return nil, err
+		// Constuct the native or JavaScript tracer to execute with
+		var stopper interface{ Stop(err error) }
+		if native, ok := tracers.NewNative(*config.Tracer); ok {
+			tracer, stopper = native, native
+		} else {
+			js, err := tracers.New(*config.Tracer)
+			if err != nil {
+				return nil, err
+			}
+			tracer, stopper = js, js
 		}
 		// Handle timeouts and RPC cancellations
 		deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
 		go func() {
 			<-deadlineCtx.Done()
-			tracer.(*tracers.Tracer).Stop(errors.New("execution timeout"))
+			stopper.Stop(errors.New("execution timeout"))
 		}()
 		defer cancel()
 
@@ -758,7 +783,7 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 	// Run the transaction with tracing enabled.
 	vmenv := vm.NewEVM(vmctx, statedb, api.eth.blockchain.Config(), vm.Config{Debug: true, Tracer: tracer})
 
-	ret, gas, failed, err := core.ApplyMessage(vmenv, message, new(core.GasPool).AddGas(message.Gas()))
+	_, result, err := core.ApplyTransactionMessage(vmenv, core.NewBlockGasPool(api.eth.blockchain.Config(), header), statedb, header, tx, message, new(uint64))
 	if err != nil {
 		return nil, fmt.Errorf("tracing failed: %v", err)
 	}
@@ -766,20 +791,34 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 	switch tracer := tracer.(type) {
 	case *vm.StructLogger:
 		return &ethapi.ExecutionResult{
-			Gas:         gas,
-			Failed:      failed,
-			ReturnValue: fmt.Sprintf("%x", ret),
+			Gas:         result.UsedGas,
+			Failed:      result.Failed(),
+			ReturnValue: fmt.Sprintf("%x", result.ReturnData),
 			StructLogs:  ethapi.FormatLogs(tracer.StructLogs()),
+			Extra:       vm.StructLogExtras(vmenv),
 		}, nil
 
 	case *tracers.Tracer:
 		return tracer.GetResult()
 
+	case tracers.NativeTracer:
+		return tracer.GetResult()
+
 	default:
 		panic(fmt.Sprintf("bad tracer type %T", tracer))
 	}
 }
 
+// applyBlockPreamble applies the state changes preceding the transactions of the
+// given block on top of the state of its parent, like block processing does.
+func (api *PrivateDebugAPI) applyBlockPreamble(block *types.Block, statedb *state.StateDB) error {
+	parent := api.eth.blockchain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return fmt.Errorf("parent %#x not found", block.ParentHash())
+	}
+	return core.ApplyBlockPreamble(api.eth.blockchain.Config(), api.eth.blockchain, nil, parent, block.Header(), statedb, vm.Config{})
+}
+
 // computeTxEnv returns the execution environment of a certain transaction.
 func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, reexec uint64) (core.Message, vm.Context, *state.StateDB, error) {
 	// Create the parent state database
@@ -795,29 +834,63 @@ func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, ree
 	if err != nil {
 		return nil, vm.Context{}, nil, err
 	}
+	if err := api.applyBlockPreamble(block, statedb); err != nil {
+		return nil, vm.Context{}, nil, err
+	}
 
 	if txIndex == 0 && len(block.Transactions()) == 0 {
 		return nil, vm.Context{}, statedb, nil
 	}
 
 	// Recompute transactions up to the target index.
-	signer := types.MakeSigner(api.eth.blockchain.Config(), block.Number())
-
+	var (
+		signer  = types.MakeSigner(api.eth.blockchain.Config(), block.Number())
+		header  = block.Header()
+		gp      = core.NewBlockGasPool(api.eth.blockchain.Config(), header)
+		usedGas uint64
+	)
 	for idx, tx := range block.Transactions() {
 		// Assemble the transaction call message and return if the requested offset
-		msg, _ := tx.AsMessage(signer)
-		context := core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
+		msg, context := api.txTraceContext(tx, signer, header)
 		if idx == txIndex {
 			return msg, context, statedb, nil
 		}
 		// Not yet the searched for transaction, execute on top of the current state
+		statedb.Prepare(tx.Hash(), block.Hash(), idx)
 		vmenv := vm.NewEVM(context, statedb, api.eth.blockchain.Config(), vm.Config{})
-		if _, _, _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas())); err != nil {
+		if _, _, err := core.ApplyTransactionMessage(vmenv, gp, statedb, header, tx, msg, &usedGas); err != nil {
 			return nil, vm.Context{}, nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
 		}
-		// Ensure any modifications are committed to the state
-		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
-		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
 	}
 	return nil, vm.Context{}, nil, fmt.Errorf("transaction index %d out of range for block %#x", txIndex, blockHash)
 }
+
+// TraceContextFunc converts a transaction into the message and EVM context to
+// trace it with, reporting whether it handles the transaction at all. The
+// context should be created with core.NewTransactionContext, so that precompiles
+// see the same predicates as during block processing.
+type TraceContextFunc func(tx *types.Transaction, signer types.Signer, header *types.Header, chain core.ChainContext) (core.Message, vm.Context, bool)
+
+// traceContextFuncs are the conversions installed by RegisterTraceContext.
+var traceContextFuncs []TraceContextFunc
+
+// RegisterTraceContext installs a conversion for transactions the tracing API
+// can't turn into messages by itself, e.g. transaction types added by chains
+// built on top of go-ethereum. Conversions are consulted in the order they were
+// registered, before falling back to Transaction.AsMessage. It is not safe for
+// concurrent use and must be called during initialisation.
+func RegisterTraceContext(fn TraceContextFunc) {
+	traceContextFuncs = append(traceContextFuncs, fn)
+}
+
+// txTraceContext returns the message and EVM context to execute the given
+// transaction of a block with.
+func (api *PrivateDebugAPI) txTraceContext(tx *types.Transaction, signer types.Signer, header *types.Header) (core.Message, vm.Context) {
+	for _, fn := range traceContextFuncs {
+		if msg, vmctx, ok := fn(tx, signer, header, api.eth.blockchain); ok {
+			return msg, vmctx
+		}
+	}
+	msg, _ := tx.AsMessage(signer)
+	return msg, core.NewTransactionContext(api.eth.blockchain.Config(), msg, header, api.eth.blockchain, nil)
+}
@@ -47,14 +47,19 @@ type bodyPack struct {
 	peerID       string
 	transactions [][]*types.Transaction
 	uncles       [][]*types.Header
+	extras       []interface{}
 }
 
 func (p *bodyPack) PeerId() string { return p.peerID }
 func (p *bodyPack) Items() int {
-	if len(p.transactions) <= len(p.uncles) {
-		return len(p.transactions)
+	items := len(p.transactions)
+	if len(p.uncles) < items {
+		items = len(p.uncles)
 	}
-	return len(p.uncles)
+	if len(p.extras) < items {
+		items = len(p.extras)
+	}
+	return items
 }
 func (p *bodyPack) Stats() string { return fmt.Sprintf("%d:%d", len(p.transactions), len(p.uncles)) }
 
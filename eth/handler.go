@@ -556,18 +556,24 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		// Deliver them all to the downloader for queuing
 		transactions := make([][]*types.Transaction, len(request))
 		uncles := make([][]*types.Header, len(request))
+		extras := make([]interface{}, len(request))
 
 		for i, body := range request {
+			extra, err := types.DecodeBodyExtra(body.Extra)
+			if err != nil {
+				return errResp(ErrDecode, "msg %v: body %d: %v", msg, i, err)
+			}
 			transactions[i] = body.Transactions
 			uncles[i] = body.Uncles
+			extras[i] = extra
 		}
 		// Filter out any explicitly requested bodies, deliver the rest to the downloader
 		filter := len(transactions) > 0 || len(uncles) > 0
 		if filter {
-			transactions, uncles = pm.fetcher.FilterBodies(p.id, transactions, uncles, time.Now())
+			transactions, uncles, extras = pm.fetcher.FilterBodies(p.id, transactions, uncles, extras, time.Now())
 		}
 		if len(transactions) > 0 || len(uncles) > 0 || !filter {
-			err := pm.downloader.DeliverBodies(p.id, transactions, uncles)
+			err := pm.downloader.DeliverBodies(p.id, transactions, uncles, extras)
 			if err != nil {
 				log.Debug("Failed to deliver bodies", "err", err)
 			}
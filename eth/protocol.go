@@ -190,6 +190,7 @@ func (request *newBlockData) sanityCheck() error {
 type blockBody struct {
 	Transactions []*types.Transaction // Transactions contained within a block
 	Uncles       []*types.Header      // Uncles contained within a block
+	Extra        []rlp.RawValue       `rlp:"tail"` // Fields of the extra body payload, if any (see types.RegisterBodyExtras)
 }
 
 // blockBodiesData is the network packet for block content distribution.
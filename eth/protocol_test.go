@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"bytes"
 	"fmt"
 	"sync"
 	"testing"
@@ -221,3 +222,35 @@ func TestGetBlockHeadersDataEncodeDecode(t *testing.T) {
 		}
 	}
 }
+
+// Tests that block bodies relay the fields of extra body payloads, while still
+// accepting bodies without any.
+func TestBlockBodyExtraEncodeDecode(t *testing.T) {
+	tx := types.NewTransaction(1, common.Address{2}, common.Big1, 21000, common.Big1, nil)
+
+	// Bodies without extra payload must decode as before
+	plain, err := rlp.EncodeToBytes(&types.Body{Transactions: []*types.Transaction{tx}})
+	if err != nil {
+		t.Fatalf("failed to encode body: %v", err)
+	}
+	body := new(blockBody)
+	if err := rlp.DecodeBytes(plain, body); err != nil {
+		t.Fatalf("failed to decode plain body: %v", err)
+	}
+	if len(body.Transactions) != 1 || len(body.Extra) != 0 {
+		t.Fatalf("plain body mismatch: have %d txs, %d extra fields", len(body.Transactions), len(body.Extra))
+	}
+	// Fields following the uncles must be carried along
+	extra := []rlp.RawValue{{0x01}, {0x82, 0x03, 0x04}}
+	enc, err := rlp.EncodeToBytes(&blockBody{Transactions: body.Transactions, Extra: extra})
+	if err != nil {
+		t.Fatalf("failed to encode body: %v", err)
+	}
+	body = new(blockBody)
+	if err := rlp.DecodeBytes(enc, body); err != nil {
+		t.Fatalf("failed to decode extended body: %v", err)
+	}
+	if len(body.Extra) != len(extra) || !bytes.Equal(body.Extra[1], extra[1]) {
+		t.Fatalf("extra fields mismatch: have %x, want %x", body.Extra, extra)
+	}
+}
@@ -2,11 +2,11 @@
 // sources:
 // 4byte_tracer.js (2.933kB)
 // bigram_tracer.js (1.712kB)
-// call_tracer.js (8.643kB)
+// call_tracer.js (10.030kB)
 // evmdis_tracer.js (4.194kB)
 // noop_tracer.js (1.271kB)
 // opcount_tracer.js (1.372kB)
-// prestate_tracer.js (4.234kB)
+// prestate_tracer.js (4.758kB)
 // trigram_tracer.js (1.788kB)
 // unigram_tracer.js (1.51kB)
 
@@ -117,7 +117,7 @@ func bigram_tracerJs() (*asset, error) {
 	return a, nil
 }
 
-var _call_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd4\x59\x5f\x6f\x1b\xb7\xb2\x7f\x96\x3e\xc5\x24\x0f\xb5\x84\x28\x92\x93\xf4\xf6\x02\x76\xd5\x0b\x5d\x47\x49\x0d\xb8\x71\x60\x2b\x0d\x82\x20\x0f\xd4\xee\xac\xc4\x9a\x4b\x6e\x49\xae\xe4\x3d\xa9\xbf\xfb\xc1\x0c\xb9\xab\xd5\x1f\x3b\x6e\x0f\xce\x41\xcf\x8b\xa0\x5d\xce\x0c\x87\x33\xbf\xf9\xc7\x1d\x8d\xe0\xcc\x14\x95\x95\x8b\xa5\x87\x97\xc7\x2f\xfe\x17\x66\x4b\x84\x85\x79\x8e\x7e\x89\x16\xcb\x1c\x26\xa5\x5f\x1a\xeb\xba\xa3\x11\xcc\x96\xd2\x41\x26\x15\x82\x74\x50\x08\xeb\xc1\x64\xe0\x77\xe8\x95\x9c\x5b\x61\xab\x61\x77\x34\x0a\x3c\x07\x97\x49\x42\x66\x11\xc1\x99\xcc\xaf\x85\xc5\x13\xa8\x4c\x09\x89\xd0\x60\x31\x95\xce\x5b\x39\x2f\x3d\x82\xf4\x20\x74\x3a\x32\x16\x72\x93\xca\xac\x22\x91\xd2\x43\xa9\x53\xb4\xbc\xb5\x47\x9b\xbb\x5a\x8f\xb7\xef\x3e\xc0\x05\x3a\x87\x16\xde\xa2\x46\x2b\x14\xbc\x2f\xe7\x4a\x26\x70\x21\x13\xd4\x0e\x41\x38\x28\xe8\x8d\x5b\x62\x0a\x73\x16\x47\x8c\x6f\x48\x95\xeb\xa8\x0a\xbc\x31\xa5\x4e\x85\x97\x46\x0f\x00\x25\x69\x0e\x2b\xb4\x4e\x1a\x0d\xaf\xea\xad\xa2\xc0\x01\x18\x4b\x42\x7a\xc2\xd3\x01\x2c\x98\x82\xf8\xfa\x20\x74\x05\x4a\xf8\x0d\xeb\x23\x0c\xb2\x39\x77\x0a\x52\xf3\x36\x4b\x53\x20\xf8\xa5\xf0\x74\xea\xb5\x54\x0a\xe6\x08\xa5\xc3\xac\x54\x03\x92\x36\x2f\x3d\x7c\x3c\x9f\xfd\x7c\xf9\x61\x06\x93\x77\x9f\xe0\xe3\xe4\xea\x6a\xf2\x6e\xf6\xe9\x14\xd6\xd2\x2f\x4d\xe9\x01\x57\x18\x44\xc9\xbc\x50\x12\x53\x58\x0b\x6b\x85\xf6\x15\x98\x8c\x24\xfc\x32\xbd\x3a\xfb\x79\xf2\x6e\x36\xf9\xff\xf3\x8b\xf3\xd9\x27\x30\x16\xde\x9c\xcf\xde\x4d\xaf\xaf\xe1\xcd\xe5\x15\x4c\xe0\xfd\xe4\x6a\x76\x7e\xf6\xe1\x62\x72\x05\xef\x3f\x5c\xbd\xbf\xbc\x9e\x0e\xe1\x1a\x49\x2b\x24\xfe\x6f\xdb\x3c\x63\xef\x59\x84\x14\xbd\x90\xca\xd5\x96\xf8\x64\x4a\x70\x4b\x53\xaa\x14\x96\x62\x85\x60\x31\x41\xb9\xc2\x14\x04\x24\xa6\xa8\x1e\xed\x54\x92\x25\x94\xd1\x0b\x3e\xf3\xbd\x80\x84\xf3\x0c\xb4\xf1\x03\x70\x88\xf0\xe3\xd2\xfb\xe2\x64\x34\x5a\xaf\xd7\xc3\x85\x2e\x87\xc6\x2e\x46\x2a\x88\x73\xa3\x9f\x86\x5d\x92\x99\x08\xa5\x66\x56\x24\x68\xc9\x39\x02\xb2\x92\xcc\xaf\xcc\x5a\x83\xb7\x42\x3b\x91\x90\xab\xe9\x7f\xc2\x60\x14\x1e\xf0\x96\x9e\xbc\x23\xd0\x82\xc5\xc2\x58\xfa\xaf\x54\x8d\x33\xa9\x3d\x5a\x2d\x14\xcb\x76\x90\x8b\x14\x61\x5e\x81\x68\x0b\x1c\xb4\x0f\x43\x30\x0a\xee\x06\xa9\x33\x63\x73\x86\xe5\xb0\xfb\xb5\xdb\x89\x1a\x3a\x2f\x92\x1b\x52\x90\xe4\x27\xa5\xb5\xa8\x3d\x99\xb2\xb4\x4e\xae\x90\x49\x20\xd0\x44\x7b\x4e\x7f\xfd\x05\xf0\x16\x93\x32\x48\xea\x34\x42\x4e\xe0\xf3\xd7\xbb\x2f\x83\x2e\x8b\x4e\xd1\x25\xa8\x53\x4c\xf9\x7c\x37\x0e\xd6\x4b\xb6\x28\xac\xf1\x68\x85\xf0\x5b\xe9\x7c\x8b\x26\xb3\x26\x07\xa1\xc1\x94\x84\xf8\xb6\x75\xa4\xf6\x86\x05\x0a\xfa\xaf\xd1\xb2\x46\xc3\x6e\xa7\x61\x3e\x81\x4c\x28\x87\x71\x5f\xe7\xb1\xa0\xd3\x48\xbd\x32\x37\x24\xd9\x58\x82\xb0\xad\xc0\x14\x89\x49\x63\x30\xd0\x39\x9a\x63\xa0\x1b\x76\x3b\xc4\x77\x02\x59\xa9\x79\xdb\x9e\x32\x8b\x01\xa4\xf3\x3e\x7c\xed\x76\x48\xec\x99\x28\x7c\x69\x91\xed\x89\xd6\x1a\xeb\x40\xe6\x39\xa6\x52\x78\x54\x55\xb7\xd3\x59\x09\x1b\x16\x60\x0c\xca\x2c\x86\x0b\xf4\x53\x7a\xec\xf5\x4f\xbb\x9d\x8e\xcc\xa0\x17\x56\x9f\x8c\xc7\x9c\x7d\x32\xa9\x31\x0d\xe2\x3b\x7e\x29\xdd\x30\x13\xa5\xf2\xcd\xbe\xc4\xd4\xb1\xe8\x4b\xab\xe9\xef\x5d\xd0\xe2\x23\x82\xd1\xaa\x82\x84\xb2\x8c\x98\x53\x78\xba\xca\x79\xcc\xe3\xe1\xdc\x00\x32\xe1\xc8\x84\x32\x83\x35\x42\x61\xf1\x79\xb2\x44\xf2\x9d\x4e\x30\x6a\xe9\x2a\xc7\x4e\x1d\x03\xed\x36\x34\xc5\xd0\x9b\x77\x65\x3e\x47\xdb\xeb\xc3\x77\x70\x7c\x9b\x1d\xf7\x61\x3c\xe6\x3f\xb5\xee\x91\x27\xea\x4b\x52\x4c\x11\x0f\xca\xfc\xd7\xde\x4a\xbd\x08\x67\x8d\xba\x9e\x67\x20\x40\xe3\x1a\x12\xa3\x19\xd4\xe4\x95\x39\x4a\xbd\x80\xc4\xa2\xf0\x98\x0e\x40\xa4\x29\x78\x13\x90\xd7\xe0\x6c\x7b\x4b\xf8\xee\x3b\xe8\xd1\x66\x63\x38\x3a\xbb\x9a\x4e\x66\xd3\x23\xf8\xe3\x0f\x08\x6f\x9e\x86\x37\x2f\x9f\xf6\x5b\x9a\x49\x7d\x99\x65\x51\x39\x16\x38\x2c\x10\x6f\x7a\x2f\xfa\xc3\x95\x50\x25\x5e\x66\x41\xcd\x48\x3b\xd5\x29\x8c\x23\xcf\xb3\x5d\x9e\x97\x5b\x3c\xc4\x34\x1a\xc1\xc4\x39\xcc\xe7\x0a\xf7\x03\x32\x46\x2c\x07\xaf\xf3\x94\xb1\x08\x7d\x89\xc9\x0b\x85\x84\xaa\x7a\xd7\x68\x7e\xd6\xb8\xe3\xab\x02\x4f\x00\x00\x4c\x31\xe0\x17\x14\x0b\xfc\xc2\x9b\x9f\xf1\x96\x7d\x54\x9b\x90\x50\x35\x49\x53\x8b\xce\xf5\xfa\xfd\x40\x2e\x75\x51\xfa\x93\x2d\xf2\x1c\x73\x63\xab\xa1\xa3\x84\xd4\xe3\xa3\x0d\xc2\x49\x6b\x9e\x85\x70\xe7\x9a\x78\x22\x52\xdf\x0a\xd7\xdb\x2c\x9d\x19\xe7\x4f\xea\x25\x7a\xa8\xd7\xd8\x16\xc4\x76\x74\x7c\x7b\xb4\x6f\xad\xe3\xfe\x06\x09\x2f\x7e\xe8\x13\xcb\xdd\x69\x83\xef\x26\x4d\x0c\x8b\xd2\x2d\x7b\x0c\xa7\xcd\xea\x26\x15\x8c\xc1\xdb\x12\x0f\xc2\x9f\x21\xb5\x0f\x27\x87\x2a\xa3\x5c\xe2\x6d\x99\x30\xac\x16\x82\x33\x0d\x47\xba\xa0\xcc\xeb\xca\x39\xdb\xdc\x1b\xb3\x8f\xae\x08\xae\xeb\xe9\xc5\x9b\xd7\xd3\xeb\xd9\xd5\x87\xb3\xd9\x51\x0b\x4e\x0a\x33\x4f\x4a\x6d\x9f\x41\xa1\x5e\xf8\x25\xeb\x4f\xe2\xb6\x57\x3f\x13\xcf\xf3\x17\x5f\xc2\x1b\x18\x1f\x08\xf9\xce\xc3\x1c\xf0\xf9\x0b\xcb\xbe\xdb\x37\xdf\x36\x69\x30\xe6\xd7\x00\x22\x53\xdc\xb5\x13\xc7\x81\x58\xcc\xd1\x2f\x4d\xca\xc9\x31\x11\x21\xbf\xd6\x56\x4c\x8d\xc6\x3f\x1f\x91\x93\x8b\x8b\x56\x3c\xf2\xf3\xd9\xe5\xeb\x76\x8c\x1e\xbd\x9e\x5e\x4c\xdf\x4e\x66\xd3\x5d\xda\xeb\xd9\x64\x76\x7e\xc6\x6f\xeb\xf0\x1d\x8d\xe0\xfa\x46\x16\x9c\x65\x39\x77\x99\xbc\xe0\x76\xb1\xd1\xd7\x0d\xc0\x2f\x0d\x35\x62\x36\x16\x91\x4c\xe8\xa4\x4e\xee\xae\x76\x9a\x37\xe4\x32\x53\xc7\xca\x7e\x2a\x68\x03\xb5\xdf\xb8\x51\xba\xf7\x16\xe3\xa6\x69\xcf\x9b\x5a\xaf\x8d\x41\x83\x47\x38\x01\x72\x92\xe9\x3d\xfe\x90\xf0\x7f\x70\x0c\x27\xf0\x22\x66\x92\x07\x52\xd5\x4b\x78\x46\xe2\xff\x42\xc2\x7a\x75\x80\xf3\xef\x99\xb6\xbc\x61\xe2\x9a\xdc\x9b\xff\x7c\x3a\x33\xa5\xbf\xcc\xb2\x13\xd8\x35\xe2\xf7\x7b\x46\x6c\xe8\x2f\x50\xef\xd3\xff\xcf\x1e\xfd\x26\xf5\x11\xaa\x4c\x01\x4f\xf6\x20\x12\x12\xcf\x93\x9d\x38\x88\xc6\xe5\x16\x87\xa5\xc1\xf8\x9e\x64\xfb\x72\x1b\xc3\xf7\x65\x8b\x7f\x29\xd9\x1e\x6c\xd5\xa8\x21\xdb\x6e\xc6\x06\x60\xd1\x5b\x89\x2b\x1a\xb7\x8e\x1c\x8b\xa4\xa6\xd5\xac\x85\x4e\x70\x08\x1f\x31\x48\xd4\x88\x9c\x5c\x62\x93\x4b\x3d\x0a\xf7\x7d\xd4\xa8\xc6\x71\x85\x21\x26\xb8\x17\xb5\x08\xb9\xa8\x68\x5c\xc9\x4a\x7d\x53\xc1\x42\x38\x48\x2b\x2d\x72\x99\xb8\x20\x8f\x1b\x5c\x8b\x0b\x61\x59\xac\xc5\xdf\x4b\x74\x34\xfb\x10\x90\x45\xe2\x4b\xa1\x54\x05\x0b\x49\x03\x0c\x71\xf7\x5e\xbe\x3a\x3e\x06\xe7\x65\x81\x3a\x1d\xc0\x0f\xaf\x46\x3f\x7c\x0f\xb6\x54\xd8\x1f\x76\x5b\x69\xbc\x39\x6a\xf4\x06\x2d\x44\xf4\xbc\xc6\xc2\x2f\x7b\x7d\xf8\xe9\x9e\x7a\x70\x4f\x72\x3f\x48\x0b\xcf\xe1\xc5\x97\x21\xe9\x35\xde\xc2\x6d\xf0\x24\xa0\x72\x18\xa5\xd1\xd0\x77\xf9\xfa\xb2\x77\x23\xac\x50\x62\x8e\xfd\x13\x1e\x02\xd9\x56\x6b\x11\xa7\x00\x72\x0a\x14\x4a\x48\x0d\x22\x49\x4c\xa9\x3d\x19\xbe\x6e\xe8\x55\x45\xf9\xfd\xc8\xd7\xf2\x78\x5e\x12\x49\x82\xce\xd5\xe9\x9e\xbd\x46\xea\x88\x9c\xb8\x41\x6a\x27\x53\x6c\x79\x85\xb2\x83\xe1\xd4\x1c\x29\x68\x9c\xac\x05\xe6\xc6\xd1\x26\x73\x84\xb5\xa5\xe1\xc3\x49\x9d\xf0\xf4\x9d\x22\x59\xdb\x81\xd1\x20\x40\x19\x1e\xf9\x39\xc6\x41\xd8\x85\x1b\x86\x7c\x4f\xdb\x52\xce\xd1\x66\x3d\xdc\x06\x72\x1b\xaa\xdc\xe6\xef\xb4\x03\x1a\xf0\x56\x3a\xcf\x5d\x25\x69\x29\x1d\x04\x24\x4b\xbd\x18\x40\x61\x0a\xce\xd3\xdf\x2a\x67\x31\x59\x5f\x4d\x7f\x9d\x5e\x35\xc5\xff\xf1\x4e\xac\xfb\xfe\xa7\xcd\x58\x04\x96\x66\x0e\x8f\xe9\xd3\x03\x8d\xfc\x01\x40\x8d\xef\x01\x14\xc9\xdf\xd4\xc6\xf7\xad\xe3\x28\xe1\xfc\xc6\x31\x0b\x0c\x33\x4d\x5b\x01\x57\x2a\xef\x76\x72\xf7\x6e\x72\x30\x45\x5d\x21\x48\x29\x4e\x3b\x94\xd8\x77\xbb\xed\xad\x85\x4d\xd3\xbd\xc1\xe7\x79\xcb\xc6\x6b\x6e\xb9\x02\x51\x2b\x35\xf0\x7a\xdd\xbb\x89\x50\x0d\x58\x77\x53\x7a\x82\x03\xd5\xef\x4d\xf2\x5b\x08\xf7\xc1\xb1\xd7\x63\xfa\x9b\xcb\xc5\xb9\xf6\xbd\x7a\xf1\x5c\xc3\x73\xa8\x1f\x28\xa9\xc3\xf3\xad\x28\x3a\x90\x1d\x3b\x29\x2a\xf4\x08\x1b\x11\xa7\xb0\xf3\x8a\x04\x05\x73\xb0\xd1\x2c\xfa\xfd\xe2\x7c\x1c\xa5\x91\xc1\x9e\x58\xf4\x43\xfc\xbd\x14\xca\xf5\x8e\x9b\x66\x21\x9c\xc0\x1b\x2e\x6f\xe3\xa6\xc0\xd5\x15\x90\x78\xb6\xda\x8f\x28\x30\xb0\x45\x6b\xd4\x6c\xe9\x3c\x54\xad\x14\x1f\x94\x10\x45\xc4\xb4\xd1\xf8\x32\x02\xf3\x50\xff\xd9\x69\x13\xc0\xd3\xa6\x21\xc8\x84\x54\xa5\xc5\xa7\xa7\x70\x20\xed\xb8\xd2\x66\x22\x61\x5f\x3a\x04\x9e\x58\x1d\x38\x93\xe3\xd2\xac\x83\x02\x87\x92\xd7\x3e\x38\x1a\x1c\xec\x94\x0f\xbe\x7a\x11\x0e\x4a\x27\x16\xd8\x02\x47\x63\xf0\xda\x51\x07\xc7\xe8\xbf\x0c\x9d\x67\xcd\xe3\x37\x50\x14\x76\xf9\x26\x34\x1e\xc2\xc6\x41\x2f\xef\x75\x39\x35\x11\xf7\x3a\xad\x87\x5a\xd5\xd0\x8a\x34\xc8\xf9\x33\x7e\xff\xf7\x38\x3e\x78\x3e\xfe\x3e\x36\xd0\x76\x69\xc3\x19\xb7\x89\xc3\x49\x37\xed\xcd\xb7\x51\xd0\xac\xde\x07\x80\xfb\x3a\x27\x82\xaa\xfe\x0d\x13\xbf\x81\x2b\x37\x3b\xf4\x54\x58\x5c\x49\x53\x52\x1d\xc3\xff\xa6\xc9\xb0\xe9\xfc\xee\xba\x9d\xbb\x78\x45\xc6\xee\x6b\xdf\x91\xad\x97\xf1\x8a\x37\x34\x4d\xad\x2a\x62\xb8\xc4\xc6\x9b\xb3\x2c\x5c\xbe\x76\x98\xff\x81\xbb\xb2\x18\xef\xde\x14\xd4\x15\xc4\x22\xa5\x2c\x8a\xb4\x6a\xea\xe2\x20\xf4\x23\xb0\x14\x3a\x8d\x33\x89\x48\x53\x49\xf2\x18\x8b\xa4\xa1\x58\x08\xa9\xbb\x07\xcd\xf8\xcd\x62\x7c\x08\x19\x7b\x2d\x6e\xbb\x9e\xc6\x59\x92\x06\x3f\xd6\xb8\xfb\x88\xba\xb9\x13\x4b\xbb\xd7\x7e\xf1\xe6\xd0\x68\x57\xe6\xdc\x10\x83\x58\x09\xa9\x04\x0d\x61\xdc\x68\xe9\x14\x12\x85\x42\x87\xcb\x7e\xcc\xbc\x59\xa1\x75\xdd\x47\x80\xfc\xaf\x60\x7c\x27\x39\xd6\x8f\xd1\x1c\x8f\x8f\xd9\xc7\x46\x6c\x38\xfe\x1b\x25\xbc\x8f\xf0\x6a\x99\x37\x44\x96\xf4\xfc\x1d\x08\xb5\xef\x3e\x2e\xa4\xb8\x75\x22\x9a\x9f\xe0\xb8\xd5\x9e\xff\x5d\x82\x6c\x1f\x62\x17\x4d\x9b\x16\x0f\xef\x8d\x19\x80\x42\xc1\xc3\x52\xfd\x95\xa6\x6e\x4b\x1f\x9a\xdd\xea\xe8\x0d\x8d\xdd\x5e\xf8\xf2\xf5\xd6\x12\xeb\x8b\x90\xd0\xe1\xcf\x11\x35\x48\x8f\x56\xd0\x58\x44\xe8\x8a\x1f\x16\x48\x4b\xc7\xe2\xd8\x2f\x92\x82\x2e\x0a\x8e\xb7\xfc\x54\x9f\xa5\x5e\x0c\xbb\x9d\xf0\xbe\x15\xef\x89\xbf\xdd\xc4\x7b\x28\x86\xcc\x19\xaf\x06\x9a\x9b\x81\xc4\xdf\x72\xd3\xc8\xd3\xf3\xce\xf5\x00\xad\xd1\xab\x30\x5a\xef\x5c\x06\x30\x63\xbc\x10\xd8\xbd\x73\xa4\x35\x7e\xb7\x05\x70\x26\x5d\x08\x17\xc4\xec\x84\x84\xbf\xdd\x8f\x88\x9a\x81\x82\xe1\xe4\x30\x03\x2d\x1d\x60\xda\xb9\xa0\x20\x62\x7e\x15\x56\x43\x61\x3f\x69\xaf\x86\x57\xf1\xa0\x32\x6f\xd9\x46\xe6\x6c\x9b\xbb\xd3\xc3\x49\xee\xb8\xc6\xe3\xe1\x64\x46\x36\x6f\x00\x7b\x0f\x6b\x7b\xe4\xd8\x27\x79\x28\x55\xb2\xf4\x3a\xb3\xdd\xc3\xca\xd2\x5b\xad\x87\xbf\x7d\xbc\xc8\x86\xb8\xad\xe2\x16\xcd\x21\x21\x31\xcf\x44\xba\x60\xd9\x5a\x40\x40\x75\xd0\x95\x11\x2d\xff\x81\x51\x62\x3b\x7e\xea\x25\xb0\x18\xbe\x43\x70\x43\x4a\xe1\x63\xe6\x5c\xfc\x4b\x47\xd3\xe4\x26\x2e\x52\x74\xd2\x62\x0a\x99\x44\x95\x82\x49\xd1\xf2\xac\xfa\x9b\x33\x3a\x7c\x71\x42\x2b\x49\x62\xf8\xb2\x16\x3e\x72\xf3\xf7\x3e\x2d\x13\xf4\x15\x64\x28\xf8\xd3\x91\x37\x50\x08\xe7\x20\x47\x41\xd3\x69\x56\x2a\x55\x81\xb1\x29\x92\xf0\x66\x5c\xa3\x90\x34\x50\x3a\xb4\x0e\xd6\x4b\x13\xcb\x24\x77\x69\x05\x35\x9d\xd2\x0f\xe2\x8d\x8c\x74\x85\x12\x15\x48\x4f\x25\x39\x1e\xaa\x1d\xa5\xcd\xf7\x1a\xfe\xe8\x63\xa8\xea\xee\x87\x68\x3d\xd8\x6d\xc7\x28\xbf\xa6\xa7\xed\xe8\x8c\x73\xcd\x76\x5c\x6e\xee\xaa\xb6\x83\xb0\x2e\x1b\xdb\x91\xd6\x2e\x42\xdb\xe1\xc4\x2b\xfc\xb4\x1d\x48\xad\x7e\x99\x17\x18\x1c\x0d\x03\x3f\xed\x84\x16\x6b\x19\x63\x2b\x7c\x9d\x6c\xc8\xf9\x69\x10\x01\x43\x5e\xec\x91\x71\x6e\xb0\xa2\x4c\x1c\x6c\xd4\x2a\x2b\xe1\xc5\xe7\x1b\xac\xbe\x1c\xae\x22\x11\x8e\x2d\xba\xa6\x6c\xd4\x90\x0e\x6b\x0f\x04\x72\xa3\x85\x1c\x1f\x9f\x82\xfc\xb1\xcd\x50\x57\x3e\x90\xcf\x9e\xd5\x7b\xb6\xd7\x3f\xcb\x2f\x75\x74\x36\x88\xdf\x59\xef\x6f\x69\x14\x63\x24\xd0\x50\x50\x74\xef\xba\xff\x0c\x00\x00\xff\xff\x00\x24\x55\x1f\xc3\x21\x00\x00")
+var _call_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd4\x5a\xdf\x6f\xdb\x38\xf2\x7f\xb6\xff\x8a\x69\x1f\x36\x36\xea\xda\x69\xbb\xdf\xfd\x02\xe9\x7a\x0f\xbe\xd4\xed\x1a\xc8\x36\x45\xe2\x6e\x51\x14\x7d\xa0\xa5\x91\xcd\x8d\x44\x6a\x49\xca\x8e\xaf\xeb\xff\xfd\x30\xfc\xa1\x5f\x56\xd2\xb4\x87\x3b\xdc\xbd\x59\x22\x67\x48\xce\x7c\x66\xe6\x33\x94\x27\x13\x38\x97\xf9\x5e\xf1\xf5\xc6\xc0\xf3\xd3\x67\xff\x0f\xcb\x0d\xc2\x5a\x3e\x45\xb3\x41\x85\x45\x06\xb3\xc2\x6c\xa4\xd2\xfd\xc9\x04\x96\x1b\xae\x21\xe1\x29\x02\xd7\x90\x33\x65\x40\x26\x60\x5a\xf3\x53\xbe\x52\x4c\xed\xc7\xfd\xc9\xc4\xc9\x74\x0e\x93\x86\x44\x21\x82\x96\x89\xd9\x31\x85\x67\xb0\x97\x05\x44\x4c\x80\xc2\x98\x6b\xa3\xf8\xaa\x30\x08\xdc\x00\x13\xf1\x44\x2a\xc8\x64\xcc\x93\x3d\xa9\xe4\x06\x0a\x11\xa3\xb2\x4b\x1b\x54\x99\x0e\xfb\x78\xf3\xf6\x3d\x5c\xa0\xd6\xa8\xe0\x0d\x0a\x54\x2c\x85\x77\xc5\x2a\xe5\x11\x5c\xf0\x08\x85\x46\x60\x1a\x72\x7a\xa3\x37\x18\xc3\xca\xaa\x23\xc1\xd7\xb4\x95\x6b\xbf\x15\x78\x2d\x0b\x11\x33\xc3\xa5\x18\x01\x72\xda\x39\x6c\x51\x69\x2e\x05\xbc\x08\x4b\x79\x85\x23\x90\x8a\x94\x0c\x98\xa1\x03\x28\x90\x39\xc9\x0d\x81\x89\x3d\xa4\xcc\x54\xa2\x0f\x30\x48\x75\xee\x18\xb8\xb0\xc7\xdb\xc8\x1c\xc1\x6c\x98\x21\x4b\xec\x78\x9a\xc2\x0a\xa1\xd0\x98\x14\xe9\x88\xb4\xad\x0a\x03\x1f\x16\xcb\x5f\x2f\xdf\x2f\x61\xf6\xf6\x23\x7c\x98\x5d\x5d\xcd\xde\x2e\x3f\xbe\x84\x1d\x37\x1b\x59\x18\xc0\x2d\x3a\x55\x3c\xcb\x53\x8e\x31\xec\x98\x52\x4c\x98\x3d\xc8\x84\x34\xfc\x36\xbf\x3a\xff\x75\xf6\x76\x39\xfb\xfb\xe2\x62\xb1\xfc\x08\x52\xc1\xeb\xc5\xf2\xed\xfc\xfa\x1a\x5e\x5f\x5e\xc1\x0c\xde\xcd\xae\x96\x8b\xf3\xf7\x17\xb3\x2b\x78\xf7\xfe\xea\xdd\xe5\xf5\x7c\x0c\xd7\x48\xbb\x42\x92\xff\xba\xcd\x13\xeb\x3d\x85\x10\xa3\x61\x3c\xd5\xc1\x12\x1f\x65\x01\x7a\x23\x8b\x34\x86\x0d\xdb\x22\x28\x8c\x90\x6f\x31\x06\x06\x91\xcc\xf7\x0f\x76\x2a\xe9\x62\xa9\x14\x6b\x7b\xe6\x3b\x01\x09\x8b\x04\x84\x34\x23\xd0\x88\xf0\xf3\xc6\x98\xfc\x6c\x32\xd9\xed\x76\xe3\xb5\x28\xc6\x52\xad\x27\xa9\x53\xa7\x27\xbf\x8c\xfb\xa4\x33\x62\x69\xba\x54\x2c\x42\x45\x68\x65\x90\x14\x64\xfe\x54\xee\x04\x18\xc5\x84\x66\x11\xb9\x9a\x7e\xd3\x14\xeb\x24\xbc\xa5\x27\xa3\x09\xb4\xa0\x30\x97\x8a\x7e\xa7\x69\xc0\x19\x17\x06\x95\x60\xa9\xd5\xad\x21\x63\x31\xc2\x6a\x0f\xac\xae\x70\x54\x3f\x0c\xc1\xc8\xb9\x1b\xb8\x48\xa4\xca\x2c\x2c\xc7\xfd\x2f\xfd\x9e\xdf\xa1\x36\x2c\xba\xa1\x0d\x92\xfe\xa8\x50\x0a\x85\x21\x53\x16\x4a\xf3\x2d\xda\x29\xe0\xe6\x78\x7b\xce\x7f\xff\x0d\xf0\x16\xa3\xc2\x69\xea\x95\x4a\xce\xe0\xd3\x97\xc3\xe7\x51\xdf\xaa\x8e\x51\x47\x28\x62\x8c\x69\x6b\xd1\x8d\x86\xdd\xc6\x5a\x14\x76\x78\xb2\x45\xf8\xa3\xd0\xa6\x36\x27\x51\x32\x03\x26\x40\x16\x84\xf8\xba\x75\xb8\x30\xd2\x2a\x64\xf4\x5b\xa0\xb2\x3b\x1a\xf7\x7b\xa5\xf0\x19\x24\x2c\xd5\xe8\xd7\x45\xb2\x50\xb5\xaa\xd9\x34\x8e\x10\x63\x6e\x36\x65\xb8\x37\x8d\x98\x2b\x8c\x64\x96\xf3\x14\xf5\xb8\xdf\xf3\x7a\xce\xe0\x53\x38\x91\x36\x98\x93\x9d\xb8\xd8\xca\x1b\x8c\x2d\x2c\x71\x8b\x6a\x0f\x32\x8f\x64\xec\xc3\x8c\x16\x2c\x0d\x64\x35\x91\xdc\x19\x24\x85\xb0\x07\x1a\xa4\x72\x3d\x82\x78\x35\x84\x2f\xfd\x1e\xa9\x3d\x67\xb9\x29\x14\xda\x80\x47\xa5\xa4\xd2\xc0\xb3\x0c\x63\xce\x0c\xa6\xfb\x7e\xaf\xb7\x65\xca\x0d\xc0\x14\x52\xb9\x1e\xaf\xd1\xcc\xe9\x71\x30\x7c\xd9\xef\xf5\x78\x02\x03\x37\xfa\x68\x3a\xb5\x79\x2d\xe1\x02\x63\xa7\xbe\x67\x36\x5c\x8f\x13\x56\xa4\xa6\x5c\x97\x84\x7a\x0a\x4d\xa1\x04\xfd\x3c\xb8\x5d\x7c\x40\x90\x22\xdd\x43\x44\xf9\x8b\xad\x28\xf0\xf5\x5e\x1b\xcc\xfc\xe1\xf4\x08\x12\xa6\xc9\x39\x3c\x81\x1d\x42\xae\xf0\x69\xb4\xc1\xe8\x06\xa4\x88\xd0\xef\x52\xef\x35\x99\x14\xa6\x40\xab\x8d\x65\x3e\x36\xf2\x6d\x91\xad\x50\x0d\x86\xf0\x03\x9c\xde\x26\xa7\x43\x98\x4e\xed\x8f\xb0\x77\x2f\xe3\xf7\x4b\x67\x95\xb9\x3f\xa8\x95\xbf\x36\x8a\x8b\xf5\x60\x58\xdb\xeb\x22\x01\x06\x02\x77\x10\x49\x41\xe0\x32\xe4\x95\x15\x72\xb1\x86\x48\x21\x33\x18\x8f\x80\xc5\x31\x18\xd9\x72\x7f\x73\x49\xf8\xe1\x07\x18\xd0\x62\x53\x38\x39\xbf\x9a\xcf\x96\xf3\x13\xf8\xeb\x2f\x70\x6f\x1e\xbb\x37\xcf\x1f\x0f\x6b\x3b\xe3\xe2\x32\x49\xfc\xe6\xac\xc2\x71\x8e\x78\x33\x78\x36\x1c\x6f\x59\x5a\xe0\x65\xe2\xb6\xe9\xe7\xce\x45\x0c\x53\x2f\xf3\xa4\x2d\xf3\xbc\x21\x43\x2e\x99\x4c\x60\xa6\x35\x66\xab\x14\x8f\x43\xdd\xe7\x02\x9b\x16\xb4\x91\xca\x25\x45\x82\x6b\x8a\x84\xaa\xb0\xaa\x37\xbf\xdd\x71\xcf\xec\x73\x3c\x03\x00\x90\xf9\xc8\xbe\xa0\x28\xb3\x2f\x8c\xfc\x15\x6f\xad\x8f\x82\x09\x09\x55\xb3\x38\x56\xa8\xf5\x60\x38\x74\xd3\xb9\xc8\x0b\x73\xd6\x98\x9e\x61\x26\xd5\x7e\xac\x29\xd5\x0d\xec\xd1\x46\xee\xa4\x41\x66\xcd\xf4\x42\x90\x8c\x47\xea\x1b\xa6\x07\xd5\xd0\xb9\xd4\xe6\x2c\x0c\xd1\x43\x18\xb3\xb6\x20\xb1\x93\xd3\xdb\x93\x63\x6b\x9d\x0e\x2b\x24\x3c\xfb\x69\x48\xea\x0e\x2f\x4b\x7c\x97\x09\x68\x9c\x17\x7a\x33\xa0\xc7\x61\x35\x5a\x25\x99\x29\x18\x55\x60\x27\xfc\x2d\xa4\x8e\xe1\xa4\x31\x4d\x28\x4b\x19\x55\x44\x16\x56\x6b\x66\x73\x98\x8d\x74\x46\x39\x5d\x17\x2b\x5a\x0f\x8c\x94\xc7\xe8\xf2\xe0\xba\x9e\x5f\xbc\x7e\x35\xbf\x5e\x5e\xbd\x3f\x5f\x9e\xd4\xe0\x94\x62\x62\x60\x0a\xad\x33\xa4\x28\xd6\x66\x63\xf7\x4f\xf1\xd1\x1c\xfd\x44\x32\x4f\x9f\x7d\x76\x6f\x60\xda\x11\xf2\xbd\xfb\x25\xe0\xd3\x67\xab\xfb\xd0\xff\xca\x54\x67\xcc\x2f\x0e\x44\x32\x3f\xd4\x13\x47\x47\x2c\x66\x68\x36\x92\x68\xc7\x56\x46\xb6\xc6\x54\x56\x8c\xa5\xc0\x6f\x8f\xc8\xd9\xc5\x45\x2d\x1e\xed\xf3\xf9\xe5\xab\x7a\x8c\x9e\xbc\x9a\x5f\xcc\xdf\xcc\x96\xf3\xf6\xdc\xeb\xe5\x6c\xb9\x38\xb7\x6f\x43\xf8\x4e\x26\x70\x7d\xc3\x73\x9b\x65\x6d\xee\x72\x99\xbe\xb6\x5f\x3d\x02\xb3\x91\x44\xf1\x94\x2f\x4f\x09\x13\x51\x48\xee\x3a\x38\xcd\x48\x72\x99\x0c\xb1\xd2\x02\xea\xb3\x26\x50\x87\xa5\x1b\xb9\x7e\x57\x96\x97\x78\x60\x64\xd8\x57\x65\x50\x6b\x51\x8b\x0b\x69\x93\xcc\xe0\xe1\x87\x84\xbf\xc1\x29\x9c\xc1\x33\x9f\x49\xee\x49\x55\xcf\xe1\x09\xc8\x24\xf9\x8e\x84\xf5\xa2\x43\xf2\xbf\x33\x6d\x19\x69\xa5\xc3\x74\x23\xff\xf3\xe9\x4c\x16\xe6\x32\x49\xce\xa0\x6d\xc4\x1f\x8f\x8c\x58\xce\xbf\x40\x71\x3c\xff\xff\x8e\xe6\x57\xa9\x8f\x50\x25\x73\x78\x74\x04\x11\x97\x78\x1e\xb5\xe2\xc0\x1b\x97\x42\xdb\x39\x1f\xa6\x77\x24\xdb\xe7\x4d\x0c\xdf\x95\x2d\xfe\xa5\x64\xdb\x49\x02\x89\xea\x35\x69\xde\x08\x14\x1a\xc5\x71\x4b\x8d\xdc\x89\xb6\x2a\x89\x0e\xcb\x1d\x13\x11\x8e\xe1\x03\x2d\x30\x99\x80\x40\x62\x7c\x32\xd0\x67\xe0\x09\x50\xad\xb3\x14\xd8\x37\x42\xa4\x8e\xba\x37\xca\xdf\x08\x19\xdb\x53\x23\x94\x14\xe2\x66\x0f\x6b\xa6\x21\xde\x0b\x96\xf1\x88\xc2\x7c\x32\xb1\x72\xa0\x70\xcd\x94\x55\xab\xf0\xcf\x02\x35\x75\x55\x54\x7f\x59\x64\x0a\x96\xa6\x7b\x58\x73\x6a\x8d\x48\x7a\xf0\xfc\xc5\xe9\x29\x68\xc3\x73\x14\xf1\x08\x7e\x7a\x31\xf9\xe9\x47\x50\x45\x8a\xc3\xb1\xcf\x70\x4d\xeb\x78\x6f\x90\x0b\x3d\x7a\x5e\x11\x31\x1d\x0c\xe1\x97\x3b\xea\x41\xf0\x5f\x73\xf0\x53\xe7\x5c\x78\x0a\xcf\x3e\x8f\x69\x5f\x25\x61\xb4\x65\xd8\x79\x12\x30\xd5\xe8\xb5\x51\x7f\x7d\xf9\xea\x72\x70\xc3\x14\x4b\xd9\x0a\x87\x67\xb6\x7d\xb7\xb6\xda\x31\x4f\x8d\xc9\x29\x90\xa7\x8c\x0b\x60\x51\x24\x0b\x61\xc8\xf0\xa1\x55\x48\xf7\x10\x4b\x71\x62\x82\x3e\xdb\x89\xb1\x28\x42\xad\x43\xba\xb7\x5e\xa3\xed\xb0\x8c\xa4\x81\x0b\xcd\x49\x6f\x58\x89\x8c\xaa\xa5\x4d\xcd\x7e\x06\x35\xaa\x41\x61\x26\xb5\x49\xad\xb7\x76\x8a\x7a\x34\xcd\x45\x44\x70\x80\x18\xc9\xda\x1a\xa4\x00\x06\xa9\xb4\x97\x09\x96\xb2\x00\x53\x6b\x3d\x76\xf9\x9e\x96\x25\xaa\x24\xe4\x6e\xdc\x04\x72\x85\xbb\xa9\x6b\x20\x5a\x74\x40\x00\xde\x72\x6d\xa8\x80\x59\x7b\x70\x4d\x60\x2c\x94\xe0\x62\x3d\x82\x5c\xe6\x14\x99\x5f\x2d\x67\x3e\x59\x5f\xcd\x7f\x9f\x5f\x95\xc5\xff\xe1\x4e\x0c\xbc\xff\x71\xd9\x70\x81\xa2\x9e\xc3\x60\xfc\xb8\x83\xc8\x77\x00\x6a\x7a\x07\xa0\x48\xbf\xdf\xce\x64\x02\xef\x6a\xc7\x49\x99\x36\x95\x63\xd6\x68\xec\xdb\xfa\x06\x74\x91\x1a\xdd\xca\xdd\xad\x45\x72\x99\x87\x0a\x41\x9b\x22\x75\x63\x4a\xec\x6d\xb6\xdd\x18\xa8\x48\x77\x85\xcf\x45\xcd\xc6\x04\x49\x06\x6e\x52\x2d\x35\xd8\xf1\xc0\xdd\x98\xab\x06\xb6\xe4\xc8\xc2\x10\x1c\x22\x19\x63\x95\xfc\xd6\x4c\xbf\xd7\x18\x57\xe9\x6f\xc5\xd7\x0b\x61\x06\x61\x70\x21\xe0\x29\x84\x07\x4a\xea\xf0\xb4\x11\x45\x1d\xd9\xb1\x17\x63\x8a\x06\x4b\xa9\x85\x78\x09\xad\x57\xa4\xc8\x99\xc3\x1a\x4d\xa1\x39\x2e\xce\xa7\x5e\x1b\x19\xec\x91\x42\x33\xc6\x3f\x0b\x96\xea\xc1\x69\x49\x16\x6c\xaf\x3d\x36\xd2\x96\xb7\x69\x59\xe0\x42\x05\x24\x99\xfa\xe6\x3c\xff\xf0\x07\xf7\xd6\x08\x62\xf1\x8a\x8e\x74\x2e\x63\xbc\x57\x83\x57\xe1\xd3\x46\xe9\x4b\x0f\xcc\x2e\xfe\xd9\xab\x4f\x80\xc7\x25\x21\x48\x18\x4f\x0b\x85\x8f\x5f\x42\x47\xda\xd1\x85\x4a\x58\x64\x93\x82\x46\xb0\x1d\xab\x06\x2d\x33\xdc\xc8\x9d\xdb\x40\x57\xf2\x3a\x06\x47\xc9\xe1\x5b\xe5\x83\x30\x42\xb9\xa0\xd0\x6c\x8d\x35\x70\x94\x06\x0f\x8e\x82\x47\x77\x9f\xe9\xdb\xa1\xf3\xa4\x7c\xfc\x0a\x8a\xfa\xbd\x07\x41\xe3\x3e\x6c\x74\x7a\xf9\x88\xe5\x84\x49\xb6\x75\xab\x3d\x84\xad\x3a\x2a\x52\x22\xe7\x5b\xfc\xfe\xef\x71\xbc\xf3\x7c\xef\xf0\x4d\x81\xd6\x9e\xeb\x08\x59\x73\xb2\x3b\x69\x45\x6f\xbe\x8e\x82\x72\xf4\x2e\x00\x74\xe4\x86\x83\xcf\xb0\x0b\xf1\x07\x46\xa6\x82\xab\x25\x3b\xf4\x94\x2b\xdc\x72\x59\x50\x1d\xc3\xff\xa5\xce\xb0\x64\x7e\x87\x7e\xef\x50\xbf\x7c\xab\xdf\x91\xed\x36\x48\xd5\xb9\xba\x5a\x83\x8c\xdd\xa0\x0d\x54\x8a\x59\xaa\xd8\x46\x83\xdc\x89\x31\x11\x0f\xed\xaf\xe4\xac\x2e\xa6\x50\x9c\x98\xea\x8a\x53\x84\x56\x6c\x04\xda\xf2\x0a\xba\xac\xa2\x19\x2b\x7b\x31\x8c\x91\xbf\xf5\xa6\xeb\xb6\x70\x7f\x57\xbb\x76\x4b\x14\xcb\xd0\x19\xa2\xdd\x71\xf8\x86\xc3\xce\xb0\xb5\x68\xd4\xaf\xba\x0e\x97\x2c\xdd\x18\x71\x4a\xd7\x18\xf8\x1e\xa3\x3e\x18\xfa\x0c\xdf\x66\xd4\x87\xec\x2b\x37\xba\x66\x9a\x24\xdd\xfb\x35\xa3\x2a\x7a\x08\x57\x61\xd5\x06\xee\x60\xef\x5d\xe4\xdd\x09\x59\x46\x7f\x04\xbe\x43\xbf\xed\xc8\x96\xeb\xec\xa0\xbf\xe9\x74\x43\xdd\xe4\xd3\x56\xae\xf0\xed\x81\xe9\x8a\x82\x93\xaf\x6f\x84\xdc\x89\x91\x63\x81\x55\xc2\xe5\xc6\x51\x70\x82\x78\xc2\x95\x36\xd6\x31\xfd\x7b\xc8\x57\x09\xa2\x5b\x6e\x3a\x30\x44\x9b\xae\xd0\x50\x87\x94\xe3\x40\x74\xd5\x8a\xb7\xdc\xd4\x7c\xee\x98\x4a\x79\xd3\x5a\xaf\x16\x94\x93\x1c\x5e\xa4\xc0\x12\x87\xd5\x55\x67\x91\x1a\x60\xa9\x42\x16\xef\x21\x49\x99\x31\x28\x68\xba\xf1\xae\x6a\x1a\x8e\xb8\x0e\xb9\xac\xdb\x78\xf0\xe5\x98\xa8\x7d\x95\x38\x7d\x57\xb2\xb9\xb7\x48\x39\x63\x84\xe1\x63\x61\x3a\x96\x9f\x73\xf7\x7d\xf2\x43\xea\x40\xa3\x0a\xd4\x35\x96\xd9\xa5\x5e\xc6\xbb\x8a\x96\x17\x72\xa5\xac\x44\xf2\x43\x12\xe3\x77\xe5\xc5\x07\xa5\xc5\x43\xff\xfe\x89\xcd\xc8\x0a\x58\x76\x40\x6a\x83\x99\x62\xc2\x75\x91\xd5\x87\x14\xc2\x20\x7d\x00\xb1\x10\xb4\xf0\x24\x40\x5b\xf9\x1a\xa2\xc3\x25\x7e\x0b\xd2\x46\xe6\x99\x2c\x59\xbb\x87\x6d\x68\x14\x42\x68\x6e\x98\x88\xfd\x25\x0d\x8b\x63\x4e\xfa\x2c\x2b\xa3\x1d\xb2\x35\xe3\xa2\xdb\x7e\x9d\xb6\xae\x77\x27\x5d\x38\x69\xa2\xbd\xd5\x60\xf8\xcb\x35\x1b\x80\x64\xb0\x6f\x88\x87\x00\xab\xf6\x77\x10\xff\x29\x45\x0a\x5d\x64\x48\xe9\x09\xd8\x96\xf1\x94\xd1\xad\x14\x91\x2f\x22\x7c\x51\x8a\x4c\xd8\x3a\x42\x28\x92\xf4\x45\xd6\x9f\x38\xc4\x4d\xe7\x51\xbe\x23\x0e\xdb\x81\x18\x1e\xbd\x39\x1e\x4e\x62\x1e\x4a\x61\xdc\xf1\x5f\xbb\x3c\x65\x6d\x5c\x33\xaf\xa3\x1a\x94\xdf\x72\xaa\xa9\xe6\x1b\x42\xc9\xce\xf9\x05\x4e\xbd\x29\xbe\x2b\xba\x1e\x16\x5e\xdf\xcc\x3a\x8e\x21\x76\x51\xf6\xad\xfe\xf0\x46\xca\x11\xa4\x48\x17\x12\x54\x51\x5c\xdc\x85\x3e\xbd\xb9\x54\x77\xf4\xba\x3c\x74\x5c\x8b\xe8\xbe\x7f\x83\xa1\x5c\xb8\x8f\xcf\x2b\x44\x01\xdc\xa0\xa2\xef\x4f\x40\xe8\xf2\xdf\x70\x29\x10\x1c\xa3\xa1\xe5\x13\x4e\x8c\xd8\x2b\xf6\x9f\x21\xa9\x71\xe5\x62\x3d\xee\xf7\xdc\xfb\x5a\xbc\x47\xe6\xb6\x8a\x77\xf2\x9a\x97\x6c\x32\x17\x80\xc8\xdc\x1e\x31\x97\x40\x4f\x68\xec\x88\xb9\xd4\x07\x03\x73\x69\x7f\x84\xa1\xb1\x63\x62\x51\xa7\x31\xd0\x0e\x09\x73\x7b\x1c\x11\x41\x80\xca\xce\x59\xb7\xc0\x71\x45\xaa\x53\xa9\xfa\x5e\x6b\x54\xca\x95\x87\x92\xa2\xd1\xa8\xaf\x18\x76\xd8\xf0\xac\x66\x1b\x9e\xe1\xa8\xce\xb5\x5a\x48\x3b\x0d\x78\xec\x4e\x66\x64\xf3\x12\xb0\x77\x88\x06\x24\x76\x6b\xbf\x2f\x55\xd6\x6a\x6e\x97\xf6\xb2\x76\xd6\x7b\x31\x73\xfb\x70\x95\xe5\xe4\xfa\x16\x1b\x73\xba\x94\xf8\x3c\xe3\xe7\x39\xcb\x06\x05\x2e\xf6\xdc\x5e\x2d\xa2\xf9\x3f\xd0\x6b\x6c\x54\x3f\x3f\x44\x7f\x27\xb0\x1f\x66\x2b\xe2\xbf\xb2\xdd\x50\xa1\xe9\x7a\xad\x8a\x8b\x18\x35\x57\xf4\x69\x9d\x63\x1a\x83\xa4\xff\xe8\xd0\xe5\xdd\x1f\x9a\xbe\x70\x4e\x26\xa0\x51\x71\xd2\x68\x3f\xd8\x50\xcb\x40\xa1\x49\x4a\x05\x8f\xd0\xec\x21\x41\x66\xbf\xa5\x1b\x09\x39\xd3\x1a\x32\x64\x74\x5d\x47\x7f\xbc\xd8\x83\x54\x31\xd1\x35\x1f\x42\x3e\x24\x25\xfd\x19\x46\xd1\xbf\x13\xa4\x2f\x93\xf6\xbe\x22\xa7\x2e\x9c\x9b\x91\xbf\xa2\xe6\x3a\x4f\xd9\x1e\xb8\xa1\x92\xec\x0f\x55\x8f\xd2\xf2\x03\x36\x85\xa8\x96\x54\x75\x5b\xcd\x05\x54\x37\x5d\xcd\x18\xb5\xaf\x29\x3c\x9b\xd1\xe9\x2f\x7a\x9a\x71\x59\xf1\xff\x66\x10\x86\xb2\xd1\x8c\xb4\xf0\x96\x9e\x9a\xe1\x64\x47\x6c\x24\x35\x03\x29\xd4\x94\x30\x60\x41\x53\x0a\xd8\xa7\x56\x68\x91\x40\x88\x2d\x5b\xf5\x74\x39\xdd\x3e\x8d\x3c\x60\xc8\x8b\x03\x32\xce\x0d\xee\x29\x13\x3b\x1b\x79\xa4\x11\x1c\xdd\x8b\x4f\x37\xb8\xff\xdc\x5d\x45\x3c\x1c\x6b\xf3\x2a\x3a\xe9\x21\xed\xc6\xee\x09\xe4\x72\x17\x7c\x7a\xfa\x12\xf8\xcf\x75\x81\x50\xf9\x80\x3f\x79\x12\xd6\xac\x8f\x7f\xe2\x9f\x61\xda\x42\x7c\x6b\xbc\x6a\xf8\x6b\x31\xe2\xe6\xbc\xec\xf7\x0e\xfd\x43\xff\x9f\x03\x00\x1c\x8f\x77\x33\x2e\x27\x00\x00")
 
 func call_tracerJsBytes() ([]byte, error) {
 	return bindataRead(
@@ -133,7 +133,7 @@ func call_tracerJs() (*asset, error) {
 	}
 
 	info := bindataFileInfo{name: "call_tracer.js", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe9, 0xef, 0x68, 0xda, 0xd8, 0x9, 0xf5, 0xd5, 0x71, 0xa8, 0x8a, 0xfb, 0x30, 0xe8, 0xf0, 0x72, 0x14, 0x36, 0x6b, 0x62, 0x5a, 0x4e, 0xff, 0x16, 0xdc, 0xd3, 0x2c, 0x68, 0x7b, 0x79, 0x9f, 0xd3}}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa, 0x9c, 0x79, 0x70, 0x2b, 0x3f, 0x8a, 0x32, 0x8b, 0x5a, 0x9e, 0x18, 0xc3, 0xc7, 0xbe, 0xed, 0x0, 0x50, 0x5c, 0x65, 0xf, 0x8a, 0x9, 0x57, 0x5f, 0x58, 0xa1, 0x8e, 0x1, 0xed, 0xa8, 0x6b}}
 	return a, nil
 }
 
@@ -197,7 +197,7 @@ func opcount_tracerJs() (*asset, error) {
 	return a, nil
 }
 
-var _prestate_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x57\xdd\x6f\xdb\x38\x12\x7f\x96\xfe\x8a\x41\x5f\x6c\xa3\xae\xdc\x64\x81\x3d\xc0\xb9\x1c\xa0\xba\x6e\x1b\x20\x9b\x04\xb6\x7b\xb9\xdc\x62\x1f\x28\x72\x24\x73\x4d\x93\x02\x49\xd9\xf1\x15\xf9\xdf\x0f\x43\x7d\xf8\xa3\x49\xd3\xdd\x37\x9b\x1c\xfe\xe6\xfb\x37\xa3\xd1\x08\x26\xa6\xdc\x59\x59\x2c\x3d\x9c\xbf\x3f\xfb\x07\x2c\x96\x08\x85\x79\x87\x7e\x89\x16\xab\x35\xa4\x95\x5f\x1a\xeb\xe2\xd1\x08\x16\x4b\xe9\x20\x97\x0a\x41\x3a\x28\x99\xf5\x60\x72\xf0\x27\xf2\x4a\x66\x96\xd9\x5d\x12\x8f\x46\xf5\x9b\x67\xaf\x09\x21\xb7\x88\xe0\x4c\xee\xb7\xcc\xe2\x18\x76\xa6\x02\xce\x34\x58\x14\xd2\x79\x2b\xb3\xca\x23\x48\x0f\x4c\x8b\x91\xb1\xb0\x36\x42\xe6\x3b\x82\x94\x1e\x2a\x2d\xd0\x06\xd5\x1e\xed\xda\xb5\x76\x7c\xbe\xf9\x0a\xd7\xe8\x1c\x5a\xf8\x8c\x1a\x2d\x53\x70\x57\x65\x4a\x72\xb8\x96\x1c\xb5\x43\x60\x0e\x4a\x3a\x71\x4b\x14\x90\x05\x38\x7a\xf8\x89\x4c\x99\x37\xa6\xc0\x27\x53\x69\xc1\xbc\x34\x7a\x08\x28\xc9\x72\xd8\xa0\x75\xd2\x68\xf8\xa5\x55\xd5\x00\x0e\xc1\x58\x02\xe9\x33\x4f\x0e\x58\x30\x25\xbd\x1b\x00\xd3\x3b\x50\xcc\xef\x9f\xfe\x44\x40\xf6\x7e\x0b\x90\x3a\xa8\x59\x9a\x12\xc1\x2f\x99\x27\xaf\xb7\x52\x29\xc8\x10\x2a\x87\x79\xa5\x86\x84\x96\x55\x1e\xee\xaf\x16\x5f\x6e\xbf\x2e\x20\xbd\x79\x80\xfb\x74\x36\x4b\x6f\x16\x0f\x17\xb0\x95\x7e\x69\x2a\x0f\xb8\xc1\x1a\x4a\xae\x4b\x25\x51\xc0\x96\x59\xcb\xb4\xdf\x81\xc9\x09\xe1\xb7\xe9\x6c\xf2\x25\xbd\x59\xa4\x1f\xae\xae\xaf\x16\x0f\x60\x2c\x7c\xba\x5a\xdc\x4c\xe7\x73\xf8\x74\x3b\x83\x14\xee\xd2\xd9\xe2\x6a\xf2\xf5\x3a\x9d\xc1\xdd\xd7\xd9\xdd\xed\x7c\x9a\xc0\x1c\xc9\x2a\xa4\xf7\xaf\xc7\x3c\x0f\xd9\xb3\x08\x02\x3d\x93\xca\xb5\x91\x78\x30\x15\xb8\xa5\xa9\x94\x80\x25\xdb\x20\x58\xe4\x28\x37\x28\x80\x01\x37\xe5\xee\xa7\x93\x4a\x58\x4c\x19\x5d\x04\x9f\x5f\x2c\x48\xb8\xca\x41\x1b\x3f\x04\x87\x08\xff\x5c\x7a\x5f\x8e\x47\xa3\xed\x76\x9b\x14\xba\x4a\x8c\x2d\x46\xaa\x86\x73\xa3\x7f\x25\x31\x61\x96\x16\x9d\x67\x1e\x17\x96\x71\xb4\x60\x2a\x5f\x56\xde\x81\xab\xf2\x5c\x72\x89\xda\x83\xd4\xb9\xb1\xeb\x50\x29\xe0\x0d\x70\x8b\xcc\x23\x30\x50\x86\x33\x05\xf8\x88\xbc\x0a\x77\x75\xa4\x43\xb9\x5a\xa6\x1d\xe3\xe1\x34\xb7\x66\x4d\xbe\x56\xce\xd3\x0f\xe7\x70\x9d\x29\x14\x50\xa0\x46\x27\x1d\x64\xca\xf0\x55\x12\x7f\x8b\xa3\x03\x63\xa8\x4e\x82\x87\x8d\x50\xa8\x8d\x2d\xf6\x2c\x42\x56\x49\x25\xa4\x2e\x92\x38\x6a\xa5\xc7\xa0\x2b\xa5\x86\x71\x80\x50\xc6\xac\xaa\x32\xe5\xdc\x54\xc1\xf6\x3f\x91\xfb\x1a\xcc\x95\xc8\x65\x4e\xc5\xc1\xba\x5b\x6f\xc2\x55\xa7\xd7\x64\x24\x9f\xc4\xd1\x11\xcc\x18\xf2\x4a\x07\x77\xfa\x4c\x08\x3b\x04\x91\x0d\xbe\xc5\x51\xb4\x61\x96\xb0\xe0\x12\xbc\xf9\x82\x8f\xe1\x72\x70\x11\x47\x91\xcc\xa1\xef\x97\xd2\x25\x2d\xf0\xef\x8c\xf3\x3f\xe0\xf2\xf2\x32\x34\x75\x2e\x35\x8a\x01\x10\x44\xf4\x9c\x58\x7d\x13\x65\x4c\x31\xcd\x71\x0c\xbd\xf7\x8f\x3d\x78\x0b\x22\x4b\x0a\xf4\x1f\xea\xd3\x5a\x59\xe2\xcd\xdc\x5b\xa9\x8b\xfe\xd9\xaf\x83\x61\x78\xa5\x4d\x78\x03\x8d\xf8\x8d\xe9\x84\xeb\x7b\x6e\x44\xb8\x6e\x6c\xae\xa5\x26\x46\x34\x42\x8d\x94\xf3\xc6\xb2\x02\xc7\xf0\xed\x89\xfe\x3f\x91\x57\x4f\x71\xf4\x74\x14\xe5\x79\x2d\xf4\x42\x94\x1b\x08\x40\xed\x6d\x57\xe7\x85\xa4\x4e\x3d\x4c\x40\xc0\xfb\x51\x12\xe6\xad\x29\x27\x49\x58\xe1\xee\xf5\x4c\xd0\x85\x14\x8f\xdd\xc5\x0a\x77\x83\x8b\xf8\xc5\x14\x25\x8d\xd1\xbf\x4b\xf1\xf8\xb3\xf9\x3a\x79\x73\x14\xd7\x39\x49\xed\xed\x1d\x0c\x4e\xe2\x68\xd1\x55\xca\x53\xb9\x4b\xbd\x31\x2b\x22\xae\x25\xc5\x47\xa9\x10\x12\x53\x52\xb6\x5c\xcd\x1c\x19\xa2\x06\xe9\xd1\x32\xa2\x4e\xb3\x41\x4b\x53\x03\x2c\xfa\xca\x6a\xd7\x85\x31\x97\x9a\xa9\x16\xb8\x89\xba\xb7\x8c\xd7\x3d\x53\x9f\x1f\xc4\x92\xfb\xc7\x10\xc5\xe0\xdd\x68\x04\xa9\x07\x72\x11\x4a\x23\xb5\x1f\xc2\x16\x41\x23\x0a\x6a\x7c\x81\xa2\xe2\x3e\xe0\xf5\x36\x4c\x55\xd8\xab\x9b\x9b\x28\x32\x3c\x35\x15\x4d\x82\x83\xe6\x1f\x06\x03\xd7\x66\x13\x46\x5c\xc6\xf8\x0a\x9a\x86\x33\x56\x16\x52\xc7\x4d\x38\x8f\x9a\x8d\x2c\x4a\x08\x38\x98\x15\x72\x45\x49\xa4\x93\x0f\x4c\xc1\x25\x64\xb2\xb8\xd2\xfe\x24\x79\x75\xd0\xdb\xa7\x83\x3f\x92\xa6\x79\x12\x47\x84\xd7\x3f\x1f\x0c\xe1\xec\xd7\xae\x22\xbc\x21\x28\x78\x1d\xcc\x9b\x97\xa1\xe2\xd3\x62\x78\xfe\x59\x50\x43\x1d\xfc\x36\x68\x4d\x5c\x95\x51\x3a\x6a\x3f\x43\x1c\x8f\xbb\xf8\xe2\x07\xb8\xc7\xbe\xb5\xb8\x4d\x68\x12\x26\xc4\xcb\xa0\x75\x8a\x3e\x22\xb7\xb8\x26\x56\xa7\x2c\x70\xa6\x14\xda\x9e\x83\xc0\x19\xc3\xa6\x9c\x42\xbe\x70\x5d\xfa\x5d\xcb\xf5\x9e\xd9\x02\xbd\x7b\xdd\xb0\x80\xf3\xee\x5d\x4b\x81\x21\x14\xbb\x12\xe1\xf2\x12\x7a\x93\xd9\x34\x5d\x4c\x7b\x4d\x1b\x8d\x46\x70\x8f\x61\x13\xca\x94\xcc\x84\xda\x81\x40\x85\x1e\x6b\xbb\x8c\x0e\x21\xea\x28\x61\x48\x2b\x0d\x2d\x1b\xf8\x28\x9d\x97\xba\x80\x9a\x29\xb6\x34\x57\x1b\xb8\xd0\x23\x9c\x55\x8e\xaa\xf5\x64\x08\x79\x43\x1b\x85\x45\xe2\x15\xe2\xff\xd0\x6e\x4c\xc9\x6e\x03\xc9\xa5\x75\x1e\x4a\xc5\x38\x26\x84\xd7\x19\xf3\x72\x7e\x9b\x4e\x26\xd5\xb3\xd0\x82\x01\x68\x3f\xe0\x98\xa2\x01\x49\xea\x1d\xf4\x5b\x8c\x41\x1c\x45\xb6\x95\x3e\xc0\xbe\xd8\x53\x82\xf3\x58\x1e\x12\x02\x2d\x16\xb8\x41\xa2\xd0\xc0\x06\xf5\x30\x24\x5d\xff\xfe\xad\x99\xbe\xe8\x92\x38\xa2\x77\x07\x7d\xad\x4c\x71\xdc\xd7\xa2\x0e\x0b\xaf\xac\xa5\xfc\x77\x14\x9c\x53\x8f\xff\x59\x39\x4f\x31\xb5\x14\x9e\x86\x2d\x9e\x23\xc9\x40\x89\x34\x6d\x07\xdf\x93\x21\xcd\xad\x30\x27\x48\x5d\x33\xa5\xea\x6d\xae\x34\x1e\xb5\x97\x4c\xa9\x1d\xe5\x61\x6b\x69\x8d\xa1\xc5\x65\x08\x4e\x92\x54\x60\x9c\x20\x2a\x35\x57\x95\xa8\xcb\x20\xd4\x71\x83\xe7\x82\xcd\xc7\xfb\xcf\x1a\x9d\x63\x05\x26\x54\x49\xb9\x7c\x6c\x36\x48\x0d\xbd\x9a\xe4\xfa\x83\x5e\xd2\x19\x79\x4c\x31\xca\x14\x49\x5b\x64\x44\xd3\xa9\x10\x16\x9d\xeb\x0f\x1a\xce\xe9\x32\x7b\xbf\x44\x4d\xc1\x07\x8d\x5b\xe8\x56\x13\xc6\x39\xad\x6a\x62\x08\x4c\x08\xa2\xb6\x93\x35\x22\x8e\x22\xb7\x95\x9e\x2f\x21\x68\x32\xe5\xbe\x17\x07\x4d\xfd\x73\xe6\x10\xde\x4c\xff\xb3\x98\xdc\x7e\x9c\x4e\x6e\xef\x1e\xde\x8c\xe1\xe8\x6c\x7e\xf5\xdf\x69\x77\xf6\x21\xbd\x4e\x6f\x26\xd3\x37\xe3\x30\x9b\x9f\x71\xc8\x9b\xd6\x05\x52\xe8\x3c\xe3\xab\xa4\x44\x5c\xf5\xdf\x1f\xf3\xc0\xde\xc1\x28\xca\x2c\xb2\xd5\xc5\xde\x98\xba\x41\x1b\x1d\x2d\xe5\xc2\x25\xbc\x18\xac\x8b\x97\xad\x99\x34\xf2\xfd\x96\xc8\xf7\xab\x48\xa0\x8a\xd7\xed\x38\xff\xcb\x86\x84\xde\x61\x7c\x35\x06\xc7\x14\x6d\xc0\xf2\x7f\xf4\xe5\x92\xe7\x0e\xfd\x10\x50\x0b\xb3\x25\xe6\xeb\x50\xeb\x9b\x06\xf7\x20\x64\x67\x83\x9a\x41\x6f\xf3\xfe\xa0\x13\x26\xb0\xef\x45\xcf\x9f\x13\x45\x2d\xe0\xb2\x45\x7f\x1b\x5e\xbe\x1e\xa8\xf3\x26\x52\x27\x0a\x7e\x39\xd9\xf0\xc2\xfd\x1a\xd7\xc6\xee\x9a\x71\x74\xe0\xdf\x8f\xa3\x9a\x5e\x5f\x77\xf5\x44\x7f\xa8\xc8\xba\x83\x8f\xd3\xeb\xe9\xe7\x74\x31\x3d\x92\x9a\x2f\xd2\xc5\xd5\xa4\x3e\xfa\xcb\x85\x77\xf6\xd3\x85\xd7\x9b\xcf\x17\xb7\xb3\x69\x6f\xdc\xfc\xbb\xbe\x4d\x3f\xf6\xbe\x53\xd8\x6c\x81\x3f\x6a\x5d\x6f\xee\x8d\x15\x7f\xa7\x03\x0e\x36\xb2\x9c\x3d\xb7\x90\x05\x6a\xe7\xbe\x3a\xf9\xe0\x01\xa6\x5b\x56\xce\xeb\x8f\xbe\x28\xbc\x7f\x96\x87\x9f\xe2\xa7\xf8\xff\x01\x00\x00\xff\xff\xb1\x28\x85\x2a\x8a\x10\x00\x00")
+var _prestate_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x58\x5d\x6f\xdb\x38\xd6\xbe\x96\x7f\xc5\x79\x7b\x63\x1b\x75\xe5\x26\x03\xcc\x0b\x38\x9b\x05\x54\xd7\x6d\x03\x78\x92\xc0\x76\x37\x9b\x1d\xcc\x05\x45\x1e\x59\x1c\xd3\xa4\x40\x52\x76\x3c\x45\xfe\xfb\xe2\x50\x92\xbf\x6a\x27\xd9\xc5\xde\x45\xe4\xe1\x73\xbe\x9f\x73\xe2\x7e\x1f\x86\xa6\xd8\x58\x39\xcf\x3d\x5c\x7e\xbc\xf8\x7f\x98\xe5\x08\x73\xf3\x01\x7d\x8e\x16\xcb\x25\x24\xa5\xcf\x8d\x75\xad\x7e\x1f\x66\xb9\x74\x90\x49\x85\x20\x1d\x14\xcc\x7a\x30\x19\xf8\x23\x79\x25\x53\xcb\xec\x26\x6e\xf5\xfb\xd5\x9b\x93\xd7\x84\x90\x59\x44\x70\x26\xf3\x6b\x66\x71\x00\x1b\x53\x02\x67\x1a\x2c\x0a\xe9\xbc\x95\x69\xe9\x11\xa4\x07\xa6\x45\xdf\x58\x58\x1a\x21\xb3\x0d\x41\x4a\x0f\xa5\x16\x68\x83\x6a\x8f\x76\xe9\x1a\x3b\xbe\xde\x7e\x87\x31\x3a\x87\x16\xbe\xa2\x46\xcb\x14\xdc\x97\xa9\x92\x1c\xc6\x92\xa3\x76\x08\xcc\x41\x41\x27\x2e\x47\x01\x69\x80\xa3\x87\x5f\xc8\x94\x69\x6d\x0a\x7c\x31\xa5\x16\xcc\x4b\xa3\x7b\x80\x92\x2c\x87\x15\x5a\x27\x8d\x86\x5f\x1a\x55\x35\x60\x0f\x8c\x25\x90\x0e\xf3\xe4\x80\x05\x53\xd0\xbb\x2e\x30\xbd\x01\xc5\xfc\xee\xe9\x1b\x02\xb2\xf3\x5b\x80\xd4\xc1\xbd\xdc\x14\x08\x3e\x67\x9e\x22\xb1\x96\x4a\x41\x8a\x50\x3a\xcc\x4a\xd5\x23\xb4\xb4\xf4\xf0\x70\x33\xfb\x76\xf7\x7d\x06\xc9\xed\x23\x3c\x24\x93\x49\x72\x3b\x7b\xbc\x82\xb5\xf4\xb9\x29\x3d\xe0\x0a\x2b\x28\xb9\x2c\x94\x44\x01\x6b\x66\x2d\xd3\x7e\x03\x26\x23\x84\xdf\x46\x93\xe1\xb7\xe4\x76\x96\x7c\xba\x19\xdf\xcc\x1e\xc1\x58\xf8\x72\x33\xbb\x1d\x4d\xa7\xf0\xe5\x6e\x02\x09\xdc\x27\x93\xd9\xcd\xf0\xfb\x38\x99\xc0\xfd\xf7\xc9\xfd\xdd\x74\x14\xc3\x14\xc9\x2a\xa4\xf7\xaf\xc7\x3c\x0b\xd9\xb3\x08\x02\x3d\x93\xca\x35\x91\x78\x34\x25\xb8\xdc\x94\x4a\x40\xce\x56\x08\x16\x39\xca\x15\x0a\x60\xc0\x4d\xb1\x79\x73\x52\x09\x8b\x29\xa3\xe7\xc1\xe7\xb3\x05\x09\x37\x19\x68\xe3\x7b\xe0\x10\xe1\x6f\xb9\xf7\xc5\xa0\xdf\x5f\xaf\xd7\xf1\x5c\x97\xb1\xb1\xf3\xbe\xaa\xe0\x5c\xff\xef\x71\x8b\x30\x0b\x8b\xce\x33\x8f\x33\xcb\x38\x5a\x30\xa5\x2f\x4a\xef\xc0\x95\x59\x26\xb9\x44\xed\x41\xea\xcc\xd8\x65\xa8\x14\xf0\x06\xb8\x45\xe6\x11\x18\x28\xc3\x99\x02\x7c\x42\x5e\x86\xbb\x2a\xd2\x64\x98\xb7\x4c\x3b\xc6\xc3\x69\x66\xcd\x92\x7c\x2d\x9d\xa7\x3f\x9c\xc3\x65\xaa\x50\xc0\x1c\x35\x3a\xe9\x20\x55\x86\x2f\xe2\xd6\x8f\x56\xb4\x67\x0c\x35\x0e\x01\x35\x42\xa1\x36\xd6\xd8\xb6\x08\x69\x29\x95\x90\x7a\x1e\xb7\xa2\x46\x7a\x00\xba\x54\xaa\xd7\x0a\x10\xca\x98\x45\x59\x24\x9c\x9b\x32\xd8\xfe\x27\x72\x4f\x00\x08\xae\x40\x2e\x33\x2a\x0e\xb6\xbd\xf5\x26\x5c\x6d\xf5\x9a\x94\xe4\xe3\x56\x74\x00\x33\x80\xac\xd4\xc1\x9d\x0e\x13\xc2\xf6\x40\xa4\xdd\x1f\xad\x28\x5a\x31\x0b\x8c\x73\xb8\x06\x6f\xbe\xe1\x53\xb8\xec\x5e\xb5\xa2\x48\x66\xd0\xf1\xb9\x74\x71\x03\xfc\x3b\xe3\xfc\x0f\xb8\xbe\xbe\x0e\x4d\x9d\x49\x8d\xa2\x0b\x04\x11\x9d\x12\xab\x6e\xa2\x94\x29\xa6\x39\x0e\xa0\xfd\xf1\xa9\x0d\xef\x41\xa4\xf1\x1c\xfd\xa7\xea\xb4\x52\x16\x7b\x33\xf5\x56\xea\x79\xe7\xe2\xd7\x6e\x2f\xbc\xd2\x26\xbc\x81\x5a\xfc\xd6\x6c\x85\xab\x7b\x6e\x44\xb8\xae\x6d\xae\xa4\x86\x46\xd4\x42\xb5\x94\xf3\xc6\xb2\x39\x0e\xe0\xc7\x33\x7d\x3f\x93\x57\xcf\xad\xe8\xf9\x20\xca\xd3\x4a\xe8\x4c\x94\x6b\x08\x40\xed\xed\xb6\xce\xe7\x92\x3a\x75\x3f\x01\x01\xef\xa5\x24\xd4\x5a\x7e\x4a\xc2\x02\x37\xaf\x67\x82\x52\x24\xc5\xd3\xf6\x62\x81\x9b\xee\x55\xeb\x6c\x8a\xe2\xda\xe8\xdf\xa5\x78\x7a\x6b\xbe\x8e\xde\xd4\x8a\xaa\xb8\x4e\x09\x79\x67\x6f\xb7\x7b\x14\x47\x8b\xae\x54\x9e\xca\x5d\xea\x95\x59\x10\x71\xe5\x14\x1f\xa5\x42\xb4\x4c\x41\xd9\x72\x15\x73\xa4\x88\x1a\xa4\x47\xcb\x88\x3a\xcd\x0a\x2d\x4d\x0d\xb0\xe8\x4b\xab\xdd\x36\x8c\x99\xd4\x4c\x35\xc0\x75\xd4\xbd\x65\xbc\xea\x99\xea\x7c\x2f\x96\xdc\x3f\x85\x28\x06\xef\xfa\x7d\x48\x3c\x90\x8b\x50\x18\xa9\x7d\x0f\xd6\x08\x1a\x51\x50\xe3\x0b\x14\x25\xa7\x5b\x84\xf6\x8a\xa9\x12\xdb\x55\x73\x13\x45\x46\xa4\xdd\x94\x1e\xed\x7e\xf3\xf7\x82\x81\x4b\xb3\x0a\x23\x2e\x65\x7c\x01\x75\xc3\x19\x2b\xe7\x52\xc7\x61\x0e\x5b\xe4\xb2\x20\xa6\xa9\x0c\x58\x33\x47\x0c\x16\x4a\x0c\x05\x94\x05\x6c\xd0\x83\xcc\x08\x22\x0f\x77\x40\x41\xe9\x01\xc6\xf3\x18\x18\x71\x06\x37\xcb\x42\x2a\x8c\x4f\xa5\x35\x24\x91\xf8\xe1\x54\xfe\xa8\xd5\x9a\xd2\xae\x6e\x0e\xfa\xbe\xc3\xfd\x53\x4c\x3e\x86\x08\x5d\x9d\x97\xf1\xa6\x96\xa8\x2b\x8e\xde\x7c\x62\x0a\xae\x21\x95\xf3\x1b\xed\x8f\x2a\xad\xaa\x90\x06\xbc\xfb\x47\x5c\x77\x7a\xec\x88\x9d\x3b\x97\xdd\x1e\x5c\xfc\xba\x2d\x5f\x6f\x08\x0a\x5e\x07\xf3\xe6\x3c\x54\x63\xfb\x2b\xcf\x82\x1a\xa2\x9b\xf7\x41\x6b\xec\xca\x94\x6a\xc7\x07\xc1\x90\xf4\x43\xca\xb9\x7a\x01\xf7\xd0\xb7\x06\xb7\x0e\x4d\xcc\x84\x38\x0f\x5a\x55\xc2\x67\xe4\x16\x97\x34\x82\xa8\x64\x38\x53\x0a\x6d\x9b\x0a\x40\x73\xec\xd5\xb5\x1f\x8a\x0b\x97\x85\xdf\x34\x83\xc9\x33\x3b\x47\xef\x5e\x37\x2c\xe0\x7c\xf8\xd0\xf0\x35\x19\xe3\x37\x05\xc2\xf5\x35\xb4\x87\x93\x51\x32\x1b\xb5\xeb\x9a\xe9\xf7\xe1\x81\x0c\xd0\x90\x2a\x99\x0a\xb5\x01\x81\x0a\x7d\xd8\x0e\x80\x1b\x1d\x42\xb4\xe5\xaf\x1e\xed\x5f\xb4\x19\xe1\x93\x74\x5e\xea\x39\x84\x63\x58\xd3\x12\x50\xc3\x85\x86\xe6\xac\x74\x28\x7e\x9a\x98\xde\xd0\xfa\x63\x91\x26\x11\x0d\xab\xc0\x0d\x4c\xc9\xed\xba\x94\x49\xeb\x3c\x14\x8a\xf1\x50\xf2\xd1\xd6\x98\xd3\xee\x52\x59\x34\x35\xde\xef\xc3\x24\xf0\x45\x00\xda\x4d\x63\xa6\x68\x9a\x93\x7a\x07\x9d\x06\xa3\xdb\x8a\x22\xdb\x48\xef\x61\x5f\xed\xf8\xcb\x79\x2c\xf6\xd9\x8b\xb6\x20\x5c\x21\xf1\x7d\xa0\xae\x6a\xab\x23\x5d\xff\xf8\xad\x5e\x15\xd0\xc5\xad\x88\xde\xed\x91\x90\x32\xf3\x43\x12\x12\x55\x58\x78\x69\x2d\xe5\x7f\x3b\x2f\x32\x22\xa4\x3f\x4b\xe7\x29\xa6\x96\x78\xb0\xa6\xb6\x97\x5b\xff\x85\xce\x27\x2f\xea\x91\x5a\xad\x9e\x85\xf1\xa8\xbd\x64\x4a\x6d\x28\x0f\x6b\x4b\x3b\x17\x6d\x59\x3d\x70\x92\xa4\x28\x16\x95\xa8\xd4\x5c\x95\x82\x4e\x10\x42\x73\xd4\x78\x2e\xd8\x7c\xb8\xac\x2d\xd1\x39\x36\xc7\x98\x2a\x29\x93\x4f\xf5\xba\xab\xa1\x5d\x31\x72\xa7\xdb\x8e\x5b\xd1\x49\x82\x51\x66\x1e\x37\x45\x46\x33\x25\x11\xc2\xa2\x73\x9d\xee\x96\x95\xea\xcc\x3e\xe4\xa8\x29\xf8\xa0\x71\x5d\xd7\x9c\x74\x34\x16\x69\xaf\x14\x3d\x60\x42\x10\x89\x1e\xed\x3c\xad\x28\x72\x6b\xe9\x79\x0e\x41\x93\x29\x76\xbd\xd8\xad\xeb\x9f\x33\x87\xf0\x6e\xf4\xcf\xd9\xf0\xee\xf3\x68\x78\x77\xff\xf8\x6e\x00\x07\x67\xd3\x9b\x7f\x8d\xb6\x67\x9f\x92\x71\x72\x3b\x1c\xbd\x1b\xb4\xa2\xd3\x0e\x79\xd3\xb8\x40\x0a\x9d\x67\x7c\x11\x17\x88\x8b\xce\xc7\x43\x1e\xd8\x39\x18\x45\xa9\x45\xb6\xb8\xda\x19\x53\x35\x68\xad\xa3\xa1\x5c\xb8\x86\xb3\xc1\xba\x3a\x6f\xcd\xb0\x96\xef\x34\x54\xbf\xdb\x9b\xe8\xe4\x0d\x76\x5c\xfe\xc7\x86\x50\x95\x90\xe3\x03\x70\x4c\xd1\xba\x2e\xff\xc2\x1e\x98\x2c\x73\xe8\x7b\x80\x5a\x98\x35\x31\xdf\x16\xb5\xba\xa9\x71\xf7\x42\x76\xd1\xad\x18\xf4\x2e\xeb\x74\xb7\xc2\x4e\xfe\x85\x3f\x8b\x5e\x9e\x12\x45\x2d\xe0\xba\xd6\x0b\xef\x83\x19\xaf\x07\xea\xb2\x8e\xd4\x91\x82\x5f\x0e\xd3\xd7\x0b\x06\x2c\x71\x69\xec\xa6\x1e\x47\x7b\xfe\xbd\x1c\xd5\x64\x3c\xde\xd6\xd3\x30\x19\x8f\xa9\xf0\xb6\x07\x9f\x47\xe3\xd1\xd7\x64\x36\x3a\x90\x9a\xce\x92\xd9\xcd\xb0\x3a\x3a\xef\x41\x93\x85\x23\xcb\x2f\xde\x5c\x78\xed\xe9\x74\x76\x37\x19\xb5\x07\xf5\xd7\xf8\x2e\xf9\xdc\xfe\x49\x61\xbd\xb2\xbe\xd4\xba\xde\x3c\x18\x2b\xfe\x9b\x0e\xd8\x5b\x1f\x43\x03\x27\xa1\xc1\x8f\x77\xc8\x40\x04\xfb\xdb\x51\x43\x04\x0e\xea\x29\xb5\xce\x25\xcf\x41\x18\xac\x76\x47\xda\xb9\x5c\x6e\xd6\xb4\x71\x85\x01\xd6\x50\x78\x4d\xdb\x22\xd0\xf6\x56\xdf\x1e\x7b\x57\xc0\x3b\x02\xff\xdf\xee\x60\x15\x3a\xed\x0b\xb6\xd1\xd2\x8c\xec\xfa\x6a\x81\x1b\xf8\xbf\x73\xab\xfa\x61\x42\x8e\xc1\x76\x08\x5b\xe0\xbd\xf0\x66\xec\xd4\x72\x4e\x6c\xce\xb8\x2f\x8f\xfe\xf9\xdd\x8b\x58\x56\xfd\x00\x10\x85\xf7\x27\xc7\xdc\x73\xeb\xb9\xf5\xef\x01\x00\x2a\x46\x5c\x25\x96\x12\x00\x00")
 
 func prestate_tracerJsBytes() ([]byte, error) {
 	return bindataRead(
@@ -213,7 +213,7 @@ func prestate_tracerJs() (*asset, error) {
 	}
 
 	info := bindataFileInfo{name: "prestate_tracer.js", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe9, 0x79, 0x70, 0x4f, 0xc5, 0x78, 0x57, 0x63, 0x6f, 0x5, 0x31, 0xce, 0x3e, 0x5d, 0xbd, 0x71, 0x4, 0x46, 0x78, 0xcd, 0x1d, 0xcd, 0xb9, 0xd8, 0x10, 0xff, 0xe6, 0xc5, 0x59, 0xb9, 0x25, 0x6e}}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x76, 0x54, 0x79, 0x9a, 0x7b, 0xff, 0x2f, 0x73, 0x7, 0x72, 0x30, 0x85, 0xb4, 0x45, 0x2a, 0xdd, 0x97, 0xde, 0xc6, 0x2e, 0x1c, 0x53, 0x10, 0x77, 0xe0, 0xa4, 0xc1, 0xfd, 0x3c, 0xe6, 0x5c, 0x7d}}
 	return a, nil
 }
 
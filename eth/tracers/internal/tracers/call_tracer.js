@@ -24,6 +24,9 @@
 	// an inner call.
 	descended: false,
 
+	// entered tracks the call stack depths of the calls made by precompiles.
+	entered: [],
+
 	// step is invoked for every opcode that the VM executes.
 	step: function(log, db) {
 		// Capture any errors immediately
@@ -155,6 +158,49 @@
 		}
 	},
 
+	// enter is invoked when a precompile makes a call of its own. These calls
+	// aren't made by any opcode, so they can't be detected in step.
+	enter: function(frame) {
+		var call = {
+			type:  frame.type,
+			from:  toHex(frame.from),
+			to:    toHex(frame.to),
+			input: toHex(frame.input),
+			gas:   frame.gas
+		};
+		if (frame.type != 'STATICCALL') {
+			call.value = '0x' + frame.value.toString(16);
+		}
+		this.callstack.push(call);
+		this.entered.push(this.callstack.length);
+
+		// The gas allowance is known, don't retrieve it from the first step
+		this.descended = false;
+	},
+
+	// exit is invoked when a call made by a precompile returns.
+	exit: function(result) {
+		// If the call failed in one of its opcodes, fault already flattened it
+		if (this.entered.pop() != this.callstack.length) {
+			return;
+		}
+		var call = this.callstack.pop();
+		call.gas = '0x' + bigInt(call.gas).toString(16);
+		call.gasUsed = '0x' + bigInt(result.gasUsed).toString(16);
+		if (result.error !== undefined) {
+			if (call.error === undefined) {
+				call.error = result.error;
+			}
+		} else {
+			call.output = toHex(result.output);
+		}
+		var left = this.callstack.length;
+		if (this.callstack[left-1].calls === undefined) {
+			this.callstack[left-1].calls = [];
+		}
+		this.callstack[left-1].calls.push(call);
+	},
+
 	// fault is invoked when the actual execution of an opcode fails.
 	fault: function(log, db) {
 		// If the topmost call already reverted, don't handle the additional fault again
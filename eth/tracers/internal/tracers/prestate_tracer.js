@@ -48,8 +48,13 @@
 	// the final result of the tracing.
 	result: function(ctx, db) {
 		// At this point, we need to deduct the 'value' from the
-		// outer transaction, and move it back to the origin
+		// outer transaction, and move it back to the origin. The recipient
+		// was not looked up yet if it has no code, e.g. a precompile.
+		if (this.prestate === null) {
+			this.prestate = {};
+		}
 		this.lookupAccount(ctx.from, db);
+		this.lookupAccount(ctx.to, db);
 
 		var fromBal = bigInt(this.prestate[toHex(ctx.from)].balance.slice(2), 16);
 		var toBal   = bigInt(this.prestate[toHex(ctx.to)].balance.slice(2), 16);
@@ -103,6 +108,18 @@
 		}
 	},
 
+	// stateAccess is invoked whenever a precompile accesses state, which does
+	// not show up as an opcode executed.
+	stateAccess: function(access, db) {
+		if (this.prestate === null) {
+			this.prestate = {};
+		}
+		this.lookupAccount(access.address, db);
+		if (access.key !== undefined) {
+			this.lookupStorage(access.address, access.key, db);
+		}
+	},
+
 	// fault is invoked when the actual execution of an opcode fails.
 	fault: function(log, db) {}
 }
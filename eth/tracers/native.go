@@ -0,0 +1,69 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ava-labs/go-ethereum/core/vm"
+)
+
+// NativeTracer is a transaction tracer implemented in Go. Like the JavaScript
+// tracers, native tracers are retrievable by name, e.g. by debug_traceTransaction.
+type NativeTracer interface {
+	vm.Tracer
+
+	// GetResult returns the JSON encoded result of the trace.
+	GetResult() (json.RawMessage, error)
+
+	// Stop aborts the trace with the given error, e.g. on a timeout. It may be
+	// called concurrently with the tracer's other methods.
+	Stop(err error)
+}
+
+// NativeTracerFactory creates a new instance of a native tracer for tracing a
+// single transaction.
+type NativeTracerFactory func() NativeTracer
+
+// natives contains all the registered native tracers by name.
+var natives = make(map[string]NativeTracerFactory)
+
+// RegisterNativeTracer makes a native tracer available under the given name,
+// allowing chains built on top of go-ethereum to extend the tracer catalogue.
+// It is not safe for concurrent use and is meant to be called from package
+// init functions. It panics if the name is already taken by a native or
+// JavaScript tracer.
+func RegisterNativeTracer(name string, factory NativeTracerFactory) {
+	if _, ok := all[name]; ok {
+		panic(fmt.Sprintf("tracers: %q is a built-in tracer", name))
+	}
+	if _, ok := natives[name]; ok {
+		panic(fmt.Sprintf("tracers: native tracer %q already registered", name))
+	}
+	natives[name] = factory
+}
+
+// NewNative creates an instance of the native tracer registered under the given
+// name, reporting whether there is one.
+func NewNative(name string) (NativeTracer, bool) {
+	factory, ok := natives[name]
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
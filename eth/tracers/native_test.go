@@ -0,0 +1,65 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/core/vm"
+)
+
+// opCounter is a native test tracer counting the executed opcodes.
+type opCounter struct {
+	vm.HookLogger
+	ops int
+}
+
+func (c *opCounter) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	c.ops++
+	return nil
+}
+
+func (c *opCounter) GetResult() (json.RawMessage, error) { return json.Marshal(c.ops) }
+func (c *opCounter) Stop(err error)                      {}
+
+func TestRegisterNativeTracer(t *testing.T) {
+	RegisterNativeTracer("opCounter", func() NativeTracer { return new(opCounter) })
+	defer delete(natives, "opCounter")
+
+	tracer, ok := NewNative("opCounter")
+	if !ok {
+		t.Fatalf("registered tracer not found")
+	}
+	if other, _ := NewNative("opCounter"); other == tracer {
+		t.Errorf("tracer instance reused")
+	}
+	if _, ok := NewNative("callTracer"); ok {
+		t.Errorf("JavaScript tracer reported as native")
+	}
+	// Registering over a built-in or native tracer must fail
+	for _, name := range []string{"callTracer", "opCounter"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected duplicate registration to panic", name)
+				}
+			}()
+			RegisterNativeTracer(name, func() NativeTracer { return new(opCounter) })
+		}()
+	}
+}
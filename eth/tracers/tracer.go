@@ -27,9 +27,11 @@ import (
 
 	"github.com/ava-labs/go-ethereum/common"
 	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/core/types"
 	"github.com/ava-labs/go-ethereum/core/vm"
 	"github.com/ava-labs/go-ethereum/crypto"
 	"github.com/ava-labs/go-ethereum/log"
+	"github.com/ava-labs/go-ethereum/params"
 	duktape "gopkg.in/olebedev/go-duktape.v3"
 )
 
@@ -300,13 +302,18 @@ type Tracer struct {
 	ctx map[string]interface{} // Transaction context gathered throughout execution
 	err error                  // Error, if one has occurred
 
+	traceFrames bool // Whether the tracer handles calls made by precompiles
+	traceState  bool // Whether the tracer handles state accessed by precompiles
+
 	interrupt uint32 // Atomic flag to signal execution interruption
 	reason    error  // Textual reason for the interruption
 }
 
 // New instantiates a new tracer instance. code specifies a Javascript snippet,
 // which must evaluate to an expression returning an object with 'step', 'fault'
-// and 'result' functions.
+// and 'result' functions, and optionally 'enter' and 'exit' functions to trace
+// the calls made by precompiles and a 'stateAccess' function to trace the state
+// they access.
 func New(code string) (*Tracer, error) {
 	// Resolve any tracers by name and assemble the tracer object
 	if tracer, ok := tracer(code); ok {
@@ -363,7 +370,7 @@ func New(code string) (*Tracer, error) {
 		nonce := uint64(ctx.GetInt(-1))
 		ctx.Pop2()
 
-		contract := crypto.CreateAddress(from, nonce)
+		contract := types.CreateAddress(from, nonce)
 		copy(makeSlice(ctx.PushFixedBuffer(20), 20), contract[:])
 		return 1
 	})
@@ -385,7 +392,7 @@ func New(code string) (*Tracer, error) {
 		}
 		codeHash := crypto.Keccak256(code)
 		ctx.Pop3()
-		contract := crypto.CreateAddress2(from, salt, codeHash)
+		contract := types.CreateAddress2(from, salt, codeHash)
 		copy(makeSlice(ctx.PushFixedBuffer(20), 20), contract[:])
 		return 1
 	})
@@ -433,6 +440,18 @@ func New(code string) (*Tracer, error) {
 	}
 	tracer.vm.Pop()
 
+	// The enter and exit functions for calls made by precompiles are optional
+	tracer.traceFrames = tracer.vm.GetPropString(tracer.tracerObject, "enter")
+	tracer.vm.Pop()
+	if tracer.vm.GetPropString(tracer.tracerObject, "exit") != tracer.traceFrames {
+		return nil, fmt.Errorf("Trace object must expose either both or none of enter() and exit()")
+	}
+	tracer.vm.Pop()
+
+	// The stateAccess function for state accessed by precompiles is optional
+	tracer.traceState = tracer.vm.GetPropString(tracer.tracerObject, "stateAccess")
+	tracer.vm.Pop()
+
 	// Tracer is valid, inject the big int library to access large numbers
 	tracer.vm.EvalString(bigIntegerJS)
 	tracer.vm.PutGlobalString("bigInt")
@@ -537,6 +556,9 @@ func (jst *Tracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost
 		// Initialize the context if it wasn't done yet
 		if !jst.inited {
 			jst.ctx["block"] = env.BlockNumber.Uint64()
+			if jst.ctx["rules"], jst.err = encodeRules(env.Rules()); jst.err != nil {
+				return nil
+			}
 			jst.inited = true
 		}
 		// If tracing was interrupted, set the error and stop
@@ -585,6 +607,70 @@ func (jst *Tracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost
 	return nil
 }
 
+// CaptureEnter implements the vm.PrecompileCallTracer interface to trace a call
+// made by a precompile, if the tracer handles them.
+func (jst *Tracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	if jst.err == nil && jst.traceFrames {
+		jst.pushValues(map[string]interface{}{
+			"type":  typ.String(),
+			"from":  from,
+			"to":    to,
+			"input": input,
+			"gas":   gas,
+			"value": value,
+		})
+		jst.vm.PutPropString(jst.stateObject, "frame")
+
+		if _, err := jst.call("enter", "frame"); err != nil {
+			jst.err = wrapError("enter", err)
+		}
+	}
+	return nil
+}
+
+// CaptureExit implements the vm.PrecompileCallTracer interface to trace the end
+// of a call made by a precompile, if the tracer handles them.
+func (jst *Tracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	if jst.err == nil && jst.traceFrames {
+		values := map[string]interface{}{
+			"output":  output,
+			"gasUsed": gasUsed,
+		}
+		if err != nil {
+			values["error"] = err.Error()
+		}
+		jst.pushValues(values)
+		jst.vm.PutPropString(jst.stateObject, "frameResult")
+
+		if _, err := jst.call("exit", "frameResult"); err != nil {
+			jst.err = wrapError("exit", err)
+		}
+	}
+	return nil
+}
+
+// CaptureStateAccess implements the vm.PrecompileStateTracer interface to trace
+// the state accessed by a precompile, if the tracer handles it.
+func (jst *Tracer) CaptureStateAccess(env *vm.EVM, addr common.Address, key *common.Hash) error {
+	if jst.err == nil && jst.traceState {
+		jst.dbWrapper.db = env.StateDB
+
+		values := map[string]interface{}{
+			"address": addr,
+		}
+		if key != nil {
+			values["key"] = key.Bytes()
+		}
+		jst.pushValues(values)
+		jst.vm.PutPropString(jst.stateObject, "access")
+
+		if _, err := jst.call("stateAccess", "access", "db"); err != nil {
+			jst.err = wrapError("stateAccess", err)
+		}
+	}
+	return nil
+}
+
 // CaptureEnd is called after the call finishes to finalize the tracing.
 func (jst *Tracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
 	jst.ctx["output"] = output
@@ -600,9 +686,27 @@ func (jst *Tracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, er
 // GetResult calls the Javascript 'result' function and returns its value, or any accumulated error
 func (jst *Tracer) GetResult() (json.RawMessage, error) {
 	// Transform the context into a JavaScript object and inject into the state
+	jst.pushValues(jst.ctx)
+	jst.vm.PutPropString(jst.stateObject, "ctx")
+
+	// Finalize the trace and return the results
+	result, err := jst.call("result", "ctx", "db")
+	if err != nil {
+		jst.err = wrapError("result", err)
+	}
+	// Clean up the JavaScript environment
+	jst.vm.DestroyHeap()
+	jst.vm.Destroy()
+
+	return result, jst.err
+}
+
+// pushValues pushes a JavaScript object with the given properties onto the
+// stack.
+func (jst *Tracer) pushValues(values map[string]interface{}) {
 	obj := jst.vm.PushObject()
 
-	for key, val := range jst.ctx {
+	for key, val := range values {
 		switch val := val.(type) {
 		case uint64:
 			jst.vm.PushUint(uint(val))
@@ -621,21 +725,49 @@ func (jst *Tracer) GetResult() (json.RawMessage, error) {
 		case *big.Int:
 			pushBigInt(val, jst.vm)
 
+		case json.RawMessage:
+			jst.vm.PushString(string(val))
+			jst.vm.JsonDecode(-1)
+
 		default:
 			panic(fmt.Sprintf("unsupported type: %T", val))
 		}
 		jst.vm.PutPropString(obj, key)
 	}
-	jst.vm.PutPropString(jst.stateObject, "ctx")
+}
 
-	// Finalize the trace and return the results
-	result, err := jst.call("result", "ctx", "db")
-	if err != nil {
-		jst.err = wrapError("result", err)
-	}
-	// Clean up the JavaScript environment
-	jst.vm.DestroyHeap()
-	jst.vm.Destroy()
+// tracerRules is the chain rules representation exposed to tracers as ctx.rules,
+// allowing them to decode chain specific precompile inputs. The configuration
+// payloads of the precompile upgrades are passed through verbatim.
+type tracerRules struct {
+	ChainID            uint64                                       `json:"chainId"`
+	IsHomestead        bool                                         `json:"isHomestead"`
+	IsEIP150           bool                                         `json:"isEIP150"`
+	IsEIP155           bool                                         `json:"isEIP155"`
+	IsEIP158           bool                                         `json:"isEIP158"`
+	IsByzantium        bool                                         `json:"isByzantium"`
+	IsConstantinople   bool                                         `json:"isConstantinople"`
+	IsPetersburg       bool                                         `json:"isPetersburg"`
+	IsIstanbul         bool                                         `json:"isIstanbul"`
+	PrecompileUpgrades map[common.Address]*params.PrecompileUpgrade `json:"precompileUpgrades"`
+}
 
-	return result, jst.err
+// encodeRules returns the JSON encoding of the given rules as exposed to tracers.
+func encodeRules(rules params.Rules) (json.RawMessage, error) {
+	upgrades := rules.PrecompileUpgrades
+	if upgrades == nil {
+		upgrades = make(map[common.Address]*params.PrecompileUpgrade)
+	}
+	return json.Marshal(&tracerRules{
+		ChainID:            rules.ChainID.Uint64(),
+		IsHomestead:        rules.IsHomestead,
+		IsEIP150:           rules.IsEIP150,
+		IsEIP155:           rules.IsEIP155,
+		IsEIP158:           rules.IsEIP158,
+		IsByzantium:        rules.IsByzantium,
+		IsConstantinople:   rules.IsConstantinople,
+		IsPetersburg:       rules.IsPetersburg,
+		IsIstanbul:         rules.IsIstanbul,
+		PrecompileUpgrades: upgrades,
+	})
 }
@@ -25,6 +25,8 @@ import (
 	"time"
 
 	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
 	"github.com/ava-labs/go-ethereum/core/state"
 	"github.com/ava-labs/go-ethereum/core/vm"
 	"github.com/ava-labs/go-ethereum/params"
@@ -145,3 +147,128 @@ func TestHaltBetweenSteps(t *testing.T) {
 		t.Errorf("Expected timeout error, got %v", err)
 	}
 }
+
+func TestRules(t *testing.T) {
+	config := *params.TestChainConfig
+	config.PrecompileUpgrades = []params.PrecompileUpgrade{{
+		Address: common.HexToAddress("0x0100000000000000000000000000000000000000"),
+		Config:  json.RawMessage(`{"admins":["0xc0ffee01"]}`),
+	}}
+	tracer, err := New(`{step: function() {}, fault: function() {}, result: function(ctx) {
+		var upgrade = ctx.rules.precompileUpgrades["0x0100000000000000000000000000000000000000"];
+		return [ctx.rules.chainId, ctx.rules.isByzantium, upgrade.config.admins[0]];
+	}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := vm.NewEVM(vm.Context{BlockNumber: big.NewInt(1)}, &dummyStatedb{}, &config, vm.Config{Debug: true, Tracer: tracer})
+
+	contract := vm.NewContract(account{}, account{}, big.NewInt(0), 10000)
+	contract.Code = []byte{byte(vm.STOP)}
+	if _, err := env.Interpreter().Run(contract, []byte{}, false); err != nil {
+		t.Fatal(err)
+	}
+	ret, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[1,true,"0xc0ffee01"]`; string(ret) != want {
+		t.Errorf("rules mismatch: have %s, want %s", ret, want)
+	}
+}
+
+func TestCallTracerPrecompileCalls(t *testing.T) {
+	tracer, err := New("callTracer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		caller     = common.HexToAddress("0xca11e7")
+		precompile = common.HexToAddress("0x0100000000000000000000000000000000000000")
+		target     = common.HexToAddress("0xc0ffee")
+	)
+	tracer.CaptureStart(caller, precompile, false, nil, 100000, new(big.Int))
+	tracer.CaptureEnter(vm.CALL, precompile, target, []byte{0x01}, 50000, big.NewInt(7))
+	tracer.CaptureExit([]byte{0x02}, 1200, nil)
+	tracer.CaptureEnter(vm.STATICCALL, precompile, target, nil, 40000, new(big.Int))
+	tracer.CaptureExit(nil, 40000, errors.New("out of gas"))
+	tracer.CaptureEnd(nil, 42000, 0, nil)
+
+	ret, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		From  common.Address
+		Calls []struct {
+			Type    string
+			From    common.Address
+			To      common.Address
+			Value   *hexutil.Big
+			Gas     hexutil.Uint64
+			GasUsed hexutil.Uint64
+			Output  hexutil.Bytes
+			Error   string
+		}
+	}
+	if err := json.Unmarshal(ret, &result); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if len(result.Calls) != 2 {
+		t.Fatalf("precompile call count mismatch: have %d, want 2: %s", len(result.Calls), ret)
+	}
+	call := result.Calls[0]
+	if call.Type != "CALL" || call.From != precompile || call.To != target || call.Value.ToInt().Int64() != 7 ||
+		call.Gas != 50000 || call.GasUsed != 1200 || !bytes.Equal(call.Output, []byte{0x02}) {
+		t.Errorf("precompile call mismatch: %s", ret)
+	}
+	call = result.Calls[1]
+	if call.Type != "STATICCALL" || call.Value != nil || call.GasUsed != 40000 || call.Error != "out of gas" {
+		t.Errorf("failed precompile call mismatch: %s", ret)
+	}
+}
+
+func TestPrestateTracerPrecompileState(t *testing.T) {
+	tracer, err := New("prestateTracer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		caller     = common.HexToAddress("0xca11e7")
+		precompile = common.HexToAddress("0x0100000000000000000000000000000000000000")
+		target     = common.HexToAddress("0xc0ffee01")
+		slot       = common.Hash{1}
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	statedb.SetNonce(caller, 1)
+	statedb.SetBalance(target, big.NewInt(1000))
+	statedb.SetState(precompile, slot, common.Hash{0x2a})
+	env := vm.NewEVM(vm.Context{BlockNumber: big.NewInt(1)}, statedb, params.TestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+
+	tracer.CaptureStart(caller, precompile, false, nil, 100000, new(big.Int))
+	tracer.CaptureStateAccess(env, target, nil)
+	tracer.CaptureStateAccess(env, precompile, &slot)
+	statedb.SetState(precompile, slot, common.Hash{0x2b})
+	tracer.CaptureEnd(nil, 21000, 0, nil)
+
+	ret, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prestate map[common.Address]struct {
+		Balance string
+		Storage map[common.Hash]common.Hash
+	}
+	if err := json.Unmarshal(ret, &prestate); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if balance := prestate[target].Balance; balance != "0x3e8" {
+		t.Errorf("accessed account balance mismatch: have %s, want 0x3e8", balance)
+	}
+	if value := prestate[precompile].Storage[slot]; value != (common.Hash{0x2a}) {
+		t.Errorf("accessed storage mismatch: have %x, want %x", value, common.Hash{0x2a})
+	}
+	if _, ok := prestate[caller]; !ok {
+		t.Errorf("sender missing from prestate: %s", ret)
+	}
+}
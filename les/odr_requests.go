@@ -114,7 +114,7 @@ func (r *BlockRequest) Validate(db ethdb.Database, msg *Msg) error {
 	if header == nil {
 		return errHeaderUnavailable
 	}
-	if header.TxHash != types.DeriveSha(types.Transactions(body.Transactions)) {
+	if header.TxHash != types.DeriveTxsSha(header.Number, types.Transactions(body.Transactions)) {
 		return errTxHashMismatch
 	}
 	if header.UncleHash != types.CalcUncleHash(body.Uncles) {
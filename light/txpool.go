@@ -28,6 +28,7 @@ import (
 	"github.com/ava-labs/go-ethereum/core/rawdb"
 	"github.com/ava-labs/go-ethereum/core/state"
 	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/core/vm"
 	"github.com/ava-labs/go-ethereum/ethdb"
 	"github.com/ava-labs/go-ethereum/event"
 	"github.com/ava-labs/go-ethereum/log"
@@ -68,7 +69,7 @@ type TxPool struct {
 	mined        map[common.Hash][]*types.Transaction // mined transactions by block hash
 	clearIdx     uint64                               // earliest block nr that can contain mined tx info
 
-	istanbul bool // Fork indicator whether we are in the istanbul stage.
+	rules params.Rules // Fork indicators of the next pending block
 }
 
 // TxRelayBackend provides an interface to the mechanism that forwards transacions
@@ -313,7 +314,7 @@ func (pool *TxPool) setNewHead(head *types.Header) {
 
 	// Update fork indicator by next pending block number
 	next := new(big.Int).Add(head.Number, big.NewInt(1))
-	pool.istanbul = pool.config.IsIstanbul(next)
+	pool.rules = pool.config.Rules(next)
 }
 
 // Stop stops the light transaction pool
@@ -381,13 +382,29 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	}
 
 	// Should supply enough intrinsic gas
-	gas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, true, pool.istanbul)
+	gas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, true, pool.rules.IsIstanbul)
 	if err != nil {
 		return err
 	}
+	msg, err := tx.AsMessage(pool.signer)
+	if err != nil {
+		return core.ErrInvalidSender
+	}
+	if msg.IsSystem() {
+		return core.ErrSystemTx
+	}
+	if gas, err = core.AdjustIntrinsicGas(msg, pool.rules, vm.NewStateReader(currentState), gas); err != nil {
+		return err
+	}
 	if tx.Gas() < gas {
 		return core.ErrIntrinsicGas
 	}
+	if gas, err = core.FloorGas(msg, pool.rules); err != nil {
+		return err
+	}
+	if tx.Gas() < gas {
+		return core.ErrFloorDataGas
+	}
 	return currentState.Error()
 }
 
@@ -27,7 +27,6 @@ import (
 	mapset "github.com/deckarep/golang-set"
 	"github.com/ava-labs/go-ethereum/common"
 	"github.com/ava-labs/go-ethereum/consensus"
-	"github.com/ava-labs/go-ethereum/consensus/misc"
 	"github.com/ava-labs/go-ethereum/core"
 	"github.com/ava-labs/go-ethereum/core/state"
 	"github.com/ava-labs/go-ethereum/core/types"
@@ -76,6 +75,10 @@ const (
 	staleThreshold = 7
 )
 
+// errGasDimensionsInvalid is returned by commitTransaction if the transaction
+// would render the gas dimensions used by the block invalid.
+var errGasDimensionsInvalid = errors.New("invalid gas dimensions")
+
 // environment is the worker's current environment and holds all of the current state information.
 type environment struct {
 	signer types.Signer
@@ -704,11 +707,28 @@ func (w *worker) updateSnapshot() {
 func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Address) ([]*types.Log, error) {
 	snap := w.current.state.Snapshot()
 
+	// Applied transactions can't be reverted, so keep the environment to drop
+	// the transaction again if it renders the used gas dimensions invalid
+	var (
+		prevState   *state.StateDB
+		prevPool    *core.GasPool
+		prevGasUsed = w.current.header.GasUsed
+	)
+	if _, tracked := w.current.gasPool.Dimension(0); tracked {
+		prevState, prevPool = w.current.state.Copy(), w.current.gasPool.Copy()
+	}
 	receipt, _, err := core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, w.current.gasPool, w.current.state, w.current.header, tx, &w.current.header.GasUsed, *w.chain.GetVMConfig())
 	if err != nil {
 		w.current.state.RevertToSnapshot(snap)
 		return nil, err
 	}
+	if prevState != nil {
+		if err := core.ValidateGasDimensions(w.chainConfig, w.current.header, w.current.gasPool); err != nil {
+			log.Trace("Transaction invalidates gas dimensions", "hash", tx.Hash(), "err", err)
+			w.current.state, w.current.gasPool, w.current.header.GasUsed = prevState, prevPool, prevGasUsed
+			return nil, errGasDimensionsInvalid
+		}
+	}
 	w.current.txs = append(w.current.txs, tx)
 	w.current.receipts = append(w.current.receipts, receipt)
 
@@ -722,11 +742,12 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 	}
 
 	if w.current.gasPool == nil {
-		w.current.gasPool = new(core.GasPool).AddGas(w.current.header.GasLimit)
+		w.current.gasPool = core.NewBlockGasPool(w.chainConfig, w.current.header)
 	}
 
 	var coalescedLogs []*types.Log
 
+loop:
 	for {
 		// In the following three cases, we will interrupt the execution of the transaction.
 		// (1) new head block event arrival, the interrupt signal is 1
@@ -776,11 +797,16 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 
 		logs, err := w.commitTransaction(tx, coinbase)
 		switch err {
-		case core.ErrGasLimitReached:
+		case core.ErrGasLimitReached, core.ErrGasDimensionLimitReached:
 			// Pop the current out-of-gas transaction without shifting in the next from the account
-			log.Trace("Gas limit exceeded for current block", "sender", from)
+			log.Trace("Gas limit exceeded for current block", "sender", from, "err", err)
 			txs.Pop()
 
+		case errGasDimensionsInvalid:
+			// The block can't take any further transactions
+			log.Trace("Gas dimensions exhausted for current block", "sender", from)
+			break loop
+
 		case core.ErrNonceTooLow:
 			// New head notification data race between the transaction pool and miner, shift
 			log.Trace("Skipping transaction with low nonce", "sender", from, "nonce", tx.Nonce())
@@ -887,9 +913,14 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	}
 	// Create the current work task and check any fork transitions needed
 	env := w.current
-	if w.chainConfig.DAOForkSupport && w.chainConfig.DAOForkBlock != nil && w.chainConfig.DAOForkBlock.Cmp(header.Number) == 0 {
-		misc.ApplyDAOHardFork(env.state)
+	// Implicit messages see the same author as the transactions, which differs
+	// from the header's coinbase with e.g. clique
+	coinbase := w.coinbase
+	if err := core.ApplyBlockPreamble(w.chainConfig, w.chain, &coinbase, parent.Header(), header, env.state, *w.chain.GetVMConfig()); err != nil {
+		log.Error("Failed to start block", "err", err)
+		return
 	}
+
 	// Accumulate the uncles for the current block
 	uncles := make([]*types.Header, 0, 2)
 	commitUncles := func(blocks map[common.Hash]*types.Block) {
@@ -942,13 +973,13 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	}
 	if len(localTxs) > 0 {
 		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, localTxs)
-		if w.commitTransactions(txs, w.coinbase, interrupt) {
+		if w.commitTransactions(txs, coinbase, interrupt) {
 			return
 		}
 	}
 	if len(remoteTxs) > 0 {
 		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, remoteTxs)
-		if w.commitTransactions(txs, w.coinbase, interrupt) {
+		if w.commitTransactions(txs, coinbase, interrupt) {
 			return
 		}
 	}
@@ -964,8 +995,21 @@ func (w *worker) commit(uncles []*types.Header, interval func(), update bool, st
 		receipts[i] = new(types.Receipt)
 		*receipts[i] = *l
 	}
+	// Importers reject blocks with invalid gas dimensions, so don't seal them
+	gp := w.current.gasPool
+	if gp == nil {
+		gp = core.NewBlockGasPool(w.chainConfig, w.current.header)
+	}
+	if err := core.ValidateGasDimensions(w.chainConfig, w.current.header, gp); err != nil {
+		return err
+	}
 	s := w.current.state.Copy()
-	block, err := w.engine.FinalizeAndAssemble(w.chain, w.current.header, s, w.current.txs, uncles, w.current.receipts)
+	coinbase := w.coinbase
+	core.ApplyImplicitBlockEnd(w.chainConfig, w.chain, &coinbase, w.current.header, s, *w.chain.GetVMConfig())
+	if err := core.ApplyBlockEnd(w.chainConfig, w.current.header, s, receipts); err != nil {
+		return err
+	}
+	block, err := w.engine.FinalizeAndAssemble(w.chain, w.current.header, s, w.current.txs, uncles, receipts)
 	if err != nil {
 		return err
 	}
@@ -979,7 +1023,7 @@ func (w *worker) commit(uncles []*types.Header, interval func(), update bool, st
 
 			feesWei := new(big.Int)
 			for i, tx := range block.Transactions() {
-				feesWei.Add(feesWei, new(big.Int).Mul(new(big.Int).SetUint64(receipts[i].GasUsed), tx.GasPrice()))
+				feesWei.Add(feesWei, new(big.Int).Mul(new(big.Int).SetUint64(receipts[i].GasUsed), tx.EffectiveGasPrice()))
 			}
 			feesEth := new(big.Float).Quo(new(big.Float).SetInt(feesWei), new(big.Float).SetInt(big.NewInt(params.Ether)))
 
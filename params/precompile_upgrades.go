@@ -0,0 +1,132 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/go-ethereum/common"
+)
+
+// PrecompileUpgrade schedules the activation, reconfiguration or deactivation
+// of the chain specific precompile at Address for all blocks with a timestamp
+// of at least Timestamp. The config is opaque to this package; it is handed to
+// the precompile module registered for the address in core/vm.
+type PrecompileUpgrade struct {
+	Address   common.Address  `json:"address"`
+	Timestamp uint64          `json:"timestamp"`
+	Disable   bool            `json:"disable,omitempty"`
+	Config    json.RawMessage `json:"config,omitempty"`
+}
+
+// CheckPrecompileUpgrades verifies that the scheduled precompile upgrades are
+// well formed: ordered by timestamp, at most one upgrade per address and block
+// timestamp, and only disabling precompiles that are enabled.
+func (c *ChainConfig) CheckPrecompileUpgrades() error {
+	var (
+		last    uint64
+		enabled = make(map[common.Address]bool)
+		times   = make(map[common.Address]uint64)
+	)
+	for i, upgrade := range c.PrecompileUpgrades {
+		if upgrade.Timestamp < last {
+			return fmt.Errorf("precompile upgrade %d at %d scheduled before preceding one at %d", i, upgrade.Timestamp, last)
+		}
+		last = upgrade.Timestamp
+
+		if time, ok := times[upgrade.Address]; ok && time == upgrade.Timestamp {
+			return fmt.Errorf("precompile upgrade %d: duplicate upgrade of %x at %d", i, upgrade.Address, upgrade.Timestamp)
+		}
+		times[upgrade.Address] = upgrade.Timestamp
+
+		if upgrade.Disable {
+			if !enabled[upgrade.Address] {
+				return fmt.Errorf("precompile upgrade %d: disabling %x which isn't enabled", i, upgrade.Address)
+			}
+			if len(upgrade.Config) != 0 {
+				return fmt.Errorf("precompile upgrade %d: disabling %x with config", i, upgrade.Address)
+			}
+		}
+		enabled[upgrade.Address] = !upgrade.Disable
+	}
+	return nil
+}
+
+// RulesAt returns the rules in effect for the block with the given number and
+// timestamp, including the precompile upgrades scheduled up to that block.
+func (c *ChainConfig) RulesAt(num *big.Int, time uint64) Rules {
+	rules := c.Rules(num)
+	for i := range c.PrecompileUpgrades {
+		upgrade := &c.PrecompileUpgrades[i]
+		if upgrade.Timestamp > time {
+			break
+		}
+		if rules.PrecompileUpgrades == nil {
+			rules.PrecompileUpgrades = make(map[common.Address]*PrecompileUpgrade)
+		}
+		rules.PrecompileUpgrades[upgrade.Address] = upgrade
+	}
+	return rules
+}
+
+// activePrecompileUpgrades returns the precompile upgrades activated up to the
+// given block timestamp, in order.
+func (c *ChainConfig) activePrecompileUpgrades(time uint64) []PrecompileUpgrade {
+	var upgrades []PrecompileUpgrade
+	for _, upgrade := range c.PrecompileUpgrades {
+		if upgrade.Timestamp > time {
+			break
+		}
+		upgrades = append(upgrades, upgrade)
+	}
+	return upgrades
+}
+
+// checkPrecompileUpgradesCompatible checks whether the precompile upgrades
+// activated up to the given head timestamp are scheduled identically by the
+// new config.
+func (c *ChainConfig) checkPrecompileUpgradesCompatible(newcfg *ChainConfig, time uint64) *ConfigCompatError {
+	var (
+		stored = c.activePrecompileUpgrades(time)
+		next   = newcfg.activePrecompileUpgrades(time)
+	)
+	for i := 0; i < len(stored) || i < len(next); i++ {
+		var storedTime, newTime *uint64
+		if i < len(stored) {
+			storedTime = &stored[i].Timestamp
+		}
+		if i < len(next) {
+			newTime = &next[i].Timestamp
+		}
+		if storedTime != nil && newTime != nil && precompileUpgradesEqual(stored[i], next[i]) {
+			continue
+		}
+		return newTimestampCompatError("precompile upgrade", storedTime, newTime)
+	}
+	return nil
+}
+
+// precompileUpgradesEqual reports whether two precompile upgrades are
+// identical, in their JSON encoding.
+func precompileUpgradesEqual(a, b PrecompileUpgrade) bool {
+	encA, errA := json.Marshal(a)
+	encB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encA, encB)
+}
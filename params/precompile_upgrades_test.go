@@ -0,0 +1,133 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+)
+
+func TestCheckPrecompileUpgrades(t *testing.T) {
+	var (
+		a = common.HexToAddress("0x0200000000000000000000000000000000000000")
+		b = common.HexToAddress("0x0200000000000000000000000000000000000001")
+	)
+	tests := []struct {
+		upgrades []PrecompileUpgrade
+		valid    bool
+	}{
+		{nil, true},
+		{[]PrecompileUpgrade{{Address: a, Timestamp: 10}, {Address: b, Timestamp: 10}, {Address: a, Timestamp: 20, Disable: true}}, true},
+		{[]PrecompileUpgrade{{Address: a, Timestamp: 10}, {Address: a, Timestamp: 20, Config: json.RawMessage(`{}`)}}, true},
+		{[]PrecompileUpgrade{{Address: a, Timestamp: 20}, {Address: b, Timestamp: 10}}, false},
+		{[]PrecompileUpgrade{{Address: a, Timestamp: 10}, {Address: a, Timestamp: 10}}, false},
+		{[]PrecompileUpgrade{{Address: a, Timestamp: 10, Disable: true}}, false},
+		{[]PrecompileUpgrade{{Address: a, Timestamp: 10}, {Address: a, Timestamp: 20, Disable: true}, {Address: a, Timestamp: 30, Disable: true}}, false},
+		{[]PrecompileUpgrade{{Address: a, Timestamp: 10}, {Address: a, Timestamp: 20, Disable: true, Config: json.RawMessage(`{}`)}}, false},
+	}
+	for i, tt := range tests {
+		config := &ChainConfig{ChainID: big.NewInt(1), PrecompileUpgrades: tt.upgrades}
+		if err := config.CheckPrecompileUpgrades(); (err == nil) != tt.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want valid %v", i, err, tt.valid)
+		}
+	}
+}
+
+func TestRulesAtPrecompileUpgrades(t *testing.T) {
+	addr := common.HexToAddress("0x0200000000000000000000000000000000000000")
+	config := &ChainConfig{ChainID: big.NewInt(1), PrecompileUpgrades: []PrecompileUpgrade{
+		{Address: addr, Timestamp: 10},
+		{Address: addr, Timestamp: 20, Disable: true},
+	}}
+	tests := []struct {
+		time    uint64
+		upgrade *PrecompileUpgrade
+	}{
+		{9, nil},
+		{10, &config.PrecompileUpgrades[0]},
+		{19, &config.PrecompileUpgrades[0]},
+		{20, &config.PrecompileUpgrades[1]},
+	}
+	for _, tt := range tests {
+		if have := config.RulesAt(new(big.Int), tt.time).PrecompileUpgrades[addr]; have != tt.upgrade {
+			t.Errorf("time %d: upgrade mismatch: have %v, want %v", tt.time, have, tt.upgrade)
+		}
+	}
+}
+
+func TestCheckCompatiblePrecompileUpgrades(t *testing.T) {
+	var (
+		addr   = common.HexToAddress("0x0200000000000000000000000000000000000000")
+		stored = &ChainConfig{PrecompileUpgrades: []PrecompileUpgrade{
+			{Address: addr, Timestamp: 10, Config: json.RawMessage(`{"fee":1}`)},
+			{Address: addr, Timestamp: 20, Disable: true},
+		}}
+		five    = uint64(5)
+		ten     = uint64(10)
+		twenty  = uint64(20)
+		fifteen = uint64(15)
+	)
+	tests := []struct {
+		new     *ChainConfig
+		time    uint64
+		wantErr *ConfigCompatError
+	}{
+		{stored, 100, nil},
+		// Reformatted configs are still identical
+		{&ChainConfig{PrecompileUpgrades: []PrecompileUpgrade{
+			{Address: addr, Timestamp: 10, Config: json.RawMessage(`{ "fee": 1 }`)},
+			{Address: addr, Timestamp: 20, Disable: true},
+		}}, 100, nil},
+		// Upgrades not yet activated may change
+		{&ChainConfig{PrecompileUpgrades: []PrecompileUpgrade{
+			{Address: addr, Timestamp: 10, Config: json.RawMessage(`{"fee":1}`)},
+		}}, 19, nil},
+		{&ChainConfig{PrecompileUpgrades: []PrecompileUpgrade{
+			{Address: addr, Timestamp: 10, Config: json.RawMessage(`{"fee":2}`)},
+			{Address: addr, Timestamp: 20, Disable: true},
+		}}, 20, &ConfigCompatError{
+			What: "precompile upgrade", StoredTime: &ten, NewTime: &ten, RewindToTime: 9,
+		}},
+		{&ChainConfig{PrecompileUpgrades: []PrecompileUpgrade{
+			{Address: addr, Timestamp: 15, Config: json.RawMessage(`{"fee":1}`)},
+		}}, 20, &ConfigCompatError{
+			What: "precompile upgrade", StoredTime: &ten, NewTime: &fifteen, RewindToTime: 9,
+		}},
+		{&ChainConfig{PrecompileUpgrades: []PrecompileUpgrade{
+			{Address: addr, Timestamp: 10, Config: json.RawMessage(`{"fee":1}`)},
+		}}, 20, &ConfigCompatError{
+			What: "precompile upgrade", StoredTime: &twenty, RewindToTime: 19,
+		}},
+		// The lowest conflict is reported along with state upgrades
+		{&ChainConfig{
+			PrecompileUpgrades: []PrecompileUpgrade{{Address: addr, Timestamp: 10, Config: json.RawMessage(`{"fee":1}`)}},
+			StateUpgrades:      []StateUpgrade{{Timestamp: 5}},
+		}, 20, &ConfigCompatError{
+			What: "state upgrade", NewTime: &five, RewindToTime: 4,
+		}},
+	}
+	for i, tt := range tests {
+		err := stored.CheckCompatibleAt(tt.new, 0, tt.time)
+		if !reflect.DeepEqual(err, tt.wantErr) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// chainPreset is a named chain configuration known to this library.
+type chainPreset struct {
+	name   string
+	config *ChainConfig
+}
+
+var (
+	presetsLock sync.RWMutex
+	presetsByID = make(map[uint64]*chainPreset)
+	presetsName = make(map[string]uint64)
+)
+
+func init() {
+	RegisterChainConfig("mainnet", MainnetChainConfig)
+	RegisterChainConfig("ropsten", TestnetChainConfig)
+	RegisterChainConfig("rinkeby", RinkebyChainConfig)
+	RegisterChainConfig("goerli", GoerliChainConfig)
+}
+
+// RegisterChainConfig makes a complete chain configuration retrievable by its
+// chain ID and by name, analogous to the built in MainnetChainConfig et al. It
+// is meant to be called from package init functions by chains built on top of
+// go-ethereum, so that tools and test harnesses can instantiate them.
+//
+// RegisterChainConfig panics if the config has no chain ID, or if either the
+// chain ID or the name is already taken by another preset.
+func RegisterChainConfig(name string, config *ChainConfig) {
+	if config == nil || config.ChainID == nil {
+		panic("params: chain preset without chain ID")
+	}
+	if !config.ChainID.IsUint64() {
+		panic(fmt.Sprintf("params: chain preset %q has out of range chain ID %v", name, config.ChainID))
+	}
+	name = strings.ToLower(name)
+	id := config.ChainID.Uint64()
+
+	presetsLock.Lock()
+	defer presetsLock.Unlock()
+
+	if preset, ok := presetsByID[id]; ok {
+		panic(fmt.Sprintf("params: chain ID %d already registered as %q", id, preset.name))
+	}
+	if _, ok := presetsName[name]; ok {
+		panic(fmt.Sprintf("params: chain preset %q already registered", name))
+	}
+	presetsByID[id] = &chainPreset{name: name, config: config}
+	presetsName[name] = id
+}
+
+// ChainConfigByID returns the registered chain configuration for the given
+// chain ID, if any.
+func ChainConfigByID(id *big.Int) (*ChainConfig, bool) {
+	if id == nil || !id.IsUint64() {
+		return nil, false
+	}
+	presetsLock.RLock()
+	defer presetsLock.RUnlock()
+
+	if preset, ok := presetsByID[id.Uint64()]; ok {
+		return preset.config, true
+	}
+	return nil, false
+}
+
+// ChainConfigByName returns the registered chain configuration with the given
+// (case insensitive) name, if any.
+func ChainConfigByName(name string) (*ChainConfig, bool) {
+	presetsLock.RLock()
+	defer presetsLock.RUnlock()
+
+	if id, ok := presetsName[strings.ToLower(name)]; ok {
+		return presetsByID[id].config, true
+	}
+	return nil, false
+}
+
+// ChainPresetNames returns the names of all registered chain configurations in
+// alphabetical order.
+func ChainPresetNames() []string {
+	presetsLock.RLock()
+	defer presetsLock.RUnlock()
+
+	names := make([]string, 0, len(presetsName))
+	for name := range presetsName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// unregisterChainConfig drops a preset registered by a test.
+func unregisterChainConfig(name string) {
+	presetsLock.Lock()
+	defer presetsLock.Unlock()
+
+	delete(presetsByID, presetsName[name])
+	delete(presetsName, name)
+}
+
+func TestBuiltinChainPresets(t *testing.T) {
+	for id, want := range map[int64]*ChainConfig{
+		1: MainnetChainConfig,
+		3: TestnetChainConfig,
+		4: RinkebyChainConfig,
+		5: GoerliChainConfig,
+	} {
+		if have, ok := ChainConfigByID(big.NewInt(id)); !ok || have != want {
+			t.Errorf("chain %d: preset mismatch: have %v, want %v", id, have, want)
+		}
+	}
+	if have, ok := ChainConfigByName("Goerli"); !ok || have != GoerliChainConfig {
+		t.Errorf("goerli preset mismatch: have %v", have)
+	}
+	if _, ok := ChainConfigByID(big.NewInt(1337)); ok {
+		t.Errorf("unexpected preset for unregistered chain ID")
+	}
+}
+
+func TestRegisterChainConfig(t *testing.T) {
+	config := &ChainConfig{ChainID: big.NewInt(43114), HomesteadBlock: big.NewInt(0)}
+	RegisterChainConfig("avalanche", config)
+	defer unregisterChainConfig("avalanche")
+
+	if have, ok := ChainConfigByID(big.NewInt(43114)); !ok || have != config {
+		t.Fatalf("preset mismatch by ID: have %v, want %v", have, config)
+	}
+	if have, ok := ChainConfigByName("avalanche"); !ok || have != config {
+		t.Fatalf("preset mismatch by name: have %v, want %v", have, config)
+	}
+	want := []string{"avalanche", "goerli", "mainnet", "rinkeby", "ropsten"}
+	if names := ChainPresetNames(); !reflect.DeepEqual(names, want) {
+		t.Fatalf("preset names mismatch: have %v, want %v", names, want)
+	}
+	// Registering a second chain under a taken ID or name must fail
+	for name, config := range map[string]*ChainConfig{
+		"other":     {ChainID: big.NewInt(43114)},
+		"avalanche": {ChainID: big.NewInt(43113)},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected duplicate registration to panic", name)
+				}
+			}()
+			RegisterChainConfig(name, config)
+		}()
+	}
+}
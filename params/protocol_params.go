@@ -93,6 +93,8 @@ const (
 	ExtcodeHashGasConstantinople uint64 = 400  // Cost of EXTCODEHASH (introduced in Constantinople)
 	ExtcodeHashGasEIP1884        uint64 = 700  // Cost of EXTCODEHASH after EIP 1884 (part in Istanbul)
 	SelfdestructGasEIP150        uint64 = 5000 // Cost of SELFDESTRUCT post EIP 150 (Tangerine)
+	TloadGasEIP1153              uint64 = 100  // Cost of TLOAD (EIP-1153), if enabled
+	TstoreGasEIP1153             uint64 = 100  // Cost of TSTORE (EIP-1153), if enabled
 
 	// EXP has a dynamic portion depending on the size of the exponent
 	ExpByteFrontier uint64 = 10 // was set to 10 in Frontier
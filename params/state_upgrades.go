@@ -0,0 +1,103 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/common/math"
+)
+
+// StateUpgrade schedules irregular modifications of accounts, applied once at
+// the start of the first block with a timestamp of at least Timestamp.
+type StateUpgrade struct {
+	Timestamp uint64                                 `json:"timestamp"`
+	Accounts  map[common.Address]StateUpgradeAccount `json:"accounts"`
+}
+
+// StateUpgradeAccount describes the modifications of a single account by a
+// state upgrade. Accounts are created as needed.
+type StateUpgradeAccount struct {
+	Code          hexutil.Bytes               `json:"code,omitempty"`          // Code replacing the account's code, if any
+	Storage       map[common.Hash]common.Hash `json:"storage,omitempty"`       // Storage slots to overwrite
+	BalanceChange *math.HexOrDecimal256       `json:"balanceChange,omitempty"` // Amount to add to the balance
+}
+
+// CheckStateUpgrades verifies that the scheduled state upgrades are well formed:
+// ordered by timestamp, at most one upgrade per timestamp and none at the zero
+// timestamp, as the genesis block isn't processed.
+func (c *ChainConfig) CheckStateUpgrades() error {
+	var last uint64
+	for i, upgrade := range c.StateUpgrades {
+		if upgrade.Timestamp == 0 {
+			return fmt.Errorf("state upgrade %d scheduled at genesis", i)
+		}
+		if upgrade.Timestamp <= last {
+			return fmt.Errorf("state upgrade %d at %d not scheduled after preceding one at %d", i, upgrade.Timestamp, last)
+		}
+		last = upgrade.Timestamp
+	}
+	return nil
+}
+
+// StateUpgradesBetween returns the state upgrades activated by a block with the
+// given timestamp following a block with the given parent timestamp, in the
+// order they must be applied.
+func (c *ChainConfig) StateUpgradesBetween(parentTime, time uint64) []StateUpgrade {
+	var upgrades []StateUpgrade
+	for _, upgrade := range c.StateUpgrades {
+		if upgrade.Timestamp > parentTime && upgrade.Timestamp <= time {
+			upgrades = append(upgrades, upgrade)
+		}
+	}
+	return upgrades
+}
+
+// checkStateUpgradesCompatible checks whether the state upgrades applied up to
+// the given head timestamp are scheduled identically by the new config.
+func (c *ChainConfig) checkStateUpgradesCompatible(newcfg *ChainConfig, time uint64) *ConfigCompatError {
+	var (
+		stored = c.StateUpgradesBetween(0, time)
+		next   = newcfg.StateUpgradesBetween(0, time)
+	)
+	for i := 0; i < len(stored) || i < len(next); i++ {
+		var storedTime, newTime *uint64
+		if i < len(stored) {
+			storedTime = &stored[i].Timestamp
+		}
+		if i < len(next) {
+			newTime = &next[i].Timestamp
+		}
+		if storedTime != nil && newTime != nil && stateUpgradesEqual(stored[i], next[i]) {
+			continue
+		}
+		return newTimestampCompatError("state upgrade", storedTime, newTime)
+	}
+	return nil
+}
+
+// stateUpgradesEqual reports whether two state upgrades are identical, in their
+// JSON encoding.
+func stateUpgradesEqual(a, b StateUpgrade) bool {
+	encA, errA := json.Marshal(a)
+	encB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encA, encB)
+}
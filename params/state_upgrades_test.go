@@ -0,0 +1,105 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/math"
+)
+
+func TestCheckStateUpgrades(t *testing.T) {
+	tests := []struct {
+		upgrades []StateUpgrade
+		valid    bool
+	}{
+		{[]StateUpgrade{{Timestamp: 10}, {Timestamp: 20}}, true},
+		{[]StateUpgrade{{Timestamp: 0}}, false},
+		{[]StateUpgrade{{Timestamp: 20}, {Timestamp: 10}}, false},
+		{[]StateUpgrade{{Timestamp: 10}, {Timestamp: 10}}, false},
+	}
+	for i, tt := range tests {
+		config := &ChainConfig{ChainID: big.NewInt(1), StateUpgrades: tt.upgrades}
+		if err := config.CheckStateUpgrades(); (err == nil) != tt.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want valid %v", i, err, tt.valid)
+		}
+	}
+}
+
+func TestStateUpgradesBetween(t *testing.T) {
+	config := &ChainConfig{ChainID: big.NewInt(1), StateUpgrades: []StateUpgrade{{Timestamp: 10}, {Timestamp: 20}}}
+	tests := []struct {
+		parent, time uint64
+		want         []uint64
+	}{
+		{0, 9, nil},
+		{0, 10, []uint64{10}},
+		{10, 19, nil},
+		{9, 25, []uint64{10, 20}},
+		{20, 30, nil},
+	}
+	for _, tt := range tests {
+		var have []uint64
+		for _, upgrade := range config.StateUpgradesBetween(tt.parent, tt.time) {
+			have = append(have, upgrade.Timestamp)
+		}
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("(%d, %d]: upgrades mismatch: have %v, want %v", tt.parent, tt.time, have, tt.want)
+		}
+	}
+}
+
+func TestCheckCompatibleStateUpgrades(t *testing.T) {
+	var (
+		addr    = common.Address{1}
+		upgrade = func(time uint64, balance int64) StateUpgrade {
+			return StateUpgrade{Timestamp: time, Accounts: map[common.Address]StateUpgradeAccount{
+				addr: {BalanceChange: (*math.HexOrDecimal256)(big.NewInt(balance))},
+			}}
+		}
+		stored  = &ChainConfig{StateUpgrades: []StateUpgrade{upgrade(10, 1), upgrade(20, 1)}}
+		ten     = uint64(10)
+		twenty  = uint64(20)
+		fifteen = uint64(15)
+	)
+	tests := []struct {
+		new     *ChainConfig
+		time    uint64
+		wantErr *ConfigCompatError
+	}{
+		{stored, 100, nil},
+		{&ChainConfig{StateUpgrades: []StateUpgrade{upgrade(10, 1), upgrade(20, 2)}}, 19, nil},
+		{&ChainConfig{StateUpgrades: []StateUpgrade{upgrade(10, 1), upgrade(20, 2)}}, 20, &ConfigCompatError{
+			What: "state upgrade", StoredTime: &twenty, NewTime: &twenty, RewindToTime: 19,
+		}},
+		{&ChainConfig{StateUpgrades: []StateUpgrade{upgrade(15, 1)}}, 20, &ConfigCompatError{
+			What: "state upgrade", StoredTime: &ten, NewTime: &fifteen, RewindToTime: 9,
+		}},
+		{&ChainConfig{StateUpgrades: []StateUpgrade{upgrade(10, 1)}}, 20, &ConfigCompatError{
+			What: "state upgrade", StoredTime: &twenty, RewindToTime: 19,
+		}},
+	}
+	for i, tt := range tests {
+		err := stored.CheckCompatibleAt(tt.new, 0, tt.time)
+		if !reflect.DeepEqual(err, tt.wantErr) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.wantErr)
+		}
+	}
+}
@@ -70,6 +70,30 @@ func SplitList(b []byte) (content, rest []byte, err error) {
 	return content, rest, nil
 }
 
+// ListElements encodes a value that is encoded as a list, e.g. a struct,
+// returning the encodings of the individual list elements.
+func ListElements(val interface{}) ([]RawValue, error) {
+	enc, err := EncodeToBytes(val)
+	if err != nil {
+		return nil, err
+	}
+	var elems []RawValue
+	if err := DecodeBytes(enc, &elems); err != nil {
+		return nil, err
+	}
+	return elems, nil
+}
+
+// DecodeListElements decodes the list made up of the given encoded elements
+// into val, which must be a non-nil pointer. It is the inverse of ListElements.
+func DecodeListElements(elems []RawValue, val interface{}) error {
+	enc, err := EncodeToBytes(elems)
+	if err != nil {
+		return err
+	}
+	return DecodeBytes(enc, val)
+}
+
 // CountValues counts the number of encoded values in b.
 func CountValues(b []byte) (int, error) {
 	i := 0
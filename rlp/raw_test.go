@@ -194,3 +194,31 @@ func TestReadSize(t *testing.T) {
 		}
 	}
 }
+
+func TestListElements(t *testing.T) {
+	type pair struct {
+		A uint
+		B []byte
+	}
+	elems, err := ListElements(pair{A: 1, B: []byte{2, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RawValue{unhex("01"), unhex("820203")}
+	if !reflect.DeepEqual(elems, want) {
+		t.Errorf("elements mismatch: got %x, want %x", elems, want)
+	}
+	var dec pair
+	if err := DecodeListElements(elems, &dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.A != 1 || !bytes.Equal(dec.B, []byte{2, 3}) {
+		t.Errorf("decoded value mismatch: got %+v", dec)
+	}
+	if _, err := ListElements(uint(1)); err == nil {
+		t.Errorf("non-list value accepted")
+	}
+	if err := DecodeListElements(elems[:1], &dec); err == nil {
+		t.Errorf("too few elements accepted")
+	}
+}